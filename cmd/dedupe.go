@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"droscher.com/BeerGargoyle/configs"
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+)
+
+type DedupeCmd struct {
+	ConfigFile string `default:".BeerGargoyle.toml"                                   help:"Path to config file" short:"c"`
+	Apply      bool   `help:"Merge the duplicates found instead of only listing them"`
+}
+
+// Run finds duplicate breweries and beers, and merges them when asked to. Breweries are merged first, as merging them
+// also merges their beers with matching names.
+func (d *DedupeCmd) Run(_ *Context) error {
+	logConfig := zap.NewDevelopmentConfig()
+	logConfig.DisableStacktrace = true
+
+	logger, _ := logConfig.Build()
+	defer logger.Sync() //nolint:errcheck // we don't care about logger sync errors
+
+	conf, err := configs.GetConfig(d.ConfigFile, logger)
+	if err != nil {
+		logger.Error("error loading config", zap.Error(err))
+
+		return err
+	}
+
+	repo, err := repository.Open(conf, logger)
+	if err != nil {
+		logger.Error("error connecting to database", zap.Error(err))
+
+		return err
+	}
+	defer repo.Close()
+
+	ctx := context.Background()
+
+	breweries, err := repo.FindDuplicateBreweries(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = d.merge(logger, "brewery", breweries, func(keepID uint, mergeID uint) error {
+		_, err := repo.MergeBreweries(ctx, keepID, mergeID)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	beers, err := repo.FindDuplicateBeers(ctx)
+	if err != nil {
+		return err
+	}
+
+	return d.merge(logger, "beer", beers, func(keepID uint, mergeID uint) error {
+		_, err := repo.MergeBeers(ctx, keepID, mergeID)
+
+		return err
+	})
+}
+
+// merge prints the candidates, and merges them when applying. A row can appear in several candidates, so rows already
+// merged are replaced by the row they were merged into.
+func (d *DedupeCmd) merge(logger *zap.Logger, kind string, candidates []model.DuplicateCandidate, merge func(keepID uint, mergeID uint) error) error {
+	mergedInto := make(map[uint]uint)
+
+	survivor := func(id uint) uint {
+		for {
+			next, merged := mergedInto[id]
+			if !merged {
+				return id
+			}
+
+			id = next
+		}
+	}
+
+	for _, candidate := range candidates {
+		fmt.Printf("%s %d %q <- %d %q (%s)\n", kind, candidate.KeepID, candidate.KeepName, candidate.MergeID, candidate.MergeName, candidate.Reason)
+
+		if !d.Apply {
+			continue
+		}
+
+		keepID, mergeID := survivor(candidate.KeepID), survivor(candidate.MergeID)
+		if keepID == mergeID {
+			continue
+		}
+
+		keepID, mergeID = min(keepID, mergeID), max(keepID, mergeID)
+
+		err := merge(keepID, mergeID)
+		if err != nil {
+			return fmt.Errorf("merging %s %d into %d: %w", kind, mergeID, keepID, err)
+		}
+
+		mergedInto[mergeID] = keepID
+
+		logger.Info("merged", zap.String("kind", kind), zap.Uint("keep_id", keepID), zap.Uint("merge_id", mergeID))
+	}
+
+	return nil
+}
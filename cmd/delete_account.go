@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+
+	"droscher.com/BeerGargoyle/configs"
+	"droscher.com/BeerGargoyle/pkg/repository"
+)
+
+const exportFileMode = 0o600
+
+type DeleteAccountCmd struct {
+	ConfigFile string `default:".BeerGargoyle.toml"               help:"Path to config file"                    short:"c"`
+	Email      string `arg:""                                     help:"Email address of the account to delete"`
+	Output     string `help:"Export file, <uuid>.json by default" short:"o"`
+	DryRun     bool   `help:"Only write the export"`
+}
+
+func (d *DeleteAccountCmd) Run(_ *Context) error {
+	logConfig := zap.NewDevelopmentConfig()
+	logConfig.DisableStacktrace = true
+
+	logger, _ := logConfig.Build()
+	defer logger.Sync() //nolint:errcheck // we don't care about logger sync errors
+
+	conf, err := configs.GetConfig(d.ConfigFile, logger)
+	if err != nil {
+		logger.Error("error loading config", zap.Error(err))
+
+		return err
+	}
+
+	repo, err := repository.Open(conf, logger)
+	if err != nil {
+		logger.Error("error connecting to database", zap.Error(err))
+
+		return err
+	}
+	defer repo.Close()
+
+	ctx := context.Background()
+
+	user, err := repo.GetUserFromEmail(ctx, d.Email)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, d.Email)
+	}
+
+	export, err := repo.ExportAccount(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	output := d.Output
+	if output == "" {
+		output = user.UUID.String() + ".json"
+	}
+
+	// The export is written before anything is deleted, so the data is never lost if writing it fails.
+	err = os.WriteFile(output, data, exportFileMode)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("account exported", zap.String("email", user.Email), zap.String("file", output))
+
+	if d.DryRun {
+		return nil
+	}
+
+	err = repo.DeleteAccount(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("account deleted", zap.String("email", user.Email), zap.Uint("user_id", user.ID))
+
+	return nil
+}
@@ -1,46 +1,125 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+
 	"go.uber.org/zap"
 
 	"droscher.com/BeerGargoyle/configs"
-	"droscher.com/BeerGargoyle/pkg/model"
 	"droscher.com/BeerGargoyle/pkg/repository"
 )
 
 type MigrateCmd struct {
 	ConfigFile string `default:".BeerGargoyle.toml" help:"Path to config file" short:"c"`
+
+	Up     MigrateUpCmd     `cmd:"" default:"1"                                             help:"Apply all pending migrations"`
+	Down   MigrateDownCmd   `cmd:"" help:"Roll back the most recent migrations"`
+	Status MigrateStatusCmd `cmd:"" help:"List the migrations and whether they are applied"`
+	To     MigrateToCmd     `cmd:"" help:"Apply or roll back migrations up to a version"`
 }
 
-func (m *MigrateCmd) Run(_ *Context) error {
-	logConfig := zap.NewDevelopmentConfig()
-	logConfig.DisableStacktrace = true
+type MigrateUpCmd struct{}
 
-	logger, _ := logConfig.Build()
-	defer logger.Sync() //nolint:errcheck // we don't care about logger sync errors
+type MigrateDownCmd struct {
+	Steps int `default:"1" help:"Number of migrations to roll back"`
+}
 
-	conf, err := configs.GetConfig(m.ConfigFile, logger)
+type MigrateStatusCmd struct{}
+
+type MigrateToCmd struct {
+	Version uint `arg:"" help:"Version to migrate to, 0 rolls back all migrations"`
+}
+
+func (m *MigrateUpCmd) Run(_ *Context, migrate *MigrateCmd) error {
+	return migrate.run(func(ctx context.Context, repo *repository.Repository) ([]repository.Migration, error) {
+		return repo.MigrateUp(ctx)
+	})
+}
+
+func (m *MigrateDownCmd) Run(_ *Context, migrate *MigrateCmd) error {
+	return migrate.run(func(ctx context.Context, repo *repository.Repository) ([]repository.Migration, error) {
+		return repo.MigrateDown(ctx, m.Steps)
+	})
+}
+
+func (m *MigrateToCmd) Run(_ *Context, migrate *MigrateCmd) error {
+	return migrate.run(func(ctx context.Context, repo *repository.Repository) ([]repository.Migration, error) {
+		return repo.MigrateTo(ctx, m.Version)
+	})
+}
+
+func (m *MigrateStatusCmd) Run(_ *Context, migrate *MigrateCmd) error {
+	logger, repo, err := migrate.open()
 	if err != nil {
-		logger.Error("error loading config", zap.Error(err))
+		return err
+	}
+	defer logger.Sync() //nolint:errcheck // we don't care about logger sync errors
+	defer repo.Close()
 
+	statuses, err := repo.MigrationStatus(context.Background())
+	if err != nil {
 		return err
 	}
 
-	repo, err := repository.Open(conf, logger)
+	for _, status := range statuses {
+		state := "pending"
+
+		switch {
+		case status.AppliedAt != nil && len(status.Up) == 0:
+			state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05") + ", unknown to this build"
+		case status.AppliedAt != nil:
+			state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+
+		fmt.Printf("%04d %s: %s\n", status.Version, status.Name, state)
+	}
+
+	return nil
+}
+
+// run migrates with the given function. The repository logs each migration it runs.
+func (m *MigrateCmd) run(migrate func(ctx context.Context, repo *repository.Repository) ([]repository.Migration, error)) error {
+	logger, repo, err := m.open()
 	if err != nil {
-		logger.Fatal("error connecting to database")
+		return err
 	}
+	defer logger.Sync() //nolint:errcheck // we don't care about logger sync errors
 	defer repo.Close()
 
-	err = repo.DB.AutoMigrate(
-		&model.Address{}, &model.Brewery{},
-		&model.BeerStyle{}, &model.BeerFormat{}, &model.Beer{},
-		&model.User{},
-		&model.Cellar{}, &model.LocationInCellar{}, &model.CellarEntry{},
-		&model.AdventCalendar{}, &model.AdventCalendarBeer{}, &model.AdventCalendarFilter{})
+	migrations, err := migrate(context.Background(), repo)
 	if err != nil {
+		logger.Error("error migrating", zap.Error(err))
+
 		return err
 	}
 
+	if len(migrations) == 0 {
+		fmt.Println("nothing to migrate")
+	}
+
 	return nil
 }
+
+func (m *MigrateCmd) open() (*zap.Logger, *repository.Repository, error) {
+	logConfig := zap.NewDevelopmentConfig()
+	logConfig.DisableStacktrace = true
+
+	logger, _ := logConfig.Build()
+
+	conf, err := configs.GetConfig(m.ConfigFile, logger)
+	if err != nil {
+		logger.Error("error loading config", zap.Error(err))
+
+		return nil, nil, err
+	}
+
+	repo, err := repository.Open(conf, logger)
+	if err != nil {
+		logger.Error("error connecting to database", zap.Error(err))
+
+		return nil, nil, err
+	}
+
+	return logger, repo, nil
+}
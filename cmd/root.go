@@ -7,6 +7,9 @@ type Context struct {
 var CLI struct {
 	Debug bool `help:"Enable debug mode"`
 
-	Serve   ServeCmd   `cmd:"" default:"1"                    help:"Run the server"`
-	Migrate MigrateCmd `cmd:"" help:"Run database migrations"`
+	Serve         ServeCmd         `cmd:"" default:"1"                                                      help:"Run the server"`
+	Migrate       MigrateCmd       `cmd:"" help:"Run database migrations"`
+	DeleteAccount DeleteAccountCmd `cmd:"" help:"Export and permanently delete an account"`
+	Dedupe        DedupeCmd        `cmd:"" help:"Find, and optionally merge, duplicate beers and breweries"`
+	Seed          SeedCmd          `cmd:"" help:"Load the reference formats, styles and tags"`
 }
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"droscher.com/BeerGargoyle/configs"
+	"droscher.com/BeerGargoyle/data"
+	"droscher.com/BeerGargoyle/pkg/repository"
+)
+
+type SeedCmd struct {
+	ConfigFile string `default:".BeerGargoyle.toml"                             help:"Path to config file" short:"c"`
+	DryRun     bool   `help:"Only print the reference data that would be added"`
+}
+
+// Run loads the embedded reference data, adding the formats, styles and tags missing from the catalog. Running it
+// again only adds what is still missing.
+func (s *SeedCmd) Run(_ *Context) error {
+	logConfig := zap.NewDevelopmentConfig()
+	logConfig.DisableStacktrace = true
+
+	logger, _ := logConfig.Build()
+	defer logger.Sync() //nolint:errcheck // we don't care about logger sync errors
+
+	conf, err := configs.GetConfig(s.ConfigFile, logger)
+	if err != nil {
+		logger.Error("error loading config", zap.Error(err))
+
+		return err
+	}
+
+	seed, err := data.Load()
+	if err != nil {
+		logger.Error("error loading seed data", zap.Error(err))
+
+		return err
+	}
+
+	repo, err := repository.Open(conf, logger)
+	if err != nil {
+		logger.Error("error connecting to database", zap.Error(err))
+
+		return err
+	}
+	defer repo.Close()
+
+	missing, err := repo.Seed(context.Background(), seed, s.DryRun)
+	if err != nil {
+		return err
+	}
+
+	for _, format := range missing.Formats {
+		fmt.Printf("format %s %gml (%goz)\n", format.Package, format.SizeMetric, format.SizeImperial)
+	}
+
+	for _, style := range missing.Styles {
+		if style.Parent != nil {
+			fmt.Printf("style %q in %q\n", style.Name, style.Parent.Name)
+		} else {
+			fmt.Printf("style family %q\n", style.Name)
+		}
+	}
+
+	for _, tag := range missing.Tags {
+		fmt.Printf("tag %q\n", tag.Tag)
+	}
+
+	action := "added"
+	if s.DryRun {
+		action = "would be added"
+	}
+
+	fmt.Printf("%d formats, %d styles and %d tags %s\n", len(missing.Formats), len(missing.Styles), len(missing.Tags), action)
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"time"
@@ -15,6 +16,7 @@ import (
 
 	"droscher.com/BeerGargoyle/configs"
 	"droscher.com/BeerGargoyle/pkg/auth"
+	"droscher.com/BeerGargoyle/pkg/events"
 	"droscher.com/BeerGargoyle/pkg/repository"
 	"droscher.com/BeerGargoyle/pkg/server"
 	"droscher.com/BeerGargoyle/pkg/server/grpc/api/v1/apiv1connect"
@@ -47,8 +49,26 @@ func (s *ServeCmd) Run(_ *Context) error {
 	}
 	defer repo.Close()
 
-	authManager := auth.NewAuthManager(conf, repo, logger)
-	interceptors := connect.WithInterceptors(authManager.GrpcAuthInterceptor())
+	authManager, err := auth.NewAuthManager(conf, repo, logger)
+	if err != nil {
+		logger.Error("error configuring authentication", zap.Error(err))
+
+		return err
+	}
+
+	interceptors := connect.WithInterceptors(authManager, server.NewErrorInterceptor(logger))
+
+	var notifier events.Notifier
+	if conf.Events.Postgres {
+		notifier = repo
+	}
+
+	broker := events.NewBroker(notifier, conf.Events.BufferSize, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go broker.Run(ctx)
 
 	mux := http.NewServeMux()
 
@@ -58,11 +78,14 @@ func (s *ServeCmd) Run(_ *Context) error {
 	path, handler = apiv1connect.NewUserServiceHandler(server.NewUserServer(repo, logger), interceptors)
 	mux.Handle(path, handler)
 
-	path, handler = apiv1connect.NewCellarServiceHandler(server.NewCellarServer(repo, repo, repo, logger), interceptors)
+	path, handler = apiv1connect.NewCellarServiceHandler(server.NewCellarServer(repo, repo, repo, broker, logger, conf), interceptors)
+	mux.Handle(path, handler)
+
+	path, handler = apiv1connect.NewTagServiceHandler(server.NewTagServer(repo, logger, conf), interceptors)
 	mux.Handle(path, handler)
 
-	reflector := grpcreflect.NewStaticReflector(grpchealth.HealthV1ServiceName, apiv1connect.BeerServiceName, apiv1connect.UserServiceName, apiv1connect.CellarServiceName)
-	checker := grpchealth.NewStaticChecker(apiv1connect.BeerServiceName, apiv1connect.UserServiceName, apiv1connect.CellarServiceName)
+	reflector := grpcreflect.NewStaticReflector(grpchealth.HealthV1ServiceName, apiv1connect.BeerServiceName, apiv1connect.UserServiceName, apiv1connect.CellarServiceName, apiv1connect.TagServiceName)
+	checker := grpchealth.NewStaticChecker(apiv1connect.BeerServiceName, apiv1connect.UserServiceName, apiv1connect.CellarServiceName, apiv1connect.TagServiceName)
 	mux.Handle(grpchealth.NewHandler(checker))
 	mux.Handle(grpcreflect.NewHandlerV1(reflector))
 	mux.Handle(grpcreflect.NewHandlerV1Alpha(reflector))
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/kkyr/fig"
 	"go.uber.org/zap"
@@ -27,17 +28,52 @@ type Integrations struct {
 	Beer []string `default:"untappd_web"`
 }
 
+// Tags controls how tag names are cleaned up before tags are looked up or created.
+type Tags struct {
+	// FoldCase lower-cases tag names, so that "Sour" and "sour" are the same tag.
+	FoldCase bool
+	// TrimSpace removes leading and trailing whitespace, and collapses runs of whitespace into a single space.
+	TrimSpace bool
+}
+
+// Events controls how changes to cellars are delivered to the clients watching them.
+type Events struct {
+	// Postgres relays events between instances of the server through LISTEN/NOTIFY, so that watchers see changes made
+	// through any instance. Without it, watchers only see changes made through the instance they are connected to.
+	Postgres bool
+	// BufferSize is the number of events a watcher can fall behind by before its stream is ended.
+	BufferSize int `default:"64"`
+}
+
 type Config struct {
 	DB           DB
 	Server       Server
 	Integrations Integrations
 	Auth         Auth
+	Tags         Tags
+	Events       Events
 }
 
 type Auth struct {
 	SecretKey string
 	Audience  string
 	Domain    string
+	// Issuer is the expected "iss" claim of access tokens. When the keys come from a JWKS it defaults to
+	// https://<Domain>/ if a domain is set, tokens signed with the SecretKey are only checked against an explicit issuer.
+	Issuer string
+	// JWKSURL and JWKSFile select where the keys for RS256/ES256 tokens are read from. When neither is set and there
+	// is no SecretKey, the JWKS is located through the issuer's OpenID discovery document.
+	JWKSURL             string
+	JWKSFile            string
+	JWKSCacheTTL        time.Duration `default:"1h"`
+	JWKSRefreshInterval time.Duration `default:"1m"`
+	// AutoProvision creates users from their token claims the first time they sign in, and keeps their names in sync.
+	AutoProvision bool
+	// Procedures listed here override the access policy built into the server, e.g. "/api.v1.UserService/AddUser".
+	PublicProcedures        []string
+	AuthenticatedProcedures []string
+	AdminProcedures         []string
+	AdminEmails             []string
 }
 
 const envPrefix = "BEERGARGOYLE" // env prefix for env vars
@@ -2,6 +2,7 @@ package configs_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/zap/zaptest"
@@ -34,7 +35,13 @@ func (suite *ConfigTestSuite) TestGetConfig_GetsNamedFile() {
 	suite.Equal("audience", config.Auth.Audience)
 	suite.Equal("domain", config.Auth.Domain)
 	suite.Equal("secret", config.Auth.SecretKey)
+	suite.Equal(time.Hour, config.Auth.JWKSCacheTTL)
+	suite.Equal(time.Minute, config.Auth.JWKSRefreshInterval)
 	suite.Equal([]string{"untappd_web"}, config.Integrations.Beer)
+	suite.True(config.Tags.FoldCase)
+	suite.True(config.Tags.TrimSpace)
+	suite.True(config.Events.Postgres)
+	suite.Equal(16, config.Events.BufferSize)
 }
 
 func (suite *ConfigTestSuite) TestGetConfig_GetsEnv() {
@@ -51,6 +58,8 @@ func (suite *ConfigTestSuite) TestGetConfig_GetsEnv() {
 	suite.T().Setenv("BEERGARGOYLE_AUTH_AUDIENCE", "audience")
 	suite.T().Setenv("BEERGARGOYLE_AUTH_DOMAIN", "domain")
 	suite.T().Setenv("BEERGARGOYLE_AUTH_SECRETKEY", "secret")
+	suite.T().Setenv("BEERGARGOYLE_AUTH_ISSUER", "https://issuer.local/")
+	suite.T().Setenv("BEERGARGOYLE_AUTH_JWKSURL", "https://issuer.local/jwks.json")
 	suite.T().Setenv("BEERGARGOYLE_INTEGRATIONS_BEER", "untappd_web")
 
 	config, err := configs.GetConfig("", logger)
@@ -67,6 +76,8 @@ func (suite *ConfigTestSuite) TestGetConfig_GetsEnv() {
 	suite.Equal("audience", config.Auth.Audience)
 	suite.Equal("domain", config.Auth.Domain)
 	suite.Equal("secret", config.Auth.SecretKey)
+	suite.Equal("https://issuer.local/", config.Auth.Issuer)
+	suite.Equal("https://issuer.local/jwks.json", config.Auth.JWKSURL)
 	suite.Equal([]string{"untappd_web"}, config.Integrations.Beer)
 }
 
@@ -1,25 +0,0 @@
-INSERT INTO beer_formats(package, size_metric, size_imperial)
-VALUES
-    ('Bottle', 180, 6.1),
-    ('Bottle', 250, 8.5),
-    ('Bottle', 275, 9.3),
-    ('Bottle', 330, 11.2),
-    ('Bottle', 341, 11.5),
-    ('Bottle', 355, 12),
-    ('Bottle', 375, 12.7),
-    ('Bottle', 500, 16.9),
-    ('Bottle', 650, 22),
-    ('Bottle', 750, 25.4),
-    ('Growler', 946, 32),
-    ('Growler', 1000, 33.8),
-    ('Growler', 1893, 64),
-    ('Growler', 2000, 67.6),
-    ('Magnum', 1500, 50.8),
-    ('Can', 237, 8),
-    ('Can', 330, 11.2),
-    ('Can', 355, 12),
-    ('Can', 473, 16),
-    ('Crowler', 946, 32);
-
-
-
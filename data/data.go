@@ -0,0 +1,121 @@
+// Package data embeds the reference data the seed command loads into the catalog.
+package data
+
+import (
+	"embed"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+)
+
+//go:embed formats.csv styles.json tags.txt
+var files embed.FS
+
+var ErrInvalidData = errors.New("invalid seed data")
+
+// styleCategory groups beer styles in a family, following the BJCP style guidelines.
+type styleCategory struct {
+	Category string   `json:"category"`
+	Styles   []string `json:"styles"`
+}
+
+// Load reads all the embedded reference data.
+func Load() (model.SeedData, error) {
+	formats, err := loadFormats()
+	if err != nil {
+		return model.SeedData{}, err
+	}
+
+	styles, err := loadStyles()
+	if err != nil {
+		return model.SeedData{}, err
+	}
+
+	tags, err := loadTags()
+	if err != nil {
+		return model.SeedData{}, err
+	}
+
+	return model.SeedData{Formats: formats, Styles: styles, Tags: tags}, nil
+}
+
+func loadFormats() ([]model.BeerFormat, error) {
+	file, err := files.Open("formats.csv")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	formats := make([]model.BeerFormat, 0, len(records))
+
+	// The first record is the header.
+	for index, record := range records[1:] {
+		metric, metricErr := strconv.ParseFloat(record[1], 64)
+		imperial, imperialErr := strconv.ParseFloat(record[2], 64)
+
+		if err := errors.Join(metricErr, imperialErr); err != nil {
+			return nil, fmt.Errorf("%w: formats.csv line %d: %w", ErrInvalidData, index+2, err)
+		}
+
+		formats = append(formats, model.BeerFormat{Package: record[0], SizeMetric: metric, SizeImperial: imperial})
+	}
+
+	return formats, nil
+}
+
+func loadStyles() ([]model.BeerStyle, error) {
+	content, err := files.ReadFile("styles.json")
+	if err != nil {
+		return nil, err
+	}
+
+	var categories []styleCategory
+
+	err = json.Unmarshal(content, &categories)
+	if err != nil {
+		return nil, fmt.Errorf("%w: styles.json: %w", ErrInvalidData, err)
+	}
+
+	var styles []model.BeerStyle
+
+	// Each category is the family of its styles. A style named after its category, such as "Fruit Beer", is the
+	// family itself.
+	for _, category := range categories {
+		styles = append(styles, model.BeerStyle{Name: category.Category})
+
+		for _, style := range category.Styles {
+			if style != category.Category {
+				styles = append(styles, model.BeerStyle{Name: style, Parent: &model.BeerStyle{Name: category.Category}})
+			}
+		}
+	}
+
+	return styles, nil
+}
+
+func loadTags() ([]model.Tag, error) {
+	content, err := files.ReadFile("tags.txt")
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []model.Tag
+
+	for _, line := range strings.Split(string(content), "\n") {
+		if tag := strings.TrimSpace(line); len(tag) > 0 {
+			tags = append(tags, model.Tag{Tag: tag})
+		}
+	}
+
+	return tags, nil
+}
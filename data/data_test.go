@@ -0,0 +1,65 @@
+package data_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"droscher.com/BeerGargoyle/data"
+)
+
+type DataTestSuite struct {
+	suite.Suite
+}
+
+func TestDataTestSuite(t *testing.T) {
+	suite.Run(t, new(DataTestSuite))
+}
+
+func (suite *DataTestSuite) TestLoad_LoadsAllData() {
+	seed, err := data.Load()
+
+	suite.Require().NoError(err)
+	suite.Require().NotEmpty(seed.Formats)
+	suite.Equal("Bottle", seed.Formats[0].Package)
+	suite.InDelta(180.0, seed.Formats[0].SizeMetric, 0.01)
+	suite.InDelta(6.1, seed.Formats[0].SizeImperial, 0.01)
+	suite.NotEmpty(seed.Styles)
+	suite.NotEmpty(seed.Tags)
+}
+
+func (suite *DataTestSuite) TestLoad_HasNoDuplicates() {
+	seed, err := data.Load()
+	suite.Require().NoError(err)
+
+	styles := make(map[string]struct{}, len(seed.Styles))
+	for _, style := range seed.Styles {
+		suite.NotContains(styles, style.Name)
+		styles[style.Name] = struct{}{}
+	}
+
+	tags := make(map[string]struct{}, len(seed.Tags))
+	for _, tag := range seed.Tags {
+		suite.NotContains(tags, tag.Tag)
+		tags[tag.Tag] = struct{}{}
+	}
+}
+
+func (suite *DataTestSuite) TestLoad_StylesHaveFamilies() {
+	seed, err := data.Load()
+	suite.Require().NoError(err)
+
+	families := make(map[string]struct{})
+
+	for _, style := range seed.Styles {
+		if style.Parent == nil {
+			families[style.Name] = struct{}{}
+		} else {
+			// Families come before their styles, so that they can be added first.
+			suite.Contains(families, style.Parent.Name, style.Name)
+		}
+	}
+
+	suite.Contains(families, "IPA")
+	suite.Contains(families, "Fruit Beer")
+}
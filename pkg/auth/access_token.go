@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	connect_go "github.com/bufbuild/connect-go"
+	"go.uber.org/zap"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+	"droscher.com/BeerGargoyle/pkg/server/grpc/api/v1/apiv1connect"
+)
+
+const (
+	// AccessTokenPrefix starts every personal access token, which tells them apart from JWTs.
+	AccessTokenPrefix = "bgpat_"
+	// accessTokenDisplayLength is the number of characters of a token kept in clear so users can recognise it.
+	accessTokenDisplayLength = len(AccessTokenPrefix) + 6
+	accessTokenBytes         = 32
+	// lastUsedInterval limits how often the last used time of a token is written, so that scripts making many calls
+	// do not cause a write for each one.
+	lastUsedInterval = time.Minute
+)
+
+// NewAccessToken generates the secret for a personal access token, returning the secret, the prefix that is stored in
+// clear and the hash that is stored in place of the secret.
+func NewAccessToken() (string, string, string, error) {
+	data := make([]byte, accessTokenBytes)
+
+	_, err := rand.Read(data)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	secret := AccessTokenPrefix + base64.RawURLEncoding.EncodeToString(data)
+
+	return secret, secret[:accessTokenDisplayLength], HashAccessToken(secret), nil
+}
+
+func HashAccessToken(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(hash[:])
+}
+
+var (
+	ErrInvalidAccessToken    = errors.New("invalid access token")
+	ErrAccessTokenExpired    = errors.New("access token has expired")
+	ErrReadOnlyAccessToken   = errors.New("read-only access token cannot call procedure")
+	ErrAccessTokenNotAllowed = errors.New("access tokens cannot be used to create access tokens")
+)
+
+func isAccessToken(token string) bool {
+	return strings.HasPrefix(token, AccessTokenPrefix)
+}
+
+// userFromAccessToken finds the user a personal access token belongs to and checks that the token may be used for the
+// procedure. Read-only tokens can only call procedures marked as having no side effects, and no token can be used to
+// create further tokens.
+func (a *Manager) userFromAccessToken(ctx context.Context, spec connect_go.Spec, secret string) (*model.User, error) {
+	token, err := a.repo.GetAccessTokenByHash(ctx, HashAccessToken(secret))
+	if err != nil {
+		if errors.Is(err, repository.ErrAccessTokenNotFound) {
+			return nil, connect_go.NewError(connect_go.CodeUnauthenticated, ErrInvalidAccessToken)
+		}
+
+		a.logger.Error("error finding access token", zap.Error(err))
+
+		return nil, connect_go.NewError(connect_go.CodeInternal, ErrAuthentication)
+	}
+
+	now := time.Now()
+	if token.Expired(now) {
+		return nil, connect_go.NewError(connect_go.CodeUnauthenticated, ErrAccessTokenExpired)
+	}
+
+	if token.Scope != model.AccessTokenScopeReadWrite && spec.IdempotencyLevel != connect_go.IdempotencyNoSideEffects {
+		return nil, connect_go.NewError(connect_go.CodePermissionDenied,
+			fmt.Errorf("%w: %s", ErrReadOnlyAccessToken, spec.Procedure))
+	}
+
+	if spec.Procedure == apiv1connect.UserServiceCreateAccessTokenProcedure {
+		return nil, connect_go.NewError(connect_go.CodePermissionDenied, ErrAccessTokenNotAllowed)
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastUsedInterval {
+		err = a.repo.TouchAccessToken(ctx, token.ID, now)
+		if err != nil {
+			a.logger.Warn("error recording access token use", zap.Uint("token_id", token.ID), zap.Error(err))
+		}
+	}
+
+	return &token.User, nil
+}
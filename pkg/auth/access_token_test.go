@@ -0,0 +1,105 @@
+package auth_test
+
+import (
+	"strings"
+	"time"
+
+	connect_go "github.com/bufbuild/connect-go"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/configs"
+	"droscher.com/BeerGargoyle/pkg/auth"
+	"droscher.com/BeerGargoyle/pkg/model"
+)
+
+const (
+	readOnlySecret  = auth.AccessTokenPrefix + "read-only"
+	readWriteSecret = auth.AccessTokenPrefix + "read-write"
+	expiredSecret   = auth.AccessTokenPrefix + "expired"
+)
+
+func (suite *AuthTestSuite) accessTokenRepository() *fakeUserRepository {
+	user := model.User{Model: gorm.Model{ID: 1}, Email: "test@example.com"}
+	expired := time.Now().Add(-time.Hour)
+
+	return &fakeUserRepository{
+		users: []*model.User{&user},
+		tokens: []*model.AccessToken{
+			{Model: gorm.Model{ID: 1}, User: user, Hash: auth.HashAccessToken(readOnlySecret), Scope: model.AccessTokenScopeReadOnly},
+			{Model: gorm.Model{ID: 2}, User: user, Hash: auth.HashAccessToken(readWriteSecret), Scope: model.AccessTokenScopeReadWrite},
+			{
+				Model: gorm.Model{ID: 3}, User: user, Hash: auth.HashAccessToken(expiredSecret),
+				Scope: model.AccessTokenScopeReadWrite, ExpiresAt: &expired,
+			},
+		},
+	}
+}
+
+func (suite *AuthTestSuite) TestNewAccessToken() {
+	secret, prefix, hash, err := auth.NewAccessToken()
+
+	suite.Require().NoError(err)
+	suite.True(strings.HasPrefix(secret, auth.AccessTokenPrefix))
+	suite.True(strings.HasPrefix(secret, prefix))
+	suite.Less(len(prefix), len(secret))
+	suite.Equal(auth.HashAccessToken(secret), hash)
+	suite.NotContains(hash, secret)
+
+	other, _, _, err := auth.NewAccessToken()
+	suite.Require().NoError(err)
+	suite.NotEqual(secret, other)
+}
+
+func (suite *AuthTestSuite) TestAccessToken_ReadWriteToken() {
+	repo := suite.accessTokenRepository()
+	url := suite.testServerWithRepository(configs.Auth{}, repo)
+
+	email, err := suite.callUnary(url, "Bearer "+readWriteSecret)
+
+	suite.Require().NoError(err)
+	suite.Equal("test@example.com", email)
+	suite.NotNil(repo.tokens[1].LastUsedAt)
+}
+
+func (suite *AuthTestSuite) TestAccessToken_ReadOnlyTokenLimitedToReads() {
+	repo := suite.accessTokenRepository()
+	url := suite.testServerWithRepository(configs.Auth{}, repo)
+
+	email, err := suite.callProcedure(url, readProcedure, "Bearer "+readOnlySecret)
+	suite.Require().NoError(err)
+	suite.Equal("test@example.com", email)
+
+	_, err = suite.callUnary(url, "Bearer "+readOnlySecret)
+	suite.ErrorContains(err, auth.ErrReadOnlyAccessToken.Error())
+	suite.Equal(connect_go.CodePermissionDenied, connect_go.CodeOf(err))
+}
+
+func (suite *AuthTestSuite) TestAccessToken_LastUsedIsThrottled() {
+	repo := suite.accessTokenRepository()
+	url := suite.testServerWithRepository(configs.Auth{}, repo)
+
+	for range 3 {
+		_, err := suite.callProcedure(url, readProcedure, "Bearer "+readOnlySecret)
+		suite.Require().NoError(err)
+	}
+
+	suite.Equal(1, repo.touches)
+}
+
+func (suite *AuthTestSuite) TestAccessToken_UnknownToken() {
+	url := suite.testServerWithRepository(configs.Auth{}, suite.accessTokenRepository())
+
+	_, err := suite.callUnary(url, "Bearer "+auth.AccessTokenPrefix+"unknown")
+
+	suite.ErrorContains(err, auth.ErrInvalidAccessToken.Error())
+	suite.Equal(connect_go.CodeUnauthenticated, connect_go.CodeOf(err))
+}
+
+func (suite *AuthTestSuite) TestAccessToken_ExpiredToken() {
+	url := suite.testServerWithRepository(configs.Auth{}, suite.accessTokenRepository())
+
+	_, err := suite.callUnary(url, "Bearer "+expiredSecret)
+
+	suite.ErrorContains(err, auth.ErrAccessTokenExpired.Error())
+	suite.Equal(connect_go.CodeUnauthenticated, connect_go.CodeOf(err))
+}
@@ -2,87 +2,199 @@ package auth
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	connect_go "github.com/bufbuild/connect-go"
 	"github.com/golang-jwt/jwt/v4"
 	"go.uber.org/zap"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 
 	"droscher.com/BeerGargoyle/configs"
-	"droscher.com/BeerGargoyle/pkg/repository"
+	"droscher.com/BeerGargoyle/pkg/model"
 )
 
 type UserKey struct{}
 
+// ProfileKey holds the *model.User read from a verified token's claims for procedures that do not require an existing
+// user. The user is not saved, so only the profile fields are set.
+type ProfileKey struct{}
+
+var ErrInvalidClaims = errors.New("invalid token claims")
+
+// The errors returned to clients when a call is not authenticated or not allowed.
+var (
+	ErrNoAuthorization      = errors.New("authorization header not found")
+	ErrInvalidAuthorization = errors.New("authorization format must be Bearer {token}")
+	ErrInvalidToken         = errors.New("invalid token")
+	ErrAuthentication       = errors.New("error authenticating user")
+	ErrUserDisabled         = errors.New("user is disabled")
+	ErrAdminRequired        = errors.New("procedure requires an administrator")
+)
+
+var _ connect_go.Interceptor = (*Manager)(nil)
+
 type Manager struct {
 	conf   *configs.Config
-	repo   *repository.Repository
+	repo   userRepository
+	keys   KeyProvider
+	policy *Policy
 	logger *zap.Logger
 }
 
-func NewAuthManager(conf *configs.Config, repo *repository.Repository, logger *zap.Logger) *Manager {
-	return &Manager{conf: conf, repo: repo, logger: logger}
+func NewAuthManager(conf *configs.Config, repo userRepository, logger *zap.Logger) (*Manager, error) {
+	keys, err := NewKeyProvider(conf.Auth, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Manager{conf: conf, repo: repo, keys: keys, policy: NewPolicy(conf.Auth), logger: logger}, nil
 }
 
-func (a *Manager) GrpcAuthInterceptor() connect_go.UnaryInterceptorFunc {
-	return func(next connect_go.UnaryFunc) connect_go.UnaryFunc {
-		return func(ctx context.Context, req connect_go.AnyRequest) (connect_go.AnyResponse, error) {
-			keyFunc := func(token *jwt.Token) (interface{}, error) {
-				_, ok := token.Method.(*jwt.SigningMethodHMAC)
-				if !ok {
-					return nil, status.Errorf(codes.Unauthenticated, "unexpected signing method: %v", token.Header["alg"])
-				}
+// ValidateToken checks the token's signature and its standard claims. The issuer and audience are only checked when
+// they are configured, and tokens from an external issuer must carry an expiry.
+func (a *Manager) ValidateToken(ctx context.Context, accessToken string) (jwt.MapClaims, error) {
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		return a.keys.Key(ctx, token)
+	}
+
+	token, err := jwt.ParseWithClaims(accessToken, jwt.MapClaims{}, keyFunc)
+	if err != nil {
+		return nil, err
+	}
 
-				return []byte(a.conf.Auth.SecretKey), nil
-			}
+	claims, found := token.Claims.(jwt.MapClaims)
+	if !found || !token.Valid {
+		return nil, ErrInvalidClaims
+	}
 
-			accessToken, err := a.extractTokenFromHeader(req.Header())
-			if err != nil {
-				return nil, err
-			}
+	expectedIssuer := issuer(a.conf.Auth)
+	if expectedIssuer != "" {
+		if !claims.VerifyIssuer(expectedIssuer, true) {
+			return nil, fmt.Errorf("%w: unexpected issuer %v", ErrInvalidClaims, claims["iss"])
+		}
 
-			token, err := jwt.ParseWithClaims(*accessToken, jwt.MapClaims{}, keyFunc)
-			if err != nil {
-				a.logger.Error("error parsing token", zap.Error(err))
+		if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+			return nil, fmt.Errorf("%w: token has no expiry", ErrInvalidClaims)
+		}
+	}
 
-				return nil, status.Errorf(codes.Unauthenticated, "error parsing token: %v", err)
-			}
+	if a.conf.Auth.Audience != "" && !claims.VerifyAudience(a.conf.Auth.Audience, true) {
+		return nil, fmt.Errorf("%w: unexpected audience %v", ErrInvalidClaims, claims["aud"])
+	}
 
-			claims, found := token.Claims.(jwt.MapClaims)
-			if !found || !token.Valid {
-				a.logger.Error("invalid token", zap.Any("claims", claims))
+	return claims, nil
+}
 
-				return nil, status.Errorf(codes.Unauthenticated, "invalid token")
-			}
+// WrapUnary authenticates unary calls according to the procedure's access policy.
+func (a *Manager) WrapUnary(next connect_go.UnaryFunc) connect_go.UnaryFunc {
+	return func(ctx context.Context, req connect_go.AnyRequest) (connect_go.AnyResponse, error) {
+		ctx, err := a.authenticate(ctx, req.Spec(), req.Header())
+		if err != nil {
+			return nil, err
+		}
 
-			a.logger.Info("claims", zap.Any("claims", claims))
+		return next(ctx, req)
+	}
+}
 
-			userID, found := claims["email"].(string)
-			if !found {
-				a.logger.Error("unable to get user id from token", zap.Any("claims", claims))
+// WrapStreamingClient leaves outgoing streams alone, the server never calls other services with user credentials.
+func (a *Manager) WrapStreamingClient(next connect_go.StreamingClientFunc) connect_go.StreamingClientFunc {
+	return next
+}
 
-				return nil, status.Errorf(codes.Unauthenticated, "unable to get user id from token")
-			}
+// WrapStreamingHandler authenticates streams before the handler runs, using the headers sent when the stream opened.
+func (a *Manager) WrapStreamingHandler(next connect_go.StreamingHandlerFunc) connect_go.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect_go.StreamingHandlerConn) error {
+		ctx, err := a.authenticate(ctx, conn.Spec(), conn.RequestHeader())
+		if err != nil {
+			return err
+		}
 
-			user, err := a.repo.GetUserFromEmail(ctx, userID)
-			if err != nil {
-				a.logger.Error("error authenticating user", zap.Error(err))
+		return next(ctx, conn)
+	}
+}
 
-				return nil, status.Errorf(codes.Internal, "error authenticating user")
-			}
+// authenticate applies the access policy for the procedure, and returns a context carrying the authenticated user
+// unless the procedure is public.
+func (a *Manager) authenticate(ctx context.Context, spec connect_go.Spec, header http.Header) (context.Context, error) {
+	procedure := spec.Procedure
 
-			if user == nil {
-				return nil, status.Errorf(codes.NotFound, "user not found")
-			}
+	access := a.policy.Access(procedure)
+	if access == AccessPublic {
+		a.logger.Debug("allowing public procedure", zap.String("procedure", procedure))
 
-			ctx = context.WithValue(ctx, UserKey{}, user)
+		return ctx, nil
+	}
 
-			return next(ctx, req)
-		}
+	accessToken, err := a.extractTokenFromHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	if access == AccessVerifiedToken {
+		return a.profileFromToken(ctx, *accessToken)
+	}
+
+	user, err := a.userFromToken(ctx, spec, *accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Disabled {
+		a.logger.Warn("denying disabled user", zap.String("procedure", procedure), zap.Uint("user_id", user.ID))
+
+		return nil, connect_go.NewError(connect_go.CodePermissionDenied, ErrUserDisabled)
+	}
+
+	if access == AccessAdmin && !a.policy.IsAdmin(user) {
+		a.logger.Warn("denying admin procedure", zap.String("procedure", procedure), zap.Uint("user_id", user.ID))
+
+		return nil, connect_go.NewError(connect_go.CodePermissionDenied, fmt.Errorf("%w: %s", ErrAdminRequired, procedure))
+	}
+
+	a.logger.Debug("allowing procedure", zap.String("procedure", procedure), zap.String("access", string(access)),
+		zap.Uint("user_id", user.ID))
+
+	return context.WithValue(ctx, UserKey{}, user), nil
+}
+
+// userFromToken identifies the user from either a personal access token or a JWT.
+func (a *Manager) userFromToken(ctx context.Context, spec connect_go.Spec, accessToken string) (*model.User, error) {
+	if isAccessToken(accessToken) {
+		return a.userFromAccessToken(ctx, spec, accessToken)
+	}
+
+	claims, err := a.ValidateToken(ctx, accessToken)
+	if err != nil {
+		a.logger.Error("invalid token", zap.Error(err))
+
+		return nil, connect_go.NewError(connect_go.CodeUnauthenticated, fmt.Errorf("%w: %w", ErrInvalidToken, err))
 	}
+
+	a.logger.Debug("token validated", zap.Any("subject", claims["sub"]))
+
+	return a.userFromClaims(ctx, claims)
+}
+
+// profileFromToken returns a context carrying the profile from a sign-in token. Personal access tokens are not
+// accepted, as they can only be created by existing users.
+func (a *Manager) profileFromToken(ctx context.Context, accessToken string) (context.Context, error) {
+	claims, err := a.ValidateToken(ctx, accessToken)
+	if err != nil {
+		a.logger.Error("invalid token", zap.Error(err))
+
+		return nil, connect_go.NewError(connect_go.CodeUnauthenticated, fmt.Errorf("%w: %w", ErrInvalidToken, err))
+	}
+
+	profile := profileFromClaims(claims)
+	if profile.Email == "" {
+		return nil, connect_go.NewError(connect_go.CodeUnauthenticated, ErrNoUserInToken)
+	}
+
+	return context.WithValue(ctx, ProfileKey{}, &profile), nil
 }
 
 func (a *Manager) extractTokenFromHeader(header http.Header) (*string, error) {
@@ -90,7 +202,7 @@ func (a *Manager) extractTokenFromHeader(header http.Header) (*string, error) {
 	if len(authorization) == 0 {
 		a.logger.Error("No authorization header found")
 
-		return nil, status.Errorf(codes.Unauthenticated, "authorization header not found")
+		return nil, connect_go.NewError(connect_go.CodeUnauthenticated, ErrNoAuthorization)
 	}
 
 	prefix := "Bearer "
@@ -100,7 +212,7 @@ func (a *Manager) extractTokenFromHeader(header http.Header) (*string, error) {
 
 	token, found := strings.CutPrefix(authorization, prefix)
 	if !found {
-		return nil, status.Errorf(codes.Unauthenticated, "authorization format must be Bearer {token}")
+		return nil, connect_go.NewError(connect_go.CodeUnauthenticated, ErrInvalidAuthorization)
 	}
 
 	return &token, nil
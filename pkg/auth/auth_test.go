@@ -0,0 +1,355 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap/zaptest"
+
+	"droscher.com/BeerGargoyle/configs"
+	"droscher.com/BeerGargoyle/pkg/auth"
+)
+
+const (
+	testIssuer   = "https://issuer.test/"
+	testAudience = "beergargoyle"
+)
+
+type AuthTestSuite struct {
+	suite.Suite
+	rsaKey *rsa.PrivateKey
+	ecKey  *ecdsa.PrivateKey
+}
+
+func TestAuthTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthTestSuite))
+}
+
+func (suite *AuthTestSuite) SetupSuite() {
+	var err error
+
+	suite.rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	suite.Require().NoError(err)
+
+	suite.ecKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	suite.Require().NoError(err)
+}
+
+func (suite *AuthTestSuite) newManager(authConfig configs.Auth) *auth.Manager {
+	manager, err := auth.NewAuthManager(&configs.Config{Auth: authConfig}, nil, zaptest.NewLogger(suite.T()))
+	suite.Require().NoError(err)
+
+	return manager
+}
+
+func (suite *AuthTestSuite) claims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":   testIssuer,
+		"aud":   testAudience,
+		"email": "test@example.com",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"nbf":   time.Now().Add(-time.Minute).Unix(),
+	}
+}
+
+func (suite *AuthTestSuite) sign(method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	suite.Require().NoError(err)
+
+	return signed
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"alg": "RS256",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "EC",
+		"kid": kid,
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func (suite *AuthTestSuite) jwks(keys ...map[string]string) []byte {
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	suite.Require().NoError(err)
+
+	return data
+}
+
+func (suite *AuthTestSuite) jwksFile(keys ...map[string]string) string {
+	path := filepath.Join(suite.T().TempDir(), "jwks.json")
+	suite.Require().NoError(os.WriteFile(path, suite.jwks(keys...), 0o600))
+
+	return path
+}
+
+func (suite *AuthTestSuite) fileManager() *auth.Manager {
+	return suite.newManager(configs.Auth{
+		Issuer:   testIssuer,
+		Audience: testAudience,
+		JWKSFile: suite.jwksFile(rsaJWK("rsa-1", &suite.rsaKey.PublicKey), ecJWK("ec-1", &suite.ecKey.PublicKey)),
+	})
+}
+
+func (suite *AuthTestSuite) TestValidateToken_HMAC() {
+	manager := suite.newManager(configs.Auth{SecretKey: "secret"})
+	token := suite.sign(jwt.SigningMethodHS256, "", []byte("secret"), jwt.MapClaims{"email": "test@example.com"})
+
+	claims, err := manager.ValidateToken(context.Background(), token)
+
+	suite.Require().NoError(err)
+	suite.Equal("test@example.com", claims["email"])
+}
+
+func (suite *AuthTestSuite) TestValidateToken_HMACRejectsRSA() {
+	manager := suite.newManager(configs.Auth{SecretKey: "secret"})
+	token := suite.sign(jwt.SigningMethodRS256, "rsa-1", suite.rsaKey, suite.claims())
+
+	_, err := manager.ValidateToken(context.Background(), token)
+
+	suite.Require().ErrorIs(err, auth.ErrUnexpectedSigningMethod)
+}
+
+func (suite *AuthTestSuite) TestValidateToken_JWKSFileRS256() {
+	token := suite.sign(jwt.SigningMethodRS256, "rsa-1", suite.rsaKey, suite.claims())
+
+	claims, err := suite.fileManager().ValidateToken(context.Background(), token)
+
+	suite.Require().NoError(err)
+	suite.Equal("test@example.com", claims["email"])
+}
+
+func (suite *AuthTestSuite) TestValidateToken_JWKSFileES256() {
+	token := suite.sign(jwt.SigningMethodES256, "ec-1", suite.ecKey, suite.claims())
+
+	claims, err := suite.fileManager().ValidateToken(context.Background(), token)
+
+	suite.Require().NoError(err)
+	suite.Equal("test@example.com", claims["email"])
+}
+
+func (suite *AuthTestSuite) TestValidateToken_KeyAlgorithmMismatch() {
+	token := suite.sign(jwt.SigningMethodRS512, "rsa-1", suite.rsaKey, suite.claims())
+
+	_, err := suite.fileManager().ValidateToken(context.Background(), token)
+
+	suite.Require().ErrorIs(err, auth.ErrUnexpectedSigningMethod)
+}
+
+func (suite *AuthTestSuite) TestValidateToken_UnknownKey() {
+	token := suite.sign(jwt.SigningMethodRS256, "rsa-2", suite.rsaKey, suite.claims())
+
+	_, err := suite.fileManager().ValidateToken(context.Background(), token)
+
+	suite.Require().ErrorIs(err, auth.ErrUnknownKey)
+}
+
+func (suite *AuthTestSuite) TestValidateToken_WrongIssuer() {
+	claims := suite.claims()
+	claims["iss"] = "https://someone-else.test/"
+	token := suite.sign(jwt.SigningMethodRS256, "rsa-1", suite.rsaKey, claims)
+
+	_, err := suite.fileManager().ValidateToken(context.Background(), token)
+
+	suite.Require().ErrorIs(err, auth.ErrInvalidClaims)
+	suite.ErrorContains(err, "unexpected issuer")
+}
+
+func (suite *AuthTestSuite) TestValidateToken_WrongAudience() {
+	claims := suite.claims()
+	claims["aud"] = []string{"another-api"}
+	token := suite.sign(jwt.SigningMethodRS256, "rsa-1", suite.rsaKey, claims)
+
+	_, err := suite.fileManager().ValidateToken(context.Background(), token)
+
+	suite.Require().ErrorIs(err, auth.ErrInvalidClaims)
+	suite.ErrorContains(err, "unexpected audience")
+}
+
+func (suite *AuthTestSuite) TestValidateToken_Expired() {
+	claims := suite.claims()
+	claims["exp"] = time.Now().Add(-time.Minute).Unix()
+	token := suite.sign(jwt.SigningMethodRS256, "rsa-1", suite.rsaKey, claims)
+
+	_, err := suite.fileManager().ValidateToken(context.Background(), token)
+
+	suite.Require().ErrorIs(err, jwt.ErrTokenExpired)
+}
+
+func (suite *AuthTestSuite) TestValidateToken_NotYetValid() {
+	claims := suite.claims()
+	claims["nbf"] = time.Now().Add(time.Hour).Unix()
+	token := suite.sign(jwt.SigningMethodRS256, "rsa-1", suite.rsaKey, claims)
+
+	_, err := suite.fileManager().ValidateToken(context.Background(), token)
+
+	suite.Require().ErrorIs(err, jwt.ErrTokenNotValidYet)
+}
+
+func (suite *AuthTestSuite) TestValidateToken_MissingExpiry() {
+	claims := suite.claims()
+	delete(claims, "exp")
+	token := suite.sign(jwt.SigningMethodRS256, "rsa-1", suite.rsaKey, claims)
+
+	_, err := suite.fileManager().ValidateToken(context.Background(), token)
+
+	suite.Require().ErrorIs(err, auth.ErrInvalidClaims)
+}
+
+func (suite *AuthTestSuite) TestValidateToken_DiscoversAndRotatesKeys() {
+	rotatedKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	suite.Require().NoError(err)
+
+	var (
+		lock      sync.Mutex
+		jwks      = suite.jwks(rsaJWK("rsa-1", &suite.rsaKey.PublicKey))
+		jwksCalls int
+	)
+
+	mux := http.NewServeMux()
+	idp := httptest.NewServer(mux)
+	defer idp.Close()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"jwks_uri": "` + idp.URL + `/keys"}`))
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, _ *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		jwksCalls++
+		_, _ = w.Write(jwks)
+	})
+
+	manager := suite.newManager(configs.Auth{Issuer: idp.URL + "/", Audience: testAudience, JWKSCacheTTL: time.Hour})
+	claims := suite.claims()
+	claims["iss"] = idp.URL + "/"
+
+	_, err = manager.ValidateToken(context.Background(), suite.sign(jwt.SigningMethodRS256, "rsa-1", suite.rsaKey, claims))
+	suite.Require().NoError(err)
+
+	lock.Lock()
+	jwks = suite.jwks(rsaJWK("rsa-1", &suite.rsaKey.PublicKey), rsaJWK("rsa-2", &rotatedKey.PublicKey))
+	lock.Unlock()
+
+	_, err = manager.ValidateToken(context.Background(), suite.sign(jwt.SigningMethodRS256, "rsa-1", suite.rsaKey, claims))
+	suite.Require().NoError(err)
+
+	_, err = manager.ValidateToken(context.Background(), suite.sign(jwt.SigningMethodRS256, "rsa-2", rotatedKey, claims))
+	suite.Require().NoError(err)
+
+	suite.Equal(2, jwksCalls)
+}
+
+func (suite *AuthTestSuite) TestValidateToken_SlowReloadDoesNotBlockCachedKeys() {
+	var (
+		calls    int
+		fetching = make(chan struct{})
+		release  = make(chan struct{})
+	)
+
+	idp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		if calls > 1 {
+			close(fetching)
+			<-release
+		}
+
+		_, _ = w.Write(suite.jwks(rsaJWK("rsa-1", &suite.rsaKey.PublicKey)))
+	}))
+	defer idp.Close()
+
+	manager := suite.newManager(configs.Auth{Issuer: testIssuer, Audience: testAudience, JWKSURL: idp.URL, JWKSCacheTTL: time.Hour})
+	known := suite.sign(jwt.SigningMethodRS256, "rsa-1", suite.rsaKey, suite.claims())
+
+	_, err := manager.ValidateToken(context.Background(), known)
+	suite.Require().NoError(err)
+
+	unknownDone := make(chan error)
+
+	go func() {
+		_, err := manager.ValidateToken(context.Background(), suite.sign(jwt.SigningMethodRS256, "rsa-9", suite.rsaKey, suite.claims()))
+		unknownDone <- err
+	}()
+
+	<-fetching
+
+	_, err = manager.ValidateToken(context.Background(), known)
+	suite.Require().NoError(err)
+
+	close(release)
+	suite.Require().ErrorIs(<-unknownDone, auth.ErrUnknownKey)
+}
+
+func (suite *AuthTestSuite) TestValidateToken_FailingIdentityProviderRetriedPerInterval() {
+	var calls int
+
+	idp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer idp.Close()
+
+	manager := suite.newManager(configs.Auth{
+		Issuer:              testIssuer,
+		Audience:            testAudience,
+		JWKSURL:             idp.URL,
+		JWKSCacheTTL:        time.Hour,
+		JWKSRefreshInterval: time.Hour,
+	})
+	token := suite.sign(jwt.SigningMethodRS256, "rsa-1", suite.rsaKey, suite.claims())
+
+	for range 3 {
+		_, err := manager.ValidateToken(context.Background(), token)
+		suite.Require().ErrorIs(err, auth.ErrFetchingKeys)
+	}
+
+	suite.Equal(1, calls)
+}
+
+func (suite *AuthTestSuite) TestValidateToken_HMACIgnoresDomainIssuer() {
+	manager := suite.newManager(configs.Auth{SecretKey: "secret", Domain: "tenant.example.com"})
+	token := suite.sign(jwt.SigningMethodHS256, "", []byte("secret"), jwt.MapClaims{"email": "test@example.com"})
+
+	_, err := manager.ValidateToken(context.Background(), token)
+
+	suite.Require().NoError(err)
+}
+
+func (suite *AuthTestSuite) TestNewAuthManager_NoKeySource() {
+	manager, err := auth.NewAuthManager(&configs.Config{}, nil, zaptest.NewLogger(suite.T()))
+
+	suite.Require().ErrorIs(err, configs.ErrConfiguration)
+	suite.Nil(manager)
+}
@@ -0,0 +1,186 @@
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	connect_go "github.com/bufbuild/connect-go"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/configs"
+	"droscher.com/BeerGargoyle/pkg/auth"
+	"droscher.com/BeerGargoyle/pkg/model"
+)
+
+const (
+	unaryProcedure  = "/test.v1.TestService/Unary"
+	readProcedure   = "/test.v1.TestService/Read"
+	streamProcedure = "/test.v1.TestService/Stream"
+)
+
+func emailFromContext(ctx context.Context) *wrapperspb.StringValue {
+	user, found := ctx.Value(auth.UserKey{}).(*model.User)
+	if !found {
+		return wrapperspb.String("")
+	}
+
+	return wrapperspb.String(user.Email)
+}
+
+// testServer serves unary and server streaming procedures, all answering with the email of the user the interceptor
+// attached to the context.
+func (suite *AuthTestSuite) testServer(authConfig configs.Auth) string {
+	return suite.testServerWithRepository(authConfig, &fakeUserRepository{users: []*model.User{
+		{Model: gorm.Model{ID: 1}, Email: "test@example.com"},
+		{Model: gorm.Model{ID: 2}, Email: "admin@example.com"},
+	}})
+}
+
+func (suite *AuthTestSuite) testServerWithRepository(authConfig configs.Auth, repo *fakeUserRepository) string {
+	authConfig.SecretKey = "secret"
+
+	manager, err := auth.NewAuthManager(&configs.Config{Auth: authConfig}, repo, zaptest.NewLogger(suite.T()))
+	suite.Require().NoError(err)
+
+	interceptors := connect_go.WithInterceptors(manager)
+
+	mux := http.NewServeMux()
+	mux.Handle(unaryProcedure, connect_go.NewUnaryHandler(unaryProcedure,
+		func(ctx context.Context, _ *connect_go.Request[emptypb.Empty]) (*connect_go.Response[wrapperspb.StringValue], error) {
+			return connect_go.NewResponse(emailFromContext(ctx)), nil
+		}, interceptors))
+	mux.Handle(readProcedure, connect_go.NewUnaryHandler(readProcedure,
+		func(ctx context.Context, _ *connect_go.Request[emptypb.Empty]) (*connect_go.Response[wrapperspb.StringValue], error) {
+			return connect_go.NewResponse(emailFromContext(ctx)), nil
+		}, interceptors, connect_go.WithIdempotency(connect_go.IdempotencyNoSideEffects)))
+	mux.Handle(streamProcedure, connect_go.NewServerStreamHandler(streamProcedure,
+		func(ctx context.Context, _ *connect_go.Request[emptypb.Empty], stream *connect_go.ServerStream[wrapperspb.StringValue]) error {
+			for range 2 {
+				err := stream.Send(emailFromContext(ctx))
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}, interceptors))
+
+	server := httptest.NewServer(mux)
+	suite.T().Cleanup(server.Close)
+
+	return server.URL
+}
+
+func (suite *AuthTestSuite) callUnary(url string, authorization string) (string, error) {
+	return suite.callProcedure(url, unaryProcedure, authorization)
+}
+
+func (suite *AuthTestSuite) callProcedure(url string, procedure string, authorization string) (string, error) {
+	client := connect_go.NewClient[emptypb.Empty, wrapperspb.StringValue](http.DefaultClient, url+procedure)
+
+	request := connect_go.NewRequest(&emptypb.Empty{})
+	if authorization != "" {
+		request.Header().Set("Authorization", authorization)
+	}
+
+	response, err := client.CallUnary(context.Background(), request)
+	if err != nil {
+		return "", err
+	}
+
+	return response.Msg.GetValue(), nil
+}
+
+func (suite *AuthTestSuite) callStream(url string, authorization string) ([]string, error) {
+	client := connect_go.NewClient[emptypb.Empty, wrapperspb.StringValue](http.DefaultClient, url+streamProcedure)
+
+	request := connect_go.NewRequest(&emptypb.Empty{})
+	if authorization != "" {
+		request.Header().Set("Authorization", authorization)
+	}
+
+	stream, err := client.CallServerStream(context.Background(), request)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	var emails []string
+	for stream.Receive() {
+		emails = append(emails, stream.Msg().GetValue())
+	}
+
+	return emails, stream.Err()
+}
+
+func (suite *AuthTestSuite) TestInterceptor_UnaryAttachesUser() {
+	url := suite.testServer(configs.Auth{})
+
+	email, err := suite.callUnary(url, suite.tokenFor("test@example.com"))
+
+	suite.Require().NoError(err)
+	suite.Equal("test@example.com", email)
+}
+
+func (suite *AuthTestSuite) TestInterceptor_UnaryRequiresToken() {
+	url := suite.testServer(configs.Auth{})
+
+	_, err := suite.callUnary(url, "")
+
+	suite.ErrorContains(err, auth.ErrNoAuthorization.Error())
+	suite.Equal(connect_go.CodeUnauthenticated, connect_go.CodeOf(err))
+}
+
+func (suite *AuthTestSuite) TestInterceptor_StreamAttachesUser() {
+	url := suite.testServer(configs.Auth{})
+
+	emails, err := suite.callStream(url, suite.tokenFor("test@example.com"))
+
+	suite.Require().NoError(err)
+	suite.Equal([]string{"test@example.com", "test@example.com"}, emails)
+}
+
+func (suite *AuthTestSuite) TestInterceptor_StreamRequiresToken() {
+	url := suite.testServer(configs.Auth{})
+
+	emails, err := suite.callStream(url, "")
+
+	suite.ErrorContains(err, auth.ErrNoAuthorization.Error())
+	suite.Equal(connect_go.CodeUnauthenticated, connect_go.CodeOf(err))
+	suite.Empty(emails)
+}
+
+func (suite *AuthTestSuite) TestInterceptor_StreamRejectsInvalidToken() {
+	url := suite.testServer(configs.Auth{})
+
+	emails, err := suite.callStream(url, "Bearer not-a-token")
+
+	suite.ErrorContains(err, auth.ErrInvalidToken.Error())
+	suite.Equal(connect_go.CodeUnauthenticated, connect_go.CodeOf(err))
+	suite.Empty(emails)
+}
+
+func (suite *AuthTestSuite) TestInterceptor_StreamAppliesPolicy() {
+	url := suite.testServer(configs.Auth{AdminProcedures: []string{streamProcedure}, AdminEmails: []string{"admin@example.com"}})
+
+	_, err := suite.callStream(url, suite.tokenFor("test@example.com"))
+	suite.ErrorContains(err, auth.ErrAdminRequired.Error())
+	suite.Equal(connect_go.CodePermissionDenied, connect_go.CodeOf(err))
+
+	emails, err := suite.callStream(url, suite.tokenFor("admin@example.com"))
+	suite.Require().NoError(err)
+	suite.Equal([]string{"admin@example.com", "admin@example.com"}, emails)
+}
+
+func (suite *AuthTestSuite) TestInterceptor_PublicStreamWithoutToken() {
+	url := suite.testServer(configs.Auth{PublicProcedures: []string{streamProcedure}})
+
+	emails, err := suite.callStream(url, "")
+
+	suite.Require().NoError(err)
+	suite.Equal([]string{"", ""}, emails)
+}
@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var ErrInvalidJWKS = errors.New("invalid JWKS")
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// verificationKey is a public key from a JWKS along with the algorithm it is restricted to, if any.
+type verificationKey struct {
+	alg string
+	key any
+}
+
+// parseJWKS parses a JSON Web Key Set into the signing keys it contains, indexed by key ID. Keys that are not meant for
+// signatures, or that use a key type we cannot verify with, are skipped.
+func parseJWKS(data []byte) (map[string]verificationKey, error) {
+	var set jsonWebKeySet
+
+	err := json.Unmarshal(data, &set)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidJWKS, err)
+	}
+
+	keys := make(map[string]verificationKey, len(set.Keys))
+
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		var key any
+
+		switch jwk.Kty {
+		case "RSA":
+			key, err = jwk.rsaPublicKey()
+		case "EC":
+			key, err = jwk.ecdsaPublicKey()
+		default:
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", jwk.Kid, err)
+		}
+
+		keys[jwk.Kid] = verificationKey{alg: jwk.Alg, key: key}
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: no signing keys found", ErrInvalidJWKS)
+	}
+
+	return keys, nil
+}
+
+func (k jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	modulus, err := decodeBigInt(k.N)
+	if err != nil {
+		return nil, err
+	}
+
+	exponent, err := decodeBigInt(k.E)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exponent.IsInt64() || exponent.Int64() > int64(^uint32(0)>>1) {
+		return nil, fmt.Errorf("%w: exponent out of range", ErrInvalidJWKS)
+	}
+
+	return &rsa.PublicKey{N: modulus, E: int(exponent.Int64())}, nil
+}
+
+func (k jsonWebKey) ecdsaPublicKey() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("%w: unsupported curve %q", ErrInvalidJWKS, k.Crv)
+	}
+
+	pointX, err := decodeBigInt(k.X)
+	if err != nil {
+		return nil, err
+	}
+
+	pointY, err := decodeBigInt(k.Y)
+	if err != nil {
+		return nil, err
+	}
+
+	if !curve.IsOnCurve(pointX, pointY) { //nolint:staticcheck // ecdsa.PublicKey still needs the raw coordinates
+		return nil, fmt.Errorf("%w: point is not on the curve", ErrInvalidJWKS)
+	}
+
+	return &ecdsa.PublicKey{Curve: curve, X: pointX, Y: pointY}, nil
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, fmt.Errorf("%w: missing key parameter", ErrInvalidJWKS)
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidJWKS, err)
+	}
+
+	return new(big.Int).SetBytes(decoded), nil
+}
@@ -0,0 +1,322 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+
+	"droscher.com/BeerGargoyle/configs"
+)
+
+const (
+	httpTimeout     = 10 * time.Second
+	maxResponseSize = 1 << 20
+)
+
+var (
+	ErrUnexpectedSigningMethod = errors.New("unexpected signing method")
+	ErrUnknownKey              = errors.New("unknown signing key")
+	ErrFetchingKeys            = errors.New("error fetching signing keys")
+)
+
+// KeyProvider looks up the key that a token's signature should be verified with.
+type KeyProvider interface {
+	Key(ctx context.Context, token *jwt.Token) (any, error)
+}
+
+// NewKeyProvider picks the key source from the auth config. A JWKS file or URL takes precedence over the shared HMAC
+// secret, and if neither is configured the JWKS is discovered from the issuer.
+func NewKeyProvider(conf configs.Auth, logger *zap.Logger) (KeyProvider, error) { //nolint:ireturn // the provider depends on config
+	client := &http.Client{Timeout: httpTimeout}
+
+	switch {
+	case conf.JWKSFile != "":
+		return newJWKSKeyProvider(fileSource(conf.JWKSFile), conf, logger), nil
+	case conf.JWKSURL != "":
+		return newJWKSKeyProvider(urlSource(client, conf.JWKSURL), conf, logger), nil
+	case conf.SecretKey != "":
+		return &hmacKeyProvider{secret: []byte(conf.SecretKey)}, nil
+	case issuer(conf) != "":
+		return newJWKSKeyProvider(discoverySource(client, issuer(conf)), conf, logger), nil
+	}
+
+	return nil, fmt.Errorf("%w: one of Auth.SecretKey, Auth.JWKSURL, Auth.JWKSFile or Auth.Issuer is required", configs.ErrConfiguration)
+}
+
+// issuer returns the configured token issuer. When the keys come from a JWKS it falls back to the Auth0 style issuer
+// for the configured domain. Tokens signed with the shared secret are only checked against an explicitly set issuer,
+// as they were never required to carry one.
+func issuer(conf configs.Auth) string {
+	if conf.Issuer != "" {
+		return conf.Issuer
+	}
+
+	sharedSecret := conf.SecretKey != "" && conf.JWKSURL == "" && conf.JWKSFile == ""
+	if conf.Domain != "" && !sharedSecret {
+		return "https://" + conf.Domain + "/"
+	}
+
+	return ""
+}
+
+type hmacKeyProvider struct {
+	secret []byte
+}
+
+func (h *hmacKeyProvider) Key(_ context.Context, token *jwt.Token) (any, error) {
+	_, ok := token.Method.(*jwt.SigningMethodHMAC)
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrUnexpectedSigningMethod, token.Header["alg"])
+	}
+
+	return h.secret, nil
+}
+
+type keySource func(ctx context.Context) ([]byte, error)
+
+// jwksKeyProvider caches the keys from a JWKS. The set is reloaded when the cache expires, and also when a token
+// refers to a key ID we have not seen, so that key rotation at the identity provider is picked up straight away.
+// Reloads triggered by unknown key IDs are limited to one per refresh interval. Both limits count from the last
+// attempt rather than the last success, so an identity provider that is down is not asked again on every request.
+// Concurrent reloads share one fetch, which runs without holding the lock so that requests with cached keys are not
+// held up by a slow identity provider.
+type jwksKeyProvider struct {
+	source          keySource
+	cacheTTL        time.Duration
+	refreshInterval time.Duration
+	logger          *zap.Logger
+	fetches         singleflight.Group
+
+	mu          sync.RWMutex
+	keys        map[string]verificationKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+}
+
+func newJWKSKeyProvider(source keySource, conf configs.Auth, logger *zap.Logger) *jwksKeyProvider {
+	return &jwksKeyProvider{
+		source:          source,
+		cacheTTL:        conf.JWKSCacheTTL,
+		refreshInterval: conf.JWKSRefreshInterval,
+		logger:          logger,
+	}
+}
+
+func (j *jwksKeyProvider) Key(ctx context.Context, token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	keys, attemptedAt := j.cached()
+
+	if j.expired(keys, attemptedAt) {
+		err := j.refresh(ctx)
+		if err != nil && keys == nil {
+			return nil, err
+		}
+
+		keys, attemptedAt = j.cached()
+	}
+
+	if keys == nil {
+		return nil, fmt.Errorf("%w: waiting to retry the identity provider", ErrFetchingKeys)
+	}
+
+	key, found := lookup(keys, kid)
+	if !found && time.Since(attemptedAt) >= j.refreshInterval {
+		j.logger.Info("unknown key id, reloading JWKS", zap.String("kid", kid))
+
+		err := j.refresh(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		keys, _ = j.cached()
+		key, found = lookup(keys, kid)
+	}
+
+	if !found {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+
+	err := checkSigningMethod(token, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return key.key, nil
+}
+
+// cached returns the keys along with when they were last reloaded, successfully or not.
+func (j *jwksKeyProvider) cached() (map[string]verificationKey, time.Time) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	return j.keys, j.attemptedAt
+}
+
+// expired reports whether the keys are due to be reloaded. Without any keys a reload is tried at most once per refresh
+// interval.
+func (j *jwksKeyProvider) expired(keys map[string]verificationKey, attemptedAt time.Time) bool {
+	if keys == nil {
+		return attemptedAt.IsZero() || time.Since(attemptedAt) >= j.refreshInterval
+	}
+
+	return j.cacheTTL > 0 && time.Since(attemptedAt) > j.cacheTTL
+}
+
+// refresh reloads the key set. Callers that arrive while a fetch is in flight wait for it instead of starting another.
+// The fetch is not cancelled with the request that started it, as other requests may be waiting on it. When the reload
+// fails the keys fetched before are kept.
+func (j *jwksKeyProvider) refresh(ctx context.Context) error {
+	_, err, _ := j.fetches.Do("jwks", func() (any, error) {
+		keys, err := j.load(ctx)
+
+		j.mu.Lock()
+		defer j.mu.Unlock()
+
+		j.attemptedAt = time.Now()
+
+		if err != nil {
+			if j.keys != nil {
+				j.logger.Warn("keeping cached JWKS", zap.Duration("age", time.Since(j.fetchedAt)))
+			}
+
+			return nil, err
+		}
+
+		j.keys = keys
+		j.fetchedAt = j.attemptedAt
+
+		return keys, nil
+	})
+
+	return err
+}
+
+func (j *jwksKeyProvider) load(ctx context.Context) (map[string]verificationKey, error) {
+	data, err := j.source(context.WithoutCancel(ctx))
+	if err != nil {
+		j.logger.Error("error fetching JWKS", zap.Error(err))
+
+		return nil, err
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		j.logger.Error("error parsing JWKS", zap.Error(err))
+
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// lookup finds a key by ID. Tokens without a key ID are only accepted when the set holds a single key.
+func lookup(keys map[string]verificationKey, kid string) (verificationKey, bool) {
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, true
+		}
+	}
+
+	key, found := keys[kid]
+
+	return key, found
+}
+
+// checkSigningMethod makes sure the token's algorithm matches the key type, so that for example a public RSA key can
+// never be used as an HMAC secret.
+func checkSigningMethod(token *jwt.Token, key verificationKey) error {
+	if key.alg != "" && key.alg != token.Method.Alg() {
+		return fmt.Errorf("%w: %s, key requires %s", ErrUnexpectedSigningMethod, token.Method.Alg(), key.alg)
+	}
+
+	var ok bool
+
+	switch key.key.(type) {
+	case *rsa.PublicKey:
+		_, ok = token.Method.(*jwt.SigningMethodRSA)
+		if !ok {
+			_, ok = token.Method.(*jwt.SigningMethodRSAPSS)
+		}
+	case *ecdsa.PublicKey:
+		_, ok = token.Method.(*jwt.SigningMethodECDSA)
+	}
+
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnexpectedSigningMethod, token.Method.Alg())
+	}
+
+	return nil
+}
+
+func fileSource(path string) keySource {
+	return func(_ context.Context) ([]byte, error) {
+		return os.ReadFile(path)
+	}
+}
+
+func urlSource(client *http.Client, url string) keySource {
+	return func(ctx context.Context) ([]byte, error) {
+		return fetch(ctx, client, url)
+	}
+}
+
+// discoverySource reads the JWKS location from the issuer's OpenID configuration. The location is remembered once it
+// has been found.
+func discoverySource(client *http.Client, issuer string) keySource {
+	var jwksURL string
+
+	return func(ctx context.Context) ([]byte, error) {
+		if jwksURL == "" {
+			data, err := fetch(ctx, client, strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration")
+			if err != nil {
+				return nil, err
+			}
+
+			var discovery struct {
+				JWKSURI string `json:"jwks_uri"`
+			}
+
+			err = json.Unmarshal(data, &discovery)
+			if err != nil || discovery.JWKSURI == "" {
+				return nil, fmt.Errorf("%w: no jwks_uri in OpenID configuration for %s", ErrFetchingKeys, issuer)
+			}
+
+			jwksURL = discovery.JWKSURI
+		}
+
+		return fetch(ctx, client, jwksURL)
+	}
+}
+
+func fetch(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFetchingKeys, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s returned %s", ErrFetchingKeys, url, response.Status)
+	}
+
+	return io.ReadAll(io.LimitReader(response.Body, maxResponseSize))
+}
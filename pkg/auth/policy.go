@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"slices"
+
+	"droscher.com/BeerGargoyle/configs"
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/server/grpc/api/v1/apiv1connect"
+)
+
+// Access is the level of authentication a procedure requires.
+type Access string
+
+const (
+	AccessPublic Access = "public"
+	// AccessVerifiedToken requires a valid sign-in token but no user for it, so that new users can sign up. The profile
+	// from the token is attached to the context under ProfileKey.
+	AccessVerifiedToken Access = "verified_token"
+	AccessAuthenticated Access = "authenticated"
+	AccessAdmin         Access = "admin"
+)
+
+// Policy maps procedures to the access they require. Procedures without an entry require an authenticated user.
+type Policy struct {
+	procedures  map[string]Access
+	adminEmails []string
+}
+
+// defaultProcedureAccess lists the procedures whose access differs from the authenticated default. Changes to the
+// shared catalog that affect what other users see, such as editing beers, breweries or styles and merging, are for
+// administrators. Adding beers and breweries stays open to every user, since cellaring a beer may need it.
+func defaultProcedureAccess() map[string]Access {
+	return map[string]Access{
+		apiv1connect.UserServiceAddUserProcedure:                AccessVerifiedToken,
+		apiv1connect.UserServiceGetUserByEmailProcedure:         AccessAdmin,
+		apiv1connect.UserServiceListUsersProcedure:              AccessAdmin,
+		apiv1connect.UserServiceUpdateUserProcedure:             AccessAdmin,
+		apiv1connect.UserServiceDisableUserProcedure:            AccessAdmin,
+		apiv1connect.UserServiceDeleteUserProcedure:             AccessAdmin,
+		apiv1connect.BeerServiceFindDuplicateBeersProcedure:     AccessAdmin,
+		apiv1connect.BeerServiceFindDuplicateBreweriesProcedure: AccessAdmin,
+		apiv1connect.BeerServiceMergeBeersProcedure:             AccessAdmin,
+		apiv1connect.BeerServiceMergeBreweriesProcedure:         AccessAdmin,
+		apiv1connect.BeerServiceUpdateBeerStyleProcedure:        AccessAdmin,
+		apiv1connect.BeerServiceUpdateBreweryProcedure:          AccessAdmin,
+		apiv1connect.BeerServiceUpdateBeerDetailsProcedure:      AccessAdmin,
+		apiv1connect.TagServiceRenameTagProcedure:               AccessAdmin,
+		apiv1connect.TagServiceMergeTagsProcedure:               AccessAdmin,
+		apiv1connect.TagServiceDeleteTagProcedure:               AccessAdmin,
+	}
+}
+
+// NewPolicy builds the policy from the defaults in code, overridden by the procedures listed in the auth config. A
+// procedure listed more than once gets the most restrictive access.
+func NewPolicy(conf configs.Auth) *Policy {
+	procedures := defaultProcedureAccess()
+
+	for _, override := range []struct {
+		access     Access
+		procedures []string
+	}{
+		{AccessPublic, conf.PublicProcedures},
+		{AccessAuthenticated, conf.AuthenticatedProcedures},
+		{AccessAdmin, conf.AdminProcedures},
+	} {
+		for _, procedure := range override.procedures {
+			procedures[procedure] = override.access
+		}
+	}
+
+	return &Policy{procedures: procedures, adminEmails: conf.AdminEmails}
+}
+
+func (p *Policy) Access(procedure string) Access {
+	access, found := p.procedures[procedure]
+	if !found {
+		return AccessAuthenticated
+	}
+
+	return access
+}
+
+// IsAdmin reports whether the user has the admin role. Users listed in Auth.AdminEmails are always administrators, so
+// that the first administrator can be set up.
+func (p *Policy) IsAdmin(user *model.User) bool {
+	return user.IsAdmin() || slices.Contains(p.adminEmails, user.Email)
+}
@@ -0,0 +1,250 @@
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	connect_go "github.com/bufbuild/connect-go"
+	"github.com/golang-jwt/jwt/v4"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/configs"
+	"droscher.com/BeerGargoyle/pkg/auth"
+	"droscher.com/BeerGargoyle/pkg/model"
+	api "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+	"droscher.com/BeerGargoyle/pkg/server/grpc/api/v1/apiv1connect"
+)
+
+// echoUserServer answers with the user or verified profile the interceptor attached to the context, if any.
+type echoUserServer struct {
+	apiv1connect.UnimplementedUserServiceHandler
+}
+
+func (e *echoUserServer) AddUser(ctx context.Context, _ *connect_go.Request[api.AddUserRequest]) (*connect_go.Response[api.AddUserResponse], error) {
+	response := &api.AddUserResponse{}
+
+	user, found := ctx.Value(auth.UserKey{}).(*model.User)
+	if found {
+		response.User = &api.User{Email: user.Email}
+	}
+
+	profile, found := ctx.Value(auth.ProfileKey{}).(*model.User)
+	if found {
+		response.User = &api.User{Email: profile.Email, UserName: profile.Username}
+	}
+
+	return connect_go.NewResponse(response), nil
+}
+
+func (e *echoUserServer) GetUserByEmail(ctx context.Context, _ *connect_go.Request[api.GetUserByEmailRequest]) (*connect_go.Response[api.GetUserByEmailResponse], error) {
+	user, _ := ctx.Value(auth.UserKey{}).(*model.User)
+
+	return connect_go.NewResponse(&api.GetUserByEmailResponse{User: &api.User{Email: user.Email}}), nil
+}
+
+func (suite *AuthTestSuite) userServiceClient(authConfig configs.Auth) apiv1connect.UserServiceClient {
+	server := suite.serve(authConfig, func(mux *http.ServeMux, interceptors connect_go.Option) {
+		mux.Handle(apiv1connect.NewUserServiceHandler(&echoUserServer{}, interceptors))
+	})
+
+	return apiv1connect.NewUserServiceClient(server.Client(), server.URL)
+}
+
+// beerServiceClient calls a beer service that implements nothing, so calls the interceptor lets through fail as
+// unimplemented.
+func (suite *AuthTestSuite) beerServiceClient(authConfig configs.Auth) apiv1connect.BeerServiceClient {
+	server := suite.serve(authConfig, func(mux *http.ServeMux, interceptors connect_go.Option) {
+		mux.Handle(apiv1connect.NewBeerServiceHandler(&apiv1connect.UnimplementedBeerServiceHandler{}, interceptors))
+	})
+
+	return apiv1connect.NewBeerServiceClient(server.Client(), server.URL)
+}
+
+// serve starts a server with the handlers registered, behind the auth interceptor configured with the test users.
+func (suite *AuthTestSuite) serve(authConfig configs.Auth, register func(mux *http.ServeMux, interceptors connect_go.Option)) *httptest.Server {
+	authConfig.SecretKey = "secret"
+	repo := &fakeUserRepository{users: []*model.User{
+		{Model: gorm.Model{ID: 1}, Email: "test@example.com"},
+		{Model: gorm.Model{ID: 2}, Email: "admin@example.com"},
+		{Model: gorm.Model{ID: 3}, Email: "role-admin@example.com", Role: model.UserRoleAdmin},
+		{Model: gorm.Model{ID: 4}, Email: "disabled@example.com", Disabled: true},
+	}}
+
+	manager, err := auth.NewAuthManager(&configs.Config{Auth: authConfig}, repo, zaptest.NewLogger(suite.T()))
+	suite.Require().NoError(err)
+
+	mux := http.NewServeMux()
+	register(mux, connect_go.WithInterceptors(manager))
+
+	server := httptest.NewServer(mux)
+	suite.T().Cleanup(server.Close)
+
+	return server
+}
+
+func (suite *AuthTestSuite) tokenFor(email string) string {
+	return "Bearer " + suite.sign(jwt.SigningMethodHS256, "", []byte("secret"), jwt.MapClaims{"email": email})
+}
+
+func (suite *AuthTestSuite) TestPolicy_AddUserRequiresVerifiedToken() {
+	client := suite.userServiceClient(configs.Auth{})
+
+	_, err := client.AddUser(context.Background(), connect_go.NewRequest(&api.AddUserRequest{Email: "new@example.com"}))
+	suite.ErrorContains(err, auth.ErrNoAuthorization.Error())
+	suite.Equal(connect_go.CodeUnauthenticated, connect_go.CodeOf(err))
+
+	request := connect_go.NewRequest(&api.AddUserRequest{})
+	request.Header().Set("Authorization", "Bearer "+suite.sign(jwt.SigningMethodHS256, "", []byte("wrong"), jwt.MapClaims{"email": "new@example.com"}))
+
+	_, err = client.AddUser(context.Background(), request)
+	suite.ErrorContains(err, auth.ErrInvalidToken.Error())
+	suite.Equal(connect_go.CodeUnauthenticated, connect_go.CodeOf(err))
+}
+
+func (suite *AuthTestSuite) TestPolicy_AddUserGetsProfileOfUnknownUser() {
+	client := suite.userServiceClient(configs.Auth{})
+
+	request := connect_go.NewRequest(&api.AddUserRequest{})
+	request.Header().Set("Authorization", "Bearer "+suite.sign(jwt.SigningMethodHS256, "", []byte("secret"),
+		jwt.MapClaims{"email": "new@example.com", "preferred_username": "newbie"}))
+
+	response, err := client.AddUser(context.Background(), request)
+
+	suite.Require().NoError(err)
+	suite.Equal("new@example.com", response.Msg.GetUser().GetEmail())
+	suite.Equal("newbie", response.Msg.GetUser().GetUserName())
+}
+
+func (suite *AuthTestSuite) TestPolicy_AuthenticatedByDefault() {
+	client := suite.userServiceClient(configs.Auth{})
+
+	_, err := client.GetUserByEmail(context.Background(), connect_go.NewRequest(&api.GetUserByEmailRequest{}))
+
+	suite.ErrorContains(err, auth.ErrNoAuthorization.Error())
+	suite.Equal(connect_go.CodeUnauthenticated, connect_go.CodeOf(err))
+}
+
+func (suite *AuthTestSuite) TestPolicy_ConfigOverridesDefault() {
+	client := suite.userServiceClient(configs.Auth{AuthenticatedProcedures: []string{apiv1connect.UserServiceAddUserProcedure}})
+
+	_, err := client.AddUser(context.Background(), connect_go.NewRequest(&api.AddUserRequest{}))
+	suite.ErrorContains(err, auth.ErrNoAuthorization.Error())
+	suite.Equal(connect_go.CodeUnauthenticated, connect_go.CodeOf(err))
+
+	request := connect_go.NewRequest(&api.AddUserRequest{})
+	request.Header().Set("Authorization", suite.tokenFor("test@example.com"))
+
+	response, err := client.AddUser(context.Background(), request)
+	suite.Require().NoError(err)
+	suite.Equal("test@example.com", response.Msg.GetUser().GetEmail())
+}
+
+func (suite *AuthTestSuite) TestPolicy_AdminProcedureDeniedForUser() {
+	client := suite.userServiceClient(configs.Auth{
+		AdminProcedures: []string{apiv1connect.UserServiceGetUserByEmailProcedure},
+		AdminEmails:     []string{"admin@example.com"},
+	})
+
+	request := connect_go.NewRequest(&api.GetUserByEmailRequest{})
+	request.Header().Set("Authorization", suite.tokenFor("test@example.com"))
+
+	_, err := client.GetUserByEmail(context.Background(), request)
+
+	suite.ErrorContains(err, auth.ErrAdminRequired.Error())
+	suite.Equal(connect_go.CodePermissionDenied, connect_go.CodeOf(err))
+}
+
+func (suite *AuthTestSuite) TestPolicy_AdminProcedureAllowedForAdmin() {
+	client := suite.userServiceClient(configs.Auth{
+		AdminProcedures: []string{apiv1connect.UserServiceGetUserByEmailProcedure},
+		AdminEmails:     []string{"admin@example.com"},
+	})
+
+	request := connect_go.NewRequest(&api.GetUserByEmailRequest{})
+	request.Header().Set("Authorization", suite.tokenFor("admin@example.com"))
+
+	response, err := client.GetUserByEmail(context.Background(), request)
+
+	suite.Require().NoError(err)
+	suite.Equal("admin@example.com", response.Msg.GetUser().GetEmail())
+}
+
+func (suite *AuthTestSuite) TestPolicy_MostRestrictiveOverrideWins() {
+	policy := auth.NewPolicy(configs.Auth{
+		PublicProcedures: []string{"/api.v1.Test/Method"},
+		AdminProcedures:  []string{"/api.v1.Test/Method"},
+	})
+
+	suite.Equal(auth.AccessAdmin, policy.Access("/api.v1.Test/Method"))
+	suite.Equal(auth.AccessAuthenticated, policy.Access("/api.v1.Test/Other"))
+	suite.Equal(auth.AccessVerifiedToken, policy.Access(apiv1connect.UserServiceAddUserProcedure))
+}
+
+func (suite *AuthTestSuite) TestPolicy_CatalogMergesRequireAdmin() {
+	policy := auth.NewPolicy(configs.Auth{})
+
+	suite.Equal(auth.AccessAdmin, policy.Access(apiv1connect.BeerServiceMergeBeersProcedure))
+	suite.Equal(auth.AccessAdmin, policy.Access(apiv1connect.BeerServiceMergeBreweriesProcedure))
+	suite.Equal(auth.AccessAdmin, policy.Access(apiv1connect.BeerServiceFindDuplicateBeersProcedure))
+	suite.Equal(auth.AccessAdmin, policy.Access(apiv1connect.BeerServiceUpdateBeerStyleProcedure))
+	suite.Equal(auth.AccessAdmin, policy.Access(apiv1connect.BeerServiceUpdateBreweryProcedure))
+	suite.Equal(auth.AccessAdmin, policy.Access(apiv1connect.BeerServiceUpdateBeerDetailsProcedure))
+	suite.Equal(auth.AccessAuthenticated, policy.Access(apiv1connect.BeerServiceListBeerStylesProcedure))
+	suite.Equal(auth.AccessAuthenticated, policy.Access(apiv1connect.BeerServiceAddBreweryProcedure))
+}
+
+func (suite *AuthTestSuite) TestPolicy_UpdateBeerDetailsDeniedForUser() {
+	client := suite.beerServiceClient(configs.Auth{})
+
+	request := connect_go.NewRequest(&api.UpdateBeerDetailsRequest{})
+	request.Header().Set("Authorization", suite.tokenFor("test@example.com"))
+
+	_, err := client.UpdateBeerDetails(context.Background(), request)
+	suite.ErrorContains(err, auth.ErrAdminRequired.Error())
+	suite.Equal(connect_go.CodePermissionDenied, connect_go.CodeOf(err))
+
+	request.Header().Set("Authorization", suite.tokenFor("role-admin@example.com"))
+
+	_, err = client.UpdateBeerDetails(context.Background(), request)
+	suite.Equal(connect_go.CodeUnimplemented, connect_go.CodeOf(err))
+}
+
+func (suite *AuthTestSuite) TestPolicy_TagManagementRequiresAdmin() {
+	policy := auth.NewPolicy(configs.Auth{})
+
+	suite.Equal(auth.AccessAdmin, policy.Access(apiv1connect.TagServiceRenameTagProcedure))
+	suite.Equal(auth.AccessAdmin, policy.Access(apiv1connect.TagServiceMergeTagsProcedure))
+	suite.Equal(auth.AccessAdmin, policy.Access(apiv1connect.TagServiceDeleteTagProcedure))
+	suite.Equal(auth.AccessAuthenticated, policy.Access(apiv1connect.TagServiceListTagsProcedure))
+}
+
+func (suite *AuthTestSuite) TestPolicy_UserManagementRequiresAdmin() {
+	client := suite.userServiceClient(configs.Auth{})
+
+	request := connect_go.NewRequest(&api.GetUserByEmailRequest{})
+	request.Header().Set("Authorization", suite.tokenFor("test@example.com"))
+
+	_, err := client.GetUserByEmail(context.Background(), request)
+	suite.ErrorContains(err, auth.ErrAdminRequired.Error())
+	suite.Equal(connect_go.CodePermissionDenied, connect_go.CodeOf(err))
+
+	request.Header().Set("Authorization", suite.tokenFor("role-admin@example.com"))
+
+	response, err := client.GetUserByEmail(context.Background(), request)
+	suite.Require().NoError(err)
+	suite.Equal("role-admin@example.com", response.Msg.GetUser().GetEmail())
+}
+
+func (suite *AuthTestSuite) TestPolicy_DisabledUserDenied() {
+	client := suite.userServiceClient(configs.Auth{AuthenticatedProcedures: []string{apiv1connect.UserServiceAddUserProcedure}})
+
+	request := connect_go.NewRequest(&api.AddUserRequest{})
+	request.Header().Set("Authorization", suite.tokenFor("disabled@example.com"))
+
+	_, err := client.AddUser(context.Background(), request)
+
+	suite.ErrorContains(err, auth.ErrUserDisabled.Error())
+	suite.Equal(connect_go.CodePermissionDenied, connect_go.CodeOf(err))
+}
@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	connect_go "github.com/bufbuild/connect-go"
+	"github.com/golang-jwt/jwt/v4"
+	"go.uber.org/zap"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+)
+
+var (
+	ErrNoUserInToken = errors.New("unable to get user id from token")
+	ErrUnknownUser   = errors.New("user not found")
+)
+
+type userRepository interface {
+	GetAccessTokenByHash(ctx context.Context, hash string) (*model.AccessToken, error)
+	GetUserFromEmail(ctx context.Context, email string) (*model.User, error)
+	ProvisionUser(ctx context.Context, user model.User) (*model.User, error)
+	TouchAccessToken(ctx context.Context, tokenID uint, usedAt time.Time) error
+	UpdateUserProfile(ctx context.Context, user *model.User) error
+}
+
+// userFromClaims finds the user the token was issued to. With Auth.AutoProvision enabled, unknown users are created
+// from the token's profile claims, and the names of existing users are updated whenever the claims change.
+func (a *Manager) userFromClaims(ctx context.Context, claims jwt.MapClaims) (*model.User, error) {
+	profile := profileFromClaims(claims)
+	if profile.Email == "" {
+		a.logger.Error("unable to get user id from token", zap.Any("claims", claims))
+
+		return nil, connect_go.NewError(connect_go.CodeUnauthenticated, ErrNoUserInToken)
+	}
+
+	user, err := a.repo.GetUserFromEmail(ctx, profile.Email)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			if !a.conf.Auth.AutoProvision {
+				return nil, connect_go.NewError(connect_go.CodeNotFound, ErrUnknownUser)
+			}
+
+			return a.provisionUser(ctx, profile)
+		}
+
+		a.logger.Error("error authenticating user", zap.Error(err))
+
+		return nil, connect_go.NewError(connect_go.CodeInternal, ErrAuthentication)
+	}
+
+	if a.conf.Auth.AutoProvision {
+		a.syncUser(ctx, user, profile)
+	}
+
+	return user, nil
+}
+
+func (a *Manager) provisionUser(ctx context.Context, profile model.User) (*model.User, error) {
+	if profile.Username == "" {
+		profile.Username = strings.TrimSpace(profile.FirstName + " " + profile.LastName)
+	}
+
+	if profile.Username == "" {
+		profile.Username, _, _ = strings.Cut(profile.Email, "@")
+	}
+
+	user, err := a.repo.ProvisionUser(ctx, profile)
+	if err != nil {
+		a.logger.Error("error provisioning user", zap.String("email", profile.Email), zap.Error(err))
+
+		return nil, connect_go.NewError(connect_go.CodeInternal, ErrAuthentication)
+	}
+
+	a.logger.Info("provisioned user", zap.String("email", user.Email), zap.String("uuid", user.UUID.String()))
+
+	return user, nil
+}
+
+// syncUser copies any profile fields present in the token to the user. Failing to save them is logged but does not
+// stop the request.
+func (a *Manager) syncUser(ctx context.Context, user *model.User, profile model.User) {
+	changed := false
+
+	for _, field := range []struct {
+		current *string
+		claimed string
+	}{
+		{&user.Username, profile.Username},
+		{&user.FirstName, profile.FirstName},
+		{&user.LastName, profile.LastName},
+	} {
+		if field.claimed != "" && field.claimed != *field.current {
+			*field.current = field.claimed
+			changed = true
+		}
+	}
+
+	if !changed {
+		return
+	}
+
+	err := a.repo.UpdateUserProfile(ctx, user)
+	if err != nil {
+		a.logger.Warn("error syncing user profile", zap.Uint("user_id", user.ID), zap.Error(err))
+	}
+}
+
+// profileFromClaims reads the standard OpenID Connect profile claims. When the provider only sends a full name it is
+// split into first and last name at the first space.
+func profileFromClaims(claims jwt.MapClaims) model.User {
+	firstName := claimString(claims, "given_name")
+	lastName := claimString(claims, "family_name")
+
+	if firstName == "" && lastName == "" {
+		firstName, lastName, _ = strings.Cut(claimString(claims, "name"), " ")
+	}
+
+	return model.User{
+		Email:     claimString(claims, "email"),
+		Username:  claimString(claims, "preferred_username"),
+		FirstName: firstName,
+		LastName:  strings.TrimSpace(lastName),
+	}
+}
+
+func claimString(claims jwt.MapClaims, name string) string {
+	value, _ := claims[name].(string)
+
+	return strings.TrimSpace(value)
+}
@@ -0,0 +1,193 @@
+package auth_test
+
+import (
+	"context"
+	"time"
+
+	connect_go "github.com/bufbuild/connect-go"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/configs"
+	"droscher.com/BeerGargoyle/pkg/auth"
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+)
+
+type fakeUserRepository struct {
+	users   []*model.User
+	tokens  []*model.AccessToken
+	updates int
+	touches int
+}
+
+func (f *fakeUserRepository) GetAccessTokenByHash(_ context.Context, hash string) (*model.AccessToken, error) {
+	for _, token := range f.tokens {
+		if token.Hash == hash {
+			found := *token
+
+			return &found, nil
+		}
+	}
+
+	return nil, repository.ErrAccessTokenNotFound
+}
+
+func (f *fakeUserRepository) TouchAccessToken(_ context.Context, tokenID uint, usedAt time.Time) error {
+	f.touches++
+
+	for _, token := range f.tokens {
+		if token.ID == tokenID {
+			token.LastUsedAt = &usedAt
+		}
+	}
+
+	return nil
+}
+
+func (f *fakeUserRepository) GetUserFromEmail(_ context.Context, email string) (*model.User, error) {
+	for _, user := range f.users {
+		if user.Email == email {
+			found := *user
+
+			return &found, nil
+		}
+	}
+
+	return nil, repository.ErrUserNotFound
+}
+
+func (f *fakeUserRepository) ProvisionUser(_ context.Context, user model.User) (*model.User, error) {
+	user.ID = uint(len(f.users) + 1)
+	user.UUID = uuid.New()
+	f.users = append(f.users, &user)
+
+	return &user, nil
+}
+
+func (f *fakeUserRepository) UpdateUserProfile(_ context.Context, user *model.User) error {
+	f.updates++
+
+	for index := range f.users {
+		if f.users[index].ID == user.ID {
+			updated := *user
+			f.users[index] = &updated
+		}
+	}
+
+	return nil
+}
+
+// authenticate runs a request with the token through the auth interceptor and returns the user it attached.
+func (suite *AuthTestSuite) authenticate(repo *fakeUserRepository, autoProvision bool, claims jwt.MapClaims) (*model.User, error) {
+	conf := &configs.Config{Auth: configs.Auth{SecretKey: "secret", AutoProvision: autoProvision}}
+	manager, err := auth.NewAuthManager(conf, repo, zaptest.NewLogger(suite.T()))
+	suite.Require().NoError(err)
+
+	var user *model.User
+
+	next := func(ctx context.Context, _ connect_go.AnyRequest) (connect_go.AnyResponse, error) {
+		user, _ = ctx.Value(auth.UserKey{}).(*model.User)
+
+		return connect_go.NewResponse(&emptypb.Empty{}), nil
+	}
+
+	request := connect_go.NewRequest(&emptypb.Empty{})
+	request.Header().Set("Authorization", "Bearer "+suite.sign(jwt.SigningMethodHS256, "", []byte("secret"), claims))
+
+	_, err = manager.WrapUnary(next)(context.Background(), request)
+
+	return user, err
+}
+
+func (suite *AuthTestSuite) TestInterceptor_UnknownUserWithoutProvisioning() {
+	repo := &fakeUserRepository{}
+
+	user, err := suite.authenticate(repo, false, jwt.MapClaims{"email": "new@example.com"})
+
+	suite.Equal(connect_go.CodeNotFound, connect_go.CodeOf(err))
+	suite.ErrorIs(err, auth.ErrUnknownUser)
+	suite.Nil(user)
+	suite.Empty(repo.users)
+}
+
+func (suite *AuthTestSuite) TestInterceptor_ProvisionsUnknownUser() {
+	repo := &fakeUserRepository{}
+
+	user, err := suite.authenticate(repo, true, jwt.MapClaims{
+		"email":              "new@example.com",
+		"preferred_username": "newbie",
+		"given_name":         "New",
+		"family_name":        "User",
+	})
+
+	suite.Require().NoError(err)
+	suite.Require().NotNil(user)
+	suite.Equal("new@example.com", user.Email)
+	suite.Equal("newbie", user.Username)
+	suite.Equal("New", user.FirstName)
+	suite.Equal("User", user.LastName)
+	suite.NotEqual(uuid.Nil, user.UUID)
+	suite.Len(repo.users, 1)
+}
+
+func (suite *AuthTestSuite) TestInterceptor_ProvisionSplitsFullName() {
+	repo := &fakeUserRepository{}
+
+	user, err := suite.authenticate(repo, true, jwt.MapClaims{"email": "jane@example.com", "name": "Jane van Dyke"})
+
+	suite.Require().NoError(err)
+	suite.Equal("Jane", user.FirstName)
+	suite.Equal("van Dyke", user.LastName)
+	suite.Equal("Jane van Dyke", user.Username)
+}
+
+func (suite *AuthTestSuite) TestInterceptor_ProvisionFallsBackToEmailForUsername() {
+	repo := &fakeUserRepository{}
+
+	user, err := suite.authenticate(repo, true, jwt.MapClaims{"email": "anon@example.com"})
+
+	suite.Require().NoError(err)
+	suite.Equal("anon", user.Username)
+}
+
+func (suite *AuthTestSuite) TestInterceptor_SyncsChangedProfile() {
+	repo := &fakeUserRepository{users: []*model.User{
+		{Model: gorm.Model{ID: 1}, Email: "test@example.com", Username: "old", FirstName: "Test", LastName: "User"},
+	}}
+
+	user, err := suite.authenticate(repo, true, jwt.MapClaims{
+		"email":              "test@example.com",
+		"preferred_username": "new",
+		"given_name":         "Test",
+	})
+
+	suite.Require().NoError(err)
+	suite.Equal("new", user.Username)
+	suite.Equal("Test", user.FirstName)
+	suite.Equal("User", user.LastName)
+	suite.Equal(1, repo.updates)
+}
+
+func (suite *AuthTestSuite) TestInterceptor_DoesNotSyncWithoutProvisioning() {
+	repo := &fakeUserRepository{users: []*model.User{
+		{Model: gorm.Model{ID: 1}, Email: "test@example.com", Username: "old"},
+	}}
+
+	user, err := suite.authenticate(repo, false, jwt.MapClaims{"email": "test@example.com", "preferred_username": "new"})
+
+	suite.Require().NoError(err)
+	suite.Equal("old", user.Username)
+	suite.Zero(repo.updates)
+}
+
+func (suite *AuthTestSuite) TestInterceptor_MissingEmailClaim() {
+	user, err := suite.authenticate(&fakeUserRepository{}, true, jwt.MapClaims{"sub": "123"})
+
+	suite.Equal(connect_go.CodeUnauthenticated, connect_go.CodeOf(err))
+	suite.ErrorIs(err, auth.ErrNoUserInToken)
+	suite.Nil(user)
+}
@@ -0,0 +1,186 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// DefaultBufferSize is the number of events a watcher can fall behind by before it is dropped.
+const DefaultBufferSize = 64
+
+// listenRetryInterval is how long to wait before listening again after losing the connection to the database.
+const listenRetryInterval = 5 * time.Second
+
+type CellarEventType string
+
+const (
+	CellarEntryAdded           CellarEventType = "entry_added"
+	CellarEntryUpdated         CellarEventType = "entry_updated"
+	CellarEntryDeleted         CellarEventType = "entry_deleted"
+	CellarEntryQuantityChanged CellarEventType = "quantity_changed"
+)
+
+// CellarEvent is a change to an entry in a cellar. Events only identify the entry, watchers load the entry themselves
+// so that events stay small enough to be sent between instances.
+type CellarEvent struct {
+	Type             CellarEventType `json:"type"`
+	CellarID         uint            `json:"cellar_id"`
+	EntryID          uint            `json:"entry_id"`
+	PreviousQuantity int64           `json:"previous_quantity,omitempty"`
+	// Source is the instance the event was published on, so that instances ignore their own events when they are
+	// relayed back to them.
+	Source string `json:"source,omitempty"`
+}
+
+// Notifier relays events between instances of the server, such as through Postgres LISTEN/NOTIFY.
+type Notifier interface {
+	NotifyCellarEvent(ctx context.Context, payload string) error
+	ListenCellarEvents(ctx context.Context, handle func(payload string)) error
+}
+
+// Broker delivers cellar events to the watchers of the cellar. Without a notifier only the watchers connected to this
+// instance get the events.
+type Broker struct {
+	id         string
+	notifier   Notifier
+	bufferSize int
+	logger     *zap.Logger
+
+	mu       sync.Mutex
+	watchers map[uint]map[chan CellarEvent]struct{}
+}
+
+func NewBroker(notifier Notifier, bufferSize int, logger *zap.Logger) *Broker {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+
+	return &Broker{
+		id:         uuid.NewString(),
+		notifier:   notifier,
+		bufferSize: bufferSize,
+		logger:     logger,
+		watchers:   map[uint]map[chan CellarEvent]struct{}{},
+	}
+}
+
+// Subscribe returns the events of the cellar, along with a function to stop watching it. The channel is closed when
+// the watcher falls too far behind, in which case it has missed events and should reload the cellar.
+func (b *Broker) Subscribe(cellarID uint) (<-chan CellarEvent, func()) {
+	events := make(chan CellarEvent, b.bufferSize)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.watchers[cellarID] == nil {
+		b.watchers[cellarID] = map[chan CellarEvent]struct{}{}
+	}
+
+	b.watchers[cellarID][events] = struct{}{}
+
+	return events, func() { b.unsubscribe(cellarID, events) }
+}
+
+func (b *Broker) unsubscribe(cellarID uint, events chan CellarEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.remove(cellarID, events)
+}
+
+// remove drops a watcher, closing its channel. It must be called with the lock held.
+func (b *Broker) remove(cellarID uint, events chan CellarEvent) {
+	if _, found := b.watchers[cellarID][events]; !found {
+		return
+	}
+
+	delete(b.watchers[cellarID], events)
+	close(events)
+
+	if len(b.watchers[cellarID]) == 0 {
+		delete(b.watchers, cellarID)
+	}
+}
+
+// Publish delivers the event to the watchers of the cellar, and to the other instances when there is a notifier.
+// Failing to notify other instances is logged, as the change itself has already been saved.
+func (b *Broker) Publish(ctx context.Context, event CellarEvent) {
+	b.deliver(event)
+
+	if b.notifier == nil {
+		return
+	}
+
+	event.Source = b.id
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		b.logger.Error("error encoding cellar event", zap.Error(err))
+
+		return
+	}
+
+	err = b.notifier.NotifyCellarEvent(ctx, string(payload))
+	if err != nil {
+		b.logger.Warn("error notifying cellar event", zap.Uint("cellar_id", event.CellarID), zap.Error(err))
+	}
+}
+
+func (b *Broker) deliver(event CellarEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for events := range b.watchers[event.CellarID] {
+		select {
+		case events <- event:
+		default:
+			b.logger.Warn("dropping cellar watcher that fell behind", zap.Uint("cellar_id", event.CellarID))
+			b.remove(event.CellarID, events)
+		}
+	}
+}
+
+// Run delivers the events published on other instances until the context is cancelled, listening again whenever the
+// connection is lost. It returns straight away without a notifier.
+func (b *Broker) Run(ctx context.Context) {
+	if b.notifier == nil {
+		return
+	}
+
+	for {
+		err := b.notifier.ListenCellarEvents(ctx, b.receive)
+		if ctx.Err() != nil {
+			return
+		}
+
+		b.logger.Error("error listening for cellar events", zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetryInterval):
+		}
+	}
+}
+
+func (b *Broker) receive(payload string) {
+	var event CellarEvent
+
+	err := json.Unmarshal([]byte(payload), &event)
+	if err != nil {
+		b.logger.Warn("ignoring invalid cellar event", zap.String("payload", payload), zap.Error(err))
+
+		return
+	}
+
+	if event.Source == b.id {
+		return
+	}
+
+	b.deliver(event)
+}
@@ -0,0 +1,141 @@
+package events_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap/zaptest"
+
+	"droscher.com/BeerGargoyle/pkg/events"
+)
+
+// fakeNotifier records the payloads notified, and hands the payloads queued up to listeners.
+type fakeNotifier struct {
+	notified []string
+	queued   []string
+}
+
+func (f *fakeNotifier) NotifyCellarEvent(_ context.Context, payload string) error {
+	f.notified = append(f.notified, payload)
+
+	return nil
+}
+
+func (f *fakeNotifier) ListenCellarEvents(ctx context.Context, handle func(payload string)) error {
+	for _, payload := range f.queued {
+		handle(payload)
+	}
+
+	<-ctx.Done()
+
+	return ctx.Err()
+}
+
+type BrokerTestSuite struct {
+	suite.Suite
+}
+
+func TestBrokerTestSuite(t *testing.T) {
+	suite.Run(t, new(BrokerTestSuite))
+}
+
+func (suite *BrokerTestSuite) TestPublish_DeliversToWatchersOfCellar() {
+	broker := events.NewBroker(nil, 0, zaptest.NewLogger(suite.T()))
+
+	first, stopFirst := broker.Subscribe(1)
+	defer stopFirst()
+
+	second, stopSecond := broker.Subscribe(1)
+	defer stopSecond()
+
+	other, stopOther := broker.Subscribe(2)
+	defer stopOther()
+
+	event := events.CellarEvent{Type: events.CellarEntryAdded, CellarID: 1, EntryID: 10}
+	broker.Publish(context.Background(), event)
+
+	suite.Equal(event, <-first)
+	suite.Equal(event, <-second)
+	suite.Empty(other)
+}
+
+func (suite *BrokerTestSuite) TestSubscribe_StopClosesEvents() {
+	broker := events.NewBroker(nil, 0, zaptest.NewLogger(suite.T()))
+
+	cellarEvents, stop := broker.Subscribe(1)
+	stop()
+	stop()
+
+	_, open := <-cellarEvents
+	suite.False(open)
+
+	broker.Publish(context.Background(), events.CellarEvent{Type: events.CellarEntryAdded, CellarID: 1, EntryID: 10})
+}
+
+func (suite *BrokerTestSuite) TestPublish_DropsWatchersFallingBehind() {
+	broker := events.NewBroker(nil, 2, zaptest.NewLogger(suite.T()))
+
+	cellarEvents, stop := broker.Subscribe(1)
+	defer stop()
+
+	for entryID := range uint(3) {
+		broker.Publish(context.Background(), events.CellarEvent{Type: events.CellarEntryUpdated, CellarID: 1, EntryID: entryID})
+	}
+
+	suite.Equal(uint(0), (<-cellarEvents).EntryID)
+	suite.Equal(uint(1), (<-cellarEvents).EntryID)
+
+	_, open := <-cellarEvents
+	suite.False(open)
+}
+
+func (suite *BrokerTestSuite) TestPublish_NotifiesOtherInstances() {
+	notifier := &fakeNotifier{}
+	broker := events.NewBroker(notifier, 0, zaptest.NewLogger(suite.T()))
+
+	broker.Publish(context.Background(), events.CellarEvent{Type: events.CellarEntryDeleted, CellarID: 1, EntryID: 10})
+
+	suite.Require().Len(notifier.notified, 1)
+
+	var event events.CellarEvent
+	suite.Require().NoError(json.Unmarshal([]byte(notifier.notified[0]), &event))
+	suite.Equal(events.CellarEntryDeleted, event.Type)
+	suite.Equal(uint(10), event.EntryID)
+	suite.NotEmpty(event.Source)
+}
+
+func (suite *BrokerTestSuite) TestRun_DeliversEventsFromOtherInstances() {
+	notifier := &fakeNotifier{}
+	broker := events.NewBroker(notifier, 0, zaptest.NewLogger(suite.T()))
+
+	// An event this instance published comes back from the database, and must not be delivered twice.
+	broker.Publish(context.Background(), events.CellarEvent{Type: events.CellarEntryAdded, CellarID: 1, EntryID: 10})
+	notifier.queued = append(notifier.queued, notifier.notified[0],
+		`{"type":"quantity_changed","cellar_id":1,"entry_id":11,"previous_quantity":2,"source":"other"}`, "not json")
+
+	cellarEvents, stop := broker.Subscribe(1)
+	defer stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		broker.Run(ctx)
+		close(done)
+	}()
+
+	event := <-cellarEvents
+	cancel()
+	<-done
+
+	suite.Equal(events.CellarEvent{
+		Type:             events.CellarEntryQuantityChanged,
+		CellarID:         1,
+		EntryID:          11,
+		PreviousQuantity: 2,
+		Source:           "other",
+	}, event)
+	suite.Empty(cellarEvents)
+}
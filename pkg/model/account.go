@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// AccountExport holds everything stored for a user, as handed to them before their account is deleted.
+type AccountExport struct {
+	ExportedAt    time.Time
+	User          User
+	AccessTokens  []AccessToken
+	Cellars       []Cellar
+	CellarEntries []CellarEntry
+	// Consumptions are the beers drunk by the user, along with those drunk by anyone from the user's cellars.
+	Consumptions    []Consumption
+	AdventCalendars []AdventCalendar
+	TastingNotes    []TastingNote
+	// Memberships are the user's memberships of cellars owned by other users.
+	Memberships []CellarMember
+	// BeerFormats are the custom formats the user created.
+	BeerFormats []BeerFormat
+}
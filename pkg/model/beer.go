@@ -1,10 +1,26 @@
 package model
 
-import "gorm.io/gorm"
+import (
+	"time"
 
+	"gorm.io/gorm"
+)
+
+// BeerStyle is a style of beer. Styles are grouped in families: "IPA - American" and "IPA - New England" both have
+// the "IPA" family as their parent, and a family has no parent. The guideline ranges are optional.
 type BeerStyle struct {
 	gorm.Model
-	Name string `gorm:"uniqueIndex"`
+	Name     string     `gorm:"uniqueIndex"`
+	ParentID *uint      `gorm:"index"`
+	Parent   *BeerStyle `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	MinABV   *float64
+	MaxABV   *float64
+	MinIBU   *uint
+	MaxIBU   *uint
+	MinSRM   *float64
+	MaxSRM   *float64
+	// CellarYears is how many years beers of the style typically keep improving in the cellar.
+	CellarYears *uint
 }
 
 type Beer struct {
@@ -25,14 +41,84 @@ type Beer struct {
 	Style   BeerStyle `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 }
 
+// TastingNote is a user's own impression of a beer, optionally of a particular vintage or of a bottle from one of
+// their cellar entries. The scores are all out of five and are each optional.
+type TastingNote struct {
+	gorm.Model
+	UserID        uint `gorm:"index"`
+	BeerID        uint `gorm:"index"`
+	CellarEntryID *uint
+	Vintage       *uint64
+	Rating        *float64
+	Aroma         *float64
+	Appearance    *float64
+	Taste         *float64
+	Mouthfeel     *float64
+	Notes         string
+	ServedAt      *time.Time
+
+	Beer Beer `gorm:"foreignKey:BeerID"`
+}
+
+// BeerFormat is a package and size beer comes in. Formats without an owner are global and available to every user,
+// formats with an owner are custom formats only available to that user.
 type BeerFormat struct {
 	gorm.Model
 	Package      string
 	SizeMetric   float64
 	SizeImperial float64
+	OwnerID      *uint `gorm:"index"`
 }
 
 type Tag struct {
 	gorm.Model
 	Tag string
 }
+
+// TagUsage counts how often a tag is used. BeerCount counts the beers in the whole catalog with the tag, and Cellars
+// counts its use in the cellars of one user.
+type TagUsage struct {
+	Tag       Tag
+	BeerCount uint64
+	Cellars   []CellarTagUsage
+}
+
+// CellarTagUsage counts the entries of a cellar having a tag, and the beers in the cellar having it as a beer tag.
+type CellarTagUsage struct {
+	TagID      uint
+	CellarID   uint
+	CellarName string
+	EntryCount uint64
+	BeerCount  uint64
+}
+
+// BeerMatch is a beer found by a search, with how well it matched. Higher scores are better matches.
+type BeerMatch struct {
+	Beer  Beer
+	Score float64
+}
+
+type BreweryMatch struct {
+	Brewery Brewery
+	Score   float64
+}
+
+// DuplicateReason is why two beers, or two breweries, are thought to be the same.
+type DuplicateReason string
+
+const (
+	// DuplicateReasonName means the names only differ in case, spacing or punctuation. Beers must also share a brewery.
+	DuplicateReasonName DuplicateReason = "name"
+	// DuplicateReasonExternalID means both were imported from the same entry of the same integration.
+	DuplicateReasonExternalID DuplicateReason = "external_id"
+)
+
+// DuplicateCandidate is a pair of beers, or of breweries, that are likely duplicates. The older of the two is proposed
+// to be kept, and the newer one merged into it.
+type DuplicateCandidate struct {
+	KeepID    uint
+	KeepName  string
+	MergeID   uint
+	MergeName string
+	Reason    DuplicateReason
+}
@@ -6,22 +6,93 @@ import (
 	"gorm.io/gorm"
 )
 
+type CellarRole string
+
+const (
+	CellarRoleNone   CellarRole = ""
+	CellarRoleViewer CellarRole = "viewer"
+	CellarRoleEditor CellarRole = "editor"
+	CellarRoleOwner  CellarRole = "owner"
+)
+
+func (r CellarRole) rank() int {
+	switch r {
+	case CellarRoleViewer:
+		return 1
+	case CellarRoleEditor:
+		return 2
+	case CellarRoleOwner:
+		return 3
+	case CellarRoleNone:
+		return 0
+	}
+
+	return 0
+}
+
+// Allows returns true if the role grants at least the access of the required role.
+func (r CellarRole) Allows(required CellarRole) bool {
+	return r.Valid() && r.rank() >= required.rank()
+}
+
+func (r CellarRole) Valid() bool {
+	return r.rank() > 0
+}
+
 type Cellar struct {
 	gorm.Model
 	Name        string `gorm:"uniqueIndex:idx_name_owner"`
 	Description string
 	OwnerID     uint `gorm:"uniqueIndex:idx_name_owner"`
 	Locations   []LocationInCellar
+	Members     []CellarMember
 
 	Owner User `gorm:"foreignKey:OwnerID"`
 }
 
+// RoleFor returns the role the user has in the cellar, based on ownership and any accepted memberships that have been
+// loaded with the cellar.
+func (c *Cellar) RoleFor(userID uint) CellarRole {
+	if c.OwnerID == userID {
+		return CellarRoleOwner
+	}
+
+	for _, member := range c.Members {
+		if member.UserID == userID && member.AcceptedAt != nil {
+			return member.Role
+		}
+	}
+
+	return CellarRoleNone
+}
+
+type CellarMember struct {
+	gorm.Model
+	CellarID    uint `gorm:"uniqueIndex:idx_cellar_member"`
+	UserID      uint `gorm:"uniqueIndex:idx_cellar_member"`
+	Role        CellarRole
+	InvitedByID uint
+	AcceptedAt  *time.Time
+
+	Cellar Cellar `gorm:"foreignKey:CellarID"`
+	User   User   `gorm:"foreignKey:UserID"`
+}
+
 type LocationInCellar struct {
 	gorm.Model
 	Name     string
 	CellarID uint
 }
 
+// MinVintage is the oldest vintage cellar entries can have.
+const MinVintage = 1800
+
+// MaxVintage returns the newest vintage cellar entries can have, which is next year's, as some beers are released
+// ahead of the year on their label.
+func MaxVintage() uint64 {
+	return uint64(time.Now().Year()) + 1 //nolint:gosec // the year is never negative
+}
+
 type CellarEntry struct {
 	gorm.Model
 	CellarID    uint
@@ -43,6 +114,23 @@ type CellarEntry struct {
 	Format   *BeerFormat       `gorm:"foreignKey:FormatID"`
 }
 
+// Consumption records beers drunk from a cellar entry. The cellar and beer are copied from the entry, so that the
+// history is kept once the entry runs out and is deleted.
+type Consumption struct {
+	gorm.Model
+	CellarEntryID uint
+	CellarID      uint
+	BeerID        uint
+	UserID        uint
+	Quantity      int64
+	DrankAt       time.Time
+	Rating        *float64
+	Notes         string
+
+	Beer Beer `gorm:"foreignKey:BeerID"`
+	User User `gorm:"foreignKey:UserID"`
+}
+
 type CellarStats struct {
 	CellarID      uint
 	BeerCount     uint64
@@ -53,6 +141,44 @@ type CellarStats struct {
 	SpecialCount  uint64
 	AverageABV    float64
 	AverageRating float64
+	Verticals     []VerticalSummary `gorm:"-"`
+}
+
+// VerticalSummary describes a beer a cellar holds more than one vintage of. A vertical is complete when the cellar
+// holds every vintage between the first and last it holds.
+type VerticalSummary struct {
+	BeerID          uint
+	BeerName        string
+	Vintages        []uint64
+	MissingVintages []uint64
+}
+
+func (v VerticalSummary) Complete() bool {
+	return len(v.MissingVintages) == 0
+}
+
+// BeerVertical is every vintage of a beer held in a cellar, with the entries and tasting notes of each vintage.
+type BeerVertical struct {
+	Beer     Beer
+	Vintages []VerticalVintage
+}
+
+// VerticalVintage groups the entries and tasting notes of one vintage of a beer. Vintage is nil for the entries and
+// notes without one.
+type VerticalVintage struct {
+	Vintage      *uint64
+	Entries      []*CellarEntry
+	TastingNotes []*TastingNote
+}
+
+func (v VerticalVintage) Quantity() int64 {
+	var quantity int64
+
+	for _, entry := range v.Entries {
+		quantity += entry.Quantity
+	}
+
+	return quantity
 }
 
 type CellarRecommendationRanges struct {
@@ -104,4 +230,7 @@ type AdventCalendarFilter struct {
 	MaximumRating   *float64
 	Tags            []Tag `gorm:"many2many:advent_calendar_filter_tags;"`
 	AddedBefore     *time.Time
+	BeerTags        []Tag `gorm:"many2many:advent_calendar_filter_beer_tags;"`
+	// UseMyRating compares the ratings the user regenerating a day gave the beers instead of the external ratings.
+	UseMyRating bool
 }
@@ -0,0 +1,9 @@
+package model
+
+// SeedData is the reference data loaded into the catalog by the seed command: the global formats, the beer styles and
+// common tags.
+type SeedData struct {
+	Formats []BeerFormat
+	Styles  []BeerStyle
+	Tags    []Tag
+}
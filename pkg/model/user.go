@@ -1,10 +1,19 @@
 package model
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+type UserRole string
+
+const (
+	UserRoleUser  UserRole = "user"
+	UserRoleAdmin UserRole = "admin"
+)
+
 type User struct {
 	gorm.Model
 	UUID            uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4()"`
@@ -13,4 +22,36 @@ type User struct {
 	LastName        string
 	Email           string
 	UntappdUserName *string
+	Role            UserRole `gorm:"default:user"`
+	Disabled        bool
+}
+
+func (u *User) IsAdmin() bool {
+	return u.Role == UserRoleAdmin
+}
+
+type AccessTokenScope string
+
+const (
+	AccessTokenScopeReadOnly  AccessTokenScope = "read_only"
+	AccessTokenScopeReadWrite AccessTokenScope = "read_write"
+)
+
+// AccessToken is a personal access token. Only a hash of the secret is stored, along with its first few characters so
+// that users can tell their tokens apart.
+type AccessToken struct {
+	gorm.Model
+	UUID       uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4()"`
+	UserID     uint      `gorm:"index"`
+	User       User
+	Name       string
+	Prefix     string
+	Hash       string `gorm:"uniqueIndex" json:"-"`
+	Scope      AccessTokenScope
+	LastUsedAt *time.Time
+	ExpiresAt  *time.Time
+}
+
+func (t *AccessToken) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
 }
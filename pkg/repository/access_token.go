@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+)
+
+var ErrAccessTokenNotFound = newError(ErrNotFound, "access token not found")
+
+func (r *Repository) CreateAccessToken(ctx context.Context, token model.AccessToken) (*model.AccessToken, error) {
+	token.UUID = uuid.New()
+
+	if result := r.DB.WithContext(ctx).Create(&token); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &token, nil
+}
+
+func (r *Repository) ListAccessTokens(ctx context.Context, userID uint) ([]*model.AccessToken, error) {
+	var tokens []*model.AccessToken
+
+	result := r.DB.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&tokens)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return tokens, nil
+}
+
+// RevokeAccessToken deletes one of the user's tokens. Tokens belonging to other users are reported as not found.
+func (r *Repository) RevokeAccessToken(ctx context.Context, userID uint, tokenUUID uuid.UUID) error {
+	result := r.DB.WithContext(ctx).Where("user_id = ? AND uuid = ?", userID, tokenUUID).Delete(&model.AccessToken{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return resourceError(ErrAccessTokenNotFound, "access_token", tokenUUID)
+	}
+
+	return nil
+}
+
+// GetAccessTokenByHash finds the token with the hashed secret, along with the user it belongs to. Tokens of deleted
+// users are not found.
+func (r *Repository) GetAccessTokenByHash(ctx context.Context, hash string) (*model.AccessToken, error) {
+	var token model.AccessToken
+
+	result := r.DB.WithContext(ctx).InnerJoins("User").Where("access_tokens.hash = ?", hash).First(&token)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrAccessTokenNotFound
+		}
+
+		return nil, result.Error
+	}
+
+	return &token, nil
+}
+
+// TouchAccessToken records when the token was last used, without changing its updated_at time.
+func (r *Repository) TouchAccessToken(ctx context.Context, tokenID uint, usedAt time.Time) error {
+	result := r.DB.WithContext(ctx).Model(&model.AccessToken{}).Where("id = ?", tokenID).UpdateColumn("last_used_at", usedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"droscher.com/BeerGargoyle/pkg/repository"
+)
+
+type AccessTokenTestSuite struct {
+	RepositorySuite
+}
+
+func TestAccessTokenTestSuite(t *testing.T) {
+	suite.Run(t, new(AccessTokenTestSuite))
+}
+
+func (suite *AccessTokenTestSuite) TearDownTest() {
+	suite.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *AccessTokenTestSuite) TestGetAccessTokenByHash_GetsTokenWithUser() {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "access_tokens"."id","access_tokens"."created_at","access_tokens"."updated_at","access_tokens"."deleted_at","access_tokens"."uuid","access_tokens"."user_id","access_tokens"."name","access_tokens"."prefix","access_tokens"."hash","access_tokens"."scope","access_tokens"."last_used_at","access_tokens"."expires_at","User"."id" AS "User__id","User"."created_at" AS "User__created_at","User"."updated_at" AS "User__updated_at","User"."deleted_at" AS "User__deleted_at","User"."uuid" AS "User__uuid","User"."username" AS "User__username","User"."first_name" AS "User__first_name","User"."last_name" AS "User__last_name","User"."email" AS "User__email","User"."untappd_user_name" AS "User__untappd_user_name","User"."role" AS "User__role","User"."disabled" AS "User__disabled" FROM "access_tokens" INNER JOIN "users" "User" ON "access_tokens"."user_id" = "User"."id" AND "User"."deleted_at" IS NULL WHERE access_tokens.hash = $1 AND "access_tokens"."deleted_at" IS NULL ORDER BY "access_tokens"."id" LIMIT $2`)).
+		WithArgs("abc123", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "scope", "User__id", "User__email"}).
+			AddRow(4, 1, "read_only", 1, "test@example.com"))
+
+	token, err := suite.repository.GetAccessTokenByHash(context.Background(), "abc123")
+
+	suite.Require().NoError(err)
+	suite.Equal(uint(4), token.ID)
+	suite.Equal("test@example.com", token.User.Email)
+}
+
+func (suite *AccessTokenTestSuite) TestGetAccessTokenByHash_NotFound() {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "access_tokens"."id","access_tokens"."created_at","access_tokens"."updated_at","access_tokens"."deleted_at","access_tokens"."uuid","access_tokens"."user_id","access_tokens"."name","access_tokens"."prefix","access_tokens"."hash","access_tokens"."scope","access_tokens"."last_used_at","access_tokens"."expires_at","User"."id" AS "User__id","User"."created_at" AS "User__created_at","User"."updated_at" AS "User__updated_at","User"."deleted_at" AS "User__deleted_at","User"."uuid" AS "User__uuid","User"."username" AS "User__username","User"."first_name" AS "User__first_name","User"."last_name" AS "User__last_name","User"."email" AS "User__email","User"."untappd_user_name" AS "User__untappd_user_name","User"."role" AS "User__role","User"."disabled" AS "User__disabled" FROM "access_tokens" INNER JOIN "users" "User" ON "access_tokens"."user_id" = "User"."id" AND "User"."deleted_at" IS NULL WHERE access_tokens.hash = $1 AND "access_tokens"."deleted_at" IS NULL ORDER BY "access_tokens"."id" LIMIT $2`)).
+		WithArgs("abc123", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	token, err := suite.repository.GetAccessTokenByHash(context.Background(), "abc123")
+
+	suite.Require().ErrorIs(err, repository.ErrAccessTokenNotFound)
+	suite.Nil(token)
+}
+
+func (suite *AccessTokenTestSuite) TestRevokeAccessToken_OtherUsersToken() {
+	tokenUUID := uuid.MustParse("3c4a8f2e-1b6d-4e0a-9f7c-5d2e8b1a6c93")
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "access_tokens" SET "deleted_at"=$1 WHERE (user_id = $2 AND uuid = $3) AND "access_tokens"."deleted_at" IS NULL`)).
+		WithArgs(sqlmock.AnyArg(), 1, tokenUUID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.RevokeAccessToken(context.Background(), 1, tokenUUID)
+
+	suite.Require().ErrorIs(err, repository.ErrAccessTokenNotFound)
+}
+
+func (suite *AccessTokenTestSuite) TestTouchAccessToken_SetsLastUsed() {
+	usedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "access_tokens" SET "last_used_at"=$1 WHERE id = $2 AND "access_tokens"."deleted_at" IS NULL`)).
+		WithArgs(usedAt, 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.TouchAccessToken(context.Background(), 4, usedAt)
+
+	suite.Require().NoError(err)
+}
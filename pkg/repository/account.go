@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+)
+
+// accountIDs are the IDs of the rows owned by a user through their cellars.
+type accountIDs struct {
+	cellars   []uint
+	entries   []uint
+	calendars []uint
+	filters   []uint
+}
+
+// ExportAccount collects all the data stored for the user.
+func (r *Repository) ExportAccount(ctx context.Context, userID uint) (*model.AccountExport, error) {
+	export := model.AccountExport{ExportedAt: time.Now().UTC()}
+
+	db := r.DB.WithContext(ctx)
+
+	result := db.First(&export.User, userID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, resourceError(ErrUserNotFound, "user", userID)
+		}
+
+		return nil, result.Error
+	}
+
+	ownedCellars := db.Model(&model.Cellar{}).Select("id").Where("owner_id = ?", userID)
+
+	for _, query := range []*gorm.DB{
+		db.Where("user_id = ?", userID).Order("id").Find(&export.AccessTokens),
+		db.Preload("Locations").Preload("Members").Where("owner_id = ?", userID).Order("id").Find(&export.Cellars),
+		db.Preload("Tags").Preload("Beer").Where("cellar_id IN (?)", ownedCellars).Order("id").Find(&export.CellarEntries),
+		db.Preload("Beer").Where("user_id = ? OR cellar_id IN (?)", userID, ownedCellars).Order("id").Find(&export.Consumptions),
+		db.Preload("Beer").Where("user_id = ?", userID).Order("id").Find(&export.TastingNotes),
+		db.Preload("Beers.Filter.Tags").Preload("Beers.Filter.BeerTags").Where("cellar_id IN (?)", ownedCellars).Order("id").Find(&export.AdventCalendars),
+		db.Preload("Cellar").Where("user_id = ?", userID).Order("id").Find(&export.Memberships),
+		db.Where("owner_id = ?", userID).Order("id").Find(&export.BeerFormats),
+	} {
+		if query.Error != nil {
+			return nil, query.Error
+		}
+	}
+
+	return &export, nil
+}
+
+// DeleteAccount permanently deletes the user along with their cellars, everything kept in them, the beers they drank,
+// their tasting notes, their memberships of other cellars, their custom formats and their access tokens, bypassing
+// soft deletion. Tags and beers are shared and are kept. Entries in other users' cellars using one of the custom
+// formats lose their format, and other users' tasting notes of the deleted entries lose their entry.
+func (r *Repository) DeleteAccount(ctx context.Context, userID uint) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user model.User
+
+		result := tx.Unscoped().Select("id").First(&user, userID)
+		if result.Error != nil {
+			if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+				return resourceError(ErrUserNotFound, "user", userID)
+			}
+
+			return result.Error
+		}
+
+		ids, err := ownedIDs(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		return execStatements(tx, []statement{
+			{"DELETE FROM advent_calendar_filter_tags WHERE advent_calendar_filter_id IN ?", []any{ids.filters}},
+			{"DELETE FROM advent_calendar_filter_beer_tags WHERE advent_calendar_filter_id IN ?", []any{ids.filters}},
+			{"DELETE FROM advent_calendar_beers WHERE advent_calendar_id IN ?", []any{ids.calendars}},
+			{"DELETE FROM advent_calendar_filters WHERE id IN ?", []any{ids.filters}},
+			{"DELETE FROM advent_calendars WHERE id IN ?", []any{ids.calendars}},
+			{"DELETE FROM consumptions WHERE cellar_id IN ? OR user_id = ?", []any{ids.cellars, userID}},
+			{"DELETE FROM tasting_notes WHERE user_id = ?", []any{userID}},
+			{"UPDATE tasting_notes SET cellar_entry_id = NULL WHERE cellar_entry_id IN ?", []any{ids.entries}},
+			{"DELETE FROM cellar_entry_tags WHERE cellar_entry_id IN ?", []any{ids.entries}},
+			{"DELETE FROM cellar_entries WHERE id IN ?", []any{ids.entries}},
+			{"DELETE FROM location_in_cellars WHERE cellar_id IN ?", []any{ids.cellars}},
+			{"DELETE FROM cellar_members WHERE cellar_id IN ? OR user_id = ?", []any{ids.cellars, userID}},
+			{"DELETE FROM cellars WHERE id IN ?", []any{ids.cellars}},
+			{"UPDATE cellar_entries SET format_id = NULL WHERE format_id IN (SELECT id FROM beer_formats WHERE owner_id = ?)", []any{userID}},
+			{"DELETE FROM beer_formats WHERE owner_id = ?", []any{userID}},
+			{"DELETE FROM access_tokens WHERE user_id = ?", []any{userID}},
+			{"DELETE FROM users WHERE id = ?", []any{userID}},
+		})
+	})
+}
+
+// ownedIDs finds the rows that belong to the user's cellars, including soft deleted ones.
+func ownedIDs(tx *gorm.DB, userID uint) (accountIDs, error) {
+	var ids accountIDs
+
+	err := tx.Unscoped().Model(&model.Cellar{}).Where("owner_id = ?", userID).Pluck("id", &ids.cellars).Error
+	if err != nil {
+		return ids, err
+	}
+
+	err = tx.Unscoped().Model(&model.CellarEntry{}).Where("cellar_id IN ?", ids.cellars).Pluck("id", &ids.entries).Error
+	if err != nil {
+		return ids, err
+	}
+
+	err = tx.Unscoped().Model(&model.AdventCalendar{}).Where("cellar_id IN ?", ids.cellars).Pluck("id", &ids.calendars).Error
+	if err != nil {
+		return ids, err
+	}
+
+	err = tx.Unscoped().Model(&model.AdventCalendarBeer{}).Where("advent_calendar_id IN ?", ids.calendars).
+		Distinct().Pluck("filter_id", &ids.filters).Error
+	if err != nil {
+		return ids, err
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,98 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+
+	"droscher.com/BeerGargoyle/pkg/repository"
+)
+
+type AccountTestSuite struct {
+	RepositorySuite
+}
+
+func TestAccountTestSuite(t *testing.T) {
+	suite.Run(t, new(AccountTestSuite))
+}
+
+func (suite *AccountTestSuite) TearDownTest() {
+	suite.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *AccountTestSuite) TestDeleteAccount_DeletesEverythingOwned() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "users" WHERE "users"."id" = $1 ORDER BY "users"."id" LIMIT $2`)).
+		WithArgs(3, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "cellars" WHERE owner_id = $1`)).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10).AddRow(11))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "cellar_entries" WHERE cellar_id IN ($1,$2)`)).
+		WithArgs(10, 11).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(100))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "advent_calendars" WHERE cellar_id IN ($1,$2)`)).
+		WithArgs(10, 11).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(20))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT DISTINCT "filter_id" FROM "advent_calendar_beers" WHERE advent_calendar_id IN ($1)`)).
+		WithArgs(20).
+		WillReturnRows(sqlmock.NewRows([]string{"filter_id"}).AddRow(30).AddRow(31))
+
+	for _, statement := range []struct {
+		sql  string
+		args []driver.Value
+	}{
+		{`DELETE FROM advent_calendar_filter_tags WHERE advent_calendar_filter_id IN ($1,$2)`, []driver.Value{30, 31}},
+		{`DELETE FROM advent_calendar_filter_beer_tags WHERE advent_calendar_filter_id IN ($1,$2)`, []driver.Value{30, 31}},
+		{`DELETE FROM advent_calendar_beers WHERE advent_calendar_id IN ($1)`, []driver.Value{20}},
+		{`DELETE FROM advent_calendar_filters WHERE id IN ($1,$2)`, []driver.Value{30, 31}},
+		{`DELETE FROM advent_calendars WHERE id IN ($1)`, []driver.Value{20}},
+		{`DELETE FROM consumptions WHERE cellar_id IN ($1,$2) OR user_id = $3`, []driver.Value{10, 11, 3}},
+		{`DELETE FROM tasting_notes WHERE user_id = $1`, []driver.Value{3}},
+		{`UPDATE tasting_notes SET cellar_entry_id = NULL WHERE cellar_entry_id IN ($1)`, []driver.Value{100}},
+		{`DELETE FROM cellar_entry_tags WHERE cellar_entry_id IN ($1)`, []driver.Value{100}},
+		{`DELETE FROM cellar_entries WHERE id IN ($1)`, []driver.Value{100}},
+		{`DELETE FROM location_in_cellars WHERE cellar_id IN ($1,$2)`, []driver.Value{10, 11}},
+		{`DELETE FROM cellar_members WHERE cellar_id IN ($1,$2) OR user_id = $3`, []driver.Value{10, 11, 3}},
+		{`DELETE FROM cellars WHERE id IN ($1,$2)`, []driver.Value{10, 11}},
+		{`UPDATE cellar_entries SET format_id = NULL WHERE format_id IN (SELECT id FROM beer_formats WHERE owner_id = $1)`, []driver.Value{3}},
+		{`DELETE FROM beer_formats WHERE owner_id = $1`, []driver.Value{3}},
+		{`DELETE FROM access_tokens WHERE user_id = $1`, []driver.Value{3}},
+		{`DELETE FROM users WHERE id = $1`, []driver.Value{3}},
+	} {
+		suite.mock.ExpectExec(regexp.QuoteMeta(statement.sql)).WithArgs(statement.args...).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.DeleteAccount(context.Background(), 3)
+
+	suite.Require().NoError(err)
+}
+
+func (suite *AccountTestSuite) TestDeleteAccount_UnknownUser() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "users" WHERE "users"."id" = $1 ORDER BY "users"."id" LIMIT $2`)).
+		WithArgs(3, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	suite.mock.ExpectRollback()
+
+	err := suite.repository.DeleteAccount(context.Background(), 3)
+
+	suite.Require().ErrorIs(err, repository.ErrUserNotFound)
+}
+
+func (suite *AccountTestSuite) TestExportAccount_UnknownUser() {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE "users"."id" = $1 AND "users"."deleted_at" IS NULL ORDER BY "users"."id" LIMIT $2`)).
+		WithArgs(3, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	export, err := suite.repository.ExportAccount(context.Background(), 3)
+
+	suite.Require().ErrorIs(err, repository.ErrUserNotFound)
+	suite.Nil(export)
+}
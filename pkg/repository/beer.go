@@ -3,14 +3,63 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 
 	"droscher.com/BeerGargoyle/pkg/model"
+	api "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
 )
 
-var ErrBreweryNotFound = errors.New("brewery not found")
+var (
+	ErrBreweryNotFound = newError(ErrNotFound, "brewery not found")
+	ErrBeerNotFound    = newError(ErrNotFound, "beer not found")
+)
+
+type BeerRepository interface { //nolint:interfacebloat // this is an acceptable interface
+	AddBeer(ctx context.Context, beer model.Beer) (*model.Beer, error)
+	AddBeerFormat(ctx context.Context, format *model.BeerFormat) error
+	AddBeerStyle(ctx context.Context, style string) (*model.BeerStyle, error)
+	AddBrewery(ctx context.Context, brewery model.Brewery) (*model.Brewery, error)
+	DeleteBeerFormat(ctx context.Context, formatID uint) error
+	FindDuplicateBeers(ctx context.Context) ([]model.DuplicateCandidate, error)
+	FindDuplicateBreweries(ctx context.Context) ([]model.DuplicateCandidate, error)
+	FindBreweryByExternalSource(ctx context.Context, externalID uint64, externalSource string) (*model.Brewery, error)
+	GetBeer(ctx context.Context, beerID uint) (*model.Beer, error)
+	GetBeerFormat(ctx context.Context, formatID uint) (*model.BeerFormat, error)
+	GetBeerFormats(ctx context.Context, userID uint) ([]*model.BeerFormat, error)
+	GetBeerStyle(ctx context.Context, styleID uint) (*model.BeerStyle, error)
+	GetBrewery(ctx context.Context, breweryID uint) (*model.Brewery, error)
+	GetTagsByNames(ctx context.Context, names []string) (map[string]model.Tag, error)
+	ListBeerStyles(ctx context.Context) ([]*model.BeerStyle, error)
+	ListBeers(ctx context.Context, filter *api.BeerFilter, sort BeerSort, page Page) ([]*model.Beer, string, error)
+	ListBreweries(ctx context.Context, query string, page Page) ([]*model.Brewery, string, error)
+	MergeBeers(ctx context.Context, keepID uint, mergeID uint) (*model.Beer, error)
+	MergeBreweries(ctx context.Context, keepID uint, mergeID uint) (*model.Brewery, error)
+	SearchBeers(ctx context.Context, query string, page Page) ([]*model.Beer, string, error)
+	SearchCatalog(ctx context.Context, query string, limit int) ([]model.BeerMatch, []model.BreweryMatch, error)
+	UpdateBeerDetails(ctx context.Context, beer *model.Beer) error
+	UpdateBeerFormat(ctx context.Context, format *model.BeerFormat) error
+	UpdateBeerStyle(ctx context.Context, style *model.BeerStyle) error
+	UpdateBrewery(ctx context.Context, brewery *model.Brewery) error
+}
+
+// BeerSortField is the value beers are listed by. Beers with the same value are ordered by ID.
+type BeerSortField string
+
+const (
+	BeerSortName   BeerSortField = "name"
+	BeerSortABV    BeerSortField = "abv"
+	BeerSortIBU    BeerSortField = "ibu"
+	BeerSortRating BeerSortField = "rating"
+)
+
+type BeerSort struct {
+	Field      BeerSortField
+	Descending bool
+}
 
 func (r *Repository) AddBeer(ctx context.Context, beer model.Beer) (*model.Beer, error) {
 	result := r.DB.WithContext(ctx).Clauses(clause.OnConflict{
@@ -25,6 +74,202 @@ func (r *Repository) AddBeer(ctx context.Context, beer model.Beer) (*model.Beer,
 	return &beer, nil
 }
 
+func (r *Repository) GetBeer(ctx context.Context, beerID uint) (*model.Beer, error) {
+	var beer model.Beer
+
+	result := r.DB.WithContext(ctx).Preload("Brewery.Address").Preload("Style").Preload("Tags").First(&beer, beerID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, resourceError(ErrBeerNotFound, "beer", beerID)
+		}
+
+		return nil, result.Error
+	}
+
+	return &beer, nil
+}
+
+// UpdateBeerDetails saves the descriptive fields of the beer and replaces its tags. The name and brewery identify the
+// beer and are not changed.
+func (r *Repository) UpdateBeerDetails(ctx context.Context, beer *model.Beer) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(beer).Select("description", "image_url", "style_id", "abv", "ibu").Updates(beer)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return resourceError(ErrBeerNotFound, "beer", beer.ID)
+		}
+
+		return tx.Model(beer).Association("Tags").Replace(beer.Tags)
+	})
+}
+
+// ListBeers returns a page of the beers in the catalog matching the filter.
+func (r *Repository) ListBeers(ctx context.Context, filter *api.BeerFilter, sort BeerSort, page Page) ([]*model.Beer, string, error) {
+	query := r.DB.WithContext(ctx)
+
+	if filter != nil {
+		query = filterBeers(query, filter)
+	}
+
+	return listBeers(query, sort, page)
+}
+
+// SearchBeers returns a page of the beers whose name, or whose brewery's name, contains the query, sorted by name.
+func (r *Repository) SearchBeers(ctx context.Context, query string, page Page) ([]*model.Beer, string, error) {
+	pattern := "%" + escapeLike(strings.TrimSpace(query)) + "%"
+
+	search := r.DB.WithContext(ctx).
+		Where("beers.name ILIKE ? OR beers.brewery_id IN (SELECT id FROM breweries WHERE name ILIKE ?)", pattern, pattern)
+
+	return listBeers(search, BeerSort{Field: BeerSortName}, page)
+}
+
+func filterBeers(query *gorm.DB, filter *api.BeerFilter) *gorm.DB {
+	if filter.BreweryId != nil {
+		query = query.Where("beers.brewery_id = ?", filter.GetBreweryId())
+	}
+
+	if filter.StyleId != nil {
+		query = query.Where("beers.style_id IN ("+styleFamily+")", filter.GetStyleId())
+	}
+
+	if filter.MinimumAbv != nil {
+		query = query.Where("beers.abv >= ?", filter.GetMinimumAbv())
+	}
+
+	if filter.MaximumAbv != nil {
+		query = query.Where("beers.abv <= ?", filter.GetMaximumAbv())
+	}
+
+	if filter.MinimumIbu != nil {
+		query = query.Where("beers.ibu >= ?", filter.GetMinimumIbu())
+	}
+
+	if filter.MaximumIbu != nil {
+		query = query.Where("beers.ibu <= ?", filter.GetMaximumIbu())
+	}
+
+	return query
+}
+
+// listBeers pages through the beers selected by the query. The page token records the sort value of the last beer,
+// so a token can only be used with the sort it was created for.
+func listBeers(query *gorm.DB, sort BeerSort, page Page) ([]*model.Beer, string, error) {
+	if sort.Field == "" {
+		sort.Field = BeerSortName
+	}
+
+	column, err := sort.column()
+	if err != nil {
+		return nil, "", err
+	}
+
+	cursor, err := page.cursor()
+	if err != nil {
+		return nil, "", err
+	}
+
+	direction, comparison := "ASC", ">"
+	if sort.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	if page.Token != "" {
+		err = cursor.check(sort.String(), sort.keyKind())
+		if err != nil {
+			return nil, "", err
+		}
+
+		query = query.Where(fmt.Sprintf("(%s, beers.id) %s (?, ?)", column, comparison), cursor.Key, cursor.LastID)
+	}
+
+	var beers []*model.Beer
+
+	limit := page.Limit()
+
+	result := query.Preload("Brewery.Address").Preload("Style").Preload("Tags").
+		Order(column + " " + direction).Order("beers.id " + direction).
+		Limit(limit + 1).
+		Find(&beers)
+	if result.Error != nil {
+		return nil, "", result.Error
+	}
+
+	beers, nextToken := nextPageToken(beers, limit, func(beer *model.Beer) pageCursor {
+		return pageCursor{LastID: beer.ID, Sort: sort.String(), Key: sort.key(beer)}
+	})
+
+	return beers, nextToken, nil
+}
+
+func (s BeerSort) String() string {
+	if s.Descending {
+		return string(s.Field) + " desc"
+	}
+
+	return string(s.Field)
+}
+
+// column returns the expression beers are sorted on. Missing values sort as zero, so that every beer has a position
+// to continue paging from.
+func (s BeerSort) column() (string, error) {
+	switch s.Field {
+	case BeerSortName:
+		return "beers.name", nil
+	case BeerSortABV:
+		return "COALESCE(beers.abv, 0)", nil
+	case BeerSortIBU:
+		return "COALESCE(beers.ibu, 0)::float8", nil
+	case BeerSortRating:
+		return "COALESCE(beers.external_rating, 0)", nil
+	}
+
+	return "", fmt.Errorf("%w: unknown sort %q", ErrInvalidSort, s.Field)
+}
+
+// key returns the value of the sort column for the beer, matching column.
+func (s BeerSort) key(beer *model.Beer) any {
+	switch s.Field {
+	case BeerSortName:
+		return beer.Name
+	case BeerSortABV:
+		return valueOrZero(beer.ABV)
+	case BeerSortIBU:
+		return float64(valueOrZero(beer.IBU))
+	case BeerSortRating:
+		return valueOrZero(beer.ExternalRating)
+	}
+
+	return nil
+}
+
+// keyKind returns the kind of value key returns.
+func (s BeerSort) keyKind() keyKind {
+	if s.Field == BeerSortName {
+		return textKey
+	}
+
+	return numberKey
+}
+
+func valueOrZero[T any](value *T) T {
+	var zero T
+
+	if value == nil {
+		return zero
+	}
+
+	return *value
+}
+
+// escapeLike escapes the wildcard characters of a LIKE pattern, so that they match literally.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
 func (r *Repository) FindBreweryByExternalSource(ctx context.Context, externalID uint64, externalSource string) (*model.Brewery, error) {
 	brewery := &model.Brewery{}
 	result := r.DB.WithContext(ctx).Model(&brewery).
@@ -42,8 +287,20 @@ func (r *Repository) FindBreweryByExternalSource(ctx context.Context, externalID
 	return brewery, nil
 }
 
+// AddBeerStyle returns the style with the given name, adding it when it is missing. A new style named like the Untappd
+// styles is put in its family, which is added as well when it is missing.
 func (r *Repository) AddBeerStyle(ctx context.Context, style string) (*model.BeerStyle, error) {
 	beerStyle := model.BeerStyle{Name: style}
+
+	if family, found := styleFamilyName(style); found {
+		parent, err := r.AddBeerStyle(ctx, family)
+		if err != nil {
+			return nil, err
+		}
+
+		beerStyle.ParentID = &parent.ID
+	}
+
 	if result := r.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&beerStyle); result.Error != nil {
 		return nil, result.Error
 	}
@@ -57,16 +314,6 @@ func (r *Repository) AddBeerStyle(ctx context.Context, style string) (*model.Bee
 	return &beerStyle, nil
 }
 
-func (r *Repository) GetBeerFormats(ctx context.Context) ([]*model.BeerFormat, error) {
-	var beerFormats []*model.BeerFormat
-
-	if result := r.DB.WithContext(ctx).Order("package, size_metric").Find(&beerFormats); result.Error != nil {
-		return nil, result.Error
-	}
-
-	return beerFormats, nil
-}
-
 func (r *Repository) GetTagsByNames(ctx context.Context, names []string) (map[string]model.Tag, error) {
 	var tags []*model.Tag
 
@@ -12,6 +12,7 @@ import (
 
 	"droscher.com/BeerGargoyle/pkg/model"
 	"droscher.com/BeerGargoyle/pkg/repository"
+	apiv1 "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
 )
 
 type BeerTestSuite struct {
@@ -70,10 +71,12 @@ func (suite *BeerTestSuite) TestFindBreweryByExternalSource_ReturnsErrorWhenNoRe
 	suite.Equal("record not found", errorLog.ContextMap()["error"])
 }
 
+const insertBeerStyle = `INSERT INTO "beer_styles" ("created_at","updated_at","deleted_at","name","parent_id","min_abv","max_abv","min_ibu","max_ibu","min_srm","max_srm","cellar_years") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12) ON CONFLICT DO NOTHING RETURNING "id"`
+
 func (suite *BeerTestSuite) TestAddBeerStyle_AddsBeer() {
 	suite.mock.ExpectBegin()
-	suite.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "beer_styles" ("created_at","updated_at","deleted_at","name") VALUES ($1,$2,$3,$4) ON CONFLICT DO NOTHING RETURNING "id"`)).
-		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "New Style!").
+	suite.mock.ExpectQuery(regexp.QuoteMeta(insertBeerStyle)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "New Style!", nil, nil, nil, nil, nil, nil, nil, nil).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uint(1)))
 	suite.mock.ExpectCommit()
 
@@ -84,12 +87,34 @@ func (suite *BeerTestSuite) TestAddBeerStyle_AddsBeer() {
 	suite.Equal("New Style!", style.Name)
 }
 
+func (suite *BeerTestSuite) TestAddBeerStyle_AddsFamily() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(insertBeerStyle)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "IPA", nil, nil, nil, nil, nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	suite.mock.ExpectCommit()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "beer_styles" WHERE name = $1 AND "beer_styles"."deleted_at" IS NULL ORDER BY "beer_styles"."id" LIMIT $2`)).
+		WithArgs("IPA", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(uint(4), "IPA"))
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(insertBeerStyle)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "IPA - New England", 4, nil, nil, nil, nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uint(9)))
+	suite.mock.ExpectCommit()
+
+	style, err := suite.repository.AddBeerStyle(context.Background(), "IPA - New England")
+	suite.Require().NoError(err)
+	suite.Equal(uint(9), style.ID)
+	suite.Equal(pointy.Uint(4), style.ParentID)
+}
+
 func (suite *BeerTestSuite) TestGetBeerFormats_GetsFormats() {
-	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "beer_formats" WHERE "beer_formats"."deleted_at" IS NULL`)).
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "beer_formats" WHERE (owner_id IS NULL OR owner_id = $1) AND "beer_formats"."deleted_at" IS NULL ORDER BY package, size_metric`)).
+		WithArgs(3).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "package", "size_metric", "size_imperial"}).
 			AddRow(uint(2), "Bottle", 650, 22).AddRow(uint(1), "Can", 355.0, 12.0))
 
-	formats, err := suite.repository.GetBeerFormats(context.Background())
+	formats, err := suite.repository.GetBeerFormats(context.Background(), 3)
 	suite.Require().NoError(err)
 	suite.NotNil(formats)
 	suite.Len(formats, 2)
@@ -100,3 +125,107 @@ func (suite *BeerTestSuite) TestGetBeerFormats_GetsFormats() {
 	suite.InDelta(355.0, formats[1].SizeMetric, 0.1)
 	suite.InDelta(12.0, formats[1].SizeImperial, 0.1)
 }
+
+func (suite *BeerTestSuite) TestGetBeer_NotFound() {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "beers" WHERE "beers"."id" = $1 AND "beers"."deleted_at" IS NULL ORDER BY "beers"."id" LIMIT $2`)).
+		WithArgs(5, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	beer, err := suite.repository.GetBeer(context.Background(), 5)
+
+	suite.Require().ErrorIs(err, repository.ErrBeerNotFound)
+	suite.Require().ErrorIs(err, repository.ErrNotFound)
+
+	var resourceErr *repository.ResourceError
+	suite.Require().ErrorAs(err, &resourceErr)
+	suite.Equal("beer", resourceErr.Resource)
+	suite.Equal("5", resourceErr.ID)
+	suite.Nil(beer)
+}
+
+func (suite *BeerTestSuite) TestListBeers_FiltersAndSorts() {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "beers" WHERE beers.brewery_id = $1 AND beers.abv >= $2 AND "beers"."deleted_at" IS NULL ORDER BY COALESCE(beers.abv, 0) DESC,beers.id DESC LIMIT $3`)).
+		WithArgs(10, 5.0, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "abv"}).
+			AddRow(3, "Strong", 9.5).
+			AddRow(1, "Medium", 7.0).
+			AddRow(2, "Lighter", 5.5))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "beer_tags" WHERE "beer_tags"."beer_id" IN ($1,$2,$3)`)).
+		WithArgs(3, 1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"beer_id", "tag_id"}))
+
+	beers, nextPageToken, err := suite.repository.ListBeers(context.Background(),
+		&apiv1.BeerFilter{BreweryId: pointy.Uint64(10), MinimumAbv: pointy.Float64(5)},
+		repository.BeerSort{Field: repository.BeerSortABV, Descending: true},
+		repository.Page{Size: 2})
+
+	suite.Require().NoError(err)
+	suite.Len(beers, 2)
+	suite.NotEmpty(nextPageToken)
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "beers" WHERE (COALESCE(beers.abv, 0), beers.id) < ($1, $2) AND "beers"."deleted_at" IS NULL ORDER BY COALESCE(beers.abv, 0) DESC,beers.id DESC LIMIT $3`)).
+		WithArgs(7.0, 1, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "abv"}).AddRow(2, "Lighter", 5.5))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "beer_tags" WHERE "beer_tags"."beer_id" = $1`)).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"beer_id", "tag_id"}))
+
+	beers, nextPageToken, err = suite.repository.ListBeers(context.Background(), nil,
+		repository.BeerSort{Field: repository.BeerSortABV, Descending: true},
+		repository.Page{Size: 2, Token: nextPageToken})
+
+	suite.Require().NoError(err)
+	suite.Len(beers, 1)
+	suite.Empty(nextPageToken)
+}
+
+func (suite *BeerTestSuite) TestListBeers_TokenFromDifferentSort() {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "beers" WHERE "beers"."deleted_at" IS NULL ORDER BY beers.name ASC,beers.id ASC LIMIT $1`)).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "A").AddRow(2, "B"))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "beer_tags" WHERE "beer_tags"."beer_id" IN ($1,$2)`)).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"beer_id", "tag_id"}))
+
+	_, nextPageToken, err := suite.repository.ListBeers(context.Background(), nil, repository.BeerSort{}, repository.Page{Size: 1})
+	suite.Require().NoError(err)
+
+	_, _, err = suite.repository.ListBeers(context.Background(), nil, repository.BeerSort{Field: repository.BeerSortIBU}, repository.Page{Token: nextPageToken})
+
+	suite.Require().ErrorIs(err, repository.ErrInvalidPageToken)
+}
+
+func (suite *BeerTestSuite) TestListBeers_TokenWithWrongKeyType() {
+	token := pageToken(`{"id":1,"sort":"abv","key":"strong"}`)
+
+	_, _, err := suite.repository.ListBeers(context.Background(), nil, repository.BeerSort{Field: repository.BeerSortABV}, repository.Page{Token: token})
+
+	suite.Require().ErrorIs(err, repository.ErrInvalidPageToken)
+}
+
+func (suite *BeerTestSuite) TestSearchBeers_EscapesWildcards() {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "beers" WHERE (beers.name ILIKE $1 OR beers.brewery_id IN (SELECT id FROM breweries WHERE name ILIKE $2)) AND "beers"."deleted_at" IS NULL ORDER BY beers.name ASC,beers.id ASC LIMIT $3`)).
+		WithArgs(`%100\% Brett%`, `%100\% Brett%`, 51).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "100% Brett"))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "beer_tags" WHERE "beer_tags"."beer_id" = $1`)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"beer_id", "tag_id"}))
+
+	beers, nextPageToken, err := suite.repository.SearchBeers(context.Background(), " 100% Brett ", repository.Page{})
+
+	suite.Require().NoError(err)
+	suite.Len(beers, 1)
+	suite.Empty(nextPageToken)
+}
+
+func (suite *BeerTestSuite) TestUpdateBeerDetails_NotFound() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "beers" SET "updated_at"=$1,"description"=$2,"image_url"=$3,"style_id"=$4,"abv"=$5,"ibu"=$6 WHERE "beers"."deleted_at" IS NULL AND "id" = $7`)).
+		WithArgs(sqlmock.AnyArg(), "Sour", "", 0, nil, nil, 9).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectRollback()
+
+	err := suite.repository.UpdateBeerDetails(context.Background(), &model.Beer{Model: gorm.Model{ID: 9}, Description: "Sour"})
+
+	suite.Require().ErrorIs(err, repository.ErrBeerNotFound)
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+)
+
+// brewerySort is the only sort breweries are listed by, recorded in page tokens.
+const brewerySort = "name"
+
+func (r *Repository) GetBrewery(ctx context.Context, breweryID uint) (*model.Brewery, error) {
+	var brewery model.Brewery
+
+	result := r.DB.WithContext(ctx).Preload("Address").First(&brewery, breweryID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, resourceError(ErrBreweryNotFound, "brewery", breweryID)
+		}
+
+		return nil, result.Error
+	}
+
+	return &brewery, nil
+}
+
+// ListBreweries returns a page of the breweries sorted by name, only including those whose name contains the query
+// when one is given.
+func (r *Repository) ListBreweries(ctx context.Context, query string, page Page) ([]*model.Brewery, string, error) {
+	cursor, err := page.cursor()
+	if err != nil {
+		return nil, "", err
+	}
+
+	search := r.DB.WithContext(ctx)
+
+	if query = strings.TrimSpace(query); query != "" {
+		search = search.Where("breweries.name ILIKE ?", "%"+escapeLike(query)+"%")
+	}
+
+	if page.Token != "" {
+		err = cursor.check(brewerySort, textKey)
+		if err != nil {
+			return nil, "", err
+		}
+
+		search = search.Where("(breweries.name, breweries.id) > (?, ?)", cursor.Key, cursor.LastID)
+	}
+
+	var breweries []*model.Brewery
+
+	limit := page.Limit()
+
+	result := search.Preload("Address").Order("breweries.name").Order("breweries.id").Limit(limit + 1).Find(&breweries)
+	if result.Error != nil {
+		return nil, "", result.Error
+	}
+
+	breweries, nextToken := nextPageToken(breweries, limit, func(brewery *model.Brewery) pageCursor {
+		return pageCursor{LastID: brewery.ID, Sort: brewerySort, Key: brewery.Name}
+	})
+
+	return breweries, nextToken, nil
+}
+
+// AddBrewery creates the brewery along with its address.
+func (r *Repository) AddBrewery(ctx context.Context, brewery model.Brewery) (*model.Brewery, error) {
+	if result := r.DB.WithContext(ctx).Create(&brewery); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &brewery, nil
+}
+
+// UpdateBrewery saves the editable fields of the brewery and its address.
+func (r *Repository) UpdateBrewery(ctx context.Context, brewery *model.Brewery) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(brewery).Select("name", "description", "image_url").Updates(brewery)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return resourceError(ErrBreweryNotFound, "brewery", brewery.ID)
+		}
+
+		if brewery.Address.ID == 0 {
+			return nil
+		}
+
+		result = tx.Model(&brewery.Address).
+			Select("country", "locality", "region", "postal_code", "street_address").
+			Updates(&brewery.Address)
+
+		return result.Error
+	})
+}
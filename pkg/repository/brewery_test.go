@@ -0,0 +1,96 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+)
+
+type BreweryTestSuite struct {
+	RepositorySuite
+}
+
+func TestBreweryTestSuite(t *testing.T) {
+	suite.Run(t, new(BreweryTestSuite))
+}
+
+func (suite *BreweryTestSuite) TearDownTest() {
+	suite.Require().NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *BreweryTestSuite) TestGetBrewery_NotFound() {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "breweries" WHERE "breweries"."id" = $1 AND "breweries"."deleted_at" IS NULL ORDER BY "breweries"."id" LIMIT $2`)).
+		WithArgs(9, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := suite.repository.GetBrewery(context.Background(), 9)
+
+	suite.Require().ErrorIs(err, repository.ErrBreweryNotFound)
+}
+
+func (suite *BreweryTestSuite) TestListBreweries_FiltersAndPages() {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "breweries" WHERE breweries.name ILIKE $1 AND "breweries"."deleted_at" IS NULL ORDER BY breweries.name,breweries.id LIMIT $2`)).
+		WithArgs(`%Bell\_%`, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "address_id"}).
+			AddRow(4, "Bell_ Brewery", 1).AddRow(2, "Bell_woods", 1).AddRow(7, "Bell_y", 1))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "addresses" WHERE "addresses"."id" = $1 AND "addresses"."deleted_at" IS NULL`)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "locality"}).AddRow(1, "Toronto"))
+
+	breweries, nextPageToken, err := suite.repository.ListBreweries(context.Background(), " Bell_ ", repository.Page{Size: 2})
+
+	suite.Require().NoError(err)
+	suite.Require().Len(breweries, 2)
+	suite.Equal("Toronto", breweries[0].Address.Locality)
+	suite.NotEmpty(nextPageToken)
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "breweries" WHERE (breweries.name, breweries.id) > ($1, $2) AND "breweries"."deleted_at" IS NULL ORDER BY breweries.name,breweries.id LIMIT $3`)).
+		WithArgs("Bell_woods", 2, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(7, "Bell_y"))
+
+	breweries, nextPageToken, err = suite.repository.ListBreweries(context.Background(), "", repository.Page{Size: 2, Token: nextPageToken})
+
+	suite.Require().NoError(err)
+	suite.Len(breweries, 1)
+	suite.Empty(nextPageToken)
+}
+
+func (suite *BreweryTestSuite) TestUpdateBrewery_UpdatesBreweryAndAddress() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "breweries" SET "updated_at"=$1,"name"=$2,"description"=$3,"image_url"=$4 WHERE "breweries"."deleted_at" IS NULL AND "id" = $5`)).
+		WithArgs(sqlmock.AnyArg(), "Bellwoods Brewery", "Ossington", "", 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "addresses" SET "updated_at"=$1,"country"=$2,"locality"=$3,"region"=$4,"postal_code"=$5,"street_address"=$6 WHERE "addresses"."deleted_at" IS NULL AND "id" = $7`)).
+		WithArgs(sqlmock.AnyArg(), "Canada", "Toronto", nil, nil, nil, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	brewery := model.Brewery{
+		Model:       gorm.Model{ID: 4},
+		Name:        "Bellwoods Brewery",
+		Description: "Ossington",
+		Address:     model.Address{Model: gorm.Model{ID: 1}, Country: "Canada", Locality: "Toronto"},
+	}
+
+	err := suite.repository.UpdateBrewery(context.Background(), &brewery)
+
+	suite.Require().NoError(err)
+}
+
+func (suite *BreweryTestSuite) TestUpdateBrewery_NotFound() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "breweries" SET "updated_at"=$1,"name"=$2,"description"=$3,"image_url"=$4 WHERE "breweries"."deleted_at" IS NULL AND "id" = $5`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectRollback()
+
+	err := suite.repository.UpdateBrewery(context.Background(), &model.Brewery{Model: gorm.Model{ID: 4}, Name: "Bellwoods"})
+
+	suite.Require().ErrorIs(err, repository.ErrBreweryNotFound)
+}
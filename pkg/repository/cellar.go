@@ -2,10 +2,12 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"droscher.com/BeerGargoyle/pkg/model"
 	api "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
@@ -13,10 +15,16 @@ import (
 
 type CellarRepository interface { //nolint:interfacebloat // this is an acceptable interface
 	AddBeerToCellar(ctx context.Context, beer model.CellarEntry) (*model.CellarEntry, error)
+	AcceptCellarInvitation(ctx context.Context, cellarID uint, userID uint) error
 	AddCellar(ctx context.Context, name string, description string, locations []string, owner model.User) (*model.Cellar, error)
+	AddCellarMember(ctx context.Context, member model.CellarMember) (*model.CellarMember, error)
+	AddTastingNote(ctx context.Context, note model.TastingNote) (*model.TastingNote, error)
 	DeleteAdventCalendar(ctx context.Context, cellarID uint64, calendarID uint64) error
 	DeleteCellarEntry(ctx context.Context, cellarEntryID uint) error
-	FindBeerRecommendations(ctx context.Context, cellarID uint64, filter *api.CellarFilter) ([]*model.CellarEntry, error)
+	DeleteTastingNote(ctx context.Context, noteID uint, userID uint) error
+	DrinkBeer(ctx context.Context, consumption model.Consumption) (*model.Consumption, *model.CellarEntry, error)
+	FindBeerRecommendations(ctx context.Context, cellarID uint64, filter *api.CellarFilter, raterID uint) ([]*model.CellarEntry, error)
+	GetBeerVertical(ctx context.Context, cellarID uint, beerID uint, userID uint) (*model.BeerVertical, error)
 	GetAdventCalendarByID(ctx context.Context, cellarID uint64, calendarID uint64) (*model.AdventCalendar, error)
 	GetAdventCalendarByName(ctx context.Context, cellarID uint64, name string) (*model.AdventCalendar, error)
 	GetAdventCalendarForDate(ctx context.Context, cellarID uint64, date time.Time) (*model.AdventCalendar, error)
@@ -24,15 +32,45 @@ type CellarRepository interface { //nolint:interfacebloat // this is an acceptab
 	GetCellarBreweryNames(ctx context.Context, cellarID uint64) ([]*model.Brewery, error)
 	GetCellarByID(ctx context.Context, cellarID uint) (*model.Cellar, error)
 	GetCellarEntryByID(ctx context.Context, cellarEntryID uint) (*model.CellarEntry, error)
-	GetCellarBeers(ctx context.Context, cellarID uint) ([]*model.CellarEntry, error)
+	GetCellarInvitationsForUser(ctx context.Context, user model.User) ([]*model.Cellar, error)
+	GetCellarMembers(ctx context.Context, cellarID uint) ([]*model.CellarMember, error)
 	GetCellarRecommendationRanges(ctx context.Context, cellarID uint64) (*model.CellarRecommendationRanges, error)
-	GetCellarStats(ctx context.Context, cellarID uint) (*model.CellarStats, error)
+	GetCellarStats(ctx context.Context, cellarID uint, raterID uint) (*model.CellarStats, error)
 	GetCellarStyles(ctx context.Context, cellarID uint64) ([]*model.BeerStyle, error)
 	GetCellarsForUser(ctx context.Context, user model.User) ([]*model.Cellar, error)
+	ListCellarBeers(ctx context.Context, cellarID uint, filter *api.CellarFilter, raterID uint, sort CellarSort, page Page) ([]*model.CellarEntry, string, int64, error)
+	ListConsumptions(ctx context.Context, filter ConsumptionFilter, page Page) ([]*model.Consumption, string, error)
+	ListTastingNotes(ctx context.Context, userID uint, beerID uint, page Page) ([]*model.TastingNote, string, error)
+	RemoveCellarMember(ctx context.Context, cellarID uint, userID uint) error
+	GetCellarEntryRole(ctx context.Context, cellarEntryID uint, userID uint) (model.CellarRole, error)
+	GetCellarRole(ctx context.Context, cellarID uint, userID uint) (model.CellarRole, error)
 	SaveAdventCalendar(ctx context.Context, calendar model.AdventCalendar) (*model.AdventCalendar, error)
 	UpdateAdventCalendar(ctx context.Context, cellarID uint64, calendarID uint64, day time.Time) error
 	UpdateAdventCalendarEntry(ctx context.Context, cellarID uint64, calendarID uint64, day time.Time, cellarEntryID uint64) error
 	UpdateCellarEntry(ctx context.Context, entry *model.CellarEntry) (*model.CellarEntry, error)
+	UpdateCellarMemberRole(ctx context.Context, cellarID uint, userID uint, role model.CellarRole) error
+	UpdateTastingNote(ctx context.Context, note *model.TastingNote) (*model.TastingNote, error)
+}
+
+// CellarSortField is the value cellar entries are listed by. Entries with the same value are ordered by ID.
+type CellarSortField string
+
+const (
+	CellarSortName        CellarSortField = "name"
+	CellarSortBrewery     CellarSortField = "brewery"
+	CellarSortABV         CellarSortField = "abv"
+	CellarSortRating      CellarSortField = "rating"
+	CellarSortDateAdded   CellarSortField = "date_added"
+	CellarSortDrinkBefore CellarSortField = "drink_before"
+	CellarSortVintage     CellarSortField = "vintage"
+)
+
+type CellarSort struct {
+	Field      CellarSortField
+	Descending bool
+
+	// rater is the user whose own ratings the rating sort uses, set by ListCellarBeers from its rater.
+	rater uint
 }
 
 func (r *Repository) AddCellar(ctx context.Context, name string, description string, locations []string, owner model.User) (*model.Cellar, error) {
@@ -57,9 +95,18 @@ func (r *Repository) AddCellar(ctx context.Context, name string, description str
 func (r *Repository) GetCellarsForUser(ctx context.Context, user model.User) ([]*model.Cellar, error) {
 	var cellars []*model.Cellar
 
-	result := r.DB.WithContext(ctx).Where("owner_id = ?", user.ID).
+	db := r.DB.WithContext(ctx)
+
+	sharedCellars := db.Model(&model.CellarMember{}).
+		Select("cellar_id").
+		Where("user_id = ? AND accepted_at IS NOT NULL", user.ID)
+
+	result := db.
+		Where("owner_id = ?", user.ID).
+		Or("cellars.id IN (?)", sharedCellars).
 		Joins("Owner").
 		Preload("Locations").
+		Preload("Members", "user_id = ?", user.ID).
 		Find(&cellars)
 	if result.Error != nil {
 		r.Logger.Error("error getting cellars for user", zap.Uint("user_id", user.ID), zap.Error(result.Error))
@@ -84,6 +131,48 @@ func (r *Repository) GetCellarByID(ctx context.Context, cellarID uint) (*model.C
 	return &cellar, nil
 }
 
+// GetCellarRole returns the role the user has in the cellar, or model.CellarRoleNone if the user is neither the owner
+// nor an accepted member, or the cellar does not exist.
+func (r *Repository) GetCellarRole(ctx context.Context, cellarID uint, userID uint) (model.CellarRole, error) {
+	var roles []model.CellarRole
+
+	result := r.DB.WithContext(ctx).Table("cellars c").
+		Select("CASE WHEN c.owner_id = ? THEN ? ELSE COALESCE(m.role, '') END", userID, model.CellarRoleOwner).
+		Joins("LEFT JOIN cellar_members m ON m.cellar_id = c.id AND m.user_id = ? AND m.accepted_at IS NOT NULL AND m.deleted_at IS NULL", userID).
+		Where("c.id = ? AND c.deleted_at IS NULL", cellarID).
+		Scan(&roles)
+	if result.Error != nil {
+		return model.CellarRoleNone, result.Error
+	}
+
+	if len(roles) == 0 {
+		return model.CellarRoleNone, nil
+	}
+
+	return roles[0], nil
+}
+
+// GetCellarEntryRole returns the role the user has in the cellar containing the cellar entry.
+func (r *Repository) GetCellarEntryRole(ctx context.Context, cellarEntryID uint, userID uint) (model.CellarRole, error) {
+	var roles []model.CellarRole
+
+	result := r.DB.WithContext(ctx).Table("cellar_entries ce").
+		Select("CASE WHEN c.owner_id = ? THEN ? ELSE COALESCE(m.role, '') END", userID, model.CellarRoleOwner).
+		Joins("INNER JOIN cellars c ON c.id = ce.cellar_id AND c.deleted_at IS NULL").
+		Joins("LEFT JOIN cellar_members m ON m.cellar_id = c.id AND m.user_id = ? AND m.accepted_at IS NOT NULL AND m.deleted_at IS NULL", userID).
+		Where("ce.id = ? AND ce.deleted_at IS NULL", cellarEntryID).
+		Scan(&roles)
+	if result.Error != nil {
+		return model.CellarRoleNone, result.Error
+	}
+
+	if len(roles) == 0 {
+		return model.CellarRoleNone, nil
+	}
+
+	return roles[0], nil
+}
+
 func (r *Repository) AddBeerToCellar(ctx context.Context, beer model.CellarEntry) (*model.CellarEntry, error) {
 	if result := r.DB.WithContext(ctx).Create(&beer); result.Error != nil {
 		return nil, result.Error
@@ -99,6 +188,7 @@ func (r *Repository) GetCellarEntryByID(ctx context.Context, cellarEntryID uint)
 		Joins("Beer").
 		Joins("Location").
 		Joins("Format").
+		Preload("Beer.Tags").
 		First(&cellarEntry, cellarEntryID)
 	if result.Error != nil {
 		return nil, result.Error
@@ -114,9 +204,16 @@ func (r *Repository) GetCellarEntryByID(ctx context.Context, cellarEntryID uint)
 	return &cellarEntry, nil
 }
 
-func (r *Repository) GetCellarStats(ctx context.Context, cellarID uint) (*model.CellarStats, error) {
+// GetCellarStats sums up the entries in a cellar and summarises its verticals. The average rating is of the external
+// ratings, or of the ratings the rater gave the beers when there is one.
+func (r *Repository) GetCellarStats(ctx context.Context, cellarID uint, raterID uint) (*model.CellarStats, error) {
 	var stats model.CellarStats
 
+	averageRating, ratingArgs := "avg(b.external_rating)", []any{}
+	if raterID != 0 {
+		averageRating, ratingArgs = "avg("+userRating("b.id")+")", []any{raterID}
+	}
+
 	result := r.DB.WithContext(ctx).Table("cellar_entries as ce").
 		Select("sum(quantity) as beer_count, "+
 			"count(distinct ce.beer_id) as unique_count, "+
@@ -125,7 +222,7 @@ func (r *Repository) GetCellarStats(ctx context.Context, cellarID uint) (*model.
 			"sum(case when had_before = true then 0 else 1 end) as untried_count, "+
 			"sum(case when special = true then 1 else 0 end) as special_count, "+
 			"avg(b.abv) as average_abv, "+
-			"avg(b.external_rating) as average_rating").
+			averageRating+" as average_rating", ratingArgs...).
 		Joins("INNER JOIN beer_formats bf on bf.id = ce.format_id").
 		Joins("INNER JOIN beers b on b.id = ce.beer_id").
 		Where("cellar_id = ?", cellarID).
@@ -138,28 +235,205 @@ func (r *Repository) GetCellarStats(ctx context.Context, cellarID uint) (*model.
 
 	stats.CellarID = cellarID
 
+	verticals, err := r.getCellarVerticals(ctx, cellarID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.Verticals = verticals
+
 	return &stats, nil
 }
 
-func (r *Repository) GetCellarBeers(ctx context.Context, cellarID uint) ([]*model.CellarEntry, error) {
-	var beers []*model.CellarEntry
+// ListCellarBeers returns a page of the entries in a cellar matching the filter, along with the number of entries
+// matching it across all pages. Ratings in the filter are compared with the rater's own ratings when there is a rater.
+// Entries sorted by rating are sorted by the rater's own ratings too. The page token records the sort value of the last
+// entry, so a token can only be used with the sort, and rater, it was created for.
+func (r *Repository) ListCellarBeers(ctx context.Context, cellarID uint, filter *api.CellarFilter, raterID uint, sort CellarSort, page Page) ([]*model.CellarEntry, string, int64, error) {
+	if sort.Field == "" {
+		sort.Field = CellarSortName
+	}
 
-	result := r.DB.WithContext(ctx).
+	if sort.Field == CellarSortRating {
+		sort.rater = raterID
+	}
+
+	column, columnArgs, err := sort.column()
+	if err != nil {
+		return nil, "", 0, err
+	}
+
+	cursor, err := page.cursor()
+	if err != nil {
+		return nil, "", 0, err
+	}
+
+	if page.Token != "" {
+		err = cursor.check(sort.String(), sort.keyKind())
+		if err != nil {
+			return nil, "", 0, err
+		}
+	}
+
+	// The filter refers to the beer and format of the entries, so both are joined when counting too.
+	query := r.DB.WithContext(ctx).Model(&model.CellarEntry{}).
 		Joins("Beer").
-		Joins("Location").
 		Joins("Format").
+		Where("cellar_entries.cellar_id = ?", cellarID)
+
+	if filter != nil {
+		updateQueryWithCriteria(filter, raterID, query)
+	}
+
+	var total int64
+
+	result := query.Session(&gorm.Session{}).Count(&total)
+	if result.Error != nil {
+		return nil, "", 0, result.Error
+	}
+
+	direction, comparison := "ASC", ">"
+	if sort.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	entries := query.Session(&gorm.Session{})
+	if page.Token != "" {
+		entries = entries.Where(fmt.Sprintf("(%s, cellar_entries.id) %s (?, ?)", column, comparison), append(columnArgs, cursor.Key, cursor.LastID)...)
+	}
+
+	var beers []*model.CellarEntry
+
+	limit := page.Limit()
+
+	result = entries.
+		Joins("Location").
 		Joins("Cellar").
 		Preload("Tags").
-		Preload("Beer.Brewery").
 		Preload("Beer.Brewery.Address").
 		Preload("Beer.Style").
-		Where("cellar_entries.cellar_id = ?", cellarID).
+		Preload("Beer.Tags").
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: column + " " + direction + ",cellar_entries.id " + direction, Vars: columnArgs}}).
+		Limit(limit + 1).
 		Find(&beers)
+	if result.Error != nil {
+		return nil, "", 0, result.Error
+	}
+
+	var key any
+
+	if len(beers) > limit {
+		key, err = r.cellarSortKey(ctx, sort, beers[limit-1])
+		if err != nil {
+			return nil, "", 0, err
+		}
+	}
+
+	beers, nextToken := nextPageToken(beers, limit, func(entry *model.CellarEntry) pageCursor {
+		return pageCursor{LastID: entry.ID, Sort: sort.String(), Key: key}
+	})
+
+	return beers, nextToken, total, nil
+}
+
+func (s CellarSort) String() string {
+	sort := string(s.Field)
+	if s.rater != 0 {
+		sort += fmt.Sprintf(" by %d", s.rater)
+	}
+
+	if s.Descending {
+		sort += " desc"
+	}
+
+	return sort
+}
+
+// column returns the expression entries are sorted on, along with its arguments. Missing values sort as zero, apart
+// from dates which sort as described on the API, so that every entry has a position to continue paging from.
+func (s CellarSort) column() (string, []any, error) {
+	switch s.Field {
+	case CellarSortName:
+		return `"Beer".name`, nil, nil
+	case CellarSortBrewery:
+		return `COALESCE((SELECT breweries.name FROM breweries WHERE breweries.id = "Beer".brewery_id), '')`, nil, nil
+	case CellarSortABV:
+		return `COALESCE("Beer".abv, 0)`, nil, nil
+	case CellarSortRating:
+		if s.rater != 0 {
+			return "COALESCE(" + userRating(`"Beer".id`) + ", 0)", []any{s.rater}, nil
+		}
+
+		return `COALESCE("Beer".external_rating, 0)`, nil, nil
+	case CellarSortDateAdded:
+		return "COALESCE(cellar_entries.date_added, cellar_entries.created_at)", nil, nil
+	case CellarSortDrinkBefore:
+		return "COALESCE(cellar_entries.drink_before, 'infinity')", nil, nil
+	case CellarSortVintage:
+		return "COALESCE(cellar_entries.vintage, 0)::float8", nil, nil
+	}
+
+	return "", nil, fmt.Errorf("%w: unknown sort %q", ErrInvalidSort, s.Field)
+}
+
+// cellarSortKey returns the sort value of the entry. The rater's own ratings are not loaded with the entries, so they
+// are read from the database.
+func (r *Repository) cellarSortKey(ctx context.Context, sort CellarSort, entry *model.CellarEntry) (any, error) {
+	if sort.rater == 0 {
+		return sort.key(entry), nil
+	}
+
+	var rating float64
+
+	result := r.DB.WithContext(ctx).Raw("SELECT COALESCE("+userRating("?")+", 0)", entry.BeerID, sort.rater).Scan(&rating)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 
-	return beers, nil
+	return rating, nil
+}
+
+// key returns the value of the sort column for the entry, matching column. Dates are kept as text, which the database
+// reads back as the same timestamp.
+func (s CellarSort) key(entry *model.CellarEntry) any {
+	switch s.Field {
+	case CellarSortName:
+		return entry.Beer.Name
+	case CellarSortBrewery:
+		return entry.Beer.Brewery.Name
+	case CellarSortABV:
+		return valueOrZero(entry.Beer.ABV)
+	case CellarSortRating:
+		return valueOrZero(entry.Beer.ExternalRating)
+	case CellarSortDateAdded:
+		if entry.DateAdded == nil {
+			return entry.CreatedAt.Format(time.RFC3339Nano)
+		}
+
+		return entry.DateAdded.Format(time.RFC3339Nano)
+	case CellarSortDrinkBefore:
+		if entry.DrinkBefore == nil {
+			return "infinity"
+		}
+
+		return entry.DrinkBefore.Format(time.RFC3339Nano)
+	case CellarSortVintage:
+		return float64(valueOrZero(entry.Vintage))
+	}
+
+	return nil
+}
+
+// keyKind returns the kind of value key returns.
+func (s CellarSort) keyKind() keyKind {
+	switch s.Field {
+	case CellarSortName, CellarSortBrewery:
+		return textKey
+	case CellarSortDateAdded, CellarSortDrinkBefore:
+		return timeKey
+	}
+
+	return numberKey
 }
 
 func (r *Repository) DeleteCellarEntry(ctx context.Context, cellarEntryID uint) error {
@@ -176,7 +450,9 @@ func (r *Repository) UpdateCellarEntry(ctx context.Context, entry *model.CellarE
 	return entry, nil
 }
 
-func (r *Repository) FindBeerRecommendations(ctx context.Context, cellarID uint64, filter *api.CellarFilter) ([]*model.CellarEntry, error) {
+// FindBeerRecommendations returns the entries in a cellar matching the filter. Ratings in the filter are compared with
+// the rater's own ratings when there is a rater.
+func (r *Repository) FindBeerRecommendations(ctx context.Context, cellarID uint64, filter *api.CellarFilter, raterID uint) ([]*model.CellarEntry, error) {
 	var beers []*model.CellarEntry
 
 	query := r.DB.WithContext(ctx).
@@ -188,9 +464,10 @@ func (r *Repository) FindBeerRecommendations(ctx context.Context, cellarID uint6
 		Preload("Beer.Brewery").
 		Preload("Beer.Brewery.Address").
 		Preload("Beer.Style").
+		Preload("Beer.Tags").
 		Where("cellar_entries.cellar_id = ?", cellarID)
 
-	updateQueryWithCriteria(filter, query)
+	updateQueryWithCriteria(filter, raterID, query)
 
 	if result := query.Find(&beers); result.Error != nil {
 		return nil, result.Error
@@ -200,7 +477,7 @@ func (r *Repository) FindBeerRecommendations(ctx context.Context, cellarID uint6
 }
 
 //nolint:cyclop // this is as simple as it can be given the number of parameters
-func updateQueryWithCriteria(filter *api.CellarFilter, query *gorm.DB) {
+func updateQueryWithCriteria(filter *api.CellarFilter, raterID uint, query *gorm.DB) {
 	if filter.BreweryId != nil {
 		query = query.Where(`"Beer".brewery_id = ?`, filter.GetBreweryId())
 	}
@@ -214,11 +491,11 @@ func updateQueryWithCriteria(filter *api.CellarFilter, query *gorm.DB) {
 	}
 
 	if filter.MinimumRating != nil {
-		query = query.Where(`"Beer".external_rating >= ?`, filter.GetMinimumRating())
+		whereRating(query, raterID, ">=", filter.GetMinimumRating())
 	}
 
 	if filter.MaximumRating != nil {
-		query = query.Where(`"Beer".external_rating <= ?`, filter.GetMaximumRating())
+		whereRating(query, raterID, "<=", filter.GetMaximumRating())
 	}
 
 	if filter.MinimumSize != nil {
@@ -238,7 +515,7 @@ func updateQueryWithCriteria(filter *api.CellarFilter, query *gorm.DB) {
 	}
 
 	if filter.StyleId != nil {
-		query.Where(`"Beer".style_id = ?`, filter.GetStyleId())
+		query.Where(`"Beer".style_id IN (`+styleFamily+`)`, filter.GetStyleId())
 	}
 
 	if filter.OverdueToDrink != nil {
@@ -264,6 +541,21 @@ func updateQueryWithCriteria(filter *api.CellarFilter, query *gorm.DB) {
 	if filter.GetAddedBefore() != nil {
 		query.Where("date_added < ?", filter.GetAddedBefore().AsTime())
 	}
+
+	if len(filter.GetBeerTags()) > 0 {
+		query.Where(`"Beer".id IN (SELECT beer_id FROM beer_tags INNER JOIN tags ON tag_id = tags.id WHERE tag IN ? GROUP BY beer_id HAVING COUNT(*) = ?)`, filter.GetBeerTags(), len(filter.GetBeerTags()))
+	}
+}
+
+// whereRating compares the external rating of the beer with the value, or the rater's own rating when there is a rater.
+func whereRating(query *gorm.DB, raterID uint, comparison string, value float64) {
+	if raterID == 0 {
+		query.Where(`"Beer".external_rating `+comparison+` ?`, value)
+
+		return
+	}
+
+	query.Where(userRating(`"Beer".id`)+" "+comparison+" ?", raterID, value)
 }
 
 func (r *Repository) GetCellarBreweryNames(ctx context.Context, cellarID uint64) ([]*model.Brewery, error) {
@@ -284,17 +576,19 @@ func (r *Repository) GetCellarBreweryNames(ctx context.Context, cellarID uint64)
 	return breweries, nil
 }
 
+// GetCellarStyles returns the styles of the beers in a cellar along with their families, so that they can be shown as
+// a tree.
 func (r *Repository) GetCellarStyles(ctx context.Context, cellarID uint64) ([]*model.BeerStyle, error) {
 	var styles []*model.BeerStyle
 
-	result := r.DB.WithContext(ctx).Table("beer_styles").
-		Joins("INNER JOIN beers b on beer_styles.id = b.style_id").
-		Joins("INNER JOIN cellar_entries ce on b.id = ce.beer_id").
-		Where("ce.cellar_id = ?", cellarID).
-		Distinct("beer_styles.id", "beer_styles.name").
-		Order("beer_styles.name asc").
-		Find(&styles)
-
+	result := r.DB.WithContext(ctx).Raw("WITH RECURSIVE cellar_styles AS ("+
+		"SELECT beer_styles.* FROM beer_styles"+
+		" INNER JOIN beers b ON beer_styles.id = b.style_id"+
+		" INNER JOIN cellar_entries ce ON b.id = ce.beer_id"+
+		" WHERE ce.cellar_id = ? AND ce.deleted_at IS NULL"+
+		" UNION SELECT beer_styles.* FROM beer_styles INNER JOIN cellar_styles ON beer_styles.id = cellar_styles.parent_id"+
+		") SELECT * FROM cellar_styles WHERE deleted_at IS NULL ORDER BY name", cellarID).
+		Scan(&styles)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -424,7 +718,8 @@ func (r *Repository) UpdateAdventCalendarEntry(ctx context.Context, cellarID uin
 
 func (r *Repository) DeleteAdventCalendar(ctx context.Context, cellarID uint64, calendarID uint64) error {
 	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		err := tx.Exec("DELETE FROM advent_calendar_beers WHERE advent_calendar_id = ?", calendarID).Error
+		err := tx.Exec("DELETE FROM advent_calendar_beers WHERE advent_calendar_id IN"+
+			" (SELECT id FROM advent_calendars WHERE id = ? AND cellar_id = ?)", calendarID, cellarID).Error
 		if err != nil {
 			return err
 		}
@@ -443,6 +738,7 @@ func (r *Repository) GetAdventCalendarFilter(ctx context.Context, cellarID uint6
 
 	result := r.DB.WithContext(ctx).
 		Preload("Tags").
+		Preload("BeerTags").
 		Joins("JOIN advent_calendar_beers ON advent_calendar_beers.filter_id = advent_calendar_filters.id").
 		Joins("JOIN advent_calendars ON advent_calendars.id = advent_calendar_beers.advent_calendar_id").
 		Where("advent_calendars.cellar_id = ?", cellarID).
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm/clause"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+)
+
+var (
+	ErrInvitationNotFound = newError(ErrNotFound, "cellar invitation not found")
+	ErrMemberNotFound     = newError(ErrNotFound, "cellar member not found")
+)
+
+// AddCellarMember records an invitation for a user to join a cellar. Re-inviting an existing member replaces their
+// role and resets the invitation so it has to be accepted again.
+func (r *Repository) AddCellarMember(ctx context.Context, member model.CellarMember) (*model.CellarMember, error) {
+	member.AcceptedAt = nil
+
+	result := r.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "cellar_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "invited_by_id", "accepted_at", "updated_at", "deleted_at"}),
+	}).Create(&member)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &member, nil
+}
+
+func (r *Repository) AcceptCellarInvitation(ctx context.Context, cellarID uint, userID uint) error {
+	result := r.DB.WithContext(ctx).Model(&model.CellarMember{}).
+		Where("cellar_id = ? AND user_id = ? AND accepted_at IS NULL", cellarID, userID).
+		Update("accepted_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrInvitationNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) UpdateCellarMemberRole(ctx context.Context, cellarID uint, userID uint, role model.CellarRole) error {
+	result := r.DB.WithContext(ctx).Model(&model.CellarMember{}).
+		Where("cellar_id = ? AND user_id = ?", cellarID, userID).
+		Update("role", role)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrMemberNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) RemoveCellarMember(ctx context.Context, cellarID uint, userID uint) error {
+	result := r.DB.WithContext(ctx).Unscoped().
+		Where("cellar_id = ? AND user_id = ?", cellarID, userID).
+		Delete(&model.CellarMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrMemberNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) GetCellarMembers(ctx context.Context, cellarID uint) ([]*model.CellarMember, error) {
+	var members []*model.CellarMember
+
+	result := r.DB.WithContext(ctx).
+		Joins("User").
+		Where("cellar_members.cellar_id = ?", cellarID).
+		Order("cellar_members.id").
+		Find(&members)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return members, nil
+}
+
+// GetCellarInvitationsForUser returns the cellars the user has been invited to but not yet joined. Each cellar has the
+// pending membership loaded so the offered role is available.
+func (r *Repository) GetCellarInvitationsForUser(ctx context.Context, user model.User) ([]*model.Cellar, error) {
+	var cellars []*model.Cellar
+
+	db := r.DB.WithContext(ctx)
+
+	pending := db.Model(&model.CellarMember{}).
+		Select("cellar_id").
+		Where("user_id = ? AND accepted_at IS NULL", user.ID)
+
+	result := db.
+		Where("cellars.id IN (?)", pending).
+		Joins("Owner").
+		Preload("Members", "user_id = ?", user.ID).
+		Find(&cellars)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return cellars, nil
+}
@@ -0,0 +1,106 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+)
+
+type CellarMemberTestSuite struct {
+	RepositorySuite
+}
+
+func TestCellarMemberTestSuite(t *testing.T) {
+	suite.Run(t, new(CellarMemberTestSuite))
+}
+
+func (suite *CellarMemberTestSuite) TearDownTest() {
+	suite.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *CellarMemberTestSuite) TestAddCellarMember_UpsertsInvitation() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "cellar_members" ("created_at","updated_at","deleted_at","cellar_id","user_id","role","invited_by_id","accepted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8) ON CONFLICT ("cellar_id","user_id") DO UPDATE SET "role"="excluded"."role","invited_by_id"="excluded"."invited_by_id","accepted_at"="excluded"."accepted_at","updated_at"="excluded"."updated_at","deleted_at"="excluded"."deleted_at" RETURNING "id"`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 10, 200, model.CellarRoleEditor, 100, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+	suite.mock.ExpectCommit()
+
+	member, err := suite.repository.AddCellarMember(context.Background(), model.CellarMember{
+		CellarID:    10,
+		UserID:      200,
+		Role:        model.CellarRoleEditor,
+		InvitedByID: 100,
+	})
+
+	suite.Require().NoError(err)
+	suite.Equal(uint(5), member.ID)
+	suite.Nil(member.AcceptedAt)
+}
+
+func (suite *CellarMemberTestSuite) TestAcceptCellarInvitation_Accepts() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "cellar_members" SET "accepted_at"=$1,"updated_at"=$2 WHERE (cellar_id = $3 AND user_id = $4 AND accepted_at IS NULL) AND "cellar_members"."deleted_at" IS NULL`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 10, 200).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.AcceptCellarInvitation(context.Background(), 10, 200)
+
+	suite.Require().NoError(err)
+}
+
+func (suite *CellarMemberTestSuite) TestAcceptCellarInvitation_NoInvitation() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "cellar_members" SET "accepted_at"=$1,"updated_at"=$2 WHERE (cellar_id = $3 AND user_id = $4 AND accepted_at IS NULL) AND "cellar_members"."deleted_at" IS NULL`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 10, 200).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.AcceptCellarInvitation(context.Background(), 10, 200)
+
+	suite.Require().ErrorIs(err, repository.ErrInvitationNotFound)
+}
+
+func (suite *CellarMemberTestSuite) TestUpdateCellarMemberRole_NotAMember() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "cellar_members" SET "role"=$1,"updated_at"=$2 WHERE (cellar_id = $3 AND user_id = $4) AND "cellar_members"."deleted_at" IS NULL`)).
+		WithArgs(model.CellarRoleViewer, sqlmock.AnyArg(), 10, 300).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.UpdateCellarMemberRole(context.Background(), 10, 300, model.CellarRoleViewer)
+
+	suite.Require().ErrorIs(err, repository.ErrMemberNotFound)
+}
+
+func (suite *CellarMemberTestSuite) TestRemoveCellarMember_HardDeletes() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "cellar_members" WHERE cellar_id = $1 AND user_id = $2`)).
+		WithArgs(10, 200).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.RemoveCellarMember(context.Background(), 10, 200)
+
+	suite.Require().NoError(err)
+}
+
+func (suite *CellarMemberTestSuite) TestGetCellarMembers_GetsMembersWithUsers() {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "cellar_members"."id","cellar_members"."created_at","cellar_members"."updated_at","cellar_members"."deleted_at","cellar_members"."cellar_id","cellar_members"."user_id","cellar_members"."role","cellar_members"."invited_by_id","cellar_members"."accepted_at","User"."id" AS "User__id","User"."created_at" AS "User__created_at","User"."updated_at" AS "User__updated_at","User"."deleted_at" AS "User__deleted_at","User"."uuid" AS "User__uuid","User"."username" AS "User__username","User"."first_name" AS "User__first_name","User"."last_name" AS "User__last_name","User"."email" AS "User__email","User"."untappd_user_name" AS "User__untappd_user_name","User"."role" AS "User__role","User"."disabled" AS "User__disabled" FROM "cellar_members" LEFT JOIN "users" "User" ON "cellar_members"."user_id" = "User"."id" AND "User"."deleted_at" IS NULL WHERE cellar_members.cellar_id = $1 AND "cellar_members"."deleted_at" IS NULL ORDER BY cellar_members.id`)).
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "cellar_id", "user_id", "role", "User__id", "User__username"}).
+			AddRow(1, 10, 200, "viewer", 200, "friend"))
+
+	members, err := suite.repository.GetCellarMembers(context.Background(), 10)
+
+	suite.Require().NoError(err)
+	suite.Require().Len(members, 1)
+	suite.Equal(model.CellarRoleViewer, members[0].Role)
+	suite.Equal("friend", members[0].User.Username)
+}
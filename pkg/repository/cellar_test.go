@@ -3,6 +3,7 @@ package repository_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"regexp"
 	"testing"
 	"time"
@@ -14,6 +15,7 @@ import (
 	"gorm.io/gorm"
 
 	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
 	api "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
 )
 
@@ -74,22 +76,29 @@ func (suite *CellarTestSuite) TestAddCellar_ReturnsError() {
 func (suite *CellarTestSuite) TestGetAllCellars_GetCellars() {
 	owner := model.User{Model: gorm.Model{ID: 100}}
 
-	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "cellars"."id","cellars"."created_at","cellars"."updated_at","cellars"."deleted_at","cellars"."name","cellars"."description","cellars"."owner_id","Owner"."id" AS "Owner__id","Owner"."created_at" AS "Owner__created_at","Owner"."updated_at" AS "Owner__updated_at","Owner"."deleted_at" AS "Owner__deleted_at","Owner"."uuid" AS "Owner__uuid","Owner"."username" AS "Owner__username","Owner"."first_name" AS "Owner__first_name","Owner"."last_name" AS "Owner__last_name","Owner"."email" AS "Owner__email","Owner"."untappd_user_name" AS "Owner__untappd_user_name" FROM "cellars" LEFT JOIN "users" "Owner" ON "cellars"."owner_id" = "Owner"."id" AND "Owner"."deleted_at" IS NULL WHERE owner_id = $1 AND "cellars"."deleted_at" IS NULL`)).
-		WithArgs(100).
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "cellars"."id","cellars"."created_at","cellars"."updated_at","cellars"."deleted_at","cellars"."name","cellars"."description","cellars"."owner_id","Owner"."id" AS "Owner__id","Owner"."created_at" AS "Owner__created_at","Owner"."updated_at" AS "Owner__updated_at","Owner"."deleted_at" AS "Owner__deleted_at","Owner"."uuid" AS "Owner__uuid","Owner"."username" AS "Owner__username","Owner"."first_name" AS "Owner__first_name","Owner"."last_name" AS "Owner__last_name","Owner"."email" AS "Owner__email","Owner"."untappd_user_name" AS "Owner__untappd_user_name","Owner"."role" AS "Owner__role","Owner"."disabled" AS "Owner__disabled" FROM "cellars" LEFT JOIN "users" "Owner" ON "cellars"."owner_id" = "Owner"."id" AND "Owner"."deleted_at" IS NULL WHERE (owner_id = $1 OR cellars.id IN (SELECT "cellar_id" FROM "cellar_members" WHERE (user_id = $2 AND accepted_at IS NOT NULL) AND "cellar_members"."deleted_at" IS NULL)) AND "cellars"."deleted_at" IS NULL`)).
+		WithArgs(100, 100).
 		WillReturnRows(
 			sqlmock.NewRows([]string{"id", "name", "description", "owner_id", "Owner__id", "Owner__username"}).
-				AddRow(1, "my cellar", "my cellar description", 100, 100, "testuser"))
+				AddRow(1, "my cellar", "my cellar description", 100, 100, "testuser").
+				AddRow(2, "shared cellar", "", 200, 200, "friend"))
 
-	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "location_in_cellars" WHERE "location_in_cellars"."cellar_id" = $1 AND "location_in_cellars"."deleted_at" IS NULL`)).
-		WithArgs(1).
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "location_in_cellars" WHERE "location_in_cellars"."cellar_id" IN ($1,$2) AND "location_in_cellars"."deleted_at" IS NULL`)).
+		WithArgs(1, 2).
 		WillReturnRows(
 			sqlmock.NewRows([]string{"id", "name", "cellar_id"}).
 				AddRow(1, "Loc A", 1).
 				AddRow(2, "Loc B", 1))
 
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "cellar_members" WHERE "cellar_members"."cellar_id" IN ($1,$2) AND user_id = $3 AND "cellar_members"."deleted_at" IS NULL`)).
+		WithArgs(1, 2, 100).
+		WillReturnRows(
+			sqlmock.NewRows([]string{"id", "cellar_id", "user_id", "role", "accepted_at"}).
+				AddRow(1, 2, 100, "editor", time.Now()))
+
 	results, err := suite.repository.GetCellarsForUser(context.Background(), owner)
 	suite.Require().NoError(err)
-	suite.Len(results, 1)
+	suite.Len(results, 2)
 	suite.Equal("my cellar", results[0].Name)
 	suite.Equal(uint(100), results[0].OwnerID)
 	suite.NotNil(results[0].Owner)
@@ -97,10 +106,12 @@ func (suite *CellarTestSuite) TestGetAllCellars_GetCellars() {
 	suite.Len(results[0].Locations, 2)
 	suite.Equal("Loc A", results[0].Locations[0].Name)
 	suite.Equal("Loc B", results[0].Locations[1].Name)
+	suite.Equal(model.CellarRoleOwner, results[0].RoleFor(owner.ID))
+	suite.Equal(model.CellarRoleEditor, results[1].RoleFor(owner.ID))
 }
 
 func (suite *CellarTestSuite) TestGetCellarById_GetsCellar() {
-	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "cellars"."id","cellars"."created_at","cellars"."updated_at","cellars"."deleted_at","cellars"."name","cellars"."description","cellars"."owner_id","Owner"."id" AS "Owner__id","Owner"."created_at" AS "Owner__created_at","Owner"."updated_at" AS "Owner__updated_at","Owner"."deleted_at" AS "Owner__deleted_at","Owner"."uuid" AS "Owner__uuid","Owner"."username" AS "Owner__username","Owner"."first_name" AS "Owner__first_name","Owner"."last_name" AS "Owner__last_name","Owner"."email" AS "Owner__email","Owner"."untappd_user_name" AS "Owner__untappd_user_name" FROM "cellars" LEFT JOIN "users" "Owner" ON "cellars"."owner_id" = "Owner"."id" AND "Owner"."deleted_at" IS NULL WHERE "cellars"."id" = $1 AND "cellars"."deleted_at" IS NULL ORDER BY "cellars"."id" LIMIT $2`)).
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "cellars"."id","cellars"."created_at","cellars"."updated_at","cellars"."deleted_at","cellars"."name","cellars"."description","cellars"."owner_id","Owner"."id" AS "Owner__id","Owner"."created_at" AS "Owner__created_at","Owner"."updated_at" AS "Owner__updated_at","Owner"."deleted_at" AS "Owner__deleted_at","Owner"."uuid" AS "Owner__uuid","Owner"."username" AS "Owner__username","Owner"."first_name" AS "Owner__first_name","Owner"."last_name" AS "Owner__last_name","Owner"."email" AS "Owner__email","Owner"."untappd_user_name" AS "Owner__untappd_user_name","Owner"."role" AS "Owner__role","Owner"."disabled" AS "Owner__disabled" FROM "cellars" LEFT JOIN "users" "Owner" ON "cellars"."owner_id" = "Owner"."id" AND "Owner"."deleted_at" IS NULL WHERE "cellars"."id" = $1 AND "cellars"."deleted_at" IS NULL ORDER BY "cellars"."id" LIMIT $2`)).
 		WithArgs(100, 1).
 		WillReturnRows(
 			sqlmock.NewRows([]string{"id", "name", "description", "owner_id", "Owner__id", "Owner__username"}).
@@ -132,7 +143,7 @@ func (suite *CellarTestSuite) TestGetCellarEntryByID_GetsCellarEntryWithAssociat
 			sqlmock.NewRows([]string{"id", "cellar_id", "Beer__name", "Location__name", "Format__package", "Format__size_metric"}).
 				AddRow(100, 10, "Tasty Beer", "Shelf 1", "Can", "330"))
 
-	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "cellars"."id","cellars"."created_at","cellars"."updated_at","cellars"."deleted_at","cellars"."name","cellars"."description","cellars"."owner_id","Owner"."id" AS "Owner__id","Owner"."created_at" AS "Owner__created_at","Owner"."updated_at" AS "Owner__updated_at","Owner"."deleted_at" AS "Owner__deleted_at","Owner"."uuid" AS "Owner__uuid","Owner"."username" AS "Owner__username","Owner"."first_name" AS "Owner__first_name","Owner"."last_name" AS "Owner__last_name","Owner"."email" AS "Owner__email","Owner"."untappd_user_name" AS "Owner__untappd_user_name" FROM "cellars" LEFT JOIN "users" "Owner" ON "cellars"."owner_id" = "Owner"."id" AND "Owner"."deleted_at" IS NULL WHERE "cellars"."id" = $1 AND "cellars"."deleted_at" IS NULL ORDER BY "cellars"."id" LIMIT $2`)).
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "cellars"."id","cellars"."created_at","cellars"."updated_at","cellars"."deleted_at","cellars"."name","cellars"."description","cellars"."owner_id","Owner"."id" AS "Owner__id","Owner"."created_at" AS "Owner__created_at","Owner"."updated_at" AS "Owner__updated_at","Owner"."deleted_at" AS "Owner__deleted_at","Owner"."uuid" AS "Owner__uuid","Owner"."username" AS "Owner__username","Owner"."first_name" AS "Owner__first_name","Owner"."last_name" AS "Owner__last_name","Owner"."email" AS "Owner__email","Owner"."untappd_user_name" AS "Owner__untappd_user_name","Owner"."role" AS "Owner__role","Owner"."disabled" AS "Owner__disabled" FROM "cellars" LEFT JOIN "users" "Owner" ON "cellars"."owner_id" = "Owner"."id" AND "Owner"."deleted_at" IS NULL WHERE "cellars"."id" = $1 AND "cellars"."deleted_at" IS NULL ORDER BY "cellars"."id" LIMIT $2`)).
 		WithArgs(10, 1).
 		WillReturnRows(
 			sqlmock.NewRows([]string{"id", "name", "description", "owner_id", "Owner__id", "Owner__username"}).
@@ -165,8 +176,17 @@ func (suite *CellarTestSuite) TestGetCellarStats_GetsCellarStats() {
 		WithArgs(100).
 		WillReturnRows(sqlmock.NewRows([]string{"beer_count", "unique_count", "total_volume", "brewery_count", "untried_count", "average_abv", "average_rating"}).
 			AddRow(10, 5, 3550, 2, 1, 9.8, 4.25))
-
-	cellarStats, err := suite.repository.GetCellarStats(context.Background(), 100)
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT DISTINCT ce.beer_id, b.name as beer_name, ce.vintage FROM cellar_entries as ce INNER JOIN beers b on b.id = ce.beer_id WHERE ce.cellar_id = $1 AND (ce.vintage BETWEEN $2 AND $3) AND ce.deleted_at is null ORDER BY b.name, ce.beer_id, ce.vintage`)).
+		WithArgs(100, model.MinVintage, model.MaxVintage()).
+		WillReturnRows(sqlmock.NewRows([]string{"beer_id", "beer_name", "vintage"}).
+			AddRow(4, "Abt 12", 2019).
+			AddRow(4, "Abt 12", 2020).
+			AddRow(7, "Bourbon County", 2018).
+			AddRow(7, "Bourbon County", 2021).
+			AddRow(7, "Bourbon County", 2022).
+			AddRow(9, "Fou' Foune", 2023))
+
+	cellarStats, err := suite.repository.GetCellarStats(context.Background(), 100, 0)
 
 	suite.Require().NoError(err)
 	suite.NotNil(cellarStats)
@@ -178,17 +198,99 @@ func (suite *CellarTestSuite) TestGetCellarStats_GetsCellarStats() {
 	suite.Equal(uint64(1), cellarStats.UntriedCount)
 	suite.InDelta(9.8, cellarStats.AverageABV, 0.01)
 	suite.InDelta(4.25, cellarStats.AverageRating, 0.001)
+	suite.Equal([]model.VerticalSummary{
+		{BeerID: 4, BeerName: "Abt 12", Vintages: []uint64{2019, 2020}},
+		{BeerID: 7, BeerName: "Bourbon County", Vintages: []uint64{2018, 2021, 2022}, MissingVintages: []uint64{2019, 2020}},
+	}, cellarStats.Verticals)
+}
+
+func (suite *CellarTestSuite) TestGetCellarStats_AveragesMyRatings() {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`avg((SELECT avg(tasting_notes.rating) FROM tasting_notes WHERE tasting_notes.beer_id = b.id AND tasting_notes.user_id = $1 AND tasting_notes.deleted_at IS NULL)) as average_rating FROM cellar_entries as ce INNER JOIN beer_formats bf on bf.id = ce.format_id INNER JOIN beers b on b.id = ce.beer_id WHERE cellar_id = $2`)).
+		WithArgs(3, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"beer_count", "average_rating"}).AddRow(10, 3.75))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT DISTINCT ce.beer_id`)).
+		WithArgs(100, model.MinVintage, model.MaxVintage()).
+		WillReturnRows(sqlmock.NewRows([]string{"beer_id", "beer_name", "vintage"}))
+
+	cellarStats, err := suite.repository.GetCellarStats(context.Background(), 100, 3)
+
+	suite.Require().NoError(err)
+	suite.InDelta(3.75, cellarStats.AverageRating, 0.001)
 }
 
 func (suite *CellarTestSuite) TestGetCellarStats_ReturnError() {
 	suite.mock.ExpectQuery("^SELECT (.+)").WillReturnError(gorm.ErrRecordNotFound)
 
-	cellarStats, err := suite.repository.GetCellarStats(context.Background(), 999)
+	cellarStats, err := suite.repository.GetCellarStats(context.Background(), 999, 0)
 
 	suite.Nil(cellarStats)
 	suite.EqualError(err, "record not found")
 }
 
+func (suite *CellarTestSuite) TestGetCellarRole_Owner() {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT CASE WHEN c.owner_id = $1 THEN $2 ELSE COALESCE(m.role, '') END FROM cellars c LEFT JOIN cellar_members m ON m.cellar_id = c.id AND m.user_id = $3 AND m.accepted_at IS NOT NULL AND m.deleted_at IS NULL WHERE c.id = $4 AND c.deleted_at IS NULL`)).
+		WithArgs(100, model.CellarRoleOwner, 100, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("owner"))
+
+	role, err := suite.repository.GetCellarRole(context.Background(), 10, 100)
+
+	suite.Require().NoError(err)
+	suite.Equal(model.CellarRoleOwner, role)
+}
+
+func (suite *CellarTestSuite) TestGetCellarRole_Member() {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT CASE WHEN c.owner_id = $1 THEN $2 ELSE COALESCE(m.role, '') END FROM cellars c LEFT JOIN cellar_members m ON m.cellar_id = c.id AND m.user_id = $3 AND m.accepted_at IS NOT NULL AND m.deleted_at IS NULL WHERE c.id = $4 AND c.deleted_at IS NULL`)).
+		WithArgs(200, model.CellarRoleOwner, 200, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("viewer"))
+
+	role, err := suite.repository.GetCellarRole(context.Background(), 10, 200)
+
+	suite.Require().NoError(err)
+	suite.Equal(model.CellarRoleViewer, role)
+}
+
+func (suite *CellarTestSuite) TestGetCellarRole_NotAMember() {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT CASE WHEN c.owner_id = $1 THEN $2 ELSE COALESCE(m.role, '') END FROM cellars c LEFT JOIN cellar_members m ON m.cellar_id = c.id AND m.user_id = $3 AND m.accepted_at IS NOT NULL AND m.deleted_at IS NULL WHERE c.id = $4 AND c.deleted_at IS NULL`)).
+		WithArgs(300, model.CellarRoleOwner, 300, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(""))
+
+	role, err := suite.repository.GetCellarRole(context.Background(), 10, 300)
+
+	suite.Require().NoError(err)
+	suite.Equal(model.CellarRoleNone, role)
+}
+
+func (suite *CellarTestSuite) TestGetCellarRole_CellarMissing() {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT CASE WHEN c.owner_id = $1 THEN $2 ELSE COALESCE(m.role, '') END FROM cellars c LEFT JOIN cellar_members m ON m.cellar_id = c.id AND m.user_id = $3 AND m.accepted_at IS NOT NULL AND m.deleted_at IS NULL WHERE c.id = $4 AND c.deleted_at IS NULL`)).
+		WithArgs(100, model.CellarRoleOwner, 100, 99).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}))
+
+	role, err := suite.repository.GetCellarRole(context.Background(), 99, 100)
+
+	suite.Require().NoError(err)
+	suite.Equal(model.CellarRoleNone, role)
+}
+
+func (suite *CellarTestSuite) TestGetCellarRole_ReturnsError() {
+	suite.mock.ExpectQuery("^SELECT (.+)").WillReturnError(errors.New("database error"))
+
+	role, err := suite.repository.GetCellarRole(context.Background(), 10, 100)
+
+	suite.Require().ErrorContains(err, "database error")
+	suite.Equal(model.CellarRoleNone, role)
+}
+
+func (suite *CellarTestSuite) TestGetCellarEntryRole_Editor() {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT CASE WHEN c.owner_id = $1 THEN $2 ELSE COALESCE(m.role, '') END FROM cellar_entries ce INNER JOIN cellars c ON c.id = ce.cellar_id AND c.deleted_at IS NULL LEFT JOIN cellar_members m ON m.cellar_id = c.id AND m.user_id = $3 AND m.accepted_at IS NOT NULL AND m.deleted_at IS NULL WHERE ce.id = $4 AND ce.deleted_at IS NULL`)).
+		WithArgs(200, model.CellarRoleOwner, 200, 50).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("editor"))
+
+	role, err := suite.repository.GetCellarEntryRole(context.Background(), 50, 200)
+
+	suite.Require().NoError(err)
+	suite.Equal(model.CellarRoleEditor, role)
+}
+
 func (suite *CellarTestSuite) TestAddBeerToCellar_AddsBeer() {
 	beer := model.CellarEntry{
 		CellarID:   1,
@@ -251,28 +353,147 @@ func (suite *CellarTestSuite) TestUpdateCellarEntry_UpdatesCellarEntry() {
 	suite.Equal(int64(2), updatedEntry.Quantity)
 }
 
-func (suite *CellarTestSuite) TestGetCellarBeers_GetsBeers() {
-	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "cellar_entries"."id","cellar_entries"."created_at","cellar_entries"."updated_at","cellar_entries"."deleted_at","cellar_entries"."cellar_id","cellar_entries"."beer_id","cellar_entries"."vintage","cellar_entries"."quantity","cellar_entries"."location_id","cellar_entries"."format_id","cellar_entries"."had_before","cellar_entries"."date_added","cellar_entries"."drink_before","cellar_entries"."cellar_until","cellar_entries"."special","Beer"."id" AS "Beer__id","Beer"."created_at" AS "Beer__created_at","Beer"."updated_at" AS "Beer__updated_at","Beer"."deleted_at" AS "Beer__deleted_at","Beer"."name" AS "Beer__name","Beer"."description" AS "Beer__description","Beer"."image_url" AS "Beer__image_url","Beer"."brewery_id" AS "Beer__brewery_id","Beer"."style_id" AS "Beer__style_id","Beer"."abv" AS "Beer__abv","Beer"."ibu" AS "Beer__ibu","Beer"."external_id" AS "Beer__external_id","Beer"."external_source" AS "Beer__external_source","Beer"."external_rating" AS "Beer__external_rating","Location"."id" AS "Location__id","Location"."created_at" AS "Location__created_at","Location"."updated_at" AS "Location__updated_at","Location"."deleted_at" AS "Location__deleted_at","Location"."name" AS "Location__name","Location"."cellar_id" AS "Location__cellar_id","Format"."id" AS "Format__id","Format"."created_at" AS "Format__created_at","Format"."updated_at" AS "Format__updated_at","Format"."deleted_at" AS "Format__deleted_at","Format"."package" AS "Format__package","Format"."size_metric" AS "Format__size_metric","Format"."size_imperial" AS "Format__size_imperial","Cellar"."id" AS "Cellar__id","Cellar"."created_at" AS "Cellar__created_at","Cellar"."updated_at" AS "Cellar__updated_at","Cellar"."deleted_at" AS "Cellar__deleted_at","Cellar"."name" AS "Cellar__name","Cellar"."description" AS "Cellar__description","Cellar"."owner_id" AS "Cellar__owner_id" FROM "cellar_entries" LEFT JOIN "beers" "Beer" ON "cellar_entries"."beer_id" = "Beer"."id" AND "Beer"."deleted_at" IS NULL LEFT JOIN "location_in_cellars" "Location" ON "cellar_entries"."location_id" = "Location"."id" AND "Location"."deleted_at" IS NULL LEFT JOIN "beer_formats" "Format" ON "cellar_entries"."format_id" = "Format"."id" AND "Format"."deleted_at" IS NULL LEFT JOIN "cellars" "Cellar" ON "cellar_entries"."cellar_id" = "Cellar"."id" AND "Cellar"."deleted_at" IS NULL WHERE cellar_entries.cellar_id = $1 AND "cellar_entries"."deleted_at" IS NULL`)).
+const cellarEntriesSelect = `SELECT "cellar_entries"."id","cellar_entries"."created_at","cellar_entries"."updated_at","cellar_entries"."deleted_at","cellar_entries"."cellar_id","cellar_entries"."beer_id","cellar_entries"."vintage","cellar_entries"."quantity","cellar_entries"."location_id","cellar_entries"."format_id","cellar_entries"."had_before","cellar_entries"."date_added","cellar_entries"."drink_before","cellar_entries"."cellar_until","cellar_entries"."special","Beer"."id" AS "Beer__id","Beer"."created_at" AS "Beer__created_at","Beer"."updated_at" AS "Beer__updated_at","Beer"."deleted_at" AS "Beer__deleted_at","Beer"."name" AS "Beer__name","Beer"."description" AS "Beer__description","Beer"."image_url" AS "Beer__image_url","Beer"."brewery_id" AS "Beer__brewery_id","Beer"."style_id" AS "Beer__style_id","Beer"."abv" AS "Beer__abv","Beer"."ibu" AS "Beer__ibu","Beer"."external_id" AS "Beer__external_id","Beer"."external_source" AS "Beer__external_source","Beer"."external_rating" AS "Beer__external_rating","Format"."id" AS "Format__id","Format"."created_at" AS "Format__created_at","Format"."updated_at" AS "Format__updated_at","Format"."deleted_at" AS "Format__deleted_at","Format"."package" AS "Format__package","Format"."size_metric" AS "Format__size_metric","Format"."size_imperial" AS "Format__size_imperial","Format"."owner_id" AS "Format__owner_id","Location"."id" AS "Location__id","Location"."created_at" AS "Location__created_at","Location"."updated_at" AS "Location__updated_at","Location"."deleted_at" AS "Location__deleted_at","Location"."name" AS "Location__name","Location"."cellar_id" AS "Location__cellar_id","Cellar"."id" AS "Cellar__id","Cellar"."created_at" AS "Cellar__created_at","Cellar"."updated_at" AS "Cellar__updated_at","Cellar"."deleted_at" AS "Cellar__deleted_at","Cellar"."name" AS "Cellar__name","Cellar"."description" AS "Cellar__description","Cellar"."owner_id" AS "Cellar__owner_id" FROM "cellar_entries" LEFT JOIN "beers" "Beer" ON "cellar_entries"."beer_id" = "Beer"."id" AND "Beer"."deleted_at" IS NULL LEFT JOIN "beer_formats" "Format" ON "cellar_entries"."format_id" = "Format"."id" AND "Format"."deleted_at" IS NULL LEFT JOIN "location_in_cellars" "Location" ON "cellar_entries"."location_id" = "Location"."id" AND "Location"."deleted_at" IS NULL LEFT JOIN "cellars" "Cellar" ON "cellar_entries"."cellar_id" = "Cellar"."id" AND "Cellar"."deleted_at" IS NULL `
+
+const cellarEntriesCount = `SELECT count(*) FROM "cellar_entries" LEFT JOIN "beers" "Beer" ON "cellar_entries"."beer_id" = "Beer"."id" AND "Beer"."deleted_at" IS NULL LEFT JOIN "beer_formats" "Format" ON "cellar_entries"."format_id" = "Format"."id" AND "Format"."deleted_at" IS NULL `
+
+func (suite *CellarTestSuite) TestListCellarBeers_PagesByName() {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(cellarEntriesCount + `WHERE cellar_entries.cellar_id = $1 AND "cellar_entries"."deleted_at" IS NULL`)).
 		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(cellarEntriesSelect+`WHERE cellar_entries.cellar_id = $1 AND "cellar_entries"."deleted_at" IS NULL ORDER BY "Beer".name ASC,cellar_entries.id ASC LIMIT $2`)).
+		WithArgs(1, 3).
 		WillReturnRows(
 			sqlmock.NewRows([]string{"id", "quantity", "Beer__name"}).
+				AddRow(uint(11), 1, "Pannepeut").
 				AddRow(uint(10), 2, "Pannepot").
-				AddRow(uint(11), 1, "Pannepeut"))
+				AddRow(uint(12), 1, "Pannepot"))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "cellar_entry_tags" WHERE "cellar_entry_tags"."cellar_entry_id" IN ($1,$2,$3)`)).
+		WithArgs(11, 10, 12).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	beers, token, total, err := suite.repository.ListCellarBeers(context.Background(), 1, nil, 0, repository.CellarSort{}, repository.Page{Size: 2})
+	suite.Require().NoError(err)
+	suite.Len(beers, 2)
+	suite.Equal("Pannepeut", beers[0].Beer.Name)
+	suite.Equal("Pannepot", beers[1].Beer.Name)
+	suite.Equal(int64(3), total)
+	suite.Require().NotEmpty(token)
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(cellarEntriesCount + `WHERE cellar_entries.cellar_id = $1 AND "cellar_entries"."deleted_at" IS NULL`)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(cellarEntriesSelect+`WHERE cellar_entries.cellar_id = $1 AND ("Beer".name, cellar_entries.id) > ($2, $3) AND "cellar_entries"."deleted_at" IS NULL ORDER BY "Beer".name ASC,cellar_entries.id ASC LIMIT $4`)).
+		WithArgs(1, "Pannepot", 10, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "quantity", "Beer__name"}).AddRow(uint(12), 1, "Pannepot"))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "cellar_entry_tags" WHERE "cellar_entry_tags"."cellar_entry_id" = $1`)).
+		WithArgs(12).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	beers, token, _, err = suite.repository.ListCellarBeers(context.Background(), 1, nil, 0, repository.CellarSort{}, repository.Page{Size: 2, Token: token})
+	suite.Require().NoError(err)
+	suite.Len(beers, 1)
+	suite.Equal(uint(12), beers[0].ID)
+	suite.Empty(token)
+}
+
+func (suite *CellarTestSuite) TestListCellarBeers_FiltersAndSortsByDrinkBefore() {
+	sort := repository.CellarSort{Field: repository.CellarSortDrinkBefore, Descending: true}
+	filter := &api.CellarFilter{Special: pointy.Bool(true)}
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(cellarEntriesCount+`WHERE cellar_entries.cellar_id = $1 AND special = $2 AND "cellar_entries"."deleted_at" IS NULL`)).
+		WithArgs(1, true).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(cellarEntriesSelect+`WHERE cellar_entries.cellar_id = $1 AND special = $2 AND "cellar_entries"."deleted_at" IS NULL ORDER BY COALESCE(cellar_entries.drink_before, 'infinity') DESC,cellar_entries.id DESC LIMIT $3`)).
+		WithArgs(1, true, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "quantity", "drink_before"}).
+			AddRow(uint(10), 1, nil).
+			AddRow(uint(11), 1, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)))
 	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "cellar_entry_tags" WHERE "cellar_entry_tags"."cellar_entry_id" IN ($1,$2)`)).
 		WithArgs(10, 11).WillReturnRows(sqlmock.NewRows([]string{"id"}))
 
-	beers, err := suite.repository.GetCellarBeers(context.Background(), 1)
+	beers, token, total, err := suite.repository.ListCellarBeers(context.Background(), 1, filter, 0, sort, repository.Page{Size: 1})
 	suite.Require().NoError(err)
-	suite.NotNil(beers)
-	suite.Len(beers, 2)
-	suite.Equal("Pannepot", beers[0].Beer.Name)
-	suite.Equal("Pannepeut", beers[1].Beer.Name)
+	suite.Len(beers, 1)
+	suite.Equal(int64(2), total)
+	suite.Require().NotEmpty(token)
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(cellarEntriesCount+`WHERE cellar_entries.cellar_id = $1 AND special = $2 AND "cellar_entries"."deleted_at" IS NULL`)).
+		WithArgs(1, true).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(cellarEntriesSelect+`WHERE cellar_entries.cellar_id = $1 AND special = $2 AND (COALESCE(cellar_entries.drink_before, 'infinity'), cellar_entries.id) < ($3, $4) AND "cellar_entries"."deleted_at" IS NULL ORDER BY COALESCE(cellar_entries.drink_before, 'infinity') DESC,cellar_entries.id DESC LIMIT $5`)).
+		WithArgs(1, true, "infinity", 10, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	beers, token, _, err = suite.repository.ListCellarBeers(context.Background(), 1, filter, 0, sort, repository.Page{Size: 1, Token: token})
+	suite.Require().NoError(err)
+	suite.Empty(beers)
+	suite.Empty(token)
+}
+
+func (suite *CellarTestSuite) TestListCellarBeers_SortsByRatersRatings() {
+	sort := repository.CellarSort{Field: repository.CellarSortRating, Descending: true}
+	rating := func(placeholder int) string {
+		return fmt.Sprintf(`COALESCE((SELECT avg(tasting_notes.rating) FROM tasting_notes WHERE tasting_notes.beer_id = "Beer".id AND tasting_notes.user_id = $%d AND tasting_notes.deleted_at IS NULL), 0)`, placeholder)
+	}
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(cellarEntriesCount + `WHERE cellar_entries.cellar_id = $1 AND "cellar_entries"."deleted_at" IS NULL`)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(cellarEntriesSelect+`WHERE cellar_entries.cellar_id = $1 AND "cellar_entries"."deleted_at" IS NULL ORDER BY `+rating(2)+` DESC,cellar_entries.id DESC LIMIT $3`)).
+		WithArgs(1, 3, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "beer_id"}).AddRow(uint(10), 5).AddRow(uint(11), 6))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "cellar_entry_tags" WHERE "cellar_entry_tags"."cellar_entry_id" IN ($1,$2)`)).
+		WithArgs(10, 11).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE((SELECT avg(tasting_notes.rating) FROM tasting_notes WHERE tasting_notes.beer_id = $1 AND tasting_notes.user_id = $2 AND tasting_notes.deleted_at IS NULL), 0)`)).
+		WithArgs(5, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(4.5))
+
+	beers, token, _, err := suite.repository.ListCellarBeers(context.Background(), 1, nil, 3, sort, repository.Page{Size: 1})
+	suite.Require().NoError(err)
+	suite.Len(beers, 1)
+	suite.Require().NotEmpty(token)
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(cellarEntriesCount + `WHERE cellar_entries.cellar_id = $1 AND "cellar_entries"."deleted_at" IS NULL`)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(cellarEntriesSelect+`WHERE cellar_entries.cellar_id = $1 AND ((`+rating(2)+`, cellar_entries.id) < ($3, $4)) AND "cellar_entries"."deleted_at" IS NULL ORDER BY `+rating(5)+` DESC,cellar_entries.id DESC LIMIT $6`)).
+		WithArgs(1, 3, 4.5, 10, 3, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, _, _, err = suite.repository.ListCellarBeers(context.Background(), 1, nil, 3, sort, repository.Page{Size: 1, Token: token})
+	suite.Require().NoError(err)
+
+	_, _, _, err = suite.repository.ListCellarBeers(context.Background(), 1, nil, 0, sort, repository.Page{Size: 1, Token: token})
+	suite.Require().ErrorIs(err, repository.ErrInvalidPageToken)
+}
+
+func (suite *CellarTestSuite) TestListCellarBeers_TokenForAnotherSort() {
+	_, token, _, err := suite.firstCellarPage()
+	suite.Require().NoError(err)
+
+	_, _, _, err = suite.repository.ListCellarBeers(context.Background(), 1, nil, 0,
+		repository.CellarSort{Field: repository.CellarSortVintage}, repository.Page{Size: 1, Token: token})
+	suite.Require().ErrorIs(err, repository.ErrInvalidPageToken)
+}
+
+func (suite *CellarTestSuite) TestListCellarBeers_UnknownSort() {
+	_, _, _, err := suite.repository.ListCellarBeers(context.Background(), 1, nil, 0, repository.CellarSort{Field: "colour"}, repository.Page{})
+	suite.Require().ErrorIs(err, repository.ErrInvalidSort)
+}
+
+// firstCellarPage lists a single entry sorted by name, returning a token for the next page.
+func (suite *CellarTestSuite) firstCellarPage() ([]*model.CellarEntry, string, int64, error) {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(cellarEntriesCount)).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(cellarEntriesSelect)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "Beer__name"}).AddRow(uint(10), "Pannepot").AddRow(uint(11), "Pannepeut"))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "cellar_entry_tags"`)).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	return suite.repository.ListCellarBeers(context.Background(), 1, nil, 0, repository.CellarSort{}, repository.Page{Size: 1})
 }
 
 func (suite *CellarTestSuite) TestFindBeerRecommendations_FindsRecommendations() {
 	expectedDate := time.Date(2012, 1, 1, 0, 0, 0, 0, time.UTC)
 
-	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "cellar_entries"."id","cellar_entries"."created_at","cellar_entries"."updated_at","cellar_entries"."deleted_at","cellar_entries"."cellar_id","cellar_entries"."beer_id","cellar_entries"."vintage","cellar_entries"."quantity","cellar_entries"."location_id","cellar_entries"."format_id","cellar_entries"."had_before","cellar_entries"."date_added","cellar_entries"."drink_before","cellar_entries"."cellar_until","cellar_entries"."special","Beer"."id" AS "Beer__id","Beer"."created_at" AS "Beer__created_at","Beer"."updated_at" AS "Beer__updated_at","Beer"."deleted_at" AS "Beer__deleted_at","Beer"."name" AS "Beer__name","Beer"."description" AS "Beer__description","Beer"."image_url" AS "Beer__image_url","Beer"."brewery_id" AS "Beer__brewery_id","Beer"."style_id" AS "Beer__style_id","Beer"."abv" AS "Beer__abv","Beer"."ibu" AS "Beer__ibu","Beer"."external_id" AS "Beer__external_id","Beer"."external_source" AS "Beer__external_source","Beer"."external_rating" AS "Beer__external_rating","Location"."id" AS "Location__id","Location"."created_at" AS "Location__created_at","Location"."updated_at" AS "Location__updated_at","Location"."deleted_at" AS "Location__deleted_at","Location"."name" AS "Location__name","Location"."cellar_id" AS "Location__cellar_id","Format"."id" AS "Format__id","Format"."created_at" AS "Format__created_at","Format"."updated_at" AS "Format__updated_at","Format"."deleted_at" AS "Format__deleted_at","Format"."package" AS "Format__package","Format"."size_metric" AS "Format__size_metric","Format"."size_imperial" AS "Format__size_imperial","Cellar"."id" AS "Cellar__id","Cellar"."created_at" AS "Cellar__created_at","Cellar"."updated_at" AS "Cellar__updated_at","Cellar"."deleted_at" AS "Cellar__deleted_at","Cellar"."name" AS "Cellar__name","Cellar"."description" AS "Cellar__description","Cellar"."owner_id" AS "Cellar__owner_id" FROM "cellar_entries" LEFT JOIN "beers" "Beer" ON "cellar_entries"."beer_id" = "Beer"."id" AND "Beer"."deleted_at" IS NULL LEFT JOIN "location_in_cellars" "Location" ON "cellar_entries"."location_id" = "Location"."id" AND "Location"."deleted_at" IS NULL LEFT JOIN "beer_formats" "Format" ON "cellar_entries"."format_id" = "Format"."id" AND "Format"."deleted_at" IS NULL LEFT JOIN "cellars" "Cellar" ON "cellar_entries"."cellar_id" = "Cellar"."id" AND "Cellar"."deleted_at" IS NULL WHERE cellar_entries.cellar_id = $1 AND "Beer".brewery_id = $2 AND "Beer".abv >= $3 AND "Beer".ABV <= $4 AND "Beer".external_rating >= $5 AND "Beer".external_rating <= $6 AND "Format".size_metric >= $7 AND "Format".size_metric <= $8 AND special = $9 AND had_before = $10 AND "Beer".style_id = $11 AND drink_before < $12 AND quantity >= $13 AND vintage >= $14 AND vintage <= $15 AND cellar_entries.id IN (SELECT cellar_entry_id FROM cellar_entry_tags INNER JOIN tags ON tag_id = tags.id WHERE tag IN ($16,$17) GROUP BY cellar_entry_id HAVING COUNT(*) = $18) AND date_added < $19 AND "cellar_entries"."deleted_at" IS NULL`)).
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "cellar_entries"."id","cellar_entries"."created_at","cellar_entries"."updated_at","cellar_entries"."deleted_at","cellar_entries"."cellar_id","cellar_entries"."beer_id","cellar_entries"."vintage","cellar_entries"."quantity","cellar_entries"."location_id","cellar_entries"."format_id","cellar_entries"."had_before","cellar_entries"."date_added","cellar_entries"."drink_before","cellar_entries"."cellar_until","cellar_entries"."special","Beer"."id" AS "Beer__id","Beer"."created_at" AS "Beer__created_at","Beer"."updated_at" AS "Beer__updated_at","Beer"."deleted_at" AS "Beer__deleted_at","Beer"."name" AS "Beer__name","Beer"."description" AS "Beer__description","Beer"."image_url" AS "Beer__image_url","Beer"."brewery_id" AS "Beer__brewery_id","Beer"."style_id" AS "Beer__style_id","Beer"."abv" AS "Beer__abv","Beer"."ibu" AS "Beer__ibu","Beer"."external_id" AS "Beer__external_id","Beer"."external_source" AS "Beer__external_source","Beer"."external_rating" AS "Beer__external_rating","Location"."id" AS "Location__id","Location"."created_at" AS "Location__created_at","Location"."updated_at" AS "Location__updated_at","Location"."deleted_at" AS "Location__deleted_at","Location"."name" AS "Location__name","Location"."cellar_id" AS "Location__cellar_id","Format"."id" AS "Format__id","Format"."created_at" AS "Format__created_at","Format"."updated_at" AS "Format__updated_at","Format"."deleted_at" AS "Format__deleted_at","Format"."package" AS "Format__package","Format"."size_metric" AS "Format__size_metric","Format"."size_imperial" AS "Format__size_imperial","Format"."owner_id" AS "Format__owner_id","Cellar"."id" AS "Cellar__id","Cellar"."created_at" AS "Cellar__created_at","Cellar"."updated_at" AS "Cellar__updated_at","Cellar"."deleted_at" AS "Cellar__deleted_at","Cellar"."name" AS "Cellar__name","Cellar"."description" AS "Cellar__description","Cellar"."owner_id" AS "Cellar__owner_id" FROM "cellar_entries" LEFT JOIN "beers" "Beer" ON "cellar_entries"."beer_id" = "Beer"."id" AND "Beer"."deleted_at" IS NULL LEFT JOIN "location_in_cellars" "Location" ON "cellar_entries"."location_id" = "Location"."id" AND "Location"."deleted_at" IS NULL LEFT JOIN "beer_formats" "Format" ON "cellar_entries"."format_id" = "Format"."id" AND "Format"."deleted_at" IS NULL LEFT JOIN "cellars" "Cellar" ON "cellar_entries"."cellar_id" = "Cellar"."id" AND "Cellar"."deleted_at" IS NULL WHERE cellar_entries.cellar_id = $1 AND "Beer".brewery_id = $2 AND "Beer".abv >= $3 AND "Beer".ABV <= $4 AND "Beer".external_rating >= $5 AND "Beer".external_rating <= $6 AND "Format".size_metric >= $7 AND "Format".size_metric <= $8 AND special = $9 AND had_before = $10 AND "Beer".style_id IN (WITH RECURSIVE family AS (SELECT id FROM beer_styles WHERE id = $11 UNION SELECT beer_styles.id FROM beer_styles INNER JOIN family ON beer_styles.parent_id = family.id WHERE beer_styles.deleted_at IS NULL) SELECT id FROM family) AND drink_before < $12 AND quantity >= $13 AND vintage >= $14 AND vintage <= $15 AND cellar_entries.id IN (SELECT cellar_entry_id FROM cellar_entry_tags INNER JOIN tags ON tag_id = tags.id WHERE tag IN ($16,$17) GROUP BY cellar_entry_id HAVING COUNT(*) = $18) AND date_added < $19 AND "cellar_entries"."deleted_at" IS NULL`)).
 		WithArgs(1, 1, 4.0, 20.0, 3.5, 5.0, 330, 375, false, false, 1, sqlmock.AnyArg(), 1, 2011, 2020, "dark fruits", "sweet", 2, expectedDate).
 		WillReturnRows(
 			sqlmock.NewRows([]string{"id", "quantity", "Beer__name"}).
@@ -300,11 +521,31 @@ func (suite *CellarTestSuite) TestFindBeerRecommendations_FindsRecommendations()
 		AddedBefore:     timestamppb.New(expectedDate),
 	}
 
-	beers, err := suite.repository.FindBeerRecommendations(context.Background(), 1, &filter)
+	beers, err := suite.repository.FindBeerRecommendations(context.Background(), 1, &filter, 0)
 	suite.Require().NoError(err)
 	suite.NotNil(beers)
 }
 
+func (suite *CellarTestSuite) TestFindBeerRecommendations_FiltersByBeerTags() {
+	suite.mock.ExpectQuery(`^SELECT .+ FROM "cellar_entries" .+`+regexp.QuoteMeta(`WHERE cellar_entries.cellar_id = $1 AND "Beer".id IN (SELECT beer_id FROM beer_tags INNER JOIN tags ON tag_id = tags.id WHERE tag IN ($2,$3) GROUP BY beer_id HAVING COUNT(*) = $4) AND "cellar_entries"."deleted_at" IS NULL`)).
+		WithArgs(1, "sour", "barrel-aged", 2).
+		WillReturnRows(
+			sqlmock.NewRows([]string{"id", "quantity", "Beer__id", "Beer__name"}).
+				AddRow(uint(10), 2, uint(3), "Oude Geuze"))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "beer_tags" WHERE "beer_tags"."beer_id" = $1`)).
+		WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"beer_id", "tag_id"}).AddRow(3, 7))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tags" WHERE "tags"."id" = $1 AND "tags"."deleted_at" IS NULL`)).
+		WithArgs(7).WillReturnRows(sqlmock.NewRows([]string{"id", "tag"}).AddRow(7, "sour"))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "cellar_entry_tags" WHERE "cellar_entry_tags"."cellar_entry_id" = $1`)).
+		WithArgs(10).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	beers, err := suite.repository.FindBeerRecommendations(context.Background(), 1, &api.CellarFilter{BeerTags: []string{"sour", "barrel-aged"}}, 0)
+
+	suite.Require().NoError(err)
+	suite.Require().Len(beers, 1)
+	suite.Equal("sour", beers[0].Beer.Tags[0].Tag)
+}
+
 func (suite *CellarTestSuite) TestGetCellarBreweryNames() {
 	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT DISTINCT breweries.id,breweries.name FROM "breweries" INNER JOIN beers b on breweries.id = b.brewery_id INNER JOIN cellar_entries ce on b.id = ce.beer_id WHERE ce.cellar_id = $1 AND "breweries"."deleted_at" IS NULL ORDER BY breweries.name asc`)).
 		WithArgs(1).
@@ -321,18 +562,22 @@ func (suite *CellarTestSuite) TestGetCellarBreweryNames() {
 	suite.Equal("Temporal Artisan Ales", names[2].Name)
 }
 
-func (suite *CellarTestSuite) TestGetCellarStyles() {
-	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT DISTINCT beer_styles.id,beer_styles.name FROM "beer_styles" INNER JOIN beers b on beer_styles.id = b.style_id INNER JOIN cellar_entries ce on b.id = ce.beer_id WHERE ce.cellar_id = $1 AND "beer_styles"."deleted_at" IS NULL ORDER BY beer_styles.name`)).
+func (suite *CellarTestSuite) TestGetCellarStyles_IncludesFamilies() {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`WITH RECURSIVE cellar_styles AS (SELECT beer_styles.* FROM beer_styles INNER JOIN beers b ON beer_styles.id = b.style_id INNER JOIN cellar_entries ce ON b.id = ce.beer_id WHERE ce.cellar_id = $1 AND ce.deleted_at IS NULL UNION SELECT beer_styles.* FROM beer_styles INNER JOIN cellar_styles ON beer_styles.id = cellar_styles.parent_id) SELECT * FROM cellar_styles WHERE deleted_at IS NULL ORDER BY name`)).
 		WithArgs(1).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
-			AddRow(uint(1), "Lambic - Kriek").
-			AddRow(uint(2), "Stout - Imperial / Double Coffee"))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "parent_id"}).
+			AddRow(uint(3), "Lambic", nil).
+			AddRow(uint(1), "Lambic - Kriek", uint(3)).
+			AddRow(uint(2), "Stout - Imperial / Double Coffee", nil))
 
 	styles, err := suite.repository.GetCellarStyles(context.Background(), 1)
 	suite.Require().NoError(err)
-	suite.Len(styles, 2)
-	suite.Equal("Lambic - Kriek", styles[0].Name)
-	suite.Equal("Stout - Imperial / Double Coffee", styles[1].Name)
+	suite.Require().Len(styles, 3)
+	suite.Equal("Lambic", styles[0].Name)
+	suite.Nil(styles[0].ParentID)
+	suite.Equal("Lambic - Kriek", styles[1].Name)
+	suite.Equal(pointy.Uint(3), styles[1].ParentID)
+	suite.Equal("Stout - Imperial / Double Coffee", styles[2].Name)
 }
 
 func (suite *CellarTestSuite) TestSaveAdventCalendar() {
@@ -363,8 +608,8 @@ func (suite *CellarTestSuite) TestSaveAdventCalendar() {
 		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, uint(1), "Christmas Calendar", "An advent calendar for December", startDate, endDate).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uint(5)))
 
-	suite.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "advent_calendar_filters" ("created_at","updated_at","deleted_at","brewery_id","minimum_abv","maximum_abv","style_id","minimum_vintage","maximum_vintage","overdue_to_drink","had_before","special","minimum_quantity","minimum_size","maximum_size","minimum_rating","maximum_rating","added_before") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18) ON CONFLICT DO NOTHING RETURNING "id"`)).
-		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 6.5, nil).
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "advent_calendar_filters" ("created_at","updated_at","deleted_at","brewery_id","minimum_abv","maximum_abv","style_id","minimum_vintage","maximum_vintage","overdue_to_drink","had_before","special","minimum_quantity","minimum_size","maximum_size","minimum_rating","maximum_rating","added_before","use_my_rating") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19) ON CONFLICT DO NOTHING RETURNING "id"`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 6.5, nil, false).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uint(1)))
 
 	suite.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "advent_calendar_beers" ("created_at","updated_at","deleted_at","advent_calendar_id","cellar_entry_id","filter_id","day","revealed") VALUES ($1,$2,$3,$4,$5,$6,$7,$8) ON CONFLICT ("id") DO UPDATE SET "advent_calendar_id"="excluded"."advent_calendar_id" RETURNING "id"`)).
@@ -523,8 +768,8 @@ func (suite *CellarTestSuite) TestUpdateAdventCalendarEntry_Error() {
 
 func (suite *CellarTestSuite) TestDeleteAdventCalendar() {
 	suite.mock.ExpectBegin()
-	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM advent_calendar_beers WHERE advent_calendar_id = $1`)).
-		WithArgs(1).
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM advent_calendar_beers WHERE advent_calendar_id IN (SELECT id FROM advent_calendars WHERE id = $1 AND cellar_id = $2)`)).
+		WithArgs(1, 1).
 		WillReturnResult(sqlmock.NewResult(0, 5))
 	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM advent_calendars WHERE id = $1 AND cellar_id = $2`)).
 		WithArgs(1, 1).
@@ -536,10 +781,25 @@ func (suite *CellarTestSuite) TestDeleteAdventCalendar() {
 	suite.NoError(err)
 }
 
+func (suite *CellarTestSuite) TestDeleteAdventCalendar_OtherCellarDeletesNothing() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM advent_calendar_beers WHERE advent_calendar_id IN (SELECT id FROM advent_calendars WHERE id = $1 AND cellar_id = $2)`)).
+		WithArgs(1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM advent_calendars WHERE id = $1 AND cellar_id = $2`)).
+		WithArgs(1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.DeleteAdventCalendar(context.Background(), 2, 1)
+
+	suite.NoError(err)
+}
+
 func (suite *CellarTestSuite) TestDeleteAdventCalendar_BeerDeletionError() {
 	suite.mock.ExpectBegin()
-	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM advent_calendar_beers WHERE advent_calendar_id = $1`)).
-		WithArgs(1).
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM advent_calendar_beers WHERE advent_calendar_id IN (SELECT id FROM advent_calendars WHERE id = $1 AND cellar_id = $2)`)).
+		WithArgs(1, 1).
 		WillReturnError(errors.New("database error"))
 	suite.mock.ExpectRollback()
 
@@ -550,8 +810,8 @@ func (suite *CellarTestSuite) TestDeleteAdventCalendar_BeerDeletionError() {
 
 func (suite *CellarTestSuite) TestDeleteAdventCalendar_CalendarDeletionError() {
 	suite.mock.ExpectBegin()
-	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM advent_calendar_beers WHERE advent_calendar_id = $1`)).
-		WithArgs(1).
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM advent_calendar_beers WHERE advent_calendar_id IN (SELECT id FROM advent_calendars WHERE id = $1 AND cellar_id = $2)`)).
+		WithArgs(1, 1).
 		WillReturnResult(sqlmock.NewResult(0, 5))
 	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM advent_calendars WHERE id = $1 AND cellar_id = $2`)).
 		WithArgs(1, 1).
@@ -566,11 +826,15 @@ func (suite *CellarTestSuite) TestDeleteAdventCalendar_CalendarDeletionError() {
 func (suite *CellarTestSuite) TestGetAdventCalendarFilter() {
 	day := time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC)
 
-	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "advent_calendar_filters"."id","advent_calendar_filters"."created_at","advent_calendar_filters"."updated_at","advent_calendar_filters"."deleted_at","advent_calendar_filters"."brewery_id","advent_calendar_filters"."minimum_abv","advent_calendar_filters"."maximum_abv","advent_calendar_filters"."style_id","advent_calendar_filters"."minimum_vintage","advent_calendar_filters"."maximum_vintage","advent_calendar_filters"."overdue_to_drink","advent_calendar_filters"."had_before","advent_calendar_filters"."special","advent_calendar_filters"."minimum_quantity","advent_calendar_filters"."minimum_size","advent_calendar_filters"."maximum_size","advent_calendar_filters"."minimum_rating","advent_calendar_filters"."maximum_rating","advent_calendar_filters"."added_before" FROM "advent_calendar_filters" JOIN advent_calendar_beers ON advent_calendar_beers.filter_id = advent_calendar_filters.id JOIN advent_calendars ON advent_calendars.id = advent_calendar_beers.advent_calendar_id WHERE advent_calendars.cellar_id = $1 AND advent_calendar_beers.advent_calendar_id = $2 AND advent_calendar_beers.day = $3 AND "advent_calendar_filters"."deleted_at" IS NULL ORDER BY "advent_calendar_filters"."id" LIMIT $4`)).
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "advent_calendar_filters"."id","advent_calendar_filters"."created_at","advent_calendar_filters"."updated_at","advent_calendar_filters"."deleted_at","advent_calendar_filters"."brewery_id","advent_calendar_filters"."minimum_abv","advent_calendar_filters"."maximum_abv","advent_calendar_filters"."style_id","advent_calendar_filters"."minimum_vintage","advent_calendar_filters"."maximum_vintage","advent_calendar_filters"."overdue_to_drink","advent_calendar_filters"."had_before","advent_calendar_filters"."special","advent_calendar_filters"."minimum_quantity","advent_calendar_filters"."minimum_size","advent_calendar_filters"."maximum_size","advent_calendar_filters"."minimum_rating","advent_calendar_filters"."maximum_rating","advent_calendar_filters"."added_before","advent_calendar_filters"."use_my_rating" FROM "advent_calendar_filters" JOIN advent_calendar_beers ON advent_calendar_beers.filter_id = advent_calendar_filters.id JOIN advent_calendars ON advent_calendars.id = advent_calendar_beers.advent_calendar_id WHERE advent_calendars.cellar_id = $1 AND advent_calendar_beers.advent_calendar_id = $2 AND advent_calendar_beers.day = $3 AND "advent_calendar_filters"."deleted_at" IS NULL ORDER BY "advent_calendar_filters"."id" LIMIT $4`)).
 		WithArgs(1, 1, day, 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "minimum_abv", "maximum_abv"}).
 			AddRow(1, 5.0, 10.0))
 
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "advent_calendar_filter_beer_tags" WHERE "advent_calendar_filter_beer_tags"."advent_calendar_filter_id" = $1`)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
 	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "advent_calendar_filter_tags" WHERE "advent_calendar_filter_tags"."advent_calendar_filter_id" = $1`)).
 		WithArgs(1).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}))
@@ -589,7 +853,7 @@ func (suite *CellarTestSuite) TestGetAdventCalendarFilter() {
 func (suite *CellarTestSuite) TestGetAdventCalendarFilter_NotFound() {
 	day := time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC)
 
-	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "advent_calendar_filters"."id","advent_calendar_filters"."created_at","advent_calendar_filters"."updated_at","advent_calendar_filters"."deleted_at","advent_calendar_filters"."brewery_id","advent_calendar_filters"."minimum_abv","advent_calendar_filters"."maximum_abv","advent_calendar_filters"."style_id","advent_calendar_filters"."minimum_vintage","advent_calendar_filters"."maximum_vintage","advent_calendar_filters"."overdue_to_drink","advent_calendar_filters"."had_before","advent_calendar_filters"."special","advent_calendar_filters"."minimum_quantity","advent_calendar_filters"."minimum_size","advent_calendar_filters"."maximum_size","advent_calendar_filters"."minimum_rating","advent_calendar_filters"."maximum_rating","advent_calendar_filters"."added_before" FROM "advent_calendar_filters" JOIN advent_calendar_beers ON advent_calendar_beers.filter_id = advent_calendar_filters.id JOIN advent_calendars ON advent_calendars.id = advent_calendar_beers.advent_calendar_id WHERE advent_calendars.cellar_id = $1 AND advent_calendar_beers.advent_calendar_id = $2 AND advent_calendar_beers.day = $3 AND "advent_calendar_filters"."deleted_at" IS NULL ORDER BY "advent_calendar_filters"."id" LIMIT $4`)).
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "advent_calendar_filters"."id","advent_calendar_filters"."created_at","advent_calendar_filters"."updated_at","advent_calendar_filters"."deleted_at","advent_calendar_filters"."brewery_id","advent_calendar_filters"."minimum_abv","advent_calendar_filters"."maximum_abv","advent_calendar_filters"."style_id","advent_calendar_filters"."minimum_vintage","advent_calendar_filters"."maximum_vintage","advent_calendar_filters"."overdue_to_drink","advent_calendar_filters"."had_before","advent_calendar_filters"."special","advent_calendar_filters"."minimum_quantity","advent_calendar_filters"."minimum_size","advent_calendar_filters"."maximum_size","advent_calendar_filters"."minimum_rating","advent_calendar_filters"."maximum_rating","advent_calendar_filters"."added_before","advent_calendar_filters"."use_my_rating" FROM "advent_calendar_filters" JOIN advent_calendar_beers ON advent_calendar_beers.filter_id = advent_calendar_filters.id JOIN advent_calendars ON advent_calendars.id = advent_calendar_beers.advent_calendar_id WHERE advent_calendars.cellar_id = $1 AND advent_calendar_beers.advent_calendar_id = $2 AND advent_calendar_beers.day = $3 AND "advent_calendar_filters"."deleted_at" IS NULL ORDER BY "advent_calendar_filters"."id" LIMIT $4`)).
 		WithArgs(1, 1, day, 1).
 		WillReturnError(gorm.ErrRecordNotFound)
 
@@ -31,7 +31,7 @@ func Open(conf *configs.Config, logger *zap.Logger) (*Repository, error) {
 	gormLogger := zapgorm2.New(logger)
 	gormLogger.SetAsDefault()
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: gormLogger})
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: gormLogger, TranslateError: true})
 	if err != nil {
 		return nil, err
 	}
@@ -2,6 +2,7 @@ package repository_test
 
 import (
 	"database/sql"
+	"encoding/base64"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/suite"
@@ -44,3 +45,8 @@ func (suite *RepositorySuite) SetupTest() {
 
 	suite.repository = repository.Repository{DB: suite.DB, Logger: observedLogger}
 }
+
+// pageToken encodes the cursor the way page tokens are, to build tokens a client could have altered.
+func pageToken(cursor string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursor))
+}
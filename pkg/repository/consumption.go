@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+)
+
+var (
+	ErrCellarEntryNotFound = newError(ErrNotFound, "cellar entry not found")
+	ErrNotEnoughBeer       = newError(ErrConflict, "not enough beer left in the cellar entry")
+)
+
+// consumptionSort is the only sort consumptions are listed by, recorded in page tokens.
+const consumptionSort = "drank_at desc"
+
+// ConsumptionFilter narrows the consumptions listed to those of a cellar, a beer or a user. Fields left as zero match
+// every consumption.
+type ConsumptionFilter struct {
+	CellarID uint
+	BeerID   uint
+	UserID   uint
+	// ViewerID limits the consumptions to those from cellars the viewer can see, along with the viewer's own.
+	ViewerID uint
+}
+
+// DrinkBeer records drinking from a cellar entry, taking the quantity drunk out of the entry and marking it as had
+// before. The entry is deleted once none are left, while the consumption keeps the cellar and beer it came from. It
+// returns the consumption along with the entry as it was left.
+func (r *Repository) DrinkBeer(ctx context.Context, consumption model.Consumption) (*model.Consumption, *model.CellarEntry, error) {
+	var entry model.CellarEntry
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&entry, consumption.CellarEntryID)
+		if result.Error != nil {
+			if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+				return resourceError(ErrCellarEntryNotFound, "cellar entry", consumption.CellarEntryID)
+			}
+
+			return result.Error
+		}
+
+		if entry.Quantity < consumption.Quantity {
+			return fmt.Errorf("%w: %d left", ErrNotEnoughBeer, entry.Quantity)
+		}
+
+		consumption.CellarID = entry.CellarID
+		consumption.BeerID = entry.BeerID
+
+		result = tx.Create(&consumption)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		entry.Quantity -= consumption.Quantity
+		entry.HadBefore = true
+
+		result = tx.Model(&entry).Select("quantity", "had_before").Updates(&entry)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if entry.Quantity > 0 {
+			return nil
+		}
+
+		return tx.Delete(&entry).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if entry.Quantity == 0 {
+		entry.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	}
+
+	return &consumption, &entry, nil
+}
+
+// ListConsumptions returns a page of the consumptions matching the filter, most recently drunk first, along with the
+// token for the next page, which is empty on the last page.
+func (r *Repository) ListConsumptions(ctx context.Context, filter ConsumptionFilter, page Page) ([]*model.Consumption, string, error) {
+	cursor, err := page.cursor()
+	if err != nil {
+		return nil, "", err
+	}
+
+	db := r.DB.WithContext(ctx)
+	query := db.Model(&model.Consumption{})
+
+	if filter.CellarID != 0 {
+		query = query.Where("consumptions.cellar_id = ?", filter.CellarID)
+	}
+
+	if filter.BeerID != 0 {
+		query = query.Where("consumptions.beer_id = ?", filter.BeerID)
+	}
+
+	if filter.UserID != 0 {
+		query = query.Where("consumptions.user_id = ?", filter.UserID)
+	}
+
+	if filter.ViewerID != 0 {
+		visibleCellars := db.Model(&model.Cellar{}).Select("id").
+			Where("owner_id = ?", filter.ViewerID).
+			Or("id IN (?)", db.Model(&model.CellarMember{}).Select("cellar_id").
+				Where("user_id = ? AND accepted_at IS NOT NULL", filter.ViewerID))
+
+		query = query.Where("(consumptions.user_id = ? OR consumptions.cellar_id IN (?))", filter.ViewerID, visibleCellars)
+	}
+
+	if page.Token != "" {
+		err = cursor.check(consumptionSort, timeKey)
+		if err != nil {
+			return nil, "", err
+		}
+
+		query = query.Where("(consumptions.drank_at, consumptions.id) < (?, ?)", cursor.Key, cursor.LastID)
+	}
+
+	var consumptions []*model.Consumption
+
+	limit := page.Limit()
+
+	result := query.
+		Joins("User").
+		Preload("Beer.Brewery").
+		Order("consumptions.drank_at DESC").Order("consumptions.id DESC").
+		Limit(limit + 1).
+		Find(&consumptions)
+	if result.Error != nil {
+		return nil, "", result.Error
+	}
+
+	consumptions, nextToken := nextPageToken(consumptions, limit, func(consumption *model.Consumption) pageCursor {
+		return pageCursor{LastID: consumption.ID, Sort: consumptionSort, Key: consumption.DrankAt.Format(time.RFC3339Nano)}
+	})
+
+	return consumptions, nextToken, nil
+}
@@ -0,0 +1,182 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+	"go.openly.dev/pointy"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+)
+
+// consumptionsVisible is the condition listing the consumptions of beer 5 that user 3 can see.
+const consumptionsVisible = `consumptions.beer_id = $1 AND ((consumptions.user_id = $2 OR consumptions.cellar_id IN ` +
+	`(SELECT "id" FROM "cellars" WHERE (owner_id = $3 OR id IN (SELECT "cellar_id" FROM "cellar_members" WHERE ` +
+	`(user_id = $4 AND accepted_at IS NOT NULL) AND "cellar_members"."deleted_at" IS NULL)) AND "cellars"."deleted_at" IS NULL)))`
+
+type ConsumptionTestSuite struct {
+	RepositorySuite
+}
+
+func TestConsumptionTestSuite(t *testing.T) {
+	suite.Run(t, new(ConsumptionTestSuite))
+}
+
+func (suite *ConsumptionTestSuite) TearDownTest() {
+	suite.Require().NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *ConsumptionTestSuite) expectEntryLocked(quantity int64) {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "cellar_entries" WHERE "cellar_entries"."id" = $1 AND "cellar_entries"."deleted_at" IS NULL ORDER BY "cellar_entries"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(10, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "cellar_id", "beer_id", "quantity"}).AddRow(10, 1, 5, quantity))
+}
+
+func (suite *ConsumptionTestSuite) expectConsumptionSaved(quantity int64) {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "consumptions" ("created_at","updated_at","deleted_at","cellar_entry_id","cellar_id","beer_id","user_id","quantity","drank_at","rating","notes") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11) RETURNING "id"`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 10, 1, 5, 3, quantity, sqlmock.AnyArg(), 4.25, "Still fresh").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+}
+
+func (suite *ConsumptionTestSuite) expectEntryUpdated(quantity int64) {
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "cellar_entries" SET "updated_at"=$1,"quantity"=$2,"had_before"=$3 WHERE "cellar_entries"."deleted_at" IS NULL AND "id" = $4`)).
+		WithArgs(sqlmock.AnyArg(), quantity, true, 10).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func consumption(quantity int64) model.Consumption {
+	return model.Consumption{
+		CellarEntryID: 10,
+		UserID:        3,
+		Quantity:      quantity,
+		DrankAt:       time.Date(2024, 12, 24, 20, 0, 0, 0, time.UTC),
+		Rating:        pointy.Float64(4.25),
+		Notes:         "Still fresh",
+	}
+}
+
+func (suite *ConsumptionTestSuite) TestDrinkBeer_TakesQuantityOutOfEntry() {
+	suite.mock.ExpectBegin()
+	suite.expectEntryLocked(3)
+	suite.expectConsumptionSaved(2)
+	suite.expectEntryUpdated(1)
+	suite.mock.ExpectCommit()
+
+	saved, entry, err := suite.repository.DrinkBeer(context.Background(), consumption(2))
+
+	suite.Require().NoError(err)
+	suite.Equal(uint(7), saved.ID)
+	suite.Equal(uint(1), saved.CellarID)
+	suite.Equal(uint(5), saved.BeerID)
+	suite.Equal(int64(1), entry.Quantity)
+	suite.True(entry.HadBefore)
+	suite.False(entry.DeletedAt.Valid)
+}
+
+func (suite *ConsumptionTestSuite) TestDrinkBeer_DeletesEmptyEntry() {
+	suite.mock.ExpectBegin()
+	suite.expectEntryLocked(2)
+	suite.expectConsumptionSaved(2)
+	suite.expectEntryUpdated(0)
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "cellar_entries" SET "deleted_at"=$1 WHERE "cellar_entries"."id" = $2 AND "cellar_entries"."deleted_at" IS NULL`)).
+		WithArgs(sqlmock.AnyArg(), 10).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	_, entry, err := suite.repository.DrinkBeer(context.Background(), consumption(2))
+
+	suite.Require().NoError(err)
+	suite.Equal(int64(0), entry.Quantity)
+	suite.True(entry.DeletedAt.Valid)
+}
+
+func (suite *ConsumptionTestSuite) TestDrinkBeer_NotEnoughLeft() {
+	suite.mock.ExpectBegin()
+	suite.expectEntryLocked(1)
+	suite.mock.ExpectRollback()
+
+	_, _, err := suite.repository.DrinkBeer(context.Background(), consumption(2))
+
+	suite.Require().ErrorIs(err, repository.ErrNotEnoughBeer)
+	suite.Require().ErrorIs(err, repository.ErrConflict)
+}
+
+func (suite *ConsumptionTestSuite) TestDrinkBeer_UnknownEntry() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "cellar_entries"`)).
+		WithArgs(10, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	suite.mock.ExpectRollback()
+
+	_, _, err := suite.repository.DrinkBeer(context.Background(), consumption(1))
+
+	var resourceErr *repository.ResourceError
+
+	suite.Require().ErrorAs(err, &resourceErr)
+	suite.Equal("cellar entry", resourceErr.Resource)
+	suite.Require().ErrorIs(err, repository.ErrNotFound)
+}
+
+func (suite *ConsumptionTestSuite) TestListConsumptions_FiltersAndPages() {
+	drankAt := time.Date(2024, 12, 24, 20, 0, 0, 0, time.UTC)
+
+	suite.mock.ExpectQuery(`^SELECT .+ FROM "consumptions" LEFT JOIN "users" "User" .+ WHERE `+regexp.QuoteMeta(consumptionsVisible+
+		` AND "consumptions"."deleted_at" IS NULL ORDER BY consumptions.drank_at DESC,consumptions.id DESC LIMIT $5`)).
+		WithArgs(5, 3, 3, 3, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "beer_id", "user_id", "quantity", "drank_at"}).
+			AddRow(8, 5, 3, 1, drankAt).
+			AddRow(7, 5, 3, 2, drankAt))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "beers" WHERE "beers"."id" = $1 AND "beers"."deleted_at" IS NULL`)).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "brewery_id"}).AddRow(5, "Abt 12", 2))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "breweries" WHERE "breweries"."id" = $1 AND "breweries"."deleted_at" IS NULL`)).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "St. Bernardus"))
+
+	consumptions, nextToken, err := suite.repository.ListConsumptions(context.Background(),
+		repository.ConsumptionFilter{BeerID: 5, ViewerID: 3}, repository.Page{Size: 1})
+
+	suite.Require().NoError(err)
+	suite.Require().Len(consumptions, 1)
+	suite.Equal(uint(8), consumptions[0].ID)
+	suite.Equal("St. Bernardus", consumptions[0].Beer.Brewery.Name)
+	suite.NotEmpty(nextToken)
+
+	suite.mock.ExpectQuery(`^SELECT .+ WHERE `+regexp.QuoteMeta(consumptionsVisible+
+		` AND (consumptions.drank_at, consumptions.id) < ($5, $6) AND "consumptions"."deleted_at" IS NULL ORDER BY consumptions.drank_at DESC,consumptions.id DESC LIMIT $7`)).
+		WithArgs(5, 3, 3, 3, drankAt.Format(time.RFC3339Nano), 8, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	consumptions, nextToken, err = suite.repository.ListConsumptions(context.Background(),
+		repository.ConsumptionFilter{BeerID: 5, ViewerID: 3}, repository.Page{Size: 1, Token: nextToken})
+
+	suite.Require().NoError(err)
+	suite.Empty(consumptions)
+	suite.Empty(nextToken)
+}
+
+func (suite *ConsumptionTestSuite) TestListConsumptions_TokenFromDifferentList() {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "breweries" WHERE "breweries"."deleted_at" IS NULL ORDER BY breweries.name,breweries.id LIMIT $1`)).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Achel").AddRow(2, "St. Bernardus"))
+
+	_, nextPageToken, err := suite.repository.ListBreweries(context.Background(), "", repository.Page{Size: 1})
+	suite.Require().NoError(err)
+
+	_, _, err = suite.repository.ListConsumptions(context.Background(), repository.ConsumptionFilter{}, repository.Page{Token: nextPageToken})
+
+	suite.Require().ErrorIs(err, repository.ErrInvalidPageToken)
+}
+
+func (suite *ConsumptionTestSuite) TestListConsumptions_TokenWithInvalidDate() {
+	token := pageToken(`{"id":1,"sort":"drank_at desc","key":"yesterday"}`)
+
+	_, _, err := suite.repository.ListConsumptions(context.Background(), repository.ConsumptionFilter{}, repository.Page{Token: token})
+
+	suite.Require().ErrorIs(err, repository.ErrInvalidPageToken)
+}
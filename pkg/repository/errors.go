@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The kinds of errors returned by the repository. Every error the repository defines is of one of these kinds, so
+// that callers such as the API can handle all the errors of a kind alike.
+var (
+	ErrNotFound      = errors.New("not found")
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrConflict is for changes prevented by the current state of the data, such as deleting a format still in use.
+	ErrConflict = errors.New("conflicts with the current data")
+	ErrInvalid  = errors.New("invalid")
+)
+
+// kindError is an error of one of the kinds above. It matches both itself and its kind.
+type kindError struct {
+	message string
+	kind    error
+}
+
+func newError(kind error, message string) error {
+	return &kindError{message: message, kind: kind}
+}
+
+func (e *kindError) Error() string {
+	return e.message
+}
+
+func (e *kindError) Unwrap() error {
+	return e.kind
+}
+
+// ResourceError identifies the resource an error is about, such as the beer that could not be found.
+type ResourceError struct {
+	Resource string
+	ID       string
+	Err      error
+}
+
+func resourceError(err error, resource string, id any) error {
+	return &ResourceError{Resource: resource, ID: fmt.Sprint(id), Err: err}
+}
+
+func (e *ResourceError) Error() string {
+	return fmt.Sprintf("%v: %s", e.Err, e.ID)
+}
+
+func (e *ResourceError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+)
+
+var (
+	ErrFormatNotFound = newError(ErrNotFound, "beer format not found")
+	ErrFormatExists   = newError(ErrAlreadyExists, "beer format already exists")
+	ErrFormatInUse    = newError(ErrConflict, "beer format is in use")
+)
+
+// GetBeerFormats returns the global formats along with the custom formats of the user.
+func (r *Repository) GetBeerFormats(ctx context.Context, userID uint) ([]*model.BeerFormat, error) {
+	var beerFormats []*model.BeerFormat
+
+	result := r.DB.WithContext(ctx).Where("owner_id IS NULL OR owner_id = ?", userID).Order("package, size_metric").Find(&beerFormats)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return beerFormats, nil
+}
+
+func (r *Repository) GetBeerFormat(ctx context.Context, formatID uint) (*model.BeerFormat, error) {
+	var format model.BeerFormat
+
+	result := r.DB.WithContext(ctx).First(&format, formatID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, resourceError(ErrFormatNotFound, "beer_format", formatID)
+		}
+
+		return nil, result.Error
+	}
+
+	return &format, nil
+}
+
+// AddBeerFormat creates a format, unless the owner can already see a format with the same package and size.
+func (r *Repository) AddBeerFormat(ctx context.Context, format *model.BeerFormat) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := checkDuplicateFormat(tx, format)
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(format).Error
+	})
+}
+
+// UpdateBeerFormat changes the package and sizes of a format, unless the owner can already see a format with the new
+// package and size.
+func (r *Repository) UpdateBeerFormat(ctx context.Context, format *model.BeerFormat) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := checkDuplicateFormat(tx, format)
+		if err != nil {
+			return err
+		}
+
+		result := tx.Model(format).Select("package", "size_metric", "size_imperial").Updates(format)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return resourceError(ErrFormatNotFound, "beer_format", format.ID)
+		}
+
+		return nil
+	})
+}
+
+// DeleteBeerFormat deletes a format, as long as no cellar entry uses it.
+func (r *Repository) DeleteBeerFormat(ctx context.Context, formatID uint) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+
+		result := tx.Model(&model.CellarEntry{}).Where("format_id = ?", formatID).Count(&count)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if count > 0 {
+			return fmt.Errorf("%w: used by %d cellar entries", ErrFormatInUse, count)
+		}
+
+		result = tx.Delete(&model.BeerFormat{}, formatID)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return resourceError(ErrFormatNotFound, "beer_format", formatID)
+		}
+
+		return nil
+	})
+}
+
+// checkDuplicateFormat looks for another format with the same package and metric size among the global formats and
+// the custom formats of the format's owner.
+func checkDuplicateFormat(tx *gorm.DB, format *model.BeerFormat) error {
+	query := tx.Model(&model.BeerFormat{}).
+		Where("lower(package) = lower(?) AND size_metric = ? AND id <> ?", format.Package, format.SizeMetric, format.ID)
+
+	if format.OwnerID == nil {
+		query = query.Where("owner_id IS NULL")
+	} else {
+		query = query.Where("owner_id IS NULL OR owner_id = ?", *format.OwnerID)
+	}
+
+	var count int64
+
+	result := query.Count(&count)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if count > 0 {
+		return fmt.Errorf("%w: %s %gml", ErrFormatExists, format.Package, format.SizeMetric)
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+	"go.openly.dev/pointy"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+)
+
+type FormatTestSuite struct {
+	RepositorySuite
+}
+
+func TestFormatTestSuite(t *testing.T) {
+	suite.Run(t, new(FormatTestSuite))
+}
+
+func (suite *FormatTestSuite) TearDownTest() {
+	suite.Require().NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *FormatTestSuite) TestGetBeerFormat_NotFound() {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "beer_formats" WHERE "beer_formats"."id" = $1 AND "beer_formats"."deleted_at" IS NULL ORDER BY "beer_formats"."id" LIMIT $2`)).
+		WithArgs(4, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	format, err := suite.repository.GetBeerFormat(context.Background(), 4)
+
+	suite.Require().ErrorIs(err, repository.ErrFormatNotFound)
+	suite.Nil(format)
+}
+
+func (suite *FormatTestSuite) TestAddBeerFormat_CreatesCustomFormat() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "beer_formats" WHERE (lower(package) = lower($1) AND size_metric = $2 AND id <> $3) AND (owner_id IS NULL OR owner_id = $4) AND "beer_formats"."deleted_at" IS NULL`)).
+		WithArgs("Can", 440.0, 0, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "beer_formats" ("created_at","updated_at","deleted_at","package","size_metric","size_imperial","owner_id") VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING "id"`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Can", 440.0, 14.9, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(40))
+	suite.mock.ExpectCommit()
+
+	format := model.BeerFormat{Package: "Can", SizeMetric: 440, SizeImperial: 14.9, OwnerID: pointy.Uint(3)}
+
+	err := suite.repository.AddBeerFormat(context.Background(), &format)
+
+	suite.Require().NoError(err)
+	suite.Equal(uint(40), format.ID)
+}
+
+func (suite *FormatTestSuite) TestAddBeerFormat_Duplicate() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "beer_formats"`)).
+		WithArgs("can", 355.0, 0, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	suite.mock.ExpectRollback()
+
+	format := model.BeerFormat{Package: "can", SizeMetric: 355, SizeImperial: 12, OwnerID: pointy.Uint(3)}
+
+	err := suite.repository.AddBeerFormat(context.Background(), &format)
+
+	suite.Require().ErrorIs(err, repository.ErrFormatExists)
+}
+
+func (suite *FormatTestSuite) TestUpdateBeerFormat_Updates() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "beer_formats"`)).
+		WithArgs("Mini-keg", 5000.0, 40, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "beer_formats" SET "updated_at"=$1,"package"=$2,"size_metric"=$3,"size_imperial"=$4 WHERE "beer_formats"."deleted_at" IS NULL AND "id" = $5`)).
+		WithArgs(sqlmock.AnyArg(), "Mini-keg", 5000.0, 169.1, 40).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	format := model.BeerFormat{Model: gorm.Model{ID: 40}, Package: "Mini-keg", SizeMetric: 5000, SizeImperial: 169.1, OwnerID: pointy.Uint(3)}
+
+	err := suite.repository.UpdateBeerFormat(context.Background(), &format)
+
+	suite.Require().NoError(err)
+}
+
+func (suite *FormatTestSuite) TestDeleteBeerFormat_InUse() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "cellar_entries" WHERE format_id = $1 AND "cellar_entries"."deleted_at" IS NULL`)).
+		WithArgs(40).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	suite.mock.ExpectRollback()
+
+	err := suite.repository.DeleteBeerFormat(context.Background(), 40)
+
+	suite.Require().ErrorIs(err, repository.ErrFormatInUse)
+	suite.Require().ErrorIs(err, repository.ErrConflict)
+}
+
+func (suite *FormatTestSuite) TestDeleteBeerFormat_Deletes() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "cellar_entries"`)).
+		WithArgs(40).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "beer_formats" SET "deleted_at"=$1 WHERE "beer_formats"."id" = $2 AND "beer_formats"."deleted_at" IS NULL`)).
+		WithArgs(sqlmock.AnyArg(), 40).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.DeleteBeerFormat(context.Background(), 40)
+
+	suite.Require().NoError(err)
+}
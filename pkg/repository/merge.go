@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+)
+
+var ErrInvalidMerge = newError(ErrInvalid, "invalid merge")
+
+// statement is a SQL statement run as one step of a larger change.
+type statement struct {
+	sql  string
+	args []any
+}
+
+// normalizedName builds the expression used to compare names, ignoring case, spacing and punctuation.
+func normalizedName(column string) string {
+	return fmt.Sprintf("regexp_replace(lower(%s), '[^[:alnum:]]+', '', 'g')", column)
+}
+
+// sameExternalID builds the condition matching rows imported from the same entry of the same integration.
+func sameExternalID(kept string, duplicate string) string {
+	return fmt.Sprintf("(%[1]s.external_id = %[2]s.external_id AND %[1]s.external_source = %[2]s.external_source)", kept, duplicate)
+}
+
+// FindDuplicateBeers proposes pairs of beers that are likely the same: beers of the same brewery whose normalized names
+// match, and beers imported from the same external entry.
+func (r *Repository) FindDuplicateBeers(ctx context.Context) ([]model.DuplicateCandidate, error) {
+	var candidates []model.DuplicateCandidate
+
+	result := r.DB.WithContext(ctx).Raw("SELECT kept.id AS keep_id, kept.name AS keep_name, dup.id AS merge_id, dup.name AS merge_name, "+
+		"CASE WHEN "+sameExternalID("kept", "dup")+" THEN ? ELSE ? END AS reason"+
+		" FROM beers kept JOIN beers dup ON kept.id < dup.id"+
+		" WHERE kept.deleted_at IS NULL AND dup.deleted_at IS NULL AND ("+sameExternalID("kept", "dup")+
+		" OR (kept.brewery_id = dup.brewery_id AND "+normalizedName("kept.name")+" = "+normalizedName("dup.name")+"))"+
+		" ORDER BY kept.id, dup.id", model.DuplicateReasonExternalID, model.DuplicateReasonName).
+		Scan(&candidates)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return candidates, nil
+}
+
+// FindDuplicateBreweries proposes pairs of breweries that are likely the same: breweries whose normalized names match,
+// and breweries imported from the same external entry.
+func (r *Repository) FindDuplicateBreweries(ctx context.Context) ([]model.DuplicateCandidate, error) {
+	var candidates []model.DuplicateCandidate
+
+	result := r.DB.WithContext(ctx).Raw("SELECT kept.id AS keep_id, kept.name AS keep_name, dup.id AS merge_id, dup.name AS merge_name, "+
+		"CASE WHEN "+sameExternalID("kept", "dup")+" THEN ? ELSE ? END AS reason"+
+		" FROM breweries kept JOIN breweries dup ON kept.id < dup.id"+
+		" WHERE kept.deleted_at IS NULL AND dup.deleted_at IS NULL AND ("+sameExternalID("kept", "dup")+
+		" OR "+normalizedName("kept.name")+" = "+normalizedName("dup.name")+")"+
+		" ORDER BY kept.id, dup.id", model.DuplicateReasonExternalID, model.DuplicateReasonName).
+		Scan(&candidates)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return candidates, nil
+}
+
+// MergeBeers moves the cellar entries, consumptions, tasting notes and tags of the merged beer to the kept beer, fills
+// in details the kept beer is missing, and deletes the merged beer.
+func (r *Repository) MergeBeers(ctx context.Context, keepID uint, mergeID uint) (*model.Beer, error) {
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if keepID == mergeID {
+			return fmt.Errorf("%w: cannot merge a beer into itself", ErrInvalidMerge)
+		}
+
+		var count int64
+
+		result := tx.Model(&model.Beer{}).Where("id IN ?", []uint{keepID, mergeID}).Count(&count)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if count != 2 {
+			return ErrBeerNotFound
+		}
+
+		return mergeBeer(tx, keepID, mergeID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetBeer(ctx, keepID)
+}
+
+// MergeBreweries moves the beers of the merged brewery to the kept brewery, merging beers whose names match one of the
+// kept brewery's beers, fills in details the kept brewery is missing, and deletes the merged brewery and its address.
+func (r *Repository) MergeBreweries(ctx context.Context, keepID uint, mergeID uint) (*model.Brewery, error) {
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if keepID == mergeID {
+			return fmt.Errorf("%w: cannot merge a brewery into itself", ErrInvalidMerge)
+		}
+
+		var breweries []*model.Brewery
+
+		result := tx.Select("id", "address_id", "external_id", "external_source").
+			Where("id IN ?", []uint{keepID, mergeID}).
+			Find(&breweries)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if len(breweries) != 2 {
+			return ErrBreweryNotFound
+		}
+
+		kept, merged := breweries[0], breweries[1]
+		if kept.ID != keepID {
+			kept, merged = merged, kept
+		}
+
+		err := mergeBreweryBeers(tx, keepID, mergeID)
+		if err != nil {
+			return err
+		}
+
+		statements := []statement{
+			{"UPDATE beers SET brewery_id = ? WHERE brewery_id = ?", []any{keepID, mergeID}},
+			{"UPDATE advent_calendar_filters SET brewery_id = ? WHERE brewery_id = ?", []any{keepID, mergeID}},
+			{"UPDATE breweries AS kept SET " +
+				"description = CASE WHEN kept.description = '' THEN dup.description ELSE kept.description END, " +
+				"image_url = CASE WHEN kept.image_url = '' THEN dup.image_url ELSE kept.image_url END, " +
+				"external_rating = COALESCE(kept.external_rating, dup.external_rating) " +
+				"FROM breweries AS dup WHERE kept.id = ? AND dup.id = ?", []any{keepID, mergeID}},
+			{"DELETE FROM breweries WHERE id = ?", []any{mergeID}},
+		}
+
+		// The external ID is only copied once the merged brewery is gone, as breweries with the same name, such as one
+		// added by hand and one imported, cannot share it.
+		if kept.ExternalID == nil && merged.ExternalID != nil {
+			statements = append(statements, statement{
+				"UPDATE breweries SET external_id = ?, external_source = ? WHERE id = ?",
+				[]any{*merged.ExternalID, merged.ExternalSource, keepID},
+			})
+		}
+
+		statements = append(statements, statement{
+			"DELETE FROM addresses WHERE id = ? AND NOT EXISTS (SELECT 1 FROM breweries WHERE address_id = ?)",
+			[]any{merged.AddressID, merged.AddressID},
+		})
+
+		return execStatements(tx, statements)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetBrewery(ctx, keepID)
+}
+
+// mergeBreweryBeers merges the beers of the merged brewery into the kept brewery's beers with the same normalized name,
+// which would otherwise be duplicates once both breweries are merged. Deleted beers are merged too, as they still hold
+// their name in the unique index. Each beer is paired with a live beer of the kept brewery where there is one, and
+// preferably the one with the same name. When only a deleted beer of the kept brewery matches, that one is merged into
+// the live beer instead, so that a live beer is never deleted in favor of a deleted one.
+func mergeBreweryBeers(tx *gorm.DB, keepID uint, mergeID uint) error {
+	var beerPairs []model.DuplicateCandidate
+
+	revived := "kept.deleted_at IS NOT NULL AND dup.deleted_at IS NULL"
+
+	result := tx.Raw("SELECT DISTINCT ON (dup.id)"+
+		" CASE WHEN "+revived+" THEN dup.id ELSE kept.id END AS keep_id,"+
+		" CASE WHEN "+revived+" THEN kept.id ELSE dup.id END AS merge_id"+
+		" FROM beers dup JOIN beers kept ON kept.brewery_id = ? AND "+normalizedName("kept.name")+" = "+normalizedName("dup.name")+
+		" WHERE dup.brewery_id = ?"+
+		" ORDER BY dup.id, kept.deleted_at IS NOT NULL, kept.name <> dup.name, kept.id", keepID, mergeID).
+		Scan(&beerPairs)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	for _, pair := range beerPairs {
+		err := mergeBeer(tx, pair.KeepID, pair.MergeID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mergeBeer re-points everything referencing the merged beer to the kept beer, and deletes the merged beer.
+func mergeBeer(tx *gorm.DB, keepID uint, mergeID uint) error {
+	return execStatements(tx, []statement{
+		{"UPDATE cellar_entries SET beer_id = ? WHERE beer_id = ?", []any{keepID, mergeID}},
+		{"UPDATE consumptions SET beer_id = ? WHERE beer_id = ?", []any{keepID, mergeID}},
+		{"UPDATE tasting_notes SET beer_id = ? WHERE beer_id = ?", []any{keepID, mergeID}},
+		{"INSERT INTO beer_tags (beer_id, tag_id) SELECT ?, tag_id FROM beer_tags WHERE beer_id = ? ON CONFLICT DO NOTHING", []any{keepID, mergeID}},
+		{"DELETE FROM beer_tags WHERE beer_id = ?", []any{mergeID}},
+		{"UPDATE beers AS kept SET " +
+			"description = CASE WHEN kept.description = '' THEN dup.description ELSE kept.description END, " +
+			"image_url = CASE WHEN kept.image_url = '' THEN dup.image_url ELSE kept.image_url END, " +
+			"abv = COALESCE(kept.abv, dup.abv), ibu = COALESCE(kept.ibu, dup.ibu), " +
+			"external_id = COALESCE(kept.external_id, dup.external_id), " +
+			"external_source = CASE WHEN kept.external_id IS NULL THEN dup.external_source ELSE kept.external_source END, " +
+			"external_rating = COALESCE(kept.external_rating, dup.external_rating) " +
+			"FROM beers AS dup WHERE kept.id = ? AND dup.id = ?", []any{keepID, mergeID}},
+		{"DELETE FROM beers WHERE id = ?", []any{mergeID}},
+	})
+}
+
+func execStatements(tx *gorm.DB, statements []statement) error {
+	for _, statement := range statements {
+		err := tx.Exec(statement.sql, statement.args...).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,222 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+)
+
+type MergeTestSuite struct {
+	RepositorySuite
+}
+
+func TestMergeTestSuite(t *testing.T) {
+	suite.Run(t, new(MergeTestSuite))
+}
+
+func (suite *MergeTestSuite) TearDownTest() {
+	suite.Require().NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *MergeTestSuite) TestFindDuplicateBeers_ReturnsCandidates() {
+	suite.mock.ExpectQuery(`^SELECT kept.id AS keep_id, .+ FROM beers kept JOIN beers dup ON kept.id < dup.id .+`+
+		regexp.QuoteMeta(`kept.brewery_id = dup.brewery_id AND regexp_replace(lower(kept.name), '[^[:alnum:]]+', '', 'g') = regexp_replace(lower(dup.name), '[^[:alnum:]]+', '', 'g')`)).
+		WithArgs(model.DuplicateReasonExternalID, model.DuplicateReasonName).
+		WillReturnRows(sqlmock.NewRows([]string{"keep_id", "keep_name", "merge_id", "merge_name", "reason"}).
+			AddRow(3, "Pliny the Elder", 8, "Pliny The Elder", "name"))
+
+	candidates, err := suite.repository.FindDuplicateBeers(context.Background())
+
+	suite.Require().NoError(err)
+	suite.Equal([]model.DuplicateCandidate{
+		{KeepID: 3, KeepName: "Pliny the Elder", MergeID: 8, MergeName: "Pliny The Elder", Reason: model.DuplicateReasonName},
+	}, candidates)
+}
+
+func (suite *MergeTestSuite) TestFindDuplicateBreweries_ReturnsCandidates() {
+	suite.mock.ExpectQuery(`^SELECT kept.id AS keep_id, .+ FROM breweries kept JOIN breweries dup ON kept.id < dup.id .+`).
+		WithArgs(model.DuplicateReasonExternalID, model.DuplicateReasonName).
+		WillReturnRows(sqlmock.NewRows([]string{"keep_id", "keep_name", "merge_id", "merge_name", "reason"}).
+			AddRow(1, "Russian River", 2, "Russian River", "external_id"))
+
+	candidates, err := suite.repository.FindDuplicateBreweries(context.Background())
+
+	suite.Require().NoError(err)
+	suite.Require().Len(candidates, 1)
+	suite.Equal(model.DuplicateReasonExternalID, candidates[0].Reason)
+}
+
+func (suite *MergeTestSuite) expectBeerMerged(keepID int, mergeID int) {
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE cellar_entries SET beer_id = $1 WHERE beer_id = $2`)).
+		WithArgs(keepID, mergeID).WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE consumptions SET beer_id = $1 WHERE beer_id = $2`)).
+		WithArgs(keepID, mergeID).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE tasting_notes SET beer_id = $1 WHERE beer_id = $2`)).
+		WithArgs(keepID, mergeID).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO beer_tags (beer_id, tag_id) SELECT $1, tag_id FROM beer_tags WHERE beer_id = $2 ON CONFLICT DO NOTHING`)).
+		WithArgs(keepID, mergeID).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM beer_tags WHERE beer_id = $1`)).
+		WithArgs(mergeID).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec(`^UPDATE beers AS kept SET .+ FROM beers AS dup WHERE kept.id = \$1 AND dup.id = \$2$`).
+		WithArgs(keepID, mergeID).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM beers WHERE id = $1`)).
+		WithArgs(mergeID).WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func (suite *MergeTestSuite) TestMergeBeers_RepointsAndDeletes() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "beers" WHERE id IN ($1,$2) AND "beers"."deleted_at" IS NULL`)).
+		WithArgs(3, 8).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	suite.expectBeerMerged(3, 8)
+	suite.mock.ExpectCommit()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "beers" WHERE "beers"."id" = $1 AND "beers"."deleted_at" IS NULL ORDER BY "beers"."id" LIMIT $2`)).
+		WithArgs(3, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3, "Pliny the Elder"))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "beer_tags" WHERE "beer_tags"."beer_id" = $1`)).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"beer_id", "tag_id"}))
+
+	beer, err := suite.repository.MergeBeers(context.Background(), 3, 8)
+
+	suite.Require().NoError(err)
+	suite.Equal("Pliny the Elder", beer.Name)
+}
+
+func (suite *MergeTestSuite) TestMergeBeers_UnknownBeer() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "beers"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	suite.mock.ExpectRollback()
+
+	_, err := suite.repository.MergeBeers(context.Background(), 3, 8)
+
+	suite.Require().ErrorIs(err, repository.ErrBeerNotFound)
+}
+
+func (suite *MergeTestSuite) TestMergeBeers_RejectsSameBeer() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectRollback()
+
+	_, err := suite.repository.MergeBeers(context.Background(), 3, 3)
+
+	suite.Require().ErrorIs(err, repository.ErrInvalidMerge)
+}
+
+func (suite *MergeTestSuite) TestMergeBreweries_MergesMatchingBeersAndRepoints() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id","address_id","external_id","external_source" FROM "breweries" WHERE id IN ($1,$2) AND "breweries"."deleted_at" IS NULL`)).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "address_id", "external_id", "external_source"}).
+			AddRow(1, 10, 4321, "untappd").
+			AddRow(2, 20, 1234, "untappd"))
+	suite.mock.ExpectQuery(`^SELECT DISTINCT ON \(dup.id\) .+ AS keep_id, .+ AS merge_id FROM beers dup JOIN beers kept ON kept.brewery_id = \$1 .+ WHERE dup.brewery_id = \$2 ORDER BY`).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"keep_id", "merge_id"}).AddRow(3, 8))
+	suite.expectBeerMerged(3, 8)
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE beers SET brewery_id = $1 WHERE brewery_id = $2`)).
+		WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 4))
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE advent_calendar_filters SET brewery_id = $1 WHERE brewery_id = $2`)).
+		WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectExec(`^UPDATE breweries AS kept SET .+ FROM breweries AS dup WHERE kept.id = \$1 AND dup.id = \$2$`).
+		WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM breweries WHERE id = $1`)).
+		WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM addresses WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM breweries WHERE address_id = $2)`)).
+		WithArgs(20, 20).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "breweries" WHERE "breweries"."id" = $1`)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Russian River"))
+
+	brewery, err := suite.repository.MergeBreweries(context.Background(), 1, 2)
+
+	suite.Require().NoError(err)
+	suite.Equal("Russian River", brewery.Name)
+}
+
+// Beer 3 of the kept brewery was deleted and has the same name as beer 8 of the merged brewery, so it is merged into
+// beer 8 before beer 8 moves to the kept brewery.
+func (suite *MergeTestSuite) TestMergeBreweries_MergesDeletedDuplicate() {
+	revived := "kept.deleted_at IS NOT NULL AND dup.deleted_at IS NULL"
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id","address_id","external_id","external_source" FROM "breweries"`)).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "address_id"}).AddRow(1, 10).AddRow(2, 20))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT DISTINCT ON (dup.id) CASE WHEN `+revived+` THEN dup.id ELSE kept.id END AS keep_id,`+
+		` CASE WHEN `+revived+` THEN kept.id ELSE dup.id END AS merge_id`+
+		` FROM beers dup JOIN beers kept ON kept.brewery_id = $1 AND regexp_replace(lower(kept.name), '[^[:alnum:]]+', '', 'g') = regexp_replace(lower(dup.name), '[^[:alnum:]]+', '', 'g')`+
+		` WHERE dup.brewery_id = $2 ORDER BY dup.id, kept.deleted_at IS NOT NULL, kept.name <> dup.name, kept.id`)).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"keep_id", "merge_id"}).AddRow(8, 3))
+	suite.expectBeerMerged(8, 3)
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE beers SET brewery_id = $1 WHERE brewery_id = $2`)).
+		WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE advent_calendar_filters SET brewery_id = $1 WHERE brewery_id = $2`)).
+		WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectExec(`^UPDATE breweries AS kept SET .+ FROM breweries AS dup WHERE kept.id = \$1 AND dup.id = \$2$`).
+		WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM breweries WHERE id = $1`)).
+		WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM addresses WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM breweries WHERE address_id = $2)`)).
+		WithArgs(20, 20).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "breweries" WHERE "breweries"."id" = $1`)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Russian River"))
+
+	_, err := suite.repository.MergeBreweries(context.Background(), 1, 2)
+
+	suite.Require().NoError(err)
+}
+
+func (suite *MergeTestSuite) TestMergeBreweries_SameNameTakesExternalIDAfterDelete() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id","address_id","external_id","external_source" FROM "breweries" WHERE id IN ($1,$2) AND "breweries"."deleted_at" IS NULL`)).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "address_id", "external_id", "external_source"}).
+			AddRow(2, 20, 1234, "untappd").
+			AddRow(1, 10, nil, nil))
+	suite.mock.ExpectQuery(`^SELECT DISTINCT ON \(dup.id\) .+ FROM beers dup`).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"keep_id", "merge_id"}))
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE beers SET brewery_id = $1 WHERE brewery_id = $2`)).
+		WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE advent_calendar_filters SET brewery_id = $1 WHERE brewery_id = $2`)).
+		WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectExec(`^UPDATE breweries AS kept SET .+ FROM breweries AS dup WHERE kept.id = \$1 AND dup.id = \$2$`).
+		WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM breweries WHERE id = $1`)).
+		WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE breweries SET external_id = $1, external_source = $2 WHERE id = $3`)).
+		WithArgs(1234, "untappd", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM addresses WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM breweries WHERE address_id = $2)`)).
+		WithArgs(20, 20).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "breweries" WHERE "breweries"."id" = $1`)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "external_id"}).AddRow(1, "Russian River", 1234))
+
+	brewery, err := suite.repository.MergeBreweries(context.Background(), 1, 2)
+
+	suite.Require().NoError(err)
+	suite.Equal(uint64(1234), *brewery.ExternalID)
+}
+
+func (suite *MergeTestSuite) TestMergeBreweries_UnknownBrewery() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id","address_id","external_id","external_source" FROM "breweries"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "address_id"}).AddRow(1, 10))
+	suite.mock.ExpectRollback()
+
+	_, err := suite.repository.MergeBreweries(context.Background(), 1, 2)
+
+	suite.Require().ErrorIs(err, repository.ErrBreweryNotFound)
+}
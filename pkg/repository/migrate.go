@@ -0,0 +1,330 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID is the key of the Postgres advisory lock held while migrating, so that concurrent deploys wait for
+// each other instead of applying the same migrations twice.
+const migrationLockID = 4_242_001
+
+var (
+	ErrInvalidMigration = errors.New("invalid migration")
+	ErrUnknownMigration = errors.New("unknown migration version")
+	ErrInvalidSteps     = errors.New("invalid number of migrations to roll back")
+)
+
+// migrationFileName matches the migration files, named <version>_<name>.<up|down>.sql.
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a versioned schema change, with the SQL applying it and the SQL rolling it back.
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus tells whether a migration has been applied. Migrations applied to the database but not known to this
+// build have an empty Up and Down.
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// schemaMigration is a row of the schema_migrations table, recording an applied migration.
+type schemaMigration struct {
+	Version   uint `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrations returns the embedded migrations ordered by version.
+func Migrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint]*Migration)
+
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("%w: unexpected file %s", ErrInvalidMigration, entry.Name())
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidMigration, entry.Name(), err)
+		}
+
+		content, err := migrationFiles.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, found := byVersion[uint(version)]
+		if !found {
+			migration = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[uint(version)] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("%w: version %d is used by %s and %s", ErrInvalidMigration, version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+
+	for _, migration := range byVersion {
+		if len(migration.Up) == 0 || len(migration.Down) == 0 {
+			return nil, fmt.Errorf("%w: %04d_%s needs both an up and a down file", ErrInvalidMigration, migration.Version, migration.Name)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	slices.SortFunc(migrations, func(a, b Migration) int { return cmp.Compare(a.Version, b.Version) })
+
+	return migrations, nil
+}
+
+// MigrationStatus lists the known migrations along with when they were applied, followed by the migrations applied to
+// the database that this build does not know about.
+func (r *Repository) MigrationStatus(ctx context.Context) ([]MigrationStatus, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	db := r.DB.WithContext(ctx)
+
+	err = db.Exec(createSchemaMigrations).Error
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+
+	for _, migration := range migrations {
+		status := MigrationStatus{Migration: migration}
+
+		if row, found := applied[migration.Version]; found {
+			status.AppliedAt = &row.AppliedAt
+			delete(applied, migration.Version)
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	for _, row := range applied {
+		statuses = append(statuses, MigrationStatus{Migration: Migration{Version: row.Version, Name: row.Name}, AppliedAt: &row.AppliedAt})
+	}
+
+	slices.SortFunc(statuses[len(migrations):], func(a, b MigrationStatus) int { return cmp.Compare(a.Version, b.Version) })
+
+	return statuses, nil
+}
+
+// MigrateUp applies all the migrations not applied yet, and returns them.
+func (r *Repository) MigrateUp(ctx context.Context) ([]Migration, error) {
+	return r.migrate(ctx, func(migrations []Migration, _ map[uint]schemaMigration) (uint, error) {
+		if len(migrations) == 0 {
+			return 0, nil
+		}
+
+		return migrations[len(migrations)-1].Version, nil
+	})
+}
+
+// MigrateDown rolls back the given number of the most recently applied migrations, and returns them.
+func (r *Repository) MigrateDown(ctx context.Context, steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("%w: %d, at least 1 is needed", ErrInvalidSteps, steps)
+	}
+
+	return r.migrate(ctx, func(migrations []Migration, applied map[uint]schemaMigration) (uint, error) {
+		// Migrations unknown to this build cannot be rolled back, and are not counted.
+		versions := make([]uint, 0, len(applied))
+
+		for _, migration := range migrations {
+			if _, found := applied[migration.Version]; found {
+				versions = append(versions, migration.Version)
+			}
+		}
+
+		if steps >= len(versions) {
+			return 0, nil
+		}
+
+		return versions[len(versions)-steps-1], nil
+	})
+}
+
+// MigrateTo applies or rolls back migrations until the given version is the last one applied. Version 0 rolls back all
+// the migrations.
+func (r *Repository) MigrateTo(ctx context.Context, version uint) ([]Migration, error) {
+	return r.migrate(ctx, func(migrations []Migration, _ map[uint]schemaMigration) (uint, error) {
+		if version != 0 && !slices.ContainsFunc(migrations, func(m Migration) bool { return m.Version == version }) {
+			return 0, fmt.Errorf("%w: %d", ErrUnknownMigration, version)
+		}
+
+		return version, nil
+	})
+}
+
+const createSchemaMigrations = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version bigint PRIMARY KEY,
+	name text NOT NULL,
+	applied_at timestamptz NOT NULL
+)`
+
+// migrate brings the schema to the target version. The target is chosen while holding the migration lock, so that it
+// is based on the migrations applied by any deploy that ran first. Each migration runs in its own transaction along
+// with the update of schema_migrations.
+func (r *Repository) migrate(ctx context.Context, target func([]Migration, map[uint]schemaMigration) (uint, error)) ([]Migration, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var run []Migration
+
+	// The advisory lock belongs to the database session, so everything runs on a single connection.
+	err = r.DB.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		// Connection hands over a shared statement; a new session keeps conditions from leaking between queries.
+		conn = conn.Session(&gorm.Session{})
+
+		err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockID).Error
+		if err != nil {
+			return err
+		}
+
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockID)
+
+		err = conn.Exec(createSchemaMigrations).Error
+		if err != nil {
+			return err
+		}
+
+		applied, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		version, err := target(migrations, applied)
+		if err != nil {
+			return err
+		}
+
+		run, err = r.runMigrations(conn, migrationPlan(migrations, applied, version))
+
+		return err
+	})
+
+	return run, err
+}
+
+// migrationStep is a migration to apply or roll back.
+type migrationStep struct {
+	migration Migration
+	up        bool
+}
+
+// migrationPlan lists the steps bringing the schema to the target version: rolling back the applied migrations after
+// it, newest first, then applying the missing migrations up to it, oldest first.
+func migrationPlan(migrations []Migration, applied map[uint]schemaMigration, target uint) []migrationStep {
+	var steps []migrationStep
+
+	for _, migration := range slices.Backward(migrations) {
+		if _, found := applied[migration.Version]; found && migration.Version > target {
+			steps = append(steps, migrationStep{migration: migration, up: false})
+		}
+	}
+
+	for _, migration := range migrations {
+		if _, found := applied[migration.Version]; !found && migration.Version <= target {
+			steps = append(steps, migrationStep{migration: migration, up: true})
+		}
+	}
+
+	return steps
+}
+
+func (r *Repository) runMigrations(conn *gorm.DB, steps []migrationStep) ([]Migration, error) {
+	run := make([]Migration, 0, len(steps))
+
+	for _, step := range steps {
+		err := conn.Transaction(func(tx *gorm.DB) error {
+			if step.up {
+				err := tx.Exec(step.migration.Up).Error
+				if err != nil {
+					return err
+				}
+
+				return tx.Create(&schemaMigration{Version: step.migration.Version, Name: step.migration.Name, AppliedAt: time.Now().UTC()}).Error
+			}
+
+			err := tx.Exec(step.migration.Down).Error
+			if err != nil {
+				return err
+			}
+
+			return tx.Where("version = ?", step.migration.Version).Delete(&schemaMigration{}).Error
+		})
+		if err != nil {
+			return run, fmt.Errorf("migration %04d_%s: %w", step.migration.Version, step.migration.Name, err)
+		}
+
+		r.Logger.Info("migration run", zap.Uint("version", step.migration.Version), zap.String("name", step.migration.Name), zap.Bool("up", step.up))
+
+		run = append(run, step.migration)
+	}
+
+	return run, nil
+}
+
+func appliedMigrations(db *gorm.DB) (map[uint]schemaMigration, error) {
+	var rows []schemaMigration
+
+	result := db.Order("version").Find(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	applied := make(map[uint]schemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+
+	return applied, nil
+}
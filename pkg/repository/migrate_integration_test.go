@@ -0,0 +1,107 @@
+//go:build integration
+
+package repository_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+)
+
+// baselineUser and baselineBeerFormat are the models as AutoMigrate created them before versioned migrations, without
+// the columns added since.
+type baselineUser struct {
+	gorm.Model
+	UUID            uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4()"`
+	Username        string
+	FirstName       string
+	LastName        string
+	Email           string
+	UntappdUserName *string
+}
+
+func (baselineUser) TableName() string {
+	return "users"
+}
+
+type baselineBeerFormat struct {
+	gorm.Model
+	Package      string
+	SizeMetric   float64
+	SizeImperial float64
+}
+
+func (baselineBeerFormat) TableName() string {
+	return "beer_formats"
+}
+
+// MigrateIntegrationTestSuite applies the migrations to databases created by AutoMigrate, against a real Postgres
+// database given by the BEERGARGOYLE_TEST_DSN environment variable. Run it with `task test:integration`.
+type MigrateIntegrationTestSuite struct {
+	suite.Suite
+	repository repository.Repository
+}
+
+func TestMigrateIntegrationTestSuite(t *testing.T) {
+	dsn := os.Getenv("BEERGARGOYLE_TEST_DSN")
+	if dsn == "" {
+		t.Skip("BEERGARGOYLE_TEST_DSN is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("error connecting to database: %v", err)
+	}
+
+	suite.Run(t, &MigrateIntegrationTestSuite{repository: repository.Repository{DB: db, Logger: zaptest.NewLogger(t)}})
+}
+
+func (suite *MigrateIntegrationTestSuite) SetupTest() {
+	_, err := suite.repository.MigrateTo(context.Background(), 0)
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(suite.repository.DB.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`).Error)
+}
+
+func (suite *MigrateIntegrationTestSuite) TearDownTest() {
+	_, err := suite.repository.MigrateTo(context.Background(), 0)
+	suite.Require().NoError(err)
+}
+
+func (suite *MigrateIntegrationTestSuite) TestMigrateUp_AddsColumnsMissingFromBaseline() {
+	db := suite.repository.DB
+	suite.Require().NoError(db.AutoMigrate(&baselineUser{}, &baselineBeerFormat{}))
+	suite.Require().NoError(db.Create(&baselineUser{Username: "existing"}).Error)
+
+	_, err := suite.repository.MigrateUp(context.Background())
+	suite.Require().NoError(err)
+
+	suite.True(db.Migrator().HasColumn(&model.User{}, "role"))
+	suite.True(db.Migrator().HasColumn(&model.User{}, "disabled"))
+	suite.True(db.Migrator().HasColumn(&model.BeerFormat{}, "owner_id"))
+	suite.True(db.Migrator().HasIndex(&model.BeerFormat{}, "idx_beer_formats_owner_id"))
+
+	var user model.User
+	suite.Require().NoError(db.Where("username = ?", "existing").First(&user).Error)
+	suite.Equal(model.UserRoleUser, user.Role)
+}
+
+func (suite *MigrateIntegrationTestSuite) TestMigrateUp_KeepsAutoMigratedStyleParent() {
+	db := suite.repository.DB
+	suite.Require().NoError(db.AutoMigrate(&model.BeerStyle{}))
+	suite.Require().True(db.Migrator().HasConstraint(&model.BeerStyle{}, "fk_beer_styles_parent"))
+
+	_, err := suite.repository.MigrateUp(context.Background())
+	suite.Require().NoError(err)
+
+	suite.True(db.Migrator().HasConstraint(&model.BeerStyle{}, "fk_beer_styles_parent"))
+}
@@ -0,0 +1,175 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+
+	"droscher.com/BeerGargoyle/pkg/repository"
+)
+
+type MigrateTestSuite struct {
+	RepositorySuite
+}
+
+func TestMigrateTestSuite(t *testing.T) {
+	suite.Run(t, new(MigrateTestSuite))
+}
+
+func (suite *MigrateTestSuite) TearDownTest() {
+	suite.Require().NoError(suite.mock.ExpectationsWereMet())
+}
+
+// expectLocked expects the migration lock to be taken and the applied migrations to be read.
+func (suite *MigrateTestSuite) expectLocked(applied ...uint) {
+	suite.mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_lock($1)`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.expectApplied(applied...)
+}
+
+func (suite *MigrateTestSuite) expectApplied(applied ...uint) {
+	suite.mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS schema_migrations`)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	rows := sqlmock.NewRows([]string{"version", "name", "applied_at"})
+	for _, version := range applied {
+		rows.AddRow(version, "initial_schema", time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	}
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "schema_migrations" ORDER BY version`)).WillReturnRows(rows)
+}
+
+func (suite *MigrateTestSuite) expectUnlocked() {
+	suite.mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_unlock($1)`)).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func (suite *MigrateTestSuite) TestMigrations_LoadsEmbeddedFiles() {
+	migrations, err := repository.Migrations()
+
+	suite.Require().NoError(err)
+	suite.Require().NotEmpty(migrations)
+	suite.Equal(uint(1), migrations[0].Version)
+	suite.Equal("initial_schema", migrations[0].Name)
+
+	for index, migration := range migrations {
+		suite.NotEmpty(migration.Up)
+		suite.NotEmpty(migration.Down)
+
+		if index > 0 {
+			suite.Greater(migration.Version, migrations[index-1].Version)
+		}
+	}
+}
+
+func (suite *MigrateTestSuite) TestMigrateUp_AppliesPendingMigrations() {
+	migrations, err := repository.Migrations()
+	suite.Require().NoError(err)
+
+	suite.expectLocked()
+
+	for _, migration := range migrations {
+		suite.mock.ExpectBegin()
+		suite.mock.ExpectExec(regexp.QuoteMeta(migration.Up)).WillReturnResult(sqlmock.NewResult(0, 0))
+		suite.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "schema_migrations" ("version","name","applied_at") VALUES ($1,$2,$3)`)).
+			WithArgs(migration.Version, migration.Name, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		suite.mock.ExpectCommit()
+	}
+
+	suite.expectUnlocked()
+
+	run, err := suite.repository.MigrateUp(context.Background())
+
+	suite.Require().NoError(err)
+	suite.Equal(migrations, run)
+}
+
+func (suite *MigrateTestSuite) TestMigrateUp_NothingPending() {
+	migrations, err := repository.Migrations()
+	suite.Require().NoError(err)
+
+	applied := make([]uint, 0, len(migrations))
+	for _, migration := range migrations {
+		applied = append(applied, migration.Version)
+	}
+
+	suite.expectLocked(applied...)
+	suite.expectUnlocked()
+
+	run, err := suite.repository.MigrateUp(context.Background())
+
+	suite.Require().NoError(err)
+	suite.Empty(run)
+}
+
+func (suite *MigrateTestSuite) TestMigrateDown_RollsBackLatest() {
+	suite.expectLocked(1)
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DROP TABLE IF EXISTS "advent_calendar_filter_beer_tags"`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "schema_migrations" WHERE version = $1`)).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+	suite.expectUnlocked()
+
+	run, err := suite.repository.MigrateDown(context.Background(), 1)
+
+	suite.Require().NoError(err)
+	suite.Require().Len(run, 1)
+	suite.Equal(uint(1), run[0].Version)
+}
+
+func (suite *MigrateTestSuite) TestMigrateDown_InvalidSteps() {
+	for _, steps := range []int{0, -1} {
+		run, err := suite.repository.MigrateDown(context.Background(), steps)
+
+		suite.Require().ErrorIs(err, repository.ErrInvalidSteps)
+		suite.Empty(run)
+	}
+}
+
+func (suite *MigrateTestSuite) TestMigrateUp_FailedMigrationRollsBack() {
+	suite.expectLocked()
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(`^-- The schema`).WillReturnError(sqlmock.ErrCancelled)
+	suite.mock.ExpectRollback()
+	suite.expectUnlocked()
+
+	run, err := suite.repository.MigrateUp(context.Background())
+
+	suite.Require().ErrorIs(err, sqlmock.ErrCancelled)
+	suite.Empty(run)
+}
+
+func (suite *MigrateTestSuite) TestMigrateTo_UnknownVersion() {
+	suite.expectLocked(1)
+	suite.expectUnlocked()
+
+	_, err := suite.repository.MigrateTo(context.Background(), 9999)
+
+	suite.Require().ErrorIs(err, repository.ErrUnknownMigration)
+}
+
+func (suite *MigrateTestSuite) TestMigrationStatus_ListsAppliedAndPending() {
+	migrations, err := repository.Migrations()
+	suite.Require().NoError(err)
+
+	suite.expectApplied(1, 9999)
+
+	statuses, err := suite.repository.MigrationStatus(context.Background())
+
+	suite.Require().NoError(err)
+	suite.Require().Len(statuses, len(migrations)+1)
+	suite.NotNil(statuses[0].AppliedAt)
+
+	for _, status := range statuses[1:len(migrations)] {
+		suite.Nil(status.AppliedAt)
+	}
+
+	unknown := statuses[len(migrations)]
+	suite.Equal(uint(9999), unknown.Version)
+	suite.NotNil(unknown.AppliedAt)
+	suite.Empty(unknown.Up)
+}
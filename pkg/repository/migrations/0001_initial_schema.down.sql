@@ -0,0 +1,21 @@
+-- Drops everything, including all the data.
+
+DROP TABLE IF EXISTS "advent_calendar_filter_beer_tags";
+DROP TABLE IF EXISTS "advent_calendar_filter_tags";
+DROP TABLE IF EXISTS "cellar_entry_tags";
+DROP TABLE IF EXISTS "beer_tags";
+DROP TABLE IF EXISTS "advent_calendar_beers";
+DROP TABLE IF EXISTS "advent_calendar_filters";
+DROP TABLE IF EXISTS "advent_calendars";
+DROP TABLE IF EXISTS "cellar_entries";
+DROP TABLE IF EXISTS "location_in_cellars";
+DROP TABLE IF EXISTS "cellar_members";
+DROP TABLE IF EXISTS "cellars";
+DROP TABLE IF EXISTS "access_tokens";
+DROP TABLE IF EXISTS "users";
+DROP TABLE IF EXISTS "beers";
+DROP TABLE IF EXISTS "tags";
+DROP TABLE IF EXISTS "beer_formats";
+DROP TABLE IF EXISTS "beer_styles";
+DROP TABLE IF EXISTS "breweries";
+DROP TABLE IF EXISTS "addresses";
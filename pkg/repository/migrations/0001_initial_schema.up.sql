@@ -0,0 +1,299 @@
+-- The schema as it was created by AutoMigrate. Every statement is guarded, so that databases created before
+-- versioned migrations are adopted by applying this migration.
+
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE TABLE IF NOT EXISTS "addresses" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "country" text,
+    "locality" text,
+    "region" text,
+    "postal_code" text,
+    "street_address" text,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_addresses_deleted_at" ON "addresses" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "breweries" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "name" text,
+    "description" text,
+    "address_id" bigint,
+    "image_url" text,
+    "external_id" bigint,
+    "external_source" text,
+    "external_rating" decimal,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_breweries_address" FOREIGN KEY ("address_id") REFERENCES "addresses"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_brewery_unique" ON "breweries" ("name","external_id");
+CREATE INDEX IF NOT EXISTS "idx_breweries_deleted_at" ON "breweries" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "beer_styles" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "name" text,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_beer_styles_name" ON "beer_styles" ("name");
+CREATE INDEX IF NOT EXISTS "idx_beer_styles_deleted_at" ON "beer_styles" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "beer_formats" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "package" text,
+    "size_metric" decimal,
+    "size_imperial" decimal,
+    "owner_id" bigint,
+    PRIMARY KEY ("id")
+);
+-- Columns added after the table was first created by AutoMigrate, which a table created then does not have yet.
+ALTER TABLE "beer_formats" ADD COLUMN IF NOT EXISTS "owner_id" bigint;
+CREATE INDEX IF NOT EXISTS "idx_beer_formats_owner_id" ON "beer_formats" ("owner_id");
+CREATE INDEX IF NOT EXISTS "idx_beer_formats_deleted_at" ON "beer_formats" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "tags" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "tag" text,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_tags_deleted_at" ON "tags" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "beers" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "name" text,
+    "description" text,
+    "image_url" text,
+    "brewery_id" bigint,
+    "style_id" bigint,
+    "abv" decimal,
+    "ibu" bigint,
+    "external_id" bigint,
+    "external_source" text,
+    "external_rating" decimal,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_beers_brewery" FOREIGN KEY ("brewery_id") REFERENCES "breweries"("id") ON DELETE SET NULL ON UPDATE CASCADE,
+    CONSTRAINT "fk_beers_style" FOREIGN KEY ("style_id") REFERENCES "beer_styles"("id") ON DELETE SET NULL ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_beer_unique" ON "beers" ("name","brewery_id");
+CREATE INDEX IF NOT EXISTS "idx_beers_deleted_at" ON "beers" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "users" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "uuid" uuid DEFAULT uuid_generate_v4(),
+    "username" text,
+    "first_name" text,
+    "last_name" text,
+    "email" text,
+    "untappd_user_name" text,
+    "role" text DEFAULT 'user',
+    "disabled" boolean,
+    PRIMARY KEY ("id")
+);
+ALTER TABLE "users"
+    ADD COLUMN IF NOT EXISTS "role" text DEFAULT 'user',
+    ADD COLUMN IF NOT EXISTS "disabled" boolean;
+CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "access_tokens" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "uuid" uuid DEFAULT uuid_generate_v4(),
+    "user_id" bigint,
+    "name" text,
+    "prefix" text,
+    "hash" text,
+    "scope" text,
+    "last_used_at" timestamptz,
+    "expires_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_access_tokens_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_access_tokens_hash" ON "access_tokens" ("hash");
+CREATE INDEX IF NOT EXISTS "idx_access_tokens_user_id" ON "access_tokens" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_access_tokens_deleted_at" ON "access_tokens" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "cellars" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "name" text,
+    "description" text,
+    "owner_id" bigint,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_cellars_owner" FOREIGN KEY ("owner_id") REFERENCES "users"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_name_owner" ON "cellars" ("name","owner_id");
+CREATE INDEX IF NOT EXISTS "idx_cellars_deleted_at" ON "cellars" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "cellar_members" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "cellar_id" bigint,
+    "user_id" bigint,
+    "role" text,
+    "invited_by_id" bigint,
+    "accepted_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_cellar_members_user" FOREIGN KEY ("user_id") REFERENCES "users"("id"),
+    CONSTRAINT "fk_cellars_members" FOREIGN KEY ("cellar_id") REFERENCES "cellars"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_cellar_member" ON "cellar_members" ("cellar_id","user_id");
+CREATE INDEX IF NOT EXISTS "idx_cellar_members_deleted_at" ON "cellar_members" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "location_in_cellars" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "name" text,
+    "cellar_id" bigint,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_cellars_locations" FOREIGN KEY ("cellar_id") REFERENCES "cellars"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_location_in_cellars_deleted_at" ON "location_in_cellars" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "cellar_entries" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "cellar_id" bigint,
+    "beer_id" bigint,
+    "vintage" bigint,
+    "quantity" bigint,
+    "location_id" bigint,
+    "format_id" bigint,
+    "had_before" boolean,
+    "date_added" timestamptz,
+    "drink_before" timestamptz,
+    "cellar_until" timestamptz,
+    "special" boolean,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_cellar_entries_cellar" FOREIGN KEY ("cellar_id") REFERENCES "cellars"("id"),
+    CONSTRAINT "fk_cellar_entries_beer" FOREIGN KEY ("beer_id") REFERENCES "beers"("id"),
+    CONSTRAINT "fk_cellar_entries_location" FOREIGN KEY ("location_id") REFERENCES "location_in_cellars"("id"),
+    CONSTRAINT "fk_cellar_entries_format" FOREIGN KEY ("format_id") REFERENCES "beer_formats"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_cellar_entries_deleted_at" ON "cellar_entries" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "advent_calendars" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "cellar_id" bigint,
+    "name" text,
+    "description" text,
+    "start_date" timestamptz,
+    "end_date" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_advent_cellar_name" ON "advent_calendars" ("cellar_id","name");
+CREATE INDEX IF NOT EXISTS "idx_advent_calendars_deleted_at" ON "advent_calendars" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "advent_calendar_filters" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "brewery_id" bigint,
+    "minimum_abv" decimal,
+    "maximum_abv" decimal,
+    "style_id" bigint,
+    "minimum_vintage" bigint,
+    "maximum_vintage" bigint,
+    "overdue_to_drink" boolean,
+    "had_before" boolean,
+    "special" boolean,
+    "minimum_quantity" bigint,
+    "minimum_size" bigint,
+    "maximum_size" bigint,
+    "minimum_rating" decimal,
+    "maximum_rating" decimal,
+    "added_before" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_advent_calendar_filters_deleted_at" ON "advent_calendar_filters" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "advent_calendar_beers" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "advent_calendar_id" bigint,
+    "cellar_entry_id" bigint,
+    "filter_id" bigint,
+    "day" timestamptz,
+    "revealed" boolean,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_advent_calendar_beers_filter" FOREIGN KEY ("filter_id") REFERENCES "advent_calendar_filters"("id"),
+    CONSTRAINT "fk_advent_calendar_beers_cellar_entry" FOREIGN KEY ("cellar_entry_id") REFERENCES "cellar_entries"("id"),
+    CONSTRAINT "fk_advent_calendars_beers" FOREIGN KEY ("advent_calendar_id") REFERENCES "advent_calendars"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_advent_calendar_beers_deleted_at" ON "advent_calendar_beers" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "beer_tags" (
+    "beer_id" bigint,
+    "tag_id" bigint,
+    PRIMARY KEY ("beer_id","tag_id"),
+    CONSTRAINT "fk_beer_tags_beer" FOREIGN KEY ("beer_id") REFERENCES "beers"("id"),
+    CONSTRAINT "fk_beer_tags_tag" FOREIGN KEY ("tag_id") REFERENCES "tags"("id")
+);
+
+CREATE TABLE IF NOT EXISTS "cellar_entry_tags" (
+    "cellar_entry_id" bigint,
+    "tag_id" bigint,
+    PRIMARY KEY ("cellar_entry_id","tag_id"),
+    CONSTRAINT "fk_cellar_entry_tags_cellar_entry" FOREIGN KEY ("cellar_entry_id") REFERENCES "cellar_entries"("id"),
+    CONSTRAINT "fk_cellar_entry_tags_tag" FOREIGN KEY ("tag_id") REFERENCES "tags"("id")
+);
+
+CREATE TABLE IF NOT EXISTS "advent_calendar_filter_tags" (
+    "advent_calendar_filter_id" bigint,
+    "tag_id" bigint,
+    PRIMARY KEY ("advent_calendar_filter_id","tag_id"),
+    CONSTRAINT "fk_advent_calendar_filter_tags_advent_calendar_filter" FOREIGN KEY ("advent_calendar_filter_id") REFERENCES "advent_calendar_filters"("id"),
+    CONSTRAINT "fk_advent_calendar_filter_tags_tag" FOREIGN KEY ("tag_id") REFERENCES "tags"("id")
+);
+
+CREATE TABLE IF NOT EXISTS "advent_calendar_filter_beer_tags" (
+    "advent_calendar_filter_id" bigint,
+    "tag_id" bigint,
+    PRIMARY KEY ("advent_calendar_filter_id","tag_id"),
+    CONSTRAINT "fk_advent_calendar_filter_beer_tags_advent_calendar_filter" FOREIGN KEY ("advent_calendar_filter_id") REFERENCES "advent_calendar_filters"("id"),
+    CONSTRAINT "fk_advent_calendar_filter_beer_tags_tag" FOREIGN KEY ("tag_id") REFERENCES "tags"("id")
+);
+
+-- Search indexes, used by SearchCatalog and the trigram matching of SearchBeers.
+CREATE INDEX IF NOT EXISTS idx_beers_name_trgm ON beers USING gin (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_beers_search ON beers USING gin ((to_tsvector('english', coalesce(name, '') || ' ' || coalesce(description, ''))));
+CREATE INDEX IF NOT EXISTS idx_breweries_name_trgm ON breweries USING gin (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_breweries_search ON breweries USING gin ((to_tsvector('english', coalesce(name, ''))));
+CREATE INDEX IF NOT EXISTS idx_beer_styles_name_trgm ON beer_styles USING gin (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_beer_styles_search ON beer_styles USING gin ((to_tsvector('english', coalesce(name, ''))));
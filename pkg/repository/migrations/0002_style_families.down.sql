@@ -0,0 +1,13 @@
+-- The families added by the up migration are kept, as they can't be told apart from styles added since.
+
+ALTER TABLE "beer_styles" DROP CONSTRAINT IF EXISTS "fk_beer_styles_parent";
+DROP INDEX IF EXISTS "idx_beer_styles_parent_id";
+ALTER TABLE "beer_styles"
+    DROP COLUMN IF EXISTS "parent_id",
+    DROP COLUMN IF EXISTS "min_abv",
+    DROP COLUMN IF EXISTS "max_abv",
+    DROP COLUMN IF EXISTS "min_ibu",
+    DROP COLUMN IF EXISTS "max_ibu",
+    DROP COLUMN IF EXISTS "min_srm",
+    DROP COLUMN IF EXISTS "max_srm",
+    DROP COLUMN IF EXISTS "cellar_years";
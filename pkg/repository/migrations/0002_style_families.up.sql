@@ -0,0 +1,34 @@
+-- Styles get a parent family and optional guideline ranges. Styles named like the Untappd styles, such as
+-- "IPA - American", are put in the family named by the part before the dash, adding the family when it is missing.
+
+ALTER TABLE "beer_styles"
+    ADD COLUMN IF NOT EXISTS "parent_id" bigint,
+    ADD COLUMN IF NOT EXISTS "min_abv" decimal,
+    ADD COLUMN IF NOT EXISTS "max_abv" decimal,
+    ADD COLUMN IF NOT EXISTS "min_ibu" bigint,
+    ADD COLUMN IF NOT EXISTS "max_ibu" bigint,
+    ADD COLUMN IF NOT EXISTS "min_srm" decimal,
+    ADD COLUMN IF NOT EXISTS "max_srm" decimal,
+    ADD COLUMN IF NOT EXISTS "cellar_years" bigint;
+-- AutoMigrate creates the same constraint, so databases it created already have it.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_beer_styles_parent' AND conrelid = 'beer_styles'::regclass) THEN
+        ALTER TABLE "beer_styles"
+            ADD CONSTRAINT "fk_beer_styles_parent" FOREIGN KEY ("parent_id") REFERENCES "beer_styles"("id") ON DELETE SET NULL ON UPDATE CASCADE;
+    END IF;
+END $$;
+CREATE INDEX IF NOT EXISTS "idx_beer_styles_parent_id" ON "beer_styles" ("parent_id");
+
+INSERT INTO "beer_styles" ("created_at", "updated_at", "name")
+SELECT DISTINCT now(), now(), split_part("name", ' - ', 1)
+FROM "beer_styles"
+WHERE "name" LIKE '% - %' AND "deleted_at" IS NULL
+ON CONFLICT ("name") DO NOTHING;
+
+UPDATE "beer_styles" AS "style"
+SET "parent_id" = "family"."id"
+FROM "beer_styles" AS "family"
+WHERE "style"."name" LIKE '% - %'
+  AND "family"."name" = split_part("style"."name", ' - ', 1)
+  AND "family"."deleted_at" IS NULL;
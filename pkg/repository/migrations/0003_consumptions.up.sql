@@ -0,0 +1,26 @@
+-- Beers drunk from cellar entries. The cellar and beer are kept on each consumption so that the history outlives the
+-- entry, which is deleted once none are left.
+
+CREATE TABLE IF NOT EXISTS "consumptions" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "cellar_entry_id" bigint,
+    "cellar_id" bigint,
+    "beer_id" bigint,
+    "user_id" bigint,
+    "quantity" bigint,
+    "drank_at" timestamptz,
+    "rating" decimal,
+    "notes" text,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_consumptions_cellar_entry" FOREIGN KEY ("cellar_entry_id") REFERENCES "cellar_entries"("id"),
+    CONSTRAINT "fk_consumptions_cellar" FOREIGN KEY ("cellar_id") REFERENCES "cellars"("id"),
+    CONSTRAINT "fk_consumptions_beer" FOREIGN KEY ("beer_id") REFERENCES "beers"("id"),
+    CONSTRAINT "fk_consumptions_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_consumptions_deleted_at" ON "consumptions" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_consumptions_cellar_id" ON "consumptions" ("cellar_id", "drank_at");
+CREATE INDEX IF NOT EXISTS "idx_consumptions_beer_id" ON "consumptions" ("beer_id", "drank_at");
+CREATE INDEX IF NOT EXISTS "idx_consumptions_user_id" ON "consumptions" ("user_id", "drank_at");
@@ -0,0 +1,2 @@
+ALTER TABLE "advent_calendar_filters" DROP COLUMN IF EXISTS "use_my_rating";
+DROP TABLE IF EXISTS "tasting_notes";
@@ -0,0 +1,28 @@
+-- Users' own tasting notes of beers, which filters and stats can use in place of the external rating.
+
+CREATE TABLE IF NOT EXISTS "tasting_notes" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "user_id" bigint,
+    "beer_id" bigint,
+    "cellar_entry_id" bigint,
+    "vintage" bigint,
+    "rating" decimal,
+    "aroma" decimal,
+    "appearance" decimal,
+    "taste" decimal,
+    "mouthfeel" decimal,
+    "notes" text,
+    "served_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_tasting_notes_user" FOREIGN KEY ("user_id") REFERENCES "users"("id"),
+    CONSTRAINT "fk_tasting_notes_beer" FOREIGN KEY ("beer_id") REFERENCES "beers"("id"),
+    CONSTRAINT "fk_tasting_notes_cellar_entry" FOREIGN KEY ("cellar_entry_id") REFERENCES "cellar_entries"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_tasting_notes_deleted_at" ON "tasting_notes" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_tasting_notes_user_id" ON "tasting_notes" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_tasting_notes_beer_id" ON "tasting_notes" ("beer_id");
+
+ALTER TABLE "advent_calendar_filters" ADD COLUMN IF NOT EXISTS "use_my_rating" boolean NOT NULL DEFAULT false;
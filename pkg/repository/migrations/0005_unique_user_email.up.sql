@@ -0,0 +1,4 @@
+-- Each email can only have one account. Deleted accounts keep their row, so they are left out of the index. Creating
+-- the index fails if two accounts already share an email, one of them has to be deleted first.
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_email" ON "users" ("email") WHERE "deleted_at" IS NULL;
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// cellarEventsChannel is the Postgres channel cellar events are sent between instances of the server on.
+const cellarEventsChannel = "cellar_events"
+
+var ErrUnsupportedDriver = errors.New("listening for notifications needs the pgx driver")
+
+// NotifyCellarEvent sends the payload to every instance listening for cellar events, including this one.
+func (r *Repository) NotifyCellarEvent(ctx context.Context, payload string) error {
+	return r.DB.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", cellarEventsChannel, payload).Error
+}
+
+// ListenCellarEvents calls handle with the payload of every cellar event until the context is cancelled or the
+// connection fails. It holds on to one of the pool's connections while listening.
+func (r *Repository) ListenCellarEvents(ctx context.Context, handle func(payload string)) error {
+	sqlDB, err := r.DB.DB()
+	if err != nil {
+		return err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		stdlibConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return ErrUnsupportedDriver
+		}
+
+		return listen(ctx, stdlibConn.Conn(), handle)
+	})
+}
+
+func listen(ctx context.Context, conn *pgx.Conn, handle func(payload string)) error {
+	_, err := conn.Exec(ctx, "LISTEN "+cellarEventsChannel)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		// The connection goes back to the pool, so it must stop listening even though the context may be done.
+		_, _ = conn.Exec(context.WithoutCancel(ctx), "UNLISTEN "+cellarEventsChannel)
+	}()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		handle(notification.Payload)
+	}
+}
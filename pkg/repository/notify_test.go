@@ -0,0 +1,37 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+
+	"droscher.com/BeerGargoyle/pkg/repository"
+)
+
+type NotifyTestSuite struct {
+	RepositorySuite
+}
+
+func TestNotifyTestSuite(t *testing.T) {
+	suite.Run(t, new(NotifyTestSuite))
+}
+
+func (suite *NotifyTestSuite) TestNotifyCellarEvent_Notifies() {
+	payload := `{"type":"entry_added","cellar_id":1,"entry_id":10}`
+
+	suite.mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_notify($1, $2)`)).
+		WithArgs("cellar_events", payload).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repository.NotifyCellarEvent(context.Background(), payload)
+	suite.Require().NoError(err)
+}
+
+func (suite *NotifyTestSuite) TestListenCellarEvents_NeedsPgx() {
+	err := suite.repository.ListenCellarEvents(context.Background(), func(string) {})
+
+	suite.Require().ErrorIs(err, repository.ErrUnsupportedDriver)
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+var (
+	ErrInvalidPageToken = newError(ErrInvalid, "invalid page token")
+	ErrInvalidSort      = newError(ErrInvalid, "invalid sort")
+)
+
+// Page selects a page of results. Pages are addressed with an opaque token returned alongside the previous page, so
+// that rows added or removed between requests do not shift the results.
+type Page struct {
+	Size  int
+	Token string
+}
+
+// pageCursor identifies the last row of a page. Results sorted on a column other than the ID also carry the sort and
+// the value of the sort column, so that the next page continues from the same position.
+type pageCursor struct {
+	LastID uint   `json:"id"`
+	Sort   string `json:"sort,omitempty"`
+	Key    any    `json:"key,omitempty"`
+}
+
+// keyKind is the type of the sort value a page cursor carries, which the cursor is checked against before the value is
+// passed to the database.
+type keyKind int
+
+const (
+	textKey keyKind = iota
+	numberKey
+	// timeKey is a timestamp kept as text, or infinity for dates sorted after every other date.
+	timeKey
+)
+
+// Limit returns the page size to use, applying the default and the maximum.
+func (p Page) Limit() int {
+	if p.Size <= 0 {
+		return DefaultPageSize
+	}
+
+	return min(p.Size, MaxPageSize)
+}
+
+func (p Page) cursor() (pageCursor, error) {
+	var cursor pageCursor
+
+	if p.Token == "" {
+		return cursor, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(p.Token)
+	if err != nil {
+		return cursor, fmt.Errorf("%w: %w", ErrInvalidPageToken, err)
+	}
+
+	err = json.Unmarshal(data, &cursor)
+	if err != nil {
+		return cursor, fmt.Errorf("%w: %w", ErrInvalidPageToken, err)
+	}
+
+	return cursor, nil
+}
+
+// check makes sure the cursor was created for the sort, and that its sort value is of the kind the sort compares.
+func (c pageCursor) check(sort string, kind keyKind) error {
+	if c.Sort != sort {
+		return fmt.Errorf("%w: token was created for a different sort", ErrInvalidPageToken)
+	}
+
+	var valid bool
+
+	switch kind {
+	case textKey:
+		_, valid = c.Key.(string)
+	case numberKey:
+		_, valid = c.Key.(float64)
+	case timeKey:
+		text, _ := c.Key.(string)
+		_, err := time.Parse(time.RFC3339Nano, text)
+		valid = err == nil || text == "infinity"
+	}
+
+	if !valid {
+		return fmt.Errorf("%w: sort value %v does not match the sort", ErrInvalidPageToken, c.Key)
+	}
+
+	return nil
+}
+
+func encodePageToken(cursor pageCursor) string {
+	data, _ := json.Marshal(cursor) //nolint:errchkjson // a struct of plain fields always marshals
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// nextPageToken trims the extra row fetched to detect a following page, and returns the token for that page if there
+// is one.
+func nextPageToken[T any](rows []T, limit int, cursor func(T) pageCursor) ([]T, string) {
+	if len(rows) <= limit {
+		return rows, ""
+	}
+
+	rows = rows[:limit]
+
+	return rows, encodePageToken(cursor(rows[limit-1]))
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+)
+
+const (
+	// searchSimilarityThreshold is the pg_trgm word similarity a name needs to match a query. It is lower than the
+	// pg_trgm default so that misspelled names still match.
+	searchSimilarityThreshold = 0.3
+	// breweryMatchWeight and styleMatchWeight scale the score of beers found through their brewery or style, so that
+	// beers matching by their own name rank first.
+	breweryMatchWeight = 0.8
+	styleMatchWeight   = 0.5
+
+	// The document expressions must match the expressions of the search indexes created by the migrations for Postgres
+	// to use them.
+	beerDocument    = "to_tsvector('english', coalesce(beers.name, '') || ' ' || coalesce(beers.description, ''))"
+	breweryDocument = "to_tsvector('english', coalesce(breweries.name, ''))"
+	styleDocument   = "to_tsvector('english', coalesce(beer_styles.name, ''))"
+	searchQuery     = "websearch_to_tsquery('english', @query)"
+)
+
+type searchMatch struct {
+	ID    uint
+	Score float64
+}
+
+// SearchCatalog finds the beers and breweries best matching the query, tolerating misspellings. Beers match on their
+// name, description, brewery and style, and breweries on their name. Results are ordered by score, highest first.
+func (r *Repository) SearchCatalog(ctx context.Context, query string, limit int) ([]model.BeerMatch, []model.BreweryMatch, error) {
+	var (
+		beers     []model.BeerMatch
+		breweries []model.BreweryMatch
+	)
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// SET LOCAL cannot take parameters, the threshold is formatted into the statement.
+		err := tx.Exec(fmt.Sprintf("SET LOCAL pg_trgm.word_similarity_threshold = %v", searchSimilarityThreshold)).Error
+		if err != nil {
+			return err
+		}
+
+		params := map[string]any{
+			"query":         query,
+			"limit":         limit,
+			"breweryWeight": breweryMatchWeight,
+			"styleWeight":   styleMatchWeight,
+		}
+
+		beers, err = searchBeers(tx, params)
+		if err != nil {
+			return err
+		}
+
+		breweries, err = searchBreweries(tx, params)
+
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return beers, breweries, nil
+}
+
+func searchBeers(tx *gorm.DB, params map[string]any) ([]model.BeerMatch, error) {
+	var matches []searchMatch
+
+	result := tx.Raw("SELECT beers.id, GREATEST("+
+		"word_similarity(@query, beers.name), "+
+		"ts_rank("+beerDocument+", "+searchQuery+"), "+
+		"word_similarity(@query, coalesce(breweries.name, '')) * @breweryWeight, "+
+		"word_similarity(@query, coalesce(beer_styles.name, '')) * @styleWeight) AS score"+
+		" FROM beers"+
+		" LEFT JOIN breweries ON breweries.id = beers.brewery_id AND breweries.deleted_at IS NULL"+
+		" LEFT JOIN beer_styles ON beer_styles.id = beers.style_id AND beer_styles.deleted_at IS NULL"+
+		" WHERE beers.deleted_at IS NULL AND (@query <% beers.name OR "+beerDocument+" @@ "+searchQuery+
+		" OR @query <% breweries.name OR "+breweryDocument+" @@ "+searchQuery+
+		" OR @query <% beer_styles.name OR "+styleDocument+" @@ "+searchQuery+")"+
+		" ORDER BY score DESC, beers.id LIMIT @limit", params).Scan(&matches)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	var beers []*model.Beer
+
+	result = tx.Preload("Brewery.Address").Preload("Style").Preload("Tags").Find(&beers, matchIDs(matches))
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return scoreMatches(matches, beers, func(beer *model.Beer) uint { return beer.ID },
+		func(beer *model.Beer, score float64) model.BeerMatch {
+			return model.BeerMatch{Beer: *beer, Score: score}
+		}), nil
+}
+
+func searchBreweries(tx *gorm.DB, params map[string]any) ([]model.BreweryMatch, error) {
+	var matches []searchMatch
+
+	result := tx.Raw("SELECT breweries.id, GREATEST("+
+		"word_similarity(@query, breweries.name), "+
+		"ts_rank("+breweryDocument+", "+searchQuery+")) AS score"+
+		" FROM breweries"+
+		" WHERE breweries.deleted_at IS NULL AND (@query <% breweries.name OR "+breweryDocument+" @@ "+searchQuery+")"+
+		" ORDER BY score DESC, breweries.id LIMIT @limit", params).Scan(&matches)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	var breweries []*model.Brewery
+
+	result = tx.Preload("Address").Find(&breweries, matchIDs(matches))
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return scoreMatches(matches, breweries, func(brewery *model.Brewery) uint { return brewery.ID },
+		func(brewery *model.Brewery, score float64) model.BreweryMatch {
+			return model.BreweryMatch{Brewery: *brewery, Score: score}
+		}), nil
+}
+
+func matchIDs(matches []searchMatch) []uint {
+	ids := make([]uint, 0, len(matches))
+	for _, match := range matches {
+		ids = append(ids, match.ID)
+	}
+
+	return ids
+}
+
+// scoreMatches pairs the loaded rows with their scores, in the order of the matches.
+func scoreMatches[T any, M any](matches []searchMatch, rows []*T, id func(*T) uint, match func(*T, float64) M) []M {
+	rowsByID := make(map[uint]*T, len(rows))
+	for _, row := range rows {
+		rowsByID[id(row)] = row
+	}
+
+	scored := make([]M, 0, len(matches))
+
+	for _, searchMatch := range matches {
+		if row, found := rowsByID[searchMatch.ID]; found {
+			scored = append(scored, match(row, searchMatch.Score))
+		}
+	}
+
+	return scored
+}
@@ -0,0 +1,107 @@
+//go:build integration
+
+package repository_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"go.openly.dev/pointy"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+)
+
+// SearchIntegrationTestSuite runs the catalog search against a real Postgres database, given by the
+// BEERGARGOYLE_TEST_DSN environment variable. Run it with `task test:integration`.
+type SearchIntegrationTestSuite struct {
+	suite.Suite
+	repository repository.Repository
+}
+
+func TestSearchIntegrationTestSuite(t *testing.T) {
+	dsn := os.Getenv("BEERGARGOYLE_TEST_DSN")
+	if dsn == "" {
+		t.Skip("BEERGARGOYLE_TEST_DSN is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("error connecting to database: %v", err)
+	}
+
+	suite.Run(t, &SearchIntegrationTestSuite{repository: repository.Repository{DB: db, Logger: zaptest.NewLogger(t)}})
+}
+
+func (suite *SearchIntegrationTestSuite) SetupTest() {
+	db := suite.repository.DB
+
+	_, err := suite.repository.MigrateUp(context.Background())
+	suite.Require().NoError(err)
+
+	saison := model.BeerStyle{Name: "Saison - Farmhouse Ale"}
+	stout := model.BeerStyle{Name: "Stout - Imperial"}
+	suite.Require().NoError(db.Create(&saison).Error)
+	suite.Require().NoError(db.Create(&stout).Error)
+
+	bellwoods := model.Brewery{Name: "Bellwoods Brewery", Address: model.Address{Locality: "Toronto"}}
+	blackberry := model.Brewery{Name: "Blackberry Farm Brewery", Address: model.Address{Locality: "Walland"}}
+	suite.Require().NoError(db.Create(&bellwoods).Error)
+	suite.Require().NoError(db.Create(&blackberry).Error)
+
+	suite.Require().NoError(db.Create([]*model.Beer{
+		{Name: "Precious Bet", Description: "Peach saison fermented with brettanomyces", BreweryID: bellwoods.ID, StyleID: saison.ID, ABV: pointy.Float64(8.2)},
+		{Name: "Jelly King", Description: "Dry hopped sour", BreweryID: bellwoods.ID, StyleID: saison.ID, ABV: pointy.Float64(5.6)},
+		{Name: "Classic Saison", Description: "Farmhouse ale", BreweryID: blackberry.ID, StyleID: saison.ID, ABV: pointy.Float64(6.3)},
+		{Name: "Grizzly Bear", Description: "Roasty imperial stout", BreweryID: blackberry.ID, StyleID: stout.ID, ABV: pointy.Float64(11.0)},
+	}).Error)
+}
+
+func (suite *SearchIntegrationTestSuite) TearDownTest() {
+	_, err := suite.repository.MigrateTo(context.Background(), 0)
+	suite.Require().NoError(err)
+}
+
+func (suite *SearchIntegrationTestSuite) TestSearchCatalog_MatchesMisspelledName() {
+	beers, _, err := suite.repository.SearchCatalog(context.Background(), "Precius Bet", 10)
+
+	suite.Require().NoError(err)
+	suite.Require().NotEmpty(beers)
+	suite.Equal("Precious Bet", beers[0].Beer.Name)
+	suite.Equal("Bellwoods Brewery", beers[0].Beer.Brewery.Name)
+}
+
+func (suite *SearchIntegrationTestSuite) TestSearchCatalog_MatchesDescription() {
+	beers, _, err := suite.repository.SearchCatalog(context.Background(), "brettanomyces", 10)
+
+	suite.Require().NoError(err)
+	suite.Require().Len(beers, 1)
+	suite.Equal("Precious Bet", beers[0].Beer.Name)
+}
+
+func (suite *SearchIntegrationTestSuite) TestSearchCatalog_MatchesBreweries() {
+	beers, breweries, err := suite.repository.SearchCatalog(context.Background(), "Belwoods", 10)
+
+	suite.Require().NoError(err)
+	suite.Require().NotEmpty(breweries)
+	suite.Equal("Bellwoods Brewery", breweries[0].Brewery.Name)
+	suite.Len(beers, 2)
+
+	for _, match := range beers {
+		suite.Equal("Bellwoods Brewery", match.Beer.Brewery.Name)
+		suite.Less(match.Score, breweries[0].Score)
+	}
+}
+
+func (suite *SearchIntegrationTestSuite) TestSearchCatalog_RanksOwnNameFirst() {
+	beers, _, err := suite.repository.SearchCatalog(context.Background(), "saison", 10)
+
+	suite.Require().NoError(err)
+	suite.Require().Len(beers, 3)
+	suite.Equal("Classic Saison", beers[0].Beer.Name)
+}
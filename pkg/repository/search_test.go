@@ -0,0 +1,51 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+type SearchTestSuite struct {
+	RepositorySuite
+}
+
+func TestSearchTestSuite(t *testing.T) {
+	suite.Run(t, new(SearchTestSuite))
+}
+
+func (suite *SearchTestSuite) TearDownTest() {
+	suite.Require().NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *SearchTestSuite) TestSearchCatalog_ReturnsMatchesInScoreOrder() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`SET LOCAL pg_trgm.word_similarity_threshold = 0.3`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectQuery(`^SELECT beers.id, GREATEST\(.+\) AS score FROM beers .+ ORDER BY score DESC, beers.id LIMIT \$\d+$`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "score"}).AddRow(7, 0.9).AddRow(3, 0.4))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "beers" WHERE "beers"."id" IN ($1,$2) AND "beers"."deleted_at" IS NULL`)).
+		WithArgs(7, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3, "Precious Gem").AddRow(7, "Precious Bet"))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "beer_tags" WHERE "beer_tags"."beer_id" IN ($1,$2)`)).
+		WithArgs(3, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"beer_id", "tag_id"}).AddRow(7, 5))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tags" WHERE "tags"."id" = $1 AND "tags"."deleted_at" IS NULL`)).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tag"}).AddRow(5, "brett"))
+	suite.mock.ExpectQuery(`^SELECT breweries.id, GREATEST\(.+\) AS score FROM breweries .+ ORDER BY score DESC, breweries.id LIMIT \$\d+$`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "score"}))
+	suite.mock.ExpectCommit()
+
+	beers, breweries, err := suite.repository.SearchCatalog(context.Background(), "Precius Bet", 10)
+
+	suite.Require().NoError(err)
+	suite.Require().Len(beers, 2)
+	suite.Equal("Precious Bet", beers[0].Beer.Name)
+	suite.Equal("brett", beers[0].Beer.Tags[0].Tag)
+	suite.InDelta(0.9, beers[0].Score, 0.001)
+	suite.Equal("Precious Gem", beers[1].Beer.Name)
+	suite.Empty(breweries)
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+)
+
+// Seed adds the reference data missing from the catalog in a single transaction, and returns what was missing. Rows
+// are matched on their natural keys, including soft deleted rows so that data deleted on purpose is not brought back.
+// In a dry run nothing is added.
+func (r *Repository) Seed(ctx context.Context, seed model.SeedData, dryRun bool) (*model.SeedData, error) {
+	var missing model.SeedData
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existingFormats []model.BeerFormat
+
+		var existingStyles, existingTags []string
+
+		for _, query := range []*gorm.DB{
+			tx.Unscoped().Select("package", "size_metric").Where("owner_id IS NULL").Find(&existingFormats),
+			tx.Unscoped().Model(&model.BeerStyle{}).Pluck("name", &existingStyles),
+			tx.Unscoped().Model(&model.Tag{}).Pluck("tag", &existingTags),
+		} {
+			if query.Error != nil {
+				return query.Error
+			}
+		}
+
+		formatKeys := make([]string, 0, len(existingFormats))
+		for _, format := range existingFormats {
+			formatKeys = append(formatKeys, formatKey(format))
+		}
+
+		missing.Formats = missingRows(seed.Formats, formatKeys, formatKey)
+		missing.Styles = missingRows(seed.Styles, existingStyles, func(style model.BeerStyle) string { return style.Name })
+		missing.Tags = missingRows(seed.Tags, existingTags, func(tag model.Tag) string { return tag.Tag })
+
+		if dryRun {
+			return nil
+		}
+
+		return createSeedRows(tx, &missing)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &missing, nil
+}
+
+func createSeedRows(tx *gorm.DB, seed *model.SeedData) error {
+	if len(seed.Formats) > 0 {
+		if result := tx.Create(&seed.Formats); result.Error != nil {
+			return result.Error
+		}
+	}
+
+	err := createSeedStyles(tx, seed.Styles)
+	if err != nil {
+		return err
+	}
+
+	if len(seed.Tags) > 0 {
+		if result := tx.Create(&seed.Tags); result.Error != nil {
+			return result.Error
+		}
+	}
+
+	return nil
+}
+
+// createSeedStyles adds the families before the styles in them, so that a style can point to its family whether the
+// family was just added or was already in the catalog.
+func createSeedStyles(tx *gorm.DB, styles []model.BeerStyle) error {
+	var families, members []*model.BeerStyle
+
+	for index := range styles {
+		if styles[index].Parent == nil {
+			families = append(families, &styles[index])
+		} else {
+			members = append(members, &styles[index])
+		}
+	}
+
+	if len(families) > 0 {
+		if result := tx.Create(&families); result.Error != nil {
+			return result.Error
+		}
+	}
+
+	if len(members) == 0 {
+		return nil
+	}
+
+	familyNames := make([]string, 0, len(members))
+	for _, member := range members {
+		familyNames = append(familyNames, member.Parent.Name)
+	}
+
+	var parents []model.BeerStyle
+	if result := tx.Select("id", "name").Where("name IN ?", familyNames).Find(&parents); result.Error != nil {
+		return result.Error
+	}
+
+	familyIDs := make(map[string]uint, len(parents))
+	for _, parent := range parents {
+		familyIDs[parent.Name] = parent.ID
+	}
+
+	for _, member := range members {
+		if familyID, found := familyIDs[member.Parent.Name]; found {
+			member.ParentID = &familyID
+		}
+	}
+
+	return tx.Omit(clause.Associations).Create(&members).Error
+}
+
+// formatKey identifies a global format by its package and metric size.
+func formatKey(format model.BeerFormat) string {
+	return fmt.Sprintf("%s/%g", strings.ToLower(format.Package), format.SizeMetric)
+}
+
+// missingRows returns the rows whose key is not among the existing keys, leaving out rows repeating a key.
+func missingRows[T any](rows []T, existing []string, key func(T) string) []T {
+	seen := make(map[string]struct{}, len(existing)+len(rows))
+	for _, value := range existing {
+		seen[value] = struct{}{}
+	}
+
+	missing := make([]T, 0, len(rows))
+
+	for _, row := range rows {
+		if _, found := seen[key(row)]; !found {
+			seen[key(row)] = struct{}{}
+			missing = append(missing, row)
+		}
+	}
+
+	return missing
+}
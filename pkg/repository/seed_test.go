@@ -0,0 +1,86 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+	"go.openly.dev/pointy"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+)
+
+type SeedTestSuite struct {
+	RepositorySuite
+}
+
+func TestSeedTestSuite(t *testing.T) {
+	suite.Run(t, new(SeedTestSuite))
+}
+
+func (suite *SeedTestSuite) TearDownTest() {
+	suite.Require().NoError(suite.mock.ExpectationsWereMet())
+}
+
+func seedData() model.SeedData {
+	return model.SeedData{
+		Formats: []model.BeerFormat{{Package: "Can", SizeMetric: 355, SizeImperial: 12}, {Package: "Can", SizeMetric: 473, SizeImperial: 16}},
+		Styles: []model.BeerStyle{
+			{Name: "European Sour Ale"},
+			{Name: "Gose", Parent: &model.BeerStyle{Name: "European Sour Ale"}},
+			{Name: "Saison", Parent: &model.BeerStyle{Name: "Belgian Ale"}},
+		},
+		Tags: []model.Tag{{Tag: "sour"}},
+	}
+}
+
+func (suite *SeedTestSuite) expectExisting() {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "package","size_metric" FROM "beer_formats" WHERE owner_id IS NULL`)).
+		WillReturnRows(sqlmock.NewRows([]string{"package", "size_metric"}).AddRow("can", 355.0))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "name" FROM "beer_styles"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Belgian Ale").AddRow("Saison"))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "tag" FROM "tags"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"tag"}).AddRow("sour"))
+}
+
+func (suite *SeedTestSuite) TestSeed_DryRunOnlyReports() {
+	suite.mock.ExpectBegin()
+	suite.expectExisting()
+	suite.mock.ExpectCommit()
+
+	missing, err := suite.repository.Seed(context.Background(), seedData(), true)
+
+	suite.Require().NoError(err)
+	suite.Equal([]model.BeerFormat{{Package: "Can", SizeMetric: 473, SizeImperial: 16}}, missing.Formats)
+	suite.Equal([]string{"European Sour Ale", "Gose"}, []string{missing.Styles[0].Name, missing.Styles[1].Name})
+	suite.Empty(missing.Tags)
+}
+
+func (suite *SeedTestSuite) TestSeed_AddsMissingRows() {
+	suite.mock.ExpectBegin()
+	suite.expectExisting()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "beer_formats" ("created_at","updated_at","deleted_at","package","size_metric","size_imperial","owner_id") VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING "id"`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Can", 473.0, 16.0, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(21))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "beer_styles" ("created_at","updated_at","deleted_at","name","parent_id","min_abv","max_abv","min_ibu","max_ibu","min_srm","max_srm","cellar_years") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12) RETURNING "id"`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "European Sour Ale", nil, nil, nil, nil, nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(39))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id","name" FROM "beer_styles" WHERE name IN ($1) AND "beer_styles"."deleted_at" IS NULL`)).
+		WithArgs("European Sour Ale").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(39, "European Sour Ale"))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "beer_styles" ("created_at","updated_at","deleted_at","name","parent_id","min_abv","max_abv","min_ibu","max_ibu","min_srm","max_srm","cellar_years") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12) RETURNING "id"`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Gose", 39, nil, nil, nil, nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(40))
+	suite.mock.ExpectCommit()
+
+	missing, err := suite.repository.Seed(context.Background(), seedData(), false)
+
+	suite.Require().NoError(err)
+	suite.Require().Len(missing.Formats, 1)
+	suite.Equal(uint(21), missing.Formats[0].ID)
+	suite.Equal(uint(39), missing.Styles[0].ID)
+	suite.Equal(uint(40), missing.Styles[1].ID)
+	suite.Equal(pointy.Uint(39), missing.Styles[1].ParentID)
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+)
+
+var (
+	ErrStyleNotFound      = newError(ErrNotFound, "beer style not found")
+	ErrInvalidStyleParent = newError(ErrInvalid, "invalid beer style parent")
+)
+
+// styleFamily selects the ID of a style along with the IDs of every style below it, at any depth. UNION rather than
+// UNION ALL stops the recursion should the parents ever form a cycle.
+const styleFamily = "WITH RECURSIVE family AS (" +
+	"SELECT id FROM beer_styles WHERE id = ?" +
+	" UNION SELECT beer_styles.id FROM beer_styles INNER JOIN family ON beer_styles.parent_id = family.id" +
+	" WHERE beer_styles.deleted_at IS NULL" +
+	") SELECT id FROM family"
+
+// styleFamilyName returns the family of a style named like the Untappd styles, such as "IPA" for "IPA - American".
+func styleFamilyName(style string) (string, bool) {
+	family, _, found := strings.Cut(style, " - ")
+
+	return family, found && len(family) > 0
+}
+
+func (r *Repository) GetBeerStyle(ctx context.Context, styleID uint) (*model.BeerStyle, error) {
+	var style model.BeerStyle
+
+	result := r.DB.WithContext(ctx).First(&style, styleID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, resourceError(ErrStyleNotFound, "beer_style", styleID)
+		}
+
+		return nil, result.Error
+	}
+
+	return &style, nil
+}
+
+// ListBeerStyles returns every style, families included, ordered by name.
+func (r *Repository) ListBeerStyles(ctx context.Context) ([]*model.BeerStyle, error) {
+	var styles []*model.BeerStyle
+
+	result := r.DB.WithContext(ctx).Order("name").Find(&styles)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return styles, nil
+}
+
+// UpdateBeerStyle changes the family and the guidelines of a style. The new family must exist and can't be the style
+// itself or one of the styles below it.
+func (r *Repository) UpdateBeerStyle(ctx context.Context, style *model.BeerStyle) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if style.ParentID != nil {
+			err := checkStyleParent(tx, style.ID, *style.ParentID)
+			if err != nil {
+				return err
+			}
+		}
+
+		result := tx.Model(style).
+			Select("parent_id", "min_abv", "max_abv", "min_ibu", "max_ibu", "min_srm", "max_srm", "cellar_years").
+			Updates(style)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return resourceError(ErrStyleNotFound, "beer_style", style.ID)
+		}
+
+		return nil
+	})
+}
+
+func checkStyleParent(tx *gorm.DB, styleID uint, parentID uint) error {
+	var inFamily int64
+
+	result := tx.Raw("SELECT COUNT(*) FROM ("+styleFamily+") family WHERE id = ?", styleID, parentID).Scan(&inFamily)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if inFamily > 0 {
+		return fmt.Errorf("%w: style %d is in the family of style %d", ErrInvalidStyleParent, parentID, styleID)
+	}
+
+	var count int64
+
+	result = tx.Model(&model.BeerStyle{}).Where("id = ?", parentID).Count(&count)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if count == 0 {
+		return resourceError(ErrStyleNotFound, "beer_style", parentID)
+	}
+
+	return nil
+}
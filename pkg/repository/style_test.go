@@ -0,0 +1,85 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+	"go.openly.dev/pointy"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+)
+
+type StyleTestSuite struct {
+	RepositorySuite
+}
+
+func TestStyleTestSuite(t *testing.T) {
+	suite.Run(t, new(StyleTestSuite))
+}
+
+func (suite *StyleTestSuite) TearDownTest() {
+	suite.Require().NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *StyleTestSuite) expectFamilyCheck(styleID uint, parentID uint, inFamily int) {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM (WITH RECURSIVE family AS (SELECT id FROM beer_styles WHERE id = $1 UNION SELECT beer_styles.id FROM beer_styles INNER JOIN family ON beer_styles.parent_id = family.id WHERE beer_styles.deleted_at IS NULL) SELECT id FROM family) family WHERE id = $2`)).
+		WithArgs(styleID, parentID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(inFamily))
+}
+
+func (suite *StyleTestSuite) TestUpdateBeerStyle_UpdatesFamilyAndGuidelines() {
+	style := model.BeerStyle{Model: gorm.Model{ID: 5}, Name: "IPA - New England", ParentID: pointy.Uint(4), MinABV: pointy.Float64(6), MaxABV: pointy.Float64(9), CellarYears: pointy.Uint(0)}
+
+	suite.mock.ExpectBegin()
+	suite.expectFamilyCheck(5, 4, 0)
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "beer_styles" WHERE id = $1 AND "beer_styles"."deleted_at" IS NULL`)).
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "beer_styles" SET "updated_at"=$1,"parent_id"=$2,"min_abv"=$3,"max_abv"=$4,"min_ibu"=$5,"max_ibu"=$6,"min_srm"=$7,"max_srm"=$8,"cellar_years"=$9 WHERE "beer_styles"."deleted_at" IS NULL AND "id" = $10`)).
+		WithArgs(sqlmock.AnyArg(), 4, 6.0, 9.0, nil, nil, nil, nil, 0, 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.UpdateBeerStyle(context.Background(), &style)
+
+	suite.Require().NoError(err)
+}
+
+func (suite *StyleTestSuite) TestUpdateBeerStyle_ParentInFamily() {
+	suite.mock.ExpectBegin()
+	suite.expectFamilyCheck(4, 5, 1)
+	suite.mock.ExpectRollback()
+
+	err := suite.repository.UpdateBeerStyle(context.Background(), &model.BeerStyle{Model: gorm.Model{ID: 4}, ParentID: pointy.Uint(5)})
+
+	suite.Require().ErrorIs(err, repository.ErrInvalidStyleParent)
+}
+
+func (suite *StyleTestSuite) TestUpdateBeerStyle_UnknownParent() {
+	suite.mock.ExpectBegin()
+	suite.expectFamilyCheck(5, 40, 0)
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "beer_styles" WHERE id = $1 AND "beer_styles"."deleted_at" IS NULL`)).
+		WithArgs(40).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	suite.mock.ExpectRollback()
+
+	err := suite.repository.UpdateBeerStyle(context.Background(), &model.BeerStyle{Model: gorm.Model{ID: 5}, ParentID: pointy.Uint(40)})
+
+	suite.Require().ErrorIs(err, repository.ErrStyleNotFound)
+}
+
+func (suite *StyleTestSuite) TestUpdateBeerStyle_UnknownStyle() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "beer_styles" SET`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectRollback()
+
+	err := suite.repository.UpdateBeerStyle(context.Background(), &model.BeerStyle{Model: gorm.Model{ID: 5}})
+
+	suite.Require().ErrorIs(err, repository.ErrStyleNotFound)
+}
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+)
+
+var (
+	ErrTagNotFound = newError(ErrNotFound, "tag not found")
+	ErrTagExists   = newError(ErrAlreadyExists, "tag already exists")
+)
+
+type TagRepository interface {
+	DeleteTag(ctx context.Context, tagID uint) error
+	GetTag(ctx context.Context, tagID uint) (*model.Tag, error)
+	ListTagUsage(ctx context.Context, userID uint) ([]*model.TagUsage, error)
+	MergeTags(ctx context.Context, keepID uint, mergeIDs []uint) (*model.Tag, error)
+	RenameTag(ctx context.Context, tagID uint, name string) (*model.Tag, error)
+}
+
+// tagJoinTable is a many2many table linking rows to tags.
+type tagJoinTable struct {
+	table  string
+	column string
+}
+
+// tagJoinTables lists every table referencing tags. Tags can only be merged or deleted safely if all of them are
+// updated.
+func tagJoinTables() []tagJoinTable {
+	return []tagJoinTable{
+		{"beer_tags", "beer_id"},
+		{"cellar_entry_tags", "cellar_entry_id"},
+		{"advent_calendar_filter_tags", "advent_calendar_filter_id"},
+		{"advent_calendar_filter_beer_tags", "advent_calendar_filter_id"},
+	}
+}
+
+func (r *Repository) GetTag(ctx context.Context, tagID uint) (*model.Tag, error) {
+	var tag model.Tag
+
+	result := r.DB.WithContext(ctx).First(&tag, tagID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, resourceError(ErrTagNotFound, "tag", tagID)
+		}
+
+		return nil, result.Error
+	}
+
+	return &tag, nil
+}
+
+// ListTagUsage returns all the tags sorted by name, with how often they are used in the cellars the user owns or is an
+// accepted member of.
+func (r *Repository) ListTagUsage(ctx context.Context, userID uint) ([]*model.TagUsage, error) {
+	db := r.DB.WithContext(ctx)
+
+	var tagCounts []struct {
+		ID        uint
+		Tag       string
+		BeerCount uint64
+	}
+
+	result := db.Table("tags").
+		Select("tags.id AS id, tags.tag AS tag, (SELECT COUNT(*) FROM beer_tags JOIN beers ON beers.id = beer_tags.beer_id AND beers.deleted_at IS NULL WHERE beer_tags.tag_id = tags.id) AS beer_count").
+		Where("tags.deleted_at IS NULL").
+		Order("tags.tag").Order("tags.id").
+		Scan(&tagCounts)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	var cellarUsages []model.CellarTagUsage
+
+	result = db.Raw("SELECT COALESCE(e.tag_id, b.tag_id) AS tag_id, COALESCE(e.cellar_id, b.cellar_id) AS cellar_id, cellars.name AS cellar_name, "+
+		"COALESCE(e.entry_count, 0) AS entry_count, COALESCE(b.beer_count, 0) AS beer_count FROM "+
+		"(SELECT cellar_entry_tags.tag_id, ce.cellar_id, COUNT(*) AS entry_count FROM cellar_entry_tags "+
+		"JOIN cellar_entries ce ON ce.id = cellar_entry_tags.cellar_entry_id AND ce.deleted_at IS NULL "+
+		"WHERE ce.cellar_id IN (@cellars) GROUP BY cellar_entry_tags.tag_id, ce.cellar_id) e "+
+		"FULL JOIN (SELECT beer_tags.tag_id, ce.cellar_id, COUNT(DISTINCT beer_tags.beer_id) AS beer_count FROM beer_tags "+
+		"JOIN cellar_entries ce ON ce.beer_id = beer_tags.beer_id AND ce.deleted_at IS NULL "+
+		"WHERE ce.cellar_id IN (@cellars) GROUP BY beer_tags.tag_id, ce.cellar_id) b "+
+		"ON b.tag_id = e.tag_id AND b.cellar_id = e.cellar_id "+
+		"JOIN cellars ON cellars.id = COALESCE(e.cellar_id, b.cellar_id) "+
+		"ORDER BY cellar_id",
+		map[string]any{"cellars": accessibleCellars(db, userID)}).
+		Scan(&cellarUsages)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	usages := make([]*model.TagUsage, 0, len(tagCounts))
+	usagesByTag := make(map[uint]*model.TagUsage, len(tagCounts))
+
+	for _, tagCount := range tagCounts {
+		usage := &model.TagUsage{Tag: model.Tag{Tag: tagCount.Tag}, BeerCount: tagCount.BeerCount}
+		usage.Tag.ID = tagCount.ID
+		usages = append(usages, usage)
+		usagesByTag[tagCount.ID] = usage
+	}
+
+	for _, cellarUsage := range cellarUsages {
+		if usage, found := usagesByTag[cellarUsage.TagID]; found {
+			usage.Cellars = append(usage.Cellars, cellarUsage)
+		}
+	}
+
+	return usages, nil
+}
+
+// accessibleCellars selects the IDs of the cellars the user owns or is an accepted member of.
+func accessibleCellars(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Model(&model.Cellar{}).Select("id").
+		Where("owner_id = ? OR id IN (SELECT cellar_id FROM cellar_members WHERE user_id = ? AND accepted_at IS NOT NULL AND deleted_at IS NULL)", userID, userID)
+}
+
+// RenameTag changes the name of the tag. Renaming a tag to the name of another tag fails with ErrTagExists, those tags
+// have to be merged instead.
+func (r *Repository) RenameTag(ctx context.Context, tagID uint, name string) (*model.Tag, error) {
+	var tag model.Tag
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.First(&tag, tagID)
+		if result.Error != nil {
+			if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+				return resourceError(ErrTagNotFound, "tag", tagID)
+			}
+
+			return result.Error
+		}
+
+		var count int64
+
+		result = tx.Model(&model.Tag{}).Where("tag = ? AND id <> ?", name, tagID).Count(&count)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if count > 0 {
+			return fmt.Errorf("%w: %q", ErrTagExists, name)
+		}
+
+		return tx.Model(&tag).Update("tag", name).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+// MergeTags replaces the merged tags with the kept tag wherever they are used, and deletes the merged tags.
+func (r *Repository) MergeTags(ctx context.Context, keepID uint, mergeIDs []uint) (*model.Tag, error) {
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(mergeIDs) == 0 || slices.Contains(mergeIDs, keepID) {
+			return fmt.Errorf("%w: tags must be merged into a different tag", ErrInvalidMerge)
+		}
+
+		var count int64
+
+		result := tx.Model(&model.Tag{}).Where("id IN ?", append([]uint{keepID}, mergeIDs...)).Count(&count)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if count != int64(len(mergeIDs)+1) {
+			return ErrTagNotFound
+		}
+
+		statements := make([]statement, 0, 2*len(tagJoinTables())+1)
+
+		for _, join := range tagJoinTables() {
+			statements = append(statements,
+				statement{fmt.Sprintf("INSERT INTO %[1]s (%[2]s, tag_id) SELECT %[2]s, ? FROM %[1]s WHERE tag_id IN ? ON CONFLICT DO NOTHING", join.table, join.column), []any{keepID, mergeIDs}},
+				statement{fmt.Sprintf("DELETE FROM %s WHERE tag_id IN ?", join.table), []any{mergeIDs}})
+		}
+
+		statements = append(statements, statement{"DELETE FROM tags WHERE id IN ?", []any{mergeIDs}})
+
+		return execStatements(tx, statements)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetTag(ctx, keepID)
+}
+
+// DeleteTag removes the tag from everything it is attached to and deletes it.
+func (r *Repository) DeleteTag(ctx context.Context, tagID uint) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var tag model.Tag
+
+		result := tx.Select("id").First(&tag, tagID)
+		if result.Error != nil {
+			if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+				return resourceError(ErrTagNotFound, "tag", tagID)
+			}
+
+			return result.Error
+		}
+
+		statements := make([]statement, 0, len(tagJoinTables())+1)
+
+		for _, join := range tagJoinTables() {
+			statements = append(statements, statement{fmt.Sprintf("DELETE FROM %s WHERE tag_id = ?", join.table), []any{tagID}})
+		}
+
+		statements = append(statements, statement{"DELETE FROM tags WHERE id = ?", []any{tagID}})
+
+		return execStatements(tx, statements)
+	})
+}
@@ -0,0 +1,181 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+)
+
+type TagTestSuite struct {
+	RepositorySuite
+}
+
+func TestTagTestSuite(t *testing.T) {
+	suite.Run(t, new(TagTestSuite))
+}
+
+func (suite *TagTestSuite) TearDownTest() {
+	suite.Require().NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *TagTestSuite) TestListTagUsage_CombinesCatalogAndCellarCounts() {
+	suite.mock.ExpectQuery(`^SELECT tags.id AS id, tags.tag AS tag, .+ AS beer_count FROM "tags" WHERE tags.deleted_at IS NULL ORDER BY tags.tag,tags.id$`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tag", "beer_count"}).
+			AddRow(2, "barrel-aged", 5).
+			AddRow(1, "sour", 0))
+	suite.mock.ExpectQuery(`^SELECT COALESCE\(e.tag_id, b.tag_id\) AS tag_id, .+ WHERE ce.cellar_id IN \(SELECT "id" FROM "cellars" WHERE \(owner_id = \$1 OR id IN \(SELECT cellar_id FROM cellar_members WHERE user_id = \$2 .+`).
+		WithArgs(7, 7, 7, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"tag_id", "cellar_id", "cellar_name", "entry_count", "beer_count"}).
+			AddRow(2, 3, "Basement", 1, 2).
+			AddRow(2, 4, "Garage", 0, 1))
+
+	usages, err := suite.repository.ListTagUsage(context.Background(), 7)
+
+	suite.Require().NoError(err)
+	suite.Require().Len(usages, 2)
+	suite.Equal(uint(2), usages[0].Tag.ID)
+	suite.Equal("barrel-aged", usages[0].Tag.Tag)
+	suite.Equal(uint64(5), usages[0].BeerCount)
+	suite.Equal([]model.CellarTagUsage{
+		{TagID: 2, CellarID: 3, CellarName: "Basement", EntryCount: 1, BeerCount: 2},
+		{TagID: 2, CellarID: 4, CellarName: "Garage", EntryCount: 0, BeerCount: 1},
+	}, usages[0].Cellars)
+	suite.Equal("sour", usages[1].Tag.Tag)
+	suite.Empty(usages[1].Cellars)
+}
+
+func (suite *TagTestSuite) TestRenameTag_Renames() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tags" WHERE "tags"."id" = $1 AND "tags"."deleted_at" IS NULL ORDER BY "tags"."id" LIMIT $2`)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tag"}).AddRow(1, "Sour"))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "tags" WHERE (tag = $1 AND id <> $2) AND "tags"."deleted_at" IS NULL`)).
+		WithArgs("sour", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tags" SET "tag"=$1,"updated_at"=$2 WHERE "tags"."deleted_at" IS NULL AND "id" = $3`)).
+		WithArgs("sour", sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	tag, err := suite.repository.RenameTag(context.Background(), 1, "sour")
+
+	suite.Require().NoError(err)
+	suite.Equal("sour", tag.Tag)
+}
+
+func (suite *TagTestSuite) TestRenameTag_ExistingName() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tags" WHERE "tags"."id" = $1`)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tag"}).AddRow(1, "Sour"))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "tags"`)).
+		WithArgs("sour", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	suite.mock.ExpectRollback()
+
+	_, err := suite.repository.RenameTag(context.Background(), 1, "sour")
+
+	suite.Require().ErrorIs(err, repository.ErrTagExists)
+}
+
+func (suite *TagTestSuite) TestRenameTag_NotFound() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tags" WHERE "tags"."id" = $1`)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tag"}))
+	suite.mock.ExpectRollback()
+
+	_, err := suite.repository.RenameTag(context.Background(), 1, "sour")
+
+	suite.Require().ErrorIs(err, repository.ErrTagNotFound)
+}
+
+func (suite *TagTestSuite) TestMergeTags_RepointsAndDeletes() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "tags" WHERE id IN ($1,$2,$3) AND "tags"."deleted_at" IS NULL`)).
+		WithArgs(1, 2, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	for _, join := range []struct{ table, column string }{
+		{"beer_tags", "beer_id"},
+		{"cellar_entry_tags", "cellar_entry_id"},
+		{"advent_calendar_filter_tags", "advent_calendar_filter_id"},
+		{"advent_calendar_filter_beer_tags", "advent_calendar_filter_id"},
+	} {
+		suite.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO `+join.table+` (`+join.column+`, tag_id) SELECT `+join.column+`, $1 FROM `+join.table+` WHERE tag_id IN ($2,$3) ON CONFLICT DO NOTHING`)).
+			WithArgs(1, 2, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+		suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM `+join.table+` WHERE tag_id IN ($1,$2)`)).
+			WithArgs(2, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM tags WHERE id IN ($1,$2)`)).
+		WithArgs(2, 3).WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.mock.ExpectCommit()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tags" WHERE "tags"."id" = $1`)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tag"}).AddRow(1, "sour"))
+
+	tag, err := suite.repository.MergeTags(context.Background(), 1, []uint{2, 3})
+
+	suite.Require().NoError(err)
+	suite.Equal("sour", tag.Tag)
+}
+
+func (suite *TagTestSuite) TestMergeTags_IntoItself() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectRollback()
+
+	_, err := suite.repository.MergeTags(context.Background(), 1, []uint{1, 2})
+
+	suite.Require().ErrorIs(err, repository.ErrInvalidMerge)
+}
+
+func (suite *TagTestSuite) TestMergeTags_MissingTag() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "tags"`)).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	suite.mock.ExpectRollback()
+
+	_, err := suite.repository.MergeTags(context.Background(), 1, []uint{2})
+
+	suite.Require().ErrorIs(err, repository.ErrTagNotFound)
+}
+
+func (suite *TagTestSuite) TestDeleteTag_DetachesAndDeletes() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "tags" WHERE "tags"."id" = $1 AND "tags"."deleted_at" IS NULL ORDER BY "tags"."id" LIMIT $2`)).
+		WithArgs(2, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+
+	for _, table := range []string{"beer_tags", "cellar_entry_tags", "advent_calendar_filter_tags", "advent_calendar_filter_beer_tags"} {
+		suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM ` + table + ` WHERE tag_id = $1`)).
+			WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	suite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM tags WHERE id = $1`)).
+		WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.DeleteTag(context.Background(), 2)
+
+	suite.Require().NoError(err)
+}
+
+func (suite *TagTestSuite) TestDeleteTag_NotFound() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "tags"`)).
+		WithArgs(2, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	suite.mock.ExpectRollback()
+
+	err := suite.repository.DeleteTag(context.Background(), 2)
+
+	suite.Require().ErrorIs(err, repository.ErrTagNotFound)
+}
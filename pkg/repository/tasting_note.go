@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+)
+
+var ErrTastingNoteNotFound = newError(ErrNotFound, "tasting note not found")
+
+// tastingNoteServed is when a tasting note was served, falling back to when it was written.
+const tastingNoteServed = "COALESCE(tasting_notes.served_at, tasting_notes.created_at)"
+
+// tastingNoteSort is the only sort tasting notes are listed by, recorded in page tokens.
+const tastingNoteSort = "served_at desc"
+
+// userRating builds the expression for the average rating the user, given as the single argument, gave the beer in
+// their tasting notes. It is NULL for beers the user has not rated.
+func userRating(beerID string) string {
+	return "(SELECT avg(tasting_notes.rating) FROM tasting_notes WHERE tasting_notes.beer_id = " + beerID +
+		" AND tasting_notes.user_id = ? AND tasting_notes.deleted_at IS NULL)"
+}
+
+func (r *Repository) AddTastingNote(ctx context.Context, note model.TastingNote) (*model.TastingNote, error) {
+	result := r.DB.WithContext(ctx).Omit("Beer").Create(&note)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return r.GetTastingNote(ctx, note.ID, note.UserID)
+}
+
+// GetTastingNote returns one of the user's tasting notes. The notes of other users are reported as not found.
+func (r *Repository) GetTastingNote(ctx context.Context, noteID uint, userID uint) (*model.TastingNote, error) {
+	var note model.TastingNote
+
+	result := r.DB.WithContext(ctx).
+		Preload("Beer.Brewery").
+		Where("user_id = ?", userID).
+		First(&note, noteID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, resourceError(ErrTastingNoteNotFound, "tasting note", noteID)
+		}
+
+		return nil, result.Error
+	}
+
+	return &note, nil
+}
+
+// UpdateTastingNote replaces the scores, notes and serving date of one of the user's tasting notes. The vintage is only
+// replaced when the note has one, otherwise the vintage it was written with is kept.
+func (r *Repository) UpdateTastingNote(ctx context.Context, note *model.TastingNote) (*model.TastingNote, error) {
+	columns := []string{"rating", "aroma", "appearance", "taste", "mouthfeel", "notes", "served_at"}
+	if note.Vintage != nil {
+		columns = append(columns, "vintage")
+	}
+
+	result := r.DB.WithContext(ctx).Model(&model.TastingNote{}).
+		Where("id = ? AND user_id = ?", note.ID, note.UserID).
+		Select(columns).
+		Updates(note)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, resourceError(ErrTastingNoteNotFound, "tasting note", note.ID)
+	}
+
+	return r.GetTastingNote(ctx, note.ID, note.UserID)
+}
+
+func (r *Repository) DeleteTastingNote(ctx context.Context, noteID uint, userID uint) error {
+	result := r.DB.WithContext(ctx).Where("user_id = ?", userID).Delete(&model.TastingNote{}, noteID)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return resourceError(ErrTastingNoteNotFound, "tasting note", noteID)
+	}
+
+	return nil
+}
+
+// ListTastingNotes returns a page of the user's tasting notes, of every beer or of one beer, most recently served
+// first, along with the token for the next page, which is empty on the last page.
+func (r *Repository) ListTastingNotes(ctx context.Context, userID uint, beerID uint, page Page) ([]*model.TastingNote, string, error) {
+	cursor, err := page.cursor()
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := r.DB.WithContext(ctx).Where("tasting_notes.user_id = ?", userID)
+
+	if beerID != 0 {
+		query = query.Where("tasting_notes.beer_id = ?", beerID)
+	}
+
+	if page.Token != "" {
+		err = cursor.check(tastingNoteSort, timeKey)
+		if err != nil {
+			return nil, "", err
+		}
+
+		query = query.Where("("+tastingNoteServed+", tasting_notes.id) < (?, ?)", cursor.Key, cursor.LastID)
+	}
+
+	var notes []*model.TastingNote
+
+	limit := page.Limit()
+
+	result := query.
+		Preload("Beer.Brewery").
+		Order(tastingNoteServed + " DESC").Order("tasting_notes.id DESC").
+		Limit(limit + 1).
+		Find(&notes)
+	if result.Error != nil {
+		return nil, "", result.Error
+	}
+
+	notes, nextToken := nextPageToken(notes, limit, func(note *model.TastingNote) pageCursor {
+		served := note.CreatedAt
+		if note.ServedAt != nil {
+			served = *note.ServedAt
+		}
+
+		return pageCursor{LastID: note.ID, Sort: tastingNoteSort, Key: served.Format(time.RFC3339Nano)}
+	})
+
+	return notes, nextToken, nil
+}
@@ -0,0 +1,184 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+	"go.openly.dev/pointy"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+)
+
+type TastingNoteTestSuite struct {
+	RepositorySuite
+}
+
+func TestTastingNoteTestSuite(t *testing.T) {
+	suite.Run(t, new(TastingNoteTestSuite))
+}
+
+func (suite *TastingNoteTestSuite) TearDownTest() {
+	suite.Require().NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *TastingNoteTestSuite) expectNoteLoaded(noteID int, userID int) {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tasting_notes" WHERE user_id = $1 AND "tasting_notes"."id" = $2 AND "tasting_notes"."deleted_at" IS NULL ORDER BY "tasting_notes"."id" LIMIT $3`)).
+		WithArgs(userID, noteID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "beer_id", "rating"}).AddRow(noteID, userID, 5, 4.5))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "beers" WHERE "beers"."id" = $1 AND "beers"."deleted_at" IS NULL`)).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "brewery_id"}).AddRow(5, "Abt 12", 2))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "breweries" WHERE "breweries"."id" = $1 AND "breweries"."deleted_at" IS NULL`)).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "St. Bernardus"))
+}
+
+func (suite *TastingNoteTestSuite) TestAddTastingNote_SavesAndLoads() {
+	servedAt := time.Date(2024, 12, 24, 20, 0, 0, 0, time.UTC)
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "tasting_notes" ("created_at","updated_at","deleted_at","user_id","beer_id","cellar_entry_id","vintage","rating","aroma","appearance","taste","mouthfeel","notes","served_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14) RETURNING "id"`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 3, 5, 10, 2022, 4.5, 4.0, nil, nil, nil, "Raisins and toffee", servedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	suite.mock.ExpectCommit()
+	suite.expectNoteLoaded(7, 3)
+
+	note, err := suite.repository.AddTastingNote(context.Background(), model.TastingNote{
+		UserID:        3,
+		BeerID:        5,
+		CellarEntryID: pointy.Uint(10),
+		Vintage:       pointy.Uint64(2022),
+		Rating:        pointy.Float64(4.5),
+		Aroma:         pointy.Float64(4),
+		Notes:         "Raisins and toffee",
+		ServedAt:      &servedAt,
+	})
+
+	suite.Require().NoError(err)
+	suite.Equal(uint(7), note.ID)
+	suite.Equal("St. Bernardus", note.Beer.Brewery.Name)
+}
+
+func (suite *TastingNoteTestSuite) TestUpdateTastingNote_ReplacesScores() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasting_notes" SET "updated_at"=$1,"vintage"=$2,"rating"=$3,"aroma"=$4,"appearance"=$5,"taste"=$6,"mouthfeel"=$7,"notes"=$8,"served_at"=$9 WHERE (id = $10 AND user_id = $11) AND "tasting_notes"."deleted_at" IS NULL`)).
+		WithArgs(sqlmock.AnyArg(), 2019, 4.5, nil, nil, nil, nil, "Better with age", nil, 7, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+	suite.expectNoteLoaded(7, 3)
+
+	note, err := suite.repository.UpdateTastingNote(context.Background(), &model.TastingNote{
+		Model:   gorm.Model{ID: 7},
+		UserID:  3,
+		Vintage: pointy.Uint64(2019),
+		Rating:  pointy.Float64(4.5),
+		Notes:   "Better with age",
+	})
+
+	suite.Require().NoError(err)
+	suite.Equal(uint(7), note.ID)
+}
+
+func (suite *TastingNoteTestSuite) TestUpdateTastingNote_KeepsVintage() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasting_notes" SET "updated_at"=$1,"rating"=$2,"aroma"=$3,"appearance"=$4,"taste"=$5,"mouthfeel"=$6,"notes"=$7,"served_at"=$8 WHERE (id = $9 AND user_id = $10) AND "tasting_notes"."deleted_at" IS NULL`)).
+		WithArgs(sqlmock.AnyArg(), 4.5, nil, nil, nil, nil, "Better with age", nil, 7, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+	suite.expectNoteLoaded(7, 3)
+
+	_, err := suite.repository.UpdateTastingNote(context.Background(), &model.TastingNote{
+		Model:  gorm.Model{ID: 7},
+		UserID: 3,
+		Rating: pointy.Float64(4.5),
+		Notes:  "Better with age",
+	})
+
+	suite.Require().NoError(err)
+}
+
+func (suite *TastingNoteTestSuite) TestUpdateTastingNote_OtherUsersNote() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(`^UPDATE "tasting_notes" SET .+ WHERE \(id = \$9 AND user_id = \$10\)`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectCommit()
+
+	_, err := suite.repository.UpdateTastingNote(context.Background(), &model.TastingNote{Model: gorm.Model{ID: 7}, UserID: 4})
+
+	suite.Require().ErrorIs(err, repository.ErrTastingNoteNotFound)
+	suite.Require().ErrorIs(err, repository.ErrNotFound)
+}
+
+func (suite *TastingNoteTestSuite) TestDeleteTastingNote_Deletes() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasting_notes" SET "deleted_at"=$1 WHERE user_id = $2 AND "tasting_notes"."id" = $3 AND "tasting_notes"."deleted_at" IS NULL`)).
+		WithArgs(sqlmock.AnyArg(), 3, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.DeleteTastingNote(context.Background(), 7, 3)
+
+	suite.Require().NoError(err)
+}
+
+func (suite *TastingNoteTestSuite) TestDeleteTastingNote_NotFound() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasting_notes" SET "deleted_at"=$1`)).
+		WithArgs(sqlmock.AnyArg(), 3, 7).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.DeleteTastingNote(context.Background(), 7, 3)
+
+	suite.Require().ErrorIs(err, repository.ErrTastingNoteNotFound)
+}
+
+func (suite *TastingNoteTestSuite) TestListTastingNotes_PagesByServedDate() {
+	servedAt := time.Date(2024, 12, 24, 20, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC)
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tasting_notes" WHERE tasting_notes.user_id = $1 AND tasting_notes.beer_id = $2 AND "tasting_notes"."deleted_at" IS NULL ORDER BY COALESCE(tasting_notes.served_at, tasting_notes.created_at) DESC,tasting_notes.id DESC LIMIT $3`)).
+		WithArgs(3, 5, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "user_id", "beer_id", "served_at"}).
+			AddRow(8, createdAt, 3, 5, nil).
+			AddRow(7, createdAt, 3, 5, servedAt))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "beers"`)).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(5, "Abt 12"))
+
+	notes, nextToken, err := suite.repository.ListTastingNotes(context.Background(), 3, 5, repository.Page{Size: 1})
+
+	suite.Require().NoError(err)
+	suite.Require().Len(notes, 1)
+	suite.Equal(uint(8), notes[0].ID)
+	suite.NotEmpty(nextToken)
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tasting_notes" WHERE tasting_notes.user_id = $1 AND tasting_notes.beer_id = $2 AND (COALESCE(tasting_notes.served_at, tasting_notes.created_at), tasting_notes.id) < ($3, $4) AND "tasting_notes"."deleted_at" IS NULL`)).
+		WithArgs(3, 5, createdAt.Format(time.RFC3339Nano), 8, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	notes, nextToken, err = suite.repository.ListTastingNotes(context.Background(), 3, 5, repository.Page{Size: 1, Token: nextToken})
+
+	suite.Require().NoError(err)
+	suite.Empty(notes)
+	suite.Empty(nextToken)
+}
+
+func (suite *TastingNoteTestSuite) TestListTastingNotes_TokenFromDifferentList() {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "breweries" WHERE "breweries"."deleted_at" IS NULL ORDER BY breweries.name,breweries.id LIMIT $1`)).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Achel").AddRow(2, "St. Bernardus"))
+
+	_, nextPageToken, err := suite.repository.ListBreweries(context.Background(), "", repository.Page{Size: 1})
+	suite.Require().NoError(err)
+
+	_, _, err = suite.repository.ListTastingNotes(context.Background(), 3, 0, repository.Page{Token: nextPageToken})
+
+	suite.Require().ErrorIs(err, repository.ErrInvalidPageToken)
+}
@@ -2,17 +2,44 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 
 	"droscher.com/BeerGargoyle/pkg/model"
 )
 
+var (
+	ErrUserNotFound = newError(ErrNotFound, "user not found")
+	ErrUserExists   = newError(ErrAlreadyExists, "user already exists")
+)
+
+type UserRepository interface { //nolint:interfacebloat // this is an acceptable interface
+	AddUser(ctx context.Context, name string, email string, untappdUserName *string) (*model.User, error)
+	CreateAccessToken(ctx context.Context, token model.AccessToken) (*model.AccessToken, error)
+	DeleteAccount(ctx context.Context, userID uint) error
+	DeleteUser(ctx context.Context, userID uint) error
+	ExportAccount(ctx context.Context, userID uint) (*model.AccountExport, error)
+	GetUserByUUID(ctx context.Context, uuid uuid.UUID) (*model.User, error)
+	GetUserFromEmail(ctx context.Context, email string) (*model.User, error)
+	ListAccessTokens(ctx context.Context, userID uint) ([]*model.AccessToken, error)
+	ListUsers(ctx context.Context, page Page) ([]*model.User, string, error)
+	RevokeAccessToken(ctx context.Context, userID uint, tokenUUID uuid.UUID) error
+	SetUserDisabled(ctx context.Context, userID uint, disabled bool) error
+	UpdateUser(ctx context.Context, user *model.User) error
+}
+
 func (r *Repository) GetUserByUUID(ctx context.Context, uuid uuid.UUID) (*model.User, error) {
 	var user model.User
 
 	result := r.DB.WithContext(ctx).Where("uuid = ?", uuid).First(&user)
 	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, resourceError(ErrUserNotFound, "user", uuid)
+		}
+
 		return nil, result.Error
 	}
 
@@ -24,6 +51,10 @@ func (r *Repository) GetUserByName(ctx context.Context, username string) (*model
 
 	result := r.DB.WithContext(ctx).Where("username = ?", username).First(&user)
 	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+
 		return nil, result.Error
 	}
 
@@ -35,12 +66,18 @@ func (r *Repository) GetUserFromEmail(ctx context.Context, email string) (*model
 
 	result := r.DB.WithContext(ctx).Where("email = ?", email).First(&user)
 	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+
 		return nil, result.Error
 	}
 
 	return user, nil
 }
 
+// AddUser creates a user with the email. Signing up an email that already has an account, including one provisioned
+// on sign in, fails with ErrUserExists.
 func (r *Repository) AddUser(ctx context.Context, name string, email string, untappdUserName *string) (*model.User, error) {
 	user := model.User{
 		UUID:            uuid.New(),
@@ -49,9 +86,108 @@ func (r *Repository) AddUser(ctx context.Context, name string, email string, unt
 		UntappdUserName: untappdUserName,
 	}
 
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+
+		result := tx.Model(&model.User{}).Where("email = ?", email).Count(&count)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if count > 0 {
+			return fmt.Errorf("%w: %q", ErrUserExists, email)
+		}
+
+		return tx.Create(&user).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// ProvisionUser creates a user from an identity provider profile the first time they sign in.
+func (r *Repository) ProvisionUser(ctx context.Context, user model.User) (*model.User, error) {
+	user.UUID = uuid.New()
+
 	if result := r.DB.WithContext(ctx).Create(&user); result.Error != nil {
 		return nil, result.Error
 	}
 
 	return &user, nil
 }
+
+// UpdateUserProfile saves the name fields of the user, which are kept in sync with the identity provider.
+func (r *Repository) UpdateUserProfile(ctx context.Context, user *model.User) error {
+	result := r.DB.WithContext(ctx).Model(user).Select("username", "first_name", "last_name").Updates(user)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return nil
+}
+
+// ListUsers returns a page of users ordered by ID, along with the token for the next page, which is empty on the last
+// page.
+func (r *Repository) ListUsers(ctx context.Context, page Page) ([]*model.User, string, error) {
+	cursor, err := page.cursor()
+	if err != nil {
+		return nil, "", err
+	}
+
+	var users []*model.User
+
+	limit := page.Limit()
+
+	result := r.DB.WithContext(ctx).Where("id > ?", cursor.LastID).Order("id").Limit(limit + 1).Find(&users)
+	if result.Error != nil {
+		return nil, "", result.Error
+	}
+
+	users, nextToken := nextPageToken(users, limit, func(user *model.User) pageCursor { return pageCursor{LastID: user.ID} })
+
+	return users, nextToken, nil
+}
+
+// UpdateUser saves the fields of the user that can be edited by an administrator.
+func (r *Repository) UpdateUser(ctx context.Context, user *model.User) error {
+	result := r.DB.WithContext(ctx).Model(user).
+		Select("username", "first_name", "last_name", "untappd_user_name", "role").
+		Updates(user)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return resourceError(ErrUserNotFound, "user", user.ID)
+	}
+
+	return nil
+}
+
+func (r *Repository) SetUserDisabled(ctx context.Context, userID uint, disabled bool) error {
+	result := r.DB.WithContext(ctx).Model(&model.User{}).Where("id = ?", userID).Update("disabled", disabled)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return resourceError(ErrUserNotFound, "user", userID)
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteUser(ctx context.Context, userID uint) error {
+	result := r.DB.WithContext(ctx).Delete(&model.User{}, userID)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return resourceError(ErrUserNotFound, "user", userID)
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+)
+
+type UserTestSuite struct {
+	RepositorySuite
+}
+
+func TestUserTestSuite(t *testing.T) {
+	suite.Run(t, new(UserTestSuite))
+}
+
+func (suite *UserTestSuite) TearDownTest() {
+	suite.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *UserTestSuite) TestGetUserFromEmail_NotFound() {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE email = $1 AND "users"."deleted_at" IS NULL ORDER BY "users"."id" LIMIT $2`)).
+		WithArgs("nobody@example.com", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	user, err := suite.repository.GetUserFromEmail(context.Background(), "nobody@example.com")
+
+	suite.Require().ErrorIs(err, repository.ErrUserNotFound)
+	suite.Nil(user)
+}
+
+func (suite *UserTestSuite) TestAddUser_CreatesUser() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users" WHERE email = $1 AND "users"."deleted_at" IS NULL`)).
+		WithArgs("new@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "users"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "uuid"}).AddRow(7, "8f0e2d5c-7d0b-4c3e-9a51-3c2f1b6d9e47"))
+	suite.mock.ExpectCommit()
+
+	user, err := suite.repository.AddUser(context.Background(), "newbie", "new@example.com", nil)
+
+	suite.Require().NoError(err)
+	suite.Equal(uint(7), user.ID)
+}
+
+func (suite *UserTestSuite) TestAddUser_EmailTaken() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users" WHERE email = $1 AND "users"."deleted_at" IS NULL`)).
+		WithArgs("new@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	suite.mock.ExpectRollback()
+
+	user, err := suite.repository.AddUser(context.Background(), "newbie", "new@example.com", nil)
+
+	suite.Require().ErrorIs(err, repository.ErrAlreadyExists)
+	suite.Nil(user)
+}
+
+func (suite *UserTestSuite) TestProvisionUser_CreatesUser() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "users" ("created_at","updated_at","deleted_at","username","first_name","last_name","email","untappd_user_name","role","disabled","uuid") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11) RETURNING "id","uuid"`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "newbie", "New", "User", "new@example.com", nil, "user", false, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "uuid"}).AddRow(7, "8f0e2d5c-7d0b-4c3e-9a51-3c2f1b6d9e47"))
+	suite.mock.ExpectCommit()
+
+	user, err := suite.repository.ProvisionUser(context.Background(), model.User{
+		Username:  "newbie",
+		FirstName: "New",
+		LastName:  "User",
+		Email:     "new@example.com",
+	})
+
+	suite.Require().NoError(err)
+	suite.Equal(uint(7), user.ID)
+	suite.Equal("8f0e2d5c-7d0b-4c3e-9a51-3c2f1b6d9e47", user.UUID.String())
+}
+
+func (suite *UserTestSuite) TestUpdateUserProfile_UpdatesNames() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "updated_at"=$1,"username"=$2,"first_name"=$3,"last_name"=$4 WHERE "users"."deleted_at" IS NULL AND "id" = $5`)).
+		WithArgs(sqlmock.AnyArg(), "new", "Test", "", 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.UpdateUserProfile(context.Background(), &model.User{
+		Model:     gorm.Model{ID: 3},
+		Username:  "new",
+		FirstName: "Test",
+	})
+
+	suite.Require().NoError(err)
+}
+
+func (suite *UserTestSuite) TestListUsers_ReturnsNextPageToken() {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE id > $1 AND "users"."deleted_at" IS NULL ORDER BY id LIMIT $2`)).
+		WithArgs(0, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(1, "one").AddRow(2, "two").AddRow(3, "three"))
+
+	users, nextPageToken, err := suite.repository.ListUsers(context.Background(), repository.Page{Size: 2})
+
+	suite.Require().NoError(err)
+	suite.Len(users, 2)
+	suite.Equal("two", users[1].Username)
+	suite.NotEmpty(nextPageToken)
+
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE id > $1 AND "users"."deleted_at" IS NULL ORDER BY id LIMIT $2`)).
+		WithArgs(2, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(3, "three"))
+
+	users, nextPageToken, err = suite.repository.ListUsers(context.Background(), repository.Page{Size: 2, Token: nextPageToken})
+
+	suite.Require().NoError(err)
+	suite.Len(users, 1)
+	suite.Empty(nextPageToken)
+}
+
+func (suite *UserTestSuite) TestListUsers_InvalidPageToken() {
+	users, _, err := suite.repository.ListUsers(context.Background(), repository.Page{Token: "not a token"})
+
+	suite.Require().ErrorIs(err, repository.ErrInvalidPageToken)
+	suite.Nil(users)
+}
+
+func (suite *UserTestSuite) TestSetUserDisabled_NotFound() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "disabled"=$1,"updated_at"=$2 WHERE id = $3 AND "users"."deleted_at" IS NULL`)).
+		WithArgs(true, sqlmock.AnyArg(), 9).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectCommit()
+
+	err := suite.repository.SetUserDisabled(context.Background(), 9, true)
+
+	suite.Require().ErrorIs(err, repository.ErrUserNotFound)
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+)
+
+// GetBeerVertical groups the entries of a beer in a cellar, and the user's tasting notes of the beer, by vintage. The
+// vintages are oldest first, followed by the entries and notes without a vintage. Vintages the cellar no longer holds
+// are included when the user has tasting notes of them.
+func (r *Repository) GetBeerVertical(ctx context.Context, cellarID uint, beerID uint, userID uint) (*model.BeerVertical, error) {
+	beer, err := r.GetBeer(ctx, beerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*model.CellarEntry
+
+	result := r.DB.WithContext(ctx).
+		Joins("Location").
+		Joins("Format").
+		Joins("Cellar").
+		Preload("Tags").
+		Where("cellar_entries.cellar_id = ? AND cellar_entries.beer_id = ?", cellarID, beerID).
+		Order("cellar_entries.id").
+		Find(&entries)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	var notes []*model.TastingNote
+
+	result = r.DB.WithContext(ctx).
+		Where("tasting_notes.user_id = ? AND tasting_notes.beer_id = ?", userID, beerID).
+		Order(tastingNoteServed + " DESC").Order("tasting_notes.id DESC").
+		Find(&notes)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	for _, entry := range entries {
+		entry.Beer = *beer
+	}
+
+	for _, note := range notes {
+		note.Beer = *beer
+	}
+
+	return &model.BeerVertical{Beer: *beer, Vintages: groupByVintage(entries, notes)}, nil
+}
+
+func groupByVintage(entries []*model.CellarEntry, notes []*model.TastingNote) []model.VerticalVintage {
+	vintages := map[uint64]*model.VerticalVintage{}
+	unknown := model.VerticalVintage{}
+
+	vintageOf := func(vintage *uint64) *model.VerticalVintage {
+		if vintage == nil {
+			return &unknown
+		}
+
+		group, ok := vintages[*vintage]
+		if !ok {
+			year := *vintage
+			group = &model.VerticalVintage{Vintage: &year}
+			vintages[year] = group
+		}
+
+		return group
+	}
+
+	for _, entry := range entries {
+		group := vintageOf(entry.Vintage)
+		group.Entries = append(group.Entries, entry)
+	}
+
+	for _, note := range notes {
+		group := vintageOf(note.Vintage)
+		group.TastingNotes = append(group.TastingNotes, note)
+	}
+
+	grouped := make([]model.VerticalVintage, 0, len(vintages)+1)
+
+	for _, year := range slices.Sorted(maps.Keys(vintages)) {
+		grouped = append(grouped, *vintages[year])
+	}
+
+	if len(unknown.Entries) > 0 || len(unknown.TastingNotes) > 0 {
+		grouped = append(grouped, unknown)
+	}
+
+	return grouped
+}
+
+// getCellarVerticals summarises the beers a cellar holds more than one vintage of, ordered by beer name. Vintages
+// outside the range entries are checked against are left out, so that the gaps listed are at most that range.
+func (r *Repository) getCellarVerticals(ctx context.Context, cellarID uint) ([]model.VerticalSummary, error) {
+	var rows []struct {
+		BeerID   uint
+		BeerName string
+		Vintage  uint64
+	}
+
+	result := r.DB.WithContext(ctx).Table("cellar_entries as ce").
+		Select("DISTINCT ce.beer_id, b.name as beer_name, ce.vintage").
+		Joins("INNER JOIN beers b on b.id = ce.beer_id").
+		Where("ce.cellar_id = ?", cellarID).
+		Where("ce.vintage BETWEEN ? AND ?", model.MinVintage, model.MaxVintage()).
+		Where("ce.deleted_at is null").
+		Order("b.name, ce.beer_id, ce.vintage").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	verticals := []model.VerticalSummary{}
+
+	for start := 0; start < len(rows); {
+		end := start
+		for end < len(rows) && rows[end].BeerID == rows[start].BeerID {
+			end++
+		}
+
+		if end-start > 1 {
+			vertical := model.VerticalSummary{BeerID: rows[start].BeerID, BeerName: rows[start].BeerName}
+
+			for _, row := range rows[start:end] {
+				if len(vertical.Vintages) > 0 {
+					for missing := vertical.Vintages[len(vertical.Vintages)-1] + 1; missing < row.Vintage; missing++ {
+						vertical.MissingVintages = append(vertical.MissingVintages, missing)
+					}
+				}
+
+				vertical.Vintages = append(vertical.Vintages, row.Vintage)
+			}
+
+			verticals = append(verticals, vertical)
+		}
+
+		start = end
+	}
+
+	return verticals, nil
+}
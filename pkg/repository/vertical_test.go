@@ -0,0 +1,85 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+
+	"droscher.com/BeerGargoyle/pkg/repository"
+)
+
+type VerticalTestSuite struct {
+	RepositorySuite
+}
+
+func TestVerticalTestSuite(t *testing.T) {
+	suite.Run(t, new(VerticalTestSuite))
+}
+
+func (suite *VerticalTestSuite) TearDownTest() {
+	suite.Require().NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *VerticalTestSuite) expectBeerLoaded(beerID int) {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "beers" WHERE "beers"."id" = $1 AND "beers"."deleted_at" IS NULL ORDER BY "beers"."id" LIMIT $2`)).
+		WithArgs(beerID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(beerID, "Bourbon County"))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "beer_tags" WHERE "beer_tags"."beer_id" = $1`)).
+		WithArgs(beerID).
+		WillReturnRows(sqlmock.NewRows([]string{"beer_id", "tag_id"}))
+}
+
+func (suite *VerticalTestSuite) TestGetBeerVertical_GroupsByVintage() {
+	suite.expectBeerLoaded(5)
+	suite.mock.ExpectQuery(`^SELECT .+ FROM "cellar_entries" LEFT JOIN .+ WHERE \(cellar_entries.cellar_id = \$1 AND cellar_entries.beer_id = \$2\) AND "cellar_entries"."deleted_at" IS NULL ORDER BY cellar_entries.id`).
+		WithArgs(1, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "cellar_id", "beer_id", "vintage", "quantity"}).
+			AddRow(10, 1, 5, 2021, 2).
+			AddRow(11, 1, 5, 2019, 1).
+			AddRow(12, 1, 5, nil, 1).
+			AddRow(13, 1, 5, 2021, 3))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "cellar_entry_tags" WHERE "cellar_entry_tags"."cellar_entry_id" IN ($1,$2,$3,$4)`)).
+		WithArgs(10, 11, 12, 13).
+		WillReturnRows(sqlmock.NewRows([]string{"cellar_entry_id", "tag_id"}))
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tasting_notes" WHERE (tasting_notes.user_id = $1 AND tasting_notes.beer_id = $2) AND "tasting_notes"."deleted_at" IS NULL ORDER BY COALESCE(tasting_notes.served_at, tasting_notes.created_at) DESC,tasting_notes.id DESC`)).
+		WithArgs(3, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "user_id", "beer_id", "vintage", "rating"}).
+			AddRow(21, time.Now(), 3, 5, 2020, 4.5).
+			AddRow(22, time.Now(), 3, 5, 2019, 4.0))
+
+	vertical, err := suite.repository.GetBeerVertical(context.Background(), 1, 5, 3)
+
+	suite.Require().NoError(err)
+	suite.Equal("Bourbon County", vertical.Beer.Name)
+	suite.Require().Len(vertical.Vintages, 4)
+
+	suite.Equal(uint64(2019), *vertical.Vintages[0].Vintage)
+	suite.Len(vertical.Vintages[0].Entries, 1)
+	suite.Len(vertical.Vintages[0].TastingNotes, 1)
+
+	suite.Equal(uint64(2020), *vertical.Vintages[1].Vintage)
+	suite.Empty(vertical.Vintages[1].Entries)
+	suite.Len(vertical.Vintages[1].TastingNotes, 1)
+
+	suite.Equal(uint64(2021), *vertical.Vintages[2].Vintage)
+	suite.Equal(int64(5), vertical.Vintages[2].Quantity())
+	suite.Equal("Bourbon County", vertical.Vintages[2].Entries[0].Beer.Name)
+
+	suite.Nil(vertical.Vintages[3].Vintage)
+	suite.Equal(int64(1), vertical.Vintages[3].Quantity())
+}
+
+func (suite *VerticalTestSuite) TestGetBeerVertical_UnknownBeer() {
+	suite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "beers"`)).
+		WithArgs(5, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	vertical, err := suite.repository.GetBeerVertical(context.Background(), 1, 5, 3)
+
+	suite.Require().ErrorIs(err, repository.ErrBeerNotFound)
+	suite.Nil(vertical)
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"droscher.com/BeerGargoyle/pkg/auth"
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/server/grpc"
+	api "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+)
+
+// CreateAccessToken creates a personal access token for the current user. The secret is returned only in this
+// response, the server keeps just its hash.
+func (u *UserServer) CreateAccessToken(ctx context.Context, request *connect.Request[api.CreateAccessTokenRequest]) (*connect.Response[api.CreateAccessTokenResponse], error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(request.Msg.GetName())
+	if name == "" {
+		return nil, invalidField("name", "access token name is required")
+	}
+
+	token := model.AccessToken{
+		UserID: user.ID,
+		Name:   name,
+		Scope:  grpc.AccessTokenScopeToModel(request.Msg.GetScope()),
+	}
+
+	if request.Msg.GetExpiresAt() != nil {
+		expiresAt := request.Msg.GetExpiresAt().AsTime()
+		if !expiresAt.After(time.Now()) {
+			return nil, invalidField("expires_at", "access token expiry must be in the future")
+		}
+
+		token.ExpiresAt = &expiresAt
+	}
+
+	secret, prefix, hash, err := auth.NewAccessToken()
+	if err != nil {
+		return nil, err
+	}
+
+	token.Prefix = prefix
+	token.Hash = hash
+
+	created, err := u.repository.CreateAccessToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	u.logger.Info("access token created", zap.Uint("user_id", user.ID), zap.String("token", created.UUID.String()),
+		zap.String("scope", string(created.Scope)))
+
+	response := api.CreateAccessTokenResponse{Token: grpc.AccessTokenFromModel(created), Secret: secret}
+
+	return connect.NewResponse(&response), nil
+}
+
+func (u *UserServer) ListAccessTokens(ctx context.Context, _ *connect.Request[api.ListAccessTokensRequest]) (*connect.Response[api.ListAccessTokensResponse], error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := u.repository.ListAccessTokens(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&api.ListAccessTokensResponse{Tokens: grpc.AccessTokensFromModel(tokens)}), nil
+}
+
+func (u *UserServer) RevokeAccessToken(ctx context.Context, request *connect.Request[api.RevokeAccessTokenRequest]) (*connect.Response[api.RevokeAccessTokenResponse], error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenUUID, err := uuid.Parse(request.Msg.GetId())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", invalidField("id", "invalid access token id"), err)
+	}
+
+	err = u.repository.RevokeAccessToken(ctx, user.ID, tokenUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	u.logger.Info("access token revoked", zap.Uint("user_id", user.ID), zap.String("token", tokenUUID.String()))
+
+	return connect.NewResponse(&api.RevokeAccessTokenResponse{}), nil
+}
@@ -0,0 +1,97 @@
+package server_test
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/auth"
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+	"droscher.com/BeerGargoyle/pkg/server"
+	apiv1 "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+)
+
+func (suite *UserTestSuite) TestCreateAccessToken_ReturnsSecretOnce() {
+	var stored model.AccessToken
+
+	suite.userRepo.EXPECT().CreateAccessToken(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, token model.AccessToken) (*model.AccessToken, error) {
+			stored = token
+			token.UUID = uuid.New()
+
+			return &token, nil
+		})
+
+	response, err := suite.service.CreateAccessToken(suite.adminContext(), connect.NewRequest(&apiv1.CreateAccessTokenRequest{
+		Name:  " nightly import ",
+		Scope: apiv1.AccessTokenScope_ACCESS_TOKEN_SCOPE_READ_WRITE,
+	}))
+
+	suite.Require().NoError(err)
+	suite.Equal(uint(1), stored.UserID)
+	suite.Equal("nightly import", stored.Name)
+	suite.Equal(model.AccessTokenScopeReadWrite, stored.Scope)
+	suite.Equal(auth.HashAccessToken(response.Msg.GetSecret()), stored.Hash)
+	suite.True(strings.HasPrefix(response.Msg.GetSecret(), response.Msg.GetToken().GetPrefix()))
+	suite.Equal(apiv1.AccessTokenScope_ACCESS_TOKEN_SCOPE_READ_WRITE, response.Msg.GetToken().GetScope())
+}
+
+func (suite *UserTestSuite) TestCreateAccessToken_DefaultsToReadOnly() {
+	suite.userRepo.EXPECT().CreateAccessToken(mock.Anything, mock.MatchedBy(func(token model.AccessToken) bool {
+		return token.Scope == model.AccessTokenScopeReadOnly
+	})).RunAndReturn(func(_ context.Context, token model.AccessToken) (*model.AccessToken, error) {
+		return &token, nil
+	})
+
+	response, err := suite.service.CreateAccessToken(suite.adminContext(), connect.NewRequest(&apiv1.CreateAccessTokenRequest{Name: "dashboard"}))
+
+	suite.Require().NoError(err)
+	suite.Equal(apiv1.AccessTokenScope_ACCESS_TOKEN_SCOPE_READ_ONLY, response.Msg.GetToken().GetScope())
+}
+
+func (suite *UserTestSuite) TestCreateAccessToken_RequiresName() {
+	_, err := suite.service.CreateAccessToken(suite.adminContext(), connect.NewRequest(&apiv1.CreateAccessTokenRequest{Name: "  "}))
+
+	suite.Require().ErrorIs(err, server.ErrInvalidInput)
+}
+
+func (suite *UserTestSuite) TestCreateAccessToken_RejectsPastExpiry() {
+	_, err := suite.service.CreateAccessToken(suite.adminContext(), connect.NewRequest(&apiv1.CreateAccessTokenRequest{
+		Name:      "dashboard",
+		ExpiresAt: timestamppb.New(time.Now().Add(-time.Hour)),
+	}))
+
+	suite.Require().ErrorIs(err, server.ErrInvalidInput)
+}
+
+func (suite *UserTestSuite) TestListAccessTokens_ListsOwnTokens() {
+	lastUsed := time.Now()
+	suite.userRepo.EXPECT().ListAccessTokens(mock.Anything, uint(1)).Return([]*model.AccessToken{
+		{Model: gorm.Model{ID: 1}, Name: "nightly import", Prefix: "bgpat_abcdef", Scope: model.AccessTokenScopeReadWrite, LastUsedAt: &lastUsed},
+		{Model: gorm.Model{ID: 2}, Name: "dashboard", Scope: model.AccessTokenScopeReadOnly},
+	}, nil)
+
+	response, err := suite.service.ListAccessTokens(suite.adminContext(), connect.NewRequest(&apiv1.ListAccessTokensRequest{}))
+
+	suite.Require().NoError(err)
+	suite.Require().Len(response.Msg.GetTokens(), 2)
+	suite.Equal("bgpat_abcdef", response.Msg.GetTokens()[0].GetPrefix())
+	suite.NotNil(response.Msg.GetTokens()[0].GetLastUsedAt())
+	suite.Nil(response.Msg.GetTokens()[1].GetLastUsedAt())
+}
+
+func (suite *UserTestSuite) TestRevokeAccessToken_NotFound() {
+	tokenUUID := uuid.New()
+	suite.userRepo.EXPECT().RevokeAccessToken(mock.Anything, uint(1), tokenUUID).Return(repository.ErrAccessTokenNotFound)
+
+	_, err := suite.service.RevokeAccessToken(suite.adminContext(), connect.NewRequest(&apiv1.RevokeAccessTokenRequest{Id: tokenUUID.String()}))
+
+	suite.Equal(connect.CodeNotFound, server.ErrorCode(err))
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/bufbuild/connect-go"
+	"go.uber.org/zap"
+
+	api "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+)
+
+// DeleteAccount deletes the current user and all their data. The data is exported first and returned in the response,
+// and nothing is deleted if the export fails.
+func (u *UserServer) DeleteAccount(ctx context.Context, request *connect.Request[api.DeleteAccountRequest]) (*connect.Response[api.DeleteAccountResponse], error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(strings.TrimSpace(request.Msg.GetConfirmEmail()), user.Email) {
+		return nil, invalidField("confirm_email", "confirmation email does not match the account")
+	}
+
+	export, err := u.repository.ExportAccount(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(export)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.repository.DeleteAccount(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	u.logger.Info("account deleted", zap.Uint("user_id", user.ID), zap.Int("export_bytes", len(data)))
+
+	return connect.NewResponse(&api.DeleteAccountResponse{Export: data}), nil
+}
@@ -0,0 +1,47 @@
+package server_test
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/stretchr/testify/mock"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/server"
+	apiv1 "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+)
+
+func (suite *UserTestSuite) TestDeleteAccount_ReturnsExport() {
+	suite.userRepo.EXPECT().ExportAccount(mock.Anything, uint(1)).Return(&model.AccountExport{
+		User:    *suite.admin,
+		Cellars: []model.Cellar{{Name: "my cellar"}},
+	}, nil)
+	suite.userRepo.EXPECT().DeleteAccount(mock.Anything, uint(1)).Return(nil)
+
+	response, err := suite.service.DeleteAccount(suite.adminContext(), connect.NewRequest(&apiv1.DeleteAccountRequest{ConfirmEmail: "Admin@example.com"}))
+
+	suite.Require().NoError(err)
+
+	var export model.AccountExport
+
+	suite.Require().NoError(json.Unmarshal(response.Msg.GetExport(), &export))
+	suite.Equal("admin@example.com", export.User.Email)
+	suite.Equal("my cellar", export.Cellars[0].Name)
+}
+
+func (suite *UserTestSuite) TestDeleteAccount_RequiresConfirmation() {
+	_, err := suite.service.DeleteAccount(suite.adminContext(), connect.NewRequest(&apiv1.DeleteAccountRequest{ConfirmEmail: "other@example.com"}))
+
+	suite.Require().ErrorIs(err, server.ErrInvalidInput)
+}
+
+func (suite *UserTestSuite) TestDeleteAccount_KeepsDataWhenExportFails() {
+	exportErr := errors.New("export failed")
+	suite.userRepo.EXPECT().ExportAccount(mock.Anything, uint(1)).Return(nil, exportErr)
+
+	_, err := suite.service.DeleteAccount(suite.adminContext(), connect.NewRequest(&apiv1.DeleteAccountRequest{ConfirmEmail: "admin@example.com"}))
+
+	suite.Require().ErrorIs(err, exportErr)
+	suite.userRepo.AssertNotCalled(suite.T(), "DeleteAccount", mock.Anything, mock.Anything)
+}
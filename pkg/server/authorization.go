@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"droscher.com/BeerGargoyle/pkg/auth"
+	"droscher.com/BeerGargoyle/pkg/model"
+)
+
+var (
+	ErrUnauthenticated  = errors.New("unauthenticated")
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+func currentUser(ctx context.Context) (*model.User, error) {
+	user, ok := ctx.Value(auth.UserKey{}).(*model.User)
+	if !ok || user == nil {
+		return nil, fmt.Errorf("%w: no user in context", ErrUnauthenticated)
+	}
+
+	return user, nil
+}
+
+// authorizeCellar checks that the user in the context has at least the required role in the cellar, and returns the
+// role they have. Cellars that do not exist are reported as permission denied so that callers cannot probe for the
+// existence of other users' cellars.
+func (c *CellarServer) authorizeCellar(ctx context.Context, cellarID uint, required model.CellarRole) (model.CellarRole, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return model.CellarRoleNone, err
+	}
+
+	role, err := c.cellarRepository.GetCellarRole(ctx, cellarID, user.ID)
+	if err != nil {
+		return model.CellarRoleNone, err
+	}
+
+	if !role.Allows(required) {
+		c.logger.Warn("cellar access denied", zap.Uint("cellar_id", cellarID), zap.Uint("user_id", user.ID),
+			zap.String("role", string(role)), zap.String("required", string(required)))
+
+		return model.CellarRoleNone, fmt.Errorf("%w: cellar %d", ErrPermissionDenied, cellarID)
+	}
+
+	return role, nil
+}
+
+func (c *CellarServer) authorizeCellarEntry(ctx context.Context, cellarEntryID uint, required model.CellarRole) error {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	role, err := c.cellarRepository.GetCellarEntryRole(ctx, cellarEntryID, user.ID)
+	if err != nil {
+		return err
+	}
+
+	if !role.Allows(required) {
+		c.logger.Warn("cellar entry access denied", zap.Uint("cellar_entry_id", cellarEntryID), zap.Uint("user_id", user.ID),
+			zap.String("role", string(role)), zap.String("required", string(required)))
+
+		return fmt.Errorf("%w: cellar entry %d", ErrPermissionDenied, cellarEntryID)
+	}
+
+	return nil
+}
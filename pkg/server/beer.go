@@ -3,8 +3,10 @@ package server
 import (
 	"context"
 	"errors"
+	"strings"
 
 	"github.com/bufbuild/connect-go"
+	"go.openly.dev/pointy"
 	"go.uber.org/zap"
 
 	"droscher.com/BeerGargoyle/configs"
@@ -18,16 +20,40 @@ import (
 
 type BeerServer struct {
 	apiv1connect.UnimplementedBeerServiceHandler
-	repository *repository.Repository
+	repository repository.BeerRepository
 	logger     *zap.Logger
 	config     *configs.Config
 }
 
-func NewBeerServer(repository *repository.Repository, logger *zap.Logger, config *configs.Config) *BeerServer {
+const (
+	// findBeerLocalLimit is the number of local beers FindBeer returns before falling back to the integrations.
+	findBeerLocalLimit = 20
+	// searchCatalogLimit and maxSearchCatalogLimit are the default and largest number of beers, and of breweries,
+	// SearchCatalog returns.
+	searchCatalogLimit    = 20
+	maxSearchCatalogLimit = 100
+)
+
+func NewBeerServer(repository repository.BeerRepository, logger *zap.Logger, config *configs.Config) *BeerServer {
 	return &BeerServer{repository: repository, logger: logger, config: config}
 }
 
-func (b *BeerServer) FindBeer(_ context.Context, request *connect.Request[api.FindBeerRequest]) (*connect.Response[api.FindBeerResponse], error) {
+// FindBeer searches the local catalog, and only searches the integrations when no local beer matches or when the
+// request asks for it.
+func (b *BeerServer) FindBeer(ctx context.Context, request *connect.Request[api.FindBeerRequest]) (*connect.Response[api.FindBeerResponse], error) {
+	if strings.TrimSpace(request.Msg.GetQuery()) == "" {
+		return nil, invalidField("query", "search query is required")
+	}
+
+	if !request.Msg.GetSearchExternal() {
+		localBeers, _, err := b.repository.SearchBeers(ctx, request.Msg.GetQuery(), repository.Page{Size: findBeerLocalLimit})
+		if err != nil {
+			b.logger.Error("failed local beer search", zap.Error(err))
+		} else if len(localBeers) > 0 {
+			return connect.NewResponse(&api.FindBeerResponse{Beers: grpc.CatalogBeersFromModel(localBeers)}), nil
+		}
+	}
+
 	var beers []*api.Beer
 
 	for _, integration := range b.config.Integrations.Beer {
@@ -66,6 +92,10 @@ func (b *BeerServer) AddBeer(ctx context.Context, request *connect.Request[api.A
 		}
 	}
 
+	if len(request.Msg.GetBeer().GetTags()) > 0 {
+		beer.Tags = tagsFromNames(ctx, b.repository, b.logger, b.config.Tags, request.Msg.GetBeer().GetTags())
+	}
+
 	newBeer, err := b.repository.AddBeer(ctx, beer)
 	if err != nil {
 		return nil, err
@@ -78,6 +108,51 @@ func (b *BeerServer) AddBeer(ctx context.Context, request *connect.Request[api.A
 	return connect.NewResponse(&response), nil
 }
 
+// UpdateBeerDetails changes the fields set in the request. Setting the tags replaces all the tags of the beer.
+func (b *BeerServer) UpdateBeerDetails(ctx context.Context, request *connect.Request[api.UpdateBeerDetailsRequest]) (*connect.Response[api.UpdateBeerDetailsResponse], error) {
+	beer, err := b.repository.GetBeer(ctx, uint(request.Msg.GetId()))
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Msg.Description != nil {
+		beer.Description = request.Msg.GetDescription()
+	}
+
+	if request.Msg.ImageUrl != nil {
+		beer.ImageURL = request.Msg.GetImageUrl()
+	}
+
+	if request.Msg.StyleId != nil {
+		beer.StyleID = uint(request.Msg.GetStyleId())
+	}
+
+	if request.Msg.Abv != nil {
+		beer.ABV = pointy.Float64(request.Msg.GetAbv())
+	}
+
+	if request.Msg.Ibu != nil {
+		beer.IBU = pointy.Uint64(request.Msg.GetIbu())
+	}
+
+	if request.Msg.GetTags() != nil {
+		beer.Tags = tagsFromNames(ctx, b.repository, b.logger, b.config.Tags, request.Msg.GetTags().GetTags())
+	}
+
+	err = b.repository.UpdateBeerDetails(ctx, beer)
+	if err != nil {
+		return nil, err
+	}
+
+	// The beer is loaded again so that the response has the new style and the IDs of newly created tags.
+	beer, err = b.repository.GetBeer(ctx, beer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&api.UpdateBeerDetailsResponse{Beer: grpc.BeerFromModel(*beer)}), nil
+}
+
 func (b *BeerServer) assignBeerStyle(ctx context.Context, beerStyle *api.BeerStyle, beer *model.Beer) error {
 	if beerStyle.GetId() != 0 {
 		beer.StyleID = uint(beerStyle.GetId())
@@ -108,15 +183,82 @@ func (b *BeerServer) loadBrewery(ctx context.Context, pbBrewery *api.Brewery, be
 	}
 }
 
-func (b *BeerServer) GetBeerFormats(ctx context.Context, _ *connect.Request[api.GetBeerFormatsRequest]) (*connect.Response[api.GetBeerFormatsResponse], error) {
-	formats, err := b.repository.GetBeerFormats(ctx)
+func (b *BeerServer) GetBeer(ctx context.Context, request *connect.Request[api.GetBeerRequest]) (*connect.Response[api.GetBeerResponse], error) {
+	beer, err := b.repository.GetBeer(ctx, uint(request.Msg.GetId()))
 	if err != nil {
 		return nil, err
 	}
 
-	response := api.GetBeerFormatsResponse{
-		Formats: grpc.FormatsFromModel(formats),
+	return connect.NewResponse(&api.GetBeerResponse{Beer: grpc.BeerFromModel(*beer)}), nil
+}
+
+func (b *BeerServer) ListBeers(ctx context.Context, request *connect.Request[api.ListBeersRequest]) (*connect.Response[api.ListBeersResponse], error) {
+	sort := repository.BeerSort{Field: beerSortField(request.Msg.GetSort()), Descending: request.Msg.GetDescending()}
+	page := repository.Page{Size: int(request.Msg.GetPageSize()), Token: request.Msg.GetPageToken()}
+
+	beers, nextPageToken, err := b.repository.ListBeers(ctx, request.Msg.GetFilter(), sort, page)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.ListBeersResponse{Beers: grpc.CatalogBeersFromModel(beers), NextPageToken: nextPageToken}
+
+	return connect.NewResponse(&response), nil
+}
+
+func (b *BeerServer) SearchLocalBeers(ctx context.Context, request *connect.Request[api.SearchLocalBeersRequest]) (*connect.Response[api.SearchLocalBeersResponse], error) {
+	if strings.TrimSpace(request.Msg.GetQuery()) == "" {
+		return nil, invalidField("query", "search query is required")
+	}
+
+	page := repository.Page{Size: int(request.Msg.GetPageSize()), Token: request.Msg.GetPageToken()}
+
+	beers, nextPageToken, err := b.repository.SearchBeers(ctx, request.Msg.GetQuery(), page)
+	if err != nil {
+		return nil, err
 	}
 
+	response := api.SearchLocalBeersResponse{Beers: grpc.CatalogBeersFromModel(beers), NextPageToken: nextPageToken}
+
 	return connect.NewResponse(&response), nil
 }
+
+// SearchCatalog returns the beers and breweries in the local catalog best matching the query, with their scores.
+func (b *BeerServer) SearchCatalog(ctx context.Context, request *connect.Request[api.SearchCatalogRequest]) (*connect.Response[api.SearchCatalogResponse], error) {
+	query := strings.TrimSpace(request.Msg.GetQuery())
+	if query == "" {
+		return nil, invalidField("query", "search query is required")
+	}
+
+	limit := int(request.Msg.GetLimit())
+	if limit <= 0 {
+		limit = searchCatalogLimit
+	}
+
+	beers, breweries, err := b.repository.SearchCatalog(ctx, query, min(limit, maxSearchCatalogLimit))
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.SearchCatalogResponse{
+		Beers:     grpc.BeerMatchesFromModel(beers),
+		Breweries: grpc.BreweryMatchesFromModel(breweries),
+	}
+
+	return connect.NewResponse(&response), nil
+}
+
+func beerSortField(field api.BeerSortField) repository.BeerSortField {
+	switch field {
+	case api.BeerSortField_BEER_SORT_FIELD_ABV:
+		return repository.BeerSortABV
+	case api.BeerSortField_BEER_SORT_FIELD_IBU:
+		return repository.BeerSortIBU
+	case api.BeerSortField_BEER_SORT_FIELD_RATING:
+		return repository.BeerSortRating
+	case api.BeerSortField_BEER_SORT_FIELD_NAME, api.BeerSortField_BEER_SORT_FIELD_UNSPECIFIED:
+		return repository.BeerSortName
+	}
+
+	return repository.BeerSortName
+}
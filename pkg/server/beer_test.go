@@ -0,0 +1,214 @@
+package server_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.openly.dev/pointy"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/configs"
+	"droscher.com/BeerGargoyle/mocks"
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+	"droscher.com/BeerGargoyle/pkg/server"
+	apiv1 "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+)
+
+type BeerTestSuite struct {
+	suite.Suite
+	beerRepo *mocks.BeerRepository
+	service  *server.BeerServer
+}
+
+func TestBeerTestSuite(t *testing.T) {
+	suite.Run(t, new(BeerTestSuite))
+}
+
+func (suite *BeerTestSuite) SetupTest() {
+	suite.beerRepo = mocks.NewBeerRepository(suite.T())
+	suite.service = server.NewBeerServer(suite.beerRepo, zaptest.NewLogger(suite.T()), &configs.Config{})
+}
+
+func (suite *BeerTestSuite) TestFindBeer_ReturnsLocalMatches() {
+	suite.beerRepo.EXPECT().SearchBeers(mock.Anything, "Precious Bet", repository.Page{Size: 20}).
+		Return([]*model.Beer{{Model: gorm.Model{ID: 4}, Name: "Precious Bet"}}, "", nil)
+
+	result, err := suite.service.FindBeer(context.Background(), connect.NewRequest(&apiv1.FindBeerRequest{Query: "Precious Bet"}))
+
+	suite.Require().NoError(err)
+	suite.Require().Len(result.Msg.GetBeers(), 1)
+	suite.Equal(uint64(4), result.Msg.GetBeers()[0].GetId())
+}
+
+func (suite *BeerTestSuite) TestFindBeer_SkipsLocalCatalogWhenAskedForExternal() {
+	result, err := suite.service.FindBeer(context.Background(), connect.NewRequest(&apiv1.FindBeerRequest{Query: "Precious Bet", SearchExternal: true}))
+
+	suite.Require().NoError(err)
+	suite.Empty(result.Msg.GetBeers())
+	suite.beerRepo.AssertNotCalled(suite.T(), "SearchBeers", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *BeerTestSuite) TestFindBeer_BlankQuery() {
+	_, err := suite.service.FindBeer(context.Background(), connect.NewRequest(&apiv1.FindBeerRequest{Query: "  "}))
+
+	suite.Equal(connect.CodeInvalidArgument, server.ErrorCode(err))
+	suite.beerRepo.AssertNotCalled(suite.T(), "SearchBeers", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *BeerTestSuite) TestGetBeer_NotFound() {
+	suite.beerRepo.EXPECT().GetBeer(mock.Anything, uint(9)).Return(nil, repository.ErrBeerNotFound)
+
+	_, err := suite.service.GetBeer(context.Background(), connect.NewRequest(&apiv1.GetBeerRequest{Id: 9}))
+
+	suite.Equal(connect.CodeNotFound, server.ErrorCode(err))
+}
+
+func (suite *BeerTestSuite) TestListBeers_PassesSortAndFilter() {
+	filter := &apiv1.BeerFilter{StyleId: new(uint64)}
+	suite.beerRepo.EXPECT().ListBeers(mock.Anything, filter,
+		repository.BeerSort{Field: repository.BeerSortRating, Descending: true}, repository.Page{Size: 10, Token: "abc"}).
+		Return([]*model.Beer{{Model: gorm.Model{ID: 1}, Name: "Best"}}, "next", nil)
+
+	result, err := suite.service.ListBeers(context.Background(), connect.NewRequest(&apiv1.ListBeersRequest{
+		PageSize:   10,
+		PageToken:  "abc",
+		Sort:       apiv1.BeerSortField_BEER_SORT_FIELD_RATING,
+		Descending: true,
+		Filter:     filter,
+	}))
+
+	suite.Require().NoError(err)
+	suite.Len(result.Msg.GetBeers(), 1)
+	suite.Equal("next", result.Msg.GetNextPageToken())
+}
+
+func (suite *BeerTestSuite) TestListBeers_InvalidPageToken() {
+	suite.beerRepo.EXPECT().ListBeers(mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, "", repository.ErrInvalidPageToken)
+
+	_, err := suite.service.ListBeers(context.Background(), connect.NewRequest(&apiv1.ListBeersRequest{PageToken: "bad"}))
+
+	suite.Equal(connect.CodeInvalidArgument, server.ErrorCode(err))
+}
+
+func (suite *BeerTestSuite) TestSearchLocalBeers_RequiresQuery() {
+	_, err := suite.service.SearchLocalBeers(context.Background(), connect.NewRequest(&apiv1.SearchLocalBeersRequest{Query: " "}))
+
+	suite.Require().ErrorIs(err, server.ErrInvalidInput)
+}
+
+func (suite *BeerTestSuite) TestSearchCatalog_ReturnsScoredMatches() {
+	suite.beerRepo.EXPECT().SearchCatalog(mock.Anything, "Precius Bet", 20).
+		Return([]model.BeerMatch{{Beer: model.Beer{Model: gorm.Model{ID: 4}, Name: "Precious Bet"}, Score: 0.8}},
+			[]model.BreweryMatch{{Brewery: model.Brewery{Model: gorm.Model{ID: 2}, Name: "Bellwoods"}, Score: 0.3}}, nil)
+
+	result, err := suite.service.SearchCatalog(context.Background(), connect.NewRequest(&apiv1.SearchCatalogRequest{Query: " Precius Bet "}))
+
+	suite.Require().NoError(err)
+	suite.Require().Len(result.Msg.GetBeers(), 1)
+	suite.Equal(uint64(4), result.Msg.GetBeers()[0].GetBeer().GetId())
+	suite.InDelta(0.8, result.Msg.GetBeers()[0].GetScore(), 0.001)
+	suite.Require().Len(result.Msg.GetBreweries(), 1)
+	suite.Equal("Bellwoods", result.Msg.GetBreweries()[0].GetBrewery().GetName())
+}
+
+func (suite *BeerTestSuite) TestSearchCatalog_CapsLimit() {
+	suite.beerRepo.EXPECT().SearchCatalog(mock.Anything, "saison", 100).Return(nil, nil, nil)
+
+	_, err := suite.service.SearchCatalog(context.Background(), connect.NewRequest(&apiv1.SearchCatalogRequest{Query: "saison", Limit: 1000}))
+
+	suite.Require().NoError(err)
+}
+
+func (suite *BeerTestSuite) TestSearchCatalog_RequiresQuery() {
+	_, err := suite.service.SearchCatalog(context.Background(), connect.NewRequest(&apiv1.SearchCatalogRequest{}))
+
+	suite.Require().ErrorIs(err, server.ErrInvalidInput)
+}
+
+func (suite *BeerTestSuite) TestAddBeer_ReusesExistingTags() {
+	suite.beerRepo.EXPECT().GetTagsByNames(mock.Anything, []string{"sour", "barrel-aged"}).
+		Return(map[string]model.Tag{"sour": {Model: gorm.Model{ID: 7}, Tag: "sour"}}, nil)
+	suite.beerRepo.EXPECT().AddBeer(mock.Anything, mock.MatchedBy(func(beer model.Beer) bool {
+		return len(beer.Tags) == 2 && beer.Tags[0].ID == 7 && beer.Tags[1].ID == 0 && beer.Tags[1].Tag == "barrel-aged"
+	})).RunAndReturn(func(_ context.Context, beer model.Beer) (*model.Beer, error) {
+		beer.ID = 4
+
+		return &beer, nil
+	})
+
+	result, err := suite.service.AddBeer(context.Background(), connect.NewRequest(&apiv1.AddBeerRequest{
+		Beer: &apiv1.Beer{Name: "Oude Geuze", Tags: []string{"sour", "barrel-aged"}},
+	}))
+
+	suite.Require().NoError(err)
+	suite.Equal([]string{"sour", "barrel-aged"}, result.Msg.GetBeer().GetTags())
+}
+
+func (suite *BeerTestSuite) TestAddBeer_NormalizesTags() {
+	suite.service = server.NewBeerServer(suite.beerRepo, zaptest.NewLogger(suite.T()), &configs.Config{
+		Tags: configs.Tags{FoldCase: true, TrimSpace: true},
+	})
+
+	suite.beerRepo.EXPECT().GetTagsByNames(mock.Anything, []string{"sour", "barrel aged"}).Return(map[string]model.Tag{}, nil)
+	suite.beerRepo.EXPECT().AddBeer(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, beer model.Beer) (*model.Beer, error) {
+			return &beer, nil
+		})
+
+	result, err := suite.service.AddBeer(context.Background(), connect.NewRequest(&apiv1.AddBeerRequest{
+		Beer: &apiv1.Beer{Name: "Oude Geuze", Tags: []string{" Sour", "sour ", "Barrel  Aged", "  "}},
+	}))
+
+	suite.Require().NoError(err)
+	suite.Equal([]string{"sour", "barrel aged"}, result.Msg.GetBeer().GetTags())
+}
+
+func (suite *BeerTestSuite) TestUpdateBeerDetails_UpdatesFieldsAndTags() {
+	beer := &model.Beer{Model: gorm.Model{ID: 4}, Name: "Oude Geuze", Description: "Old", Tags: []model.Tag{{Model: gorm.Model{ID: 7}, Tag: "sour"}}}
+	updated := &model.Beer{Model: gorm.Model{ID: 4}, Name: "Oude Geuze", Description: "Blend", Tags: []model.Tag{{Model: gorm.Model{ID: 8}, Tag: "lambic"}}}
+
+	suite.beerRepo.EXPECT().GetBeer(mock.Anything, uint(4)).Return(beer, nil).Once()
+	suite.beerRepo.EXPECT().GetTagsByNames(mock.Anything, []string{"lambic"}).Return(map[string]model.Tag{}, nil)
+	suite.beerRepo.EXPECT().UpdateBeerDetails(mock.Anything, mock.MatchedBy(func(beer *model.Beer) bool {
+		return beer.Description == "Blend" && *beer.ABV == 6.0 && len(beer.Tags) == 1 && beer.Tags[0].Tag == "lambic"
+	})).Return(nil)
+	suite.beerRepo.EXPECT().GetBeer(mock.Anything, uint(4)).Return(updated, nil).Once()
+
+	result, err := suite.service.UpdateBeerDetails(context.Background(), connect.NewRequest(&apiv1.UpdateBeerDetailsRequest{
+		Id:          4,
+		Description: pointy.String("Blend"),
+		Abv:         pointy.Float64(6.0),
+		Tags:        &apiv1.Tags{Tags: []string{"lambic"}},
+	}))
+
+	suite.Require().NoError(err)
+	suite.Equal("Blend", result.Msg.GetBeer().GetDescription())
+	suite.Equal([]string{"lambic"}, result.Msg.GetBeer().GetTags())
+}
+
+func (suite *BeerTestSuite) TestUpdateBeerDetails_KeepsTagsWhenNotSet() {
+	beer := &model.Beer{Model: gorm.Model{ID: 4}, Name: "Oude Geuze", Tags: []model.Tag{{Model: gorm.Model{ID: 7}, Tag: "sour"}}}
+
+	suite.beerRepo.EXPECT().GetBeer(mock.Anything, uint(4)).Return(beer, nil)
+	suite.beerRepo.EXPECT().UpdateBeerDetails(mock.Anything, mock.MatchedBy(func(beer *model.Beer) bool {
+		return len(beer.Tags) == 1 && beer.Tags[0].ID == 7
+	})).Return(nil)
+
+	_, err := suite.service.UpdateBeerDetails(context.Background(), connect.NewRequest(&apiv1.UpdateBeerDetailsRequest{Id: 4, ImageUrl: pointy.String("geuze.png")}))
+
+	suite.Require().NoError(err)
+}
+
+func (suite *BeerTestSuite) TestUpdateBeerDetails_NotFound() {
+	suite.beerRepo.EXPECT().GetBeer(mock.Anything, uint(9)).Return(nil, repository.ErrBeerNotFound)
+
+	_, err := suite.service.UpdateBeerDetails(context.Background(), connect.NewRequest(&apiv1.UpdateBeerDetailsRequest{Id: 9}))
+
+	suite.Equal(connect.CodeNotFound, server.ErrorCode(err))
+}
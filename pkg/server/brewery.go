@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bufbuild/connect-go"
+	"go.openly.dev/pointy"
+	"go.uber.org/zap"
+
+	"droscher.com/BeerGargoyle/pkg/integrations"
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+	"droscher.com/BeerGargoyle/pkg/server/grpc"
+	api "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+)
+
+// FindBrewery searches the configured integrations for breweries matching the query.
+func (b *BeerServer) FindBrewery(_ context.Context, request *connect.Request[api.FindBreweryRequest]) (*connect.Response[api.FindBreweryResponse], error) {
+	var breweries []*api.Brewery
+
+	for _, integration := range b.config.Integrations.Beer {
+		breweryIntegration := integrations.GetIntegration(integration, b.logger)
+
+		foundBreweries, err := breweryIntegration.FindBrewery(request.Msg.GetQuery())
+		if err != nil {
+			b.logger.Error("failed brewery search", zap.String("integration", integration), zap.Error(err))
+
+			continue
+		}
+
+		for _, brewery := range foundBreweries {
+			breweries = append(breweries, grpc.BreweryFromModel(brewery))
+		}
+	}
+
+	return connect.NewResponse(&api.FindBreweryResponse{Breweries: breweries}), nil
+}
+
+func (b *BeerServer) GetBrewery(ctx context.Context, request *connect.Request[api.GetBreweryRequest]) (*connect.Response[api.GetBreweryResponse], error) {
+	brewery, err := b.brewery(ctx, request.Msg.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&api.GetBreweryResponse{Brewery: grpc.BreweryFromModel(*brewery)}), nil
+}
+
+func (b *BeerServer) ListBreweries(ctx context.Context, request *connect.Request[api.ListBreweriesRequest]) (*connect.Response[api.ListBreweriesResponse], error) {
+	page := repository.Page{Size: int(request.Msg.GetPageSize()), Token: request.Msg.GetPageToken()}
+
+	breweries, nextPageToken, err := b.repository.ListBreweries(ctx, request.Msg.GetQuery(), page)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.ListBreweriesResponse{Breweries: grpc.CatalogBreweriesFromModel(breweries), NextPageToken: nextPageToken}
+
+	return connect.NewResponse(&response), nil
+}
+
+// ListBreweryBeers returns a page of the beers in the catalog brewed by the brewery.
+func (b *BeerServer) ListBreweryBeers(ctx context.Context, request *connect.Request[api.ListBreweryBeersRequest]) (*connect.Response[api.ListBreweryBeersResponse], error) {
+	brewery, err := b.brewery(ctx, request.Msg.GetBreweryId())
+	if err != nil {
+		return nil, err
+	}
+
+	filter := api.BeerFilter{BreweryId: pointy.Uint64(uint64(brewery.ID))}
+	sort := repository.BeerSort{Field: beerSortField(request.Msg.GetSort()), Descending: request.Msg.GetDescending()}
+	page := repository.Page{Size: int(request.Msg.GetPageSize()), Token: request.Msg.GetPageToken()}
+
+	beers, nextPageToken, err := b.repository.ListBeers(ctx, &filter, sort, page)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.ListBreweryBeersResponse{Beers: grpc.CatalogBeersFromModel(beers), NextPageToken: nextPageToken}
+
+	return connect.NewResponse(&response), nil
+}
+
+func (b *BeerServer) AddBrewery(ctx context.Context, request *connect.Request[api.AddBreweryRequest]) (*connect.Response[api.AddBreweryResponse], error) {
+	pbBrewery := request.Msg.GetBrewery()
+	if strings.TrimSpace(pbBrewery.GetName()) == "" {
+		return nil, invalidField("brewery.name", "brewery name is required")
+	}
+
+	// Breweries always reference an address, even if only the country is known.
+	if pbBrewery.GetAddress() == nil {
+		return nil, invalidField("brewery.address", "brewery address is required")
+	}
+
+	brewery, err := b.repository.AddBrewery(ctx, grpc.BreweryToModel(pbBrewery))
+	if err != nil {
+		return nil, err
+	}
+
+	b.logger.Info("brewery added", zap.Uint("brewery_id", brewery.ID))
+
+	return connect.NewResponse(&api.AddBreweryResponse{Brewery: grpc.BreweryFromModel(*brewery)}), nil
+}
+
+func (b *BeerServer) UpdateBrewery(ctx context.Context, request *connect.Request[api.UpdateBreweryRequest]) (*connect.Response[api.UpdateBreweryResponse], error) {
+	brewery, err := b.brewery(ctx, request.Msg.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Msg.Name != nil {
+		if strings.TrimSpace(request.Msg.GetName()) == "" {
+			return nil, invalidField("name", "brewery name cannot be empty")
+		}
+
+		brewery.Name = request.Msg.GetName()
+	}
+
+	if request.Msg.Description != nil {
+		brewery.Description = request.Msg.GetDescription()
+	}
+
+	if request.Msg.ImageUrl != nil {
+		brewery.ImageURL = request.Msg.GetImageUrl()
+	}
+
+	if request.Msg.GetAddress() != nil {
+		address := grpc.AddressToModel(request.Msg.GetAddress())
+		address.Model = brewery.Address.Model
+		brewery.Address = address
+	}
+
+	err = b.repository.UpdateBrewery(ctx, brewery)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&api.UpdateBreweryResponse{Brewery: grpc.BreweryFromModel(*brewery)}), nil
+}
+
+func (b *BeerServer) brewery(ctx context.Context, breweryID uint64) (*model.Brewery, error) {
+	brewery, err := b.repository.GetBrewery(ctx, uint(breweryID))
+	if err != nil {
+		return nil, err
+	}
+
+	return brewery, nil
+}
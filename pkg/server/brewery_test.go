@@ -0,0 +1,131 @@
+package server_test
+
+import (
+	"context"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/stretchr/testify/mock"
+	"go.openly.dev/pointy"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+	"droscher.com/BeerGargoyle/pkg/server"
+	apiv1 "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+)
+
+func bellwoods() *model.Brewery {
+	return &model.Brewery{
+		Model:       gorm.Model{ID: 4},
+		Name:        "Bellwoods Brewery",
+		Description: "Ossington",
+		Address:     model.Address{Model: gorm.Model{ID: 1}, Country: "Canada", Locality: "Toronto"},
+	}
+}
+
+func (suite *BeerTestSuite) TestGetBrewery_ReturnsBreweryWithAddress() {
+	suite.beerRepo.EXPECT().GetBrewery(mock.Anything, uint(4)).Return(bellwoods(), nil)
+
+	result, err := suite.service.GetBrewery(context.Background(), connect.NewRequest(&apiv1.GetBreweryRequest{Id: 4}))
+
+	suite.Require().NoError(err)
+	suite.Equal("Bellwoods Brewery", result.Msg.GetBrewery().GetName())
+	suite.Equal("Ossington", result.Msg.GetBrewery().GetDescription())
+	suite.Equal("Canada", result.Msg.GetBrewery().GetAddress().GetCountry())
+}
+
+func (suite *BeerTestSuite) TestGetBrewery_NotFound() {
+	suite.beerRepo.EXPECT().GetBrewery(mock.Anything, uint(9)).Return(nil, repository.ErrBreweryNotFound)
+
+	_, err := suite.service.GetBrewery(context.Background(), connect.NewRequest(&apiv1.GetBreweryRequest{Id: 9}))
+
+	suite.Equal(connect.CodeNotFound, server.ErrorCode(err))
+}
+
+func (suite *BeerTestSuite) TestListBreweries_ReturnsPage() {
+	suite.beerRepo.EXPECT().ListBreweries(mock.Anything, "bell", repository.Page{Size: 10}).
+		Return([]*model.Brewery{bellwoods()}, "next", nil)
+
+	result, err := suite.service.ListBreweries(context.Background(), connect.NewRequest(&apiv1.ListBreweriesRequest{Query: "bell", PageSize: 10}))
+
+	suite.Require().NoError(err)
+	suite.Len(result.Msg.GetBreweries(), 1)
+	suite.Equal("next", result.Msg.GetNextPageToken())
+}
+
+func (suite *BeerTestSuite) TestListBreweryBeers_FiltersByBrewery() {
+	suite.beerRepo.EXPECT().GetBrewery(mock.Anything, uint(4)).Return(bellwoods(), nil)
+	suite.beerRepo.EXPECT().ListBeers(mock.Anything, mock.MatchedBy(func(filter *apiv1.BeerFilter) bool {
+		return filter.GetBreweryId() == 4
+	}), repository.BeerSort{Field: repository.BeerSortABV, Descending: true}, repository.Page{}).
+		Return([]*model.Beer{{Model: gorm.Model{ID: 2}, Name: "Jelly King", BreweryID: 4}}, "", nil)
+
+	result, err := suite.service.ListBreweryBeers(context.Background(), connect.NewRequest(&apiv1.ListBreweryBeersRequest{
+		BreweryId:  4,
+		Sort:       apiv1.BeerSortField_BEER_SORT_FIELD_ABV,
+		Descending: true,
+	}))
+
+	suite.Require().NoError(err)
+	suite.Require().Len(result.Msg.GetBeers(), 1)
+	suite.Equal("Jelly King", result.Msg.GetBeers()[0].GetName())
+}
+
+func (suite *BeerTestSuite) TestListBreweryBeers_UnknownBrewery() {
+	suite.beerRepo.EXPECT().GetBrewery(mock.Anything, uint(9)).Return(nil, repository.ErrBreweryNotFound)
+
+	_, err := suite.service.ListBreweryBeers(context.Background(), connect.NewRequest(&apiv1.ListBreweryBeersRequest{BreweryId: 9}))
+
+	suite.Equal(connect.CodeNotFound, server.ErrorCode(err))
+}
+
+func (suite *BeerTestSuite) TestAddBrewery_AddsBrewery() {
+	suite.beerRepo.EXPECT().AddBrewery(mock.Anything, mock.MatchedBy(func(brewery model.Brewery) bool {
+		return brewery.Name == "Bellwoods Brewery" && brewery.Address.Locality == "Toronto"
+	})).Return(bellwoods(), nil)
+
+	result, err := suite.service.AddBrewery(context.Background(), connect.NewRequest(&apiv1.AddBreweryRequest{
+		Brewery: &apiv1.Brewery{Name: "Bellwoods Brewery", Address: &apiv1.Address{Country: "Canada", Locality: "Toronto"}},
+	}))
+
+	suite.Require().NoError(err)
+	suite.Equal(uint64(4), result.Msg.GetBrewery().GetId())
+}
+
+func (suite *BeerTestSuite) TestAddBrewery_RequiresNameAndAddress() {
+	_, err := suite.service.AddBrewery(context.Background(), connect.NewRequest(&apiv1.AddBreweryRequest{
+		Brewery: &apiv1.Brewery{Address: &apiv1.Address{Country: "Canada"}},
+	}))
+	suite.Require().ErrorIs(err, server.ErrInvalidInput)
+
+	_, err = suite.service.AddBrewery(context.Background(), connect.NewRequest(&apiv1.AddBreweryRequest{
+		Brewery: &apiv1.Brewery{Name: "Bellwoods Brewery"},
+	}))
+	suite.Require().ErrorIs(err, server.ErrInvalidInput)
+}
+
+func (suite *BeerTestSuite) TestUpdateBrewery_UpdatesGivenFields() {
+	suite.beerRepo.EXPECT().GetBrewery(mock.Anything, uint(4)).Return(bellwoods(), nil)
+	suite.beerRepo.EXPECT().UpdateBrewery(mock.Anything, mock.MatchedBy(func(brewery *model.Brewery) bool {
+		return brewery.Name == "Bellwoods" && brewery.Description == "Ossington" &&
+			brewery.Address.ID == 1 && brewery.Address.Locality == "Hamilton"
+	})).Return(nil)
+
+	result, err := suite.service.UpdateBrewery(context.Background(), connect.NewRequest(&apiv1.UpdateBreweryRequest{
+		Id:      4,
+		Name:    pointy.String("Bellwoods"),
+		Address: &apiv1.Address{Country: "Canada", Locality: "Hamilton"},
+	}))
+
+	suite.Require().NoError(err)
+	suite.Equal("Bellwoods", result.Msg.GetBrewery().GetName())
+	suite.Equal("Hamilton", result.Msg.GetBrewery().GetAddress().GetLocality())
+}
+
+func (suite *BeerTestSuite) TestUpdateBrewery_RejectsEmptyName() {
+	suite.beerRepo.EXPECT().GetBrewery(mock.Anything, uint(4)).Return(bellwoods(), nil)
+
+	_, err := suite.service.UpdateBrewery(context.Background(), connect.NewRequest(&apiv1.UpdateBreweryRequest{Id: 4, Name: pointy.String(" ")}))
+
+	suite.Require().ErrorIs(err, server.ErrInvalidInput)
+}
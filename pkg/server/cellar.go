@@ -14,7 +14,8 @@ import (
 	"go.uber.org/zap"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
-	"droscher.com/BeerGargoyle/pkg/auth"
+	"droscher.com/BeerGargoyle/configs"
+	"droscher.com/BeerGargoyle/pkg/events"
 	"droscher.com/BeerGargoyle/pkg/model"
 	"droscher.com/BeerGargoyle/pkg/repository"
 	"droscher.com/BeerGargoyle/pkg/server/grpc"
@@ -28,6 +29,8 @@ type CellarServer struct {
 	cellarRepository repository.CellarRepository
 	beerRepository   beerRepository
 	userRepository   userRepository
+	events           cellarEvents
+	config           *configs.Config
 }
 
 const (
@@ -42,22 +45,42 @@ var (
 
 type userRepository interface {
 	GetUserByUUID(ctx context.Context, uuid uuid.UUID) (*model.User, error)
+	GetUserFromEmail(ctx context.Context, email string) (*model.User, error)
 }
 
 type beerRepository interface {
-	GetTagsByNames(ctx context.Context, names []string) (map[string]model.Tag, error)
+	tagRepository
+	GetBeerFormat(ctx context.Context, formatID uint) (*model.BeerFormat, error)
 }
 
-func NewCellarServer(cellarRepo repository.CellarRepository, beerRepo beerRepository, userRepo userRepository, logger *zap.Logger) *CellarServer {
-	return &CellarServer{cellarRepository: cellarRepo, beerRepository: beerRepo, userRepository: userRepo, logger: logger}
+func NewCellarServer(cellarRepo repository.CellarRepository, beerRepo beerRepository, userRepo userRepository, cellarEvents cellarEvents, logger *zap.Logger, config *configs.Config) *CellarServer {
+	return &CellarServer{
+		cellarRepository: cellarRepo,
+		beerRepository:   beerRepo,
+		userRepository:   userRepo,
+		events:           cellarEvents,
+		logger:           logger,
+		config:           config,
+	}
 }
 
 func (c *CellarServer) AddCellar(ctx context.Context, request *connect.Request[api.AddCellarRequest]) (*connect.Response[api.AddCellarResponse], error) {
-	user, err := c.userRepository.GetUserByUUID(ctx, uuid.MustParse(request.Msg.GetOwnerUuid()))
+	user, err := currentUser(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	if len(request.Msg.GetOwnerUuid()) > 0 {
+		ownerUUID, err := uuid.Parse(request.Msg.GetOwnerUuid())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", invalidField("owner_uuid", "invalid owner uuid"), err)
+		}
+
+		if ownerUUID != user.UUID {
+			return nil, fmt.Errorf("%w: cannot create a cellar for another user", ErrPermissionDenied)
+		}
+	}
+
 	owner := api.User{
 		Id:       user.UUID.String(),
 		UserName: user.Username,
@@ -78,6 +101,7 @@ func (c *CellarServer) AddCellar(ctx context.Context, request *connect.Request[a
 		Owner:       &owner,
 		Name:        request.Msg.GetName(),
 		Description: request.Msg.GetDescription(),
+		Role:        api.CellarRole_CELLAR_ROLE_OWNER,
 	}
 
 	for _, location := range cellar.Locations {
@@ -93,9 +117,9 @@ func (c *CellarServer) AddCellar(ctx context.Context, request *connect.Request[a
 }
 
 func (c *CellarServer) GetCellarList(ctx context.Context, _ *connect.Request[api.GetCellarListRequest]) (*connect.Response[api.GetCellarListResponse], error) {
-	user, ok := ctx.Value(auth.UserKey{}).(*model.User)
-	if !ok {
-		return nil, fmt.Errorf("%w: no user in context", ErrInvalidInput)
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	cellars, err := c.cellarRepository.GetCellarsForUser(ctx, *user)
@@ -103,18 +127,29 @@ func (c *CellarServer) GetCellarList(ctx context.Context, _ *connect.Request[api
 		return nil, err
 	}
 
-	response := api.GetCellarListResponse{Cellars: grpc.CellarsFromModel(cellars)}
+	pbCellars := grpc.CellarsFromModel(cellars)
+	for index, cellar := range cellars {
+		pbCellars[index].Role = grpc.CellarRoleFromModel(cellar.RoleFor(user.ID))
+	}
+
+	response := api.GetCellarListResponse{Cellars: pbCellars}
 
 	return connect.NewResponse(&response), nil
 }
 
 func (c *CellarServer) GetCellar(ctx context.Context, request *connect.Request[api.GetCellarRequest]) (*connect.Response[api.GetCellarResponse], error) {
+	role, err := c.authorizeCellar(ctx, uint(request.Msg.GetCellarId()), model.CellarRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
 	cellar, err := c.cellarRepository.GetCellarByID(ctx, uint(request.Msg.GetCellarId()))
 	if err != nil {
 		return nil, err
 	}
 
 	pbCellar := grpc.CellarFromModel(cellar)
+	pbCellar.Role = grpc.CellarRoleFromModel(role)
 
 	response := api.GetCellarResponse{Cellar: pbCellar}
 
@@ -122,6 +157,11 @@ func (c *CellarServer) GetCellar(ctx context.Context, request *connect.Request[a
 }
 
 func (c *CellarServer) AddCellarBeer(ctx context.Context, request *connect.Request[api.AddCellarBeerRequest]) (*connect.Response[api.AddCellarBeerResponse], error) {
+	_, err := c.authorizeCellar(ctx, uint(request.Msg.GetCellarId()), model.CellarRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
 	cellar, err := c.cellarRepository.GetCellarByID(ctx, uint(request.Msg.GetCellarId()))
 	if err != nil {
 		return nil, err
@@ -131,6 +171,11 @@ func (c *CellarServer) AddCellarBeer(ctx context.Context, request *connect.Reque
 		return nil, fmt.Errorf("%w: id %d", ErrCellarNotFound, request.Msg.GetCellarId())
 	}
 
+	err = c.validateCellarEntry(ctx, cellar, uint(request.Msg.GetLocationId()), uint(request.Msg.GetFormatId()), request.Msg.GetVintage())
+	if err != nil {
+		return nil, err
+	}
+
 	beer := model.CellarEntry{
 		CellarID:   uint(request.Msg.GetCellarId()),
 		BeerID:     uint(request.Msg.GetBeerId()),
@@ -164,8 +209,7 @@ func (c *CellarServer) AddCellarBeer(ctx context.Context, request *connect.Reque
 	}
 
 	if len(request.Msg.GetTags()) > 0 {
-		tags := c.fetchTags(ctx, request.Msg.GetTags())
-		beer.Tags = tags
+		beer.Tags = tagsFromNames(ctx, c.beerRepository, c.logger, c.config.Tags, request.Msg.GetTags())
 	}
 
 	cellarEntry, err := c.cellarRepository.AddBeerToCellar(ctx, beer)
@@ -179,33 +223,17 @@ func (c *CellarServer) AddCellarBeer(ctx context.Context, request *connect.Reque
 		fullCellarEntry = cellarEntry
 	}
 
-	reply := api.AddCellarBeerResponse{Beer: grpc.CellarBeerFromModel(fullCellarEntry)}
+	c.publishEntryEvent(ctx, events.CellarEntryAdded, cellarEntry, 0)
 
-	return connect.NewResponse(&reply), nil
+	return connect.NewResponse(&api.AddCellarBeerResponse{Beer: grpc.CellarBeerFromModel(fullCellarEntry)}), nil
 }
 
-func (c *CellarServer) fetchTags(ctx context.Context, requestTags []string) []model.Tag {
-	tags := make([]model.Tag, 0, len(requestTags))
-
-	tagsByName, err := c.beerRepository.GetTagsByNames(ctx, requestTags)
+func (c *CellarServer) GetCellarEntry(ctx context.Context, request *connect.Request[api.GetCellarEntryRequest]) (*connect.Response[api.GetCellarEntryResponse], error) {
+	err := c.authorizeCellarEntry(ctx, uint(request.Msg.GetCellarEntryId()), model.CellarRoleViewer)
 	if err != nil {
-		c.logger.Error("error getting tags by name", zap.Error(err))
-
-		tagsByName = map[string]model.Tag{}
-	}
-
-	for _, tagName := range requestTags {
-		if tag, ok := tagsByName[tagName]; ok {
-			tags = append(tags, tag)
-		} else {
-			tags = append(tags, model.Tag{Tag: tagName})
-		}
+		return nil, err
 	}
 
-	return tags
-}
-
-func (c *CellarServer) GetCellarEntry(ctx context.Context, request *connect.Request[api.GetCellarEntryRequest]) (*connect.Response[api.GetCellarEntryResponse], error) {
 	cellarEntry, err := c.cellarRepository.GetCellarEntryByID(ctx, uint(request.Msg.GetCellarEntryId()))
 	if err != nil {
 		return nil, err
@@ -217,7 +245,17 @@ func (c *CellarServer) GetCellarEntry(ctx context.Context, request *connect.Requ
 }
 
 func (c *CellarServer) GetCellarStats(ctx context.Context, request *connect.Request[api.GetCellarStatsRequest]) (*connect.Response[api.GetCellarStatsResponse], error) {
-	stats, err := c.cellarRepository.GetCellarStats(ctx, uint(request.Msg.GetCellarId()))
+	_, err := c.authorizeCellar(ctx, uint(request.Msg.GetCellarId()), model.CellarRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	rater, err := raterID(ctx, request.Msg.GetRatingSource())
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := c.cellarRepository.GetCellarStats(ctx, uint(request.Msg.GetCellarId()), rater)
 	if err != nil {
 		return nil, err
 	}
@@ -228,44 +266,166 @@ func (c *CellarServer) GetCellarStats(ctx context.Context, request *connect.Requ
 }
 
 func (c *CellarServer) ListCellarBeers(ctx context.Context, request *connect.Request[api.ListCellarBeersRequest]) (*connect.Response[api.ListCellarBeersResponse], error) {
-	beers, err := c.cellarRepository.GetCellarBeers(ctx, uint(request.Msg.GetCellarId()))
+	_, err := c.authorizeCellar(ctx, uint(request.Msg.GetCellarId()), model.CellarRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	rater, err := raterID(ctx, request.Msg.GetFilter().GetRatingSource())
 	if err != nil {
 		return nil, err
 	}
 
-	response := api.ListCellarBeersResponse{Beers: grpc.CellarBeersFromModel(beers)}
+	sort := repository.CellarSort{Field: cellarSortField(request.Msg.GetSort()), Descending: request.Msg.GetDescending()}
+	page := repository.Page{Size: int(request.Msg.GetPageSize()), Token: request.Msg.GetPageToken()}
+
+	beers, nextPageToken, total, err := c.cellarRepository.ListCellarBeers(ctx, uint(request.Msg.GetCellarId()),
+		request.Msg.GetFilter(), rater, sort, page)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.ListCellarBeersResponse{
+		Beers:         grpc.CellarBeersFromModel(beers),
+		NextPageToken: nextPageToken,
+		TotalCount:    total,
+	}
 
 	return connect.NewResponse(&response), nil
 }
 
+func cellarSortField(field api.CellarSortField) repository.CellarSortField {
+	switch field {
+	case api.CellarSortField_CELLAR_SORT_FIELD_BREWERY:
+		return repository.CellarSortBrewery
+	case api.CellarSortField_CELLAR_SORT_FIELD_ABV:
+		return repository.CellarSortABV
+	case api.CellarSortField_CELLAR_SORT_FIELD_RATING:
+		return repository.CellarSortRating
+	case api.CellarSortField_CELLAR_SORT_FIELD_DATE_ADDED:
+		return repository.CellarSortDateAdded
+	case api.CellarSortField_CELLAR_SORT_FIELD_DRINK_BEFORE:
+		return repository.CellarSortDrinkBefore
+	case api.CellarSortField_CELLAR_SORT_FIELD_VINTAGE:
+		return repository.CellarSortVintage
+	case api.CellarSortField_CELLAR_SORT_FIELD_NAME, api.CellarSortField_CELLAR_SORT_FIELD_UNSPECIFIED:
+		return repository.CellarSortName
+	}
+
+	return repository.CellarSortName
+}
+
 func (c *CellarServer) UpdateBeer(ctx context.Context, request *connect.Request[api.UpdateBeerRequest]) (*connect.Response[api.UpdateBeerResponse], error) {
+	err := c.authorizeCellarEntry(ctx, uint(request.Msg.GetCellarEntryId()), model.CellarRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	cellarEntry, err := c.cellarRepository.GetCellarEntryByID(ctx, uint(request.Msg.GetCellarEntryId()))
+	if err != nil {
+		return nil, err
+	}
+
 	if request.Msg.Quantity != nil && request.Msg.GetQuantity() == 0 {
-		err := c.cellarRepository.DeleteCellarEntry(ctx, uint(request.Msg.GetCellarEntryId()))
+		err = c.cellarRepository.DeleteCellarEntry(ctx, cellarEntry.ID)
 		if err != nil {
 			return nil, err
 		}
 
+		c.publishEntryEvent(ctx, events.CellarEntryDeleted, cellarEntry, 0)
+
 		return connect.NewResponse(&api.UpdateBeerResponse{Beer: nil}), nil
 	}
 
-	cellarEntry, err := c.cellarRepository.GetCellarEntryByID(ctx, uint(request.Msg.GetCellarEntryId()))
+	previousQuantity := cellarEntry.Quantity
+
+	err = c.updateCellarEntry(ctx, request, cellarEntry)
 	if err != nil {
 		return nil, err
 	}
 
-	c.updateCellarEntry(ctx, request, cellarEntry)
-
 	updatedEntry, err := c.cellarRepository.UpdateCellarEntry(ctx, cellarEntry)
 	if err != nil {
 		return nil, err
 	}
 
+	if updatedEntry.Quantity != previousQuantity {
+		c.publishEntryEvent(ctx, events.CellarEntryQuantityChanged, updatedEntry, previousQuantity)
+	} else {
+		c.publishEntryEvent(ctx, events.CellarEntryUpdated, updatedEntry, 0)
+	}
+
 	response := api.UpdateBeerResponse{Beer: grpc.CellarBeerFromModel(updatedEntry)}
 
 	return connect.NewResponse(&response), nil
 }
 
-func (c *CellarServer) updateCellarEntry(ctx context.Context, request *connect.Request[api.UpdateBeerRequest], cellarEntry *model.CellarEntry) {
+// validateCellarEntry checks the location, format and vintage given for an entry of the cellar. Zero values are left
+// unset on the entry, so they are not checked.
+func (c *CellarServer) validateCellarEntry(ctx context.Context, cellar *model.Cellar, locationID uint, formatID uint, vintage uint64) error {
+	if locationID != 0 {
+		err := validateLocation(cellar, locationID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if formatID != 0 {
+		err := c.validateFormat(ctx, formatID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if vintage != 0 && (vintage < model.MinVintage || vintage > model.MaxVintage()) {
+		return invalidField("vintage", fmt.Sprintf("the vintage must be between %d and %d", model.MinVintage, model.MaxVintage()))
+	}
+
+	return nil
+}
+
+// validateLocation checks that the location is one of the cellar's, so that entries cannot be put in the locations
+// of other cellars.
+func validateLocation(cellar *model.Cellar, locationID uint) error {
+	for _, location := range cellar.Locations {
+		if location.ID == locationID {
+			return nil
+		}
+	}
+
+	return invalidField("location_id", "the location is not in the cellar")
+}
+
+// validateFormat checks that the format is a global one or one of the current user's custom formats. Other users'
+// custom formats are reported the same as missing ones, so their IDs are not revealed.
+func (c *CellarServer) validateFormat(ctx context.Context, formatID uint) error {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	format, err := c.beerRepository.GetBeerFormat(ctx, formatID)
+	if errors.Is(err, repository.ErrFormatNotFound) {
+		return invalidField("format_id", "the format does not exist")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if format.OwnerID != nil && *format.OwnerID != user.ID {
+		return invalidField("format_id", "the format does not exist")
+	}
+
+	return nil
+}
+
+func (c *CellarServer) updateCellarEntry(ctx context.Context, request *connect.Request[api.UpdateBeerRequest], cellarEntry *model.CellarEntry) error {
+	err := c.validateCellarEntry(ctx, &cellarEntry.Cellar, uint(request.Msg.GetLocationId()), uint(request.Msg.GetFormatId()), request.Msg.GetVintage())
+	if err != nil {
+		return err
+	}
+
 	if request.Msg.GetLocationId() != 0 {
 		cellarEntry.LocationID = pointy.Uint(uint(request.Msg.GetLocationId()))
 		cellarEntry.Location = nil
@@ -308,12 +468,26 @@ func (c *CellarServer) updateCellarEntry(ctx context.Context, request *connect.R
 	}
 
 	if request.Msg.GetTags() != nil {
-		cellarEntry.Tags = c.fetchTags(ctx, request.Msg.GetTags().GetTags())
+		cellarEntry.Tags = tagsFromNames(ctx, c.beerRepository, c.logger, c.config.Tags, request.Msg.GetTags().GetTags())
 	}
+
+	return nil
 }
 
 func (c *CellarServer) RecommendBeer(ctx context.Context, request *connect.Request[api.RecommendBeerRequest]) (*connect.Response[api.RecommendBeerResponse], error) {
-	candidates, err := c.cellarRepository.FindBeerRecommendations(ctx, request.Msg.GetCellarId(), request.Msg.GetFilter())
+	_, err := c.authorizeCellar(ctx, uint(request.Msg.GetCellarId()), model.CellarRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	normalizeFilterTags(c.config.Tags, request.Msg.GetFilter())
+
+	rater, err := raterID(ctx, request.Msg.GetFilter().GetRatingSource())
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := c.cellarRepository.FindBeerRecommendations(ctx, request.Msg.GetCellarId(), request.Msg.GetFilter(), rater)
 	if err != nil {
 		return nil, err
 	}
@@ -330,6 +504,11 @@ func (c *CellarServer) RecommendBeer(ctx context.Context, request *connect.Reque
 }
 
 func (c *CellarServer) GetCellarRecommendationParams(ctx context.Context, request *connect.Request[api.GetCellarRecommendationParamsRequest]) (*connect.Response[api.GetCellarRecommendationParamsResponse], error) {
+	_, err := c.authorizeCellar(ctx, uint(request.Msg.GetCellarId()), model.CellarRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
 	breweries, err := c.cellarRepository.GetCellarBreweryNames(ctx, request.Msg.GetCellarId())
 	if err != nil {
 		return nil, err
@@ -347,7 +526,7 @@ func (c *CellarServer) GetCellarRecommendationParams(ctx context.Context, reques
 
 	response := api.GetCellarRecommendationParamsResponse{
 		Breweries:       grpc.BreweriesFromModel(breweries),
-		Styles:          grpc.StylesFromModel(styles),
+		Styles:          grpc.StyleTreeFromModel(styles),
 		MinimumAbv:      params.MinimumAbv,
 		MaximumAbv:      params.MaximumAbv,
 		MinimumSize:     params.MinimumSize,
@@ -363,6 +542,11 @@ func (c *CellarServer) GetCellarRecommendationParams(ctx context.Context, reques
 }
 
 func (c *CellarServer) CreateAdventCalendar(ctx context.Context, request *connect.Request[api.CreateAdventCalendarRequest]) (*connect.Response[api.CreateAdventCalendarResponse], error) {
+	_, err := c.authorizeCellar(ctx, uint(request.Msg.GetCellarId()), model.CellarRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
 	startDate := truncateToDay(request.Msg.GetStartDate().AsTime())
 	endDate := truncateToDay(request.Msg.GetEndDate().AsTime())
 
@@ -414,6 +598,8 @@ func (c *CellarServer) createAdventCalendar(ctx context.Context, cellarID uint64
 	beerMap := make(map[uint64]struct{}, days)
 
 	for day := startDate; !day.After(endDate); day = day.AddDate(0, 0, 1) {
+		normalizeFilterTags(c.config.Tags, filters[index])
+
 		recommendation, err := c.uniqueRecommendation(ctx, cellarID, filters[index], beerMap)
 		if err != nil {
 			return nil, nil, err
@@ -421,11 +607,20 @@ func (c *CellarServer) createAdventCalendar(ctx context.Context, cellarID uint64
 
 		beerMap[recommendation.GetCellarEntryId()] = struct{}{}
 
+		filter := grpc.CellarFilterToModel(filters[index])
+		if len(filter.Tags) > 0 {
+			filter.Tags = tagsFromNames(ctx, c.beerRepository, c.logger, c.config.Tags, filters[index].GetTags())
+		}
+
+		if len(filter.BeerTags) > 0 {
+			filter.BeerTags = tagsFromNames(ctx, c.beerRepository, c.logger, c.config.Tags, filters[index].GetBeerTags())
+		}
+
 		beer := model.AdventCalendarBeer{
 			CellarEntryID: uint(recommendation.GetCellarEntryId()),
 			Day:           day,
 			Revealed:      false,
-			Filter:        grpc.CellarFilterToModel(filters[index]),
+			Filter:        filter,
 		}
 
 		pbBeer := api.AdventCalendarBeer{
@@ -446,7 +641,12 @@ func (c *CellarServer) createAdventCalendar(ctx context.Context, cellarID uint64
 func (c *CellarServer) uniqueRecommendation(ctx context.Context, cellarID uint64, filter *api.CellarFilter, beerMap map[uint64]struct{}) (*api.CellarBeer, error) {
 	var result *api.CellarBeer
 
-	candidates, err := c.cellarRepository.FindBeerRecommendations(ctx, cellarID, filter)
+	rater, err := raterID(ctx, filter.GetRatingSource())
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := c.cellarRepository.FindBeerRecommendations(ctx, cellarID, filter, rater)
 	if err != nil {
 		return nil, err
 	}
@@ -478,10 +678,12 @@ func truncateToDay(t time.Time) time.Time {
 }
 
 func (c *CellarServer) GetAdventCalendar(ctx context.Context, request *connect.Request[api.GetAdventCalendarRequest]) (*connect.Response[api.GetAdventCalendarResponse], error) {
-	var (
-		calendar *model.AdventCalendar
-		err      error
-	)
+	_, err := c.authorizeCellar(ctx, uint(request.Msg.GetCellarId()), model.CellarRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	var calendar *model.AdventCalendar
 
 	switch requestType := request.Msg.GetCriteria().(type) {
 	case *api.GetAdventCalendarRequest_Id:
@@ -514,9 +716,14 @@ func (c *CellarServer) GetAdventCalendar(ctx context.Context, request *connect.R
 }
 
 func (c *CellarServer) UpdateAdventCalendar(ctx context.Context, request *connect.Request[api.UpdateAdventCalendarRequest]) (*connect.Response[api.UpdateAdventCalendarResponse], error) {
+	_, err := c.authorizeCellar(ctx, uint(request.Msg.GetCellarId()), model.CellarRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
 	day := truncateToDay(request.Msg.GetRevealDay().AsTime())
 
-	err := c.cellarRepository.UpdateAdventCalendar(ctx, request.Msg.GetCellarId(), request.Msg.GetId(), day)
+	err = c.cellarRepository.UpdateAdventCalendar(ctx, request.Msg.GetCellarId(), request.Msg.GetId(), day)
 	if err != nil {
 		return nil, err
 	}
@@ -525,7 +732,12 @@ func (c *CellarServer) UpdateAdventCalendar(ctx context.Context, request *connec
 }
 
 func (c *CellarServer) DeleteAdventCalendar(ctx context.Context, request *connect.Request[api.DeleteAdventCalendarRequest]) (*connect.Response[api.DeleteAdventCalendarResponse], error) {
-	err := c.cellarRepository.DeleteAdventCalendar(ctx, request.Msg.GetCellarId(), request.Msg.GetId())
+	_, err := c.authorizeCellar(ctx, uint(request.Msg.GetCellarId()), model.CellarRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.cellarRepository.DeleteAdventCalendar(ctx, request.Msg.GetCellarId(), request.Msg.GetId())
 	if err != nil {
 		return nil, err
 	}
@@ -534,13 +746,18 @@ func (c *CellarServer) DeleteAdventCalendar(ctx context.Context, request *connec
 }
 
 func (c *CellarServer) RegenerateAdventCalendarDay(ctx context.Context, request *connect.Request[api.RegenerateAdventCalendarDayRequest]) (*connect.Response[api.RegenerateAdventCalendarDayResponse], error) {
+	_, err := c.authorizeCellar(ctx, uint(request.Msg.GetCellarId()), model.CellarRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
 	adventCalendar, err := c.cellarRepository.GetAdventCalendarByID(ctx, request.Msg.GetCellarId(), request.Msg.GetAdventCalendarId())
 	if err != nil {
 		return nil, err
 	}
 
 	if request.Msg.Day == nil {
-		return nil, fmt.Errorf("%w: day must be set", ErrInvalidInput)
+		return nil, invalidField("day", "day must be set")
 	}
 
 	day := truncateToDay(request.Msg.GetDay().AsTime())
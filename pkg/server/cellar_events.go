@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bufbuild/connect-go"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/events"
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/server/grpc"
+	api "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+)
+
+var ErrWatcherBehind = errors.New("fell too far behind the changes to the cellar")
+
+// cellarEvents delivers changes to cellars to the clients watching them.
+type cellarEvents interface {
+	Publish(ctx context.Context, event events.CellarEvent)
+	Subscribe(cellarID uint) (<-chan events.CellarEvent, func())
+}
+
+// WatchCellar streams the changes to the entries of a cellar until the client goes away, or until the user can no
+// longer view the cellar.
+func (c *CellarServer) WatchCellar(ctx context.Context, request *connect.Request[api.WatchCellarRequest], stream *connect.ServerStream[api.WatchCellarResponse]) error {
+	cellarID := uint(request.Msg.GetCellarId())
+
+	_, err := c.authorizeCellar(ctx, cellarID, model.CellarRoleViewer)
+	if err != nil {
+		return err
+	}
+
+	cellarEvents, stop := c.events.Subscribe(cellarID)
+	defer stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, open := <-cellarEvents:
+			if !open {
+				return fmt.Errorf("%w: cellar %d", ErrWatcherBehind, cellarID)
+			}
+
+			err = c.sendCellarEvent(ctx, stream, event)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// sendCellarEvent sends the event along with the entry it is about. Events for entries deleted since are skipped, the
+// client gets the deletion next. The user's role is checked again for every event, so that watchers removed from the
+// cellar, or whose invitation was revoked, stop getting its changes.
+func (c *CellarServer) sendCellarEvent(ctx context.Context, stream *connect.ServerStream[api.WatchCellarResponse], event events.CellarEvent) error {
+	_, err := c.authorizeCellar(ctx, event.CellarID, model.CellarRoleViewer)
+	if err != nil {
+		return err
+	}
+
+	response := &api.WatchCellarResponse{
+		Type:             cellarEventType(event.Type),
+		CellarEntryId:    uint64(event.EntryID),
+		PreviousQuantity: event.PreviousQuantity,
+	}
+
+	if event.Type != events.CellarEntryDeleted {
+		entry, err := c.cellarRepository.GetCellarEntryByID(ctx, event.EntryID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.logger.Debug("skipping event for deleted cellar entry", zap.Uint("id", event.EntryID))
+
+				return nil
+			}
+
+			return err
+		}
+
+		response.Entry = grpc.CellarBeerFromModel(entry)
+	}
+
+	return stream.Send(response)
+}
+
+func (c *CellarServer) publishEntryEvent(ctx context.Context, eventType events.CellarEventType, entry *model.CellarEntry, previousQuantity int64) {
+	c.events.Publish(ctx, events.CellarEvent{
+		Type:             eventType,
+		CellarID:         entry.CellarID,
+		EntryID:          entry.ID,
+		PreviousQuantity: previousQuantity,
+	})
+}
+
+func cellarEventType(eventType events.CellarEventType) api.CellarEventType {
+	switch eventType {
+	case events.CellarEntryAdded:
+		return api.CellarEventType_CELLAR_EVENT_TYPE_ENTRY_ADDED
+	case events.CellarEntryUpdated:
+		return api.CellarEventType_CELLAR_EVENT_TYPE_ENTRY_UPDATED
+	case events.CellarEntryDeleted:
+		return api.CellarEventType_CELLAR_EVENT_TYPE_ENTRY_DELETED
+	case events.CellarEntryQuantityChanged:
+		return api.CellarEventType_CELLAR_EVENT_TYPE_QUANTITY_CHANGED
+	}
+
+	return api.CellarEventType_CELLAR_EVENT_TYPE_UNSPECIFIED
+}
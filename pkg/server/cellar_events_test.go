@@ -0,0 +1,203 @@
+package server_test
+
+import (
+	"context"
+	"net/http/httptest"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/stretchr/testify/mock"
+	"go.openly.dev/pointy"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/configs"
+	"droscher.com/BeerGargoyle/pkg/auth"
+	"droscher.com/BeerGargoyle/pkg/events"
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/server"
+	apiv1 "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+	"droscher.com/BeerGargoyle/pkg/server/grpc/api/v1/apiv1connect"
+)
+
+// queuedEvents hands watchers the events queued up front, then closes the stream as if the watcher fell behind.
+type queuedEvents struct {
+	events []events.CellarEvent
+}
+
+func (q *queuedEvents) Publish(context.Context, events.CellarEvent) {}
+
+func (q *queuedEvents) Subscribe(uint) (<-chan events.CellarEvent, func()) {
+	queue := make(chan events.CellarEvent, len(q.events))
+	for _, event := range q.events {
+		queue <- event
+	}
+
+	close(queue)
+
+	return queue, func() {}
+}
+
+// userInterceptor authenticates every call as the user, standing in for the auth interceptor.
+type userInterceptor struct {
+	user *model.User
+}
+
+func (u userInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, request connect.AnyRequest) (connect.AnyResponse, error) {
+		return next(context.WithValue(ctx, auth.UserKey{}, u.user), request)
+	}
+}
+
+func (u userInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (u userInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		return next(context.WithValue(ctx, auth.UserKey{}, u.user), conn)
+	}
+}
+
+func (suite *CellarTestSuite) TestUpdateBeer_PublishesQuantityChange() {
+	ctx := suite.userContext()
+	suite.expectCellarEntryRole(ctx, 10, model.CellarRoleEditor)
+	suite.cellarRepo.EXPECT().GetCellarEntryByID(ctx, uint(10)).
+		Return(&model.CellarEntry{Model: gorm.Model{ID: 10}, CellarID: 1, Quantity: 3}, nil)
+	suite.cellarRepo.EXPECT().UpdateCellarEntry(ctx, mock.Anything).
+		Return(&model.CellarEntry{Model: gorm.Model{ID: 10}, CellarID: 1, Quantity: 2}, nil)
+
+	cellarEvents, stop := suite.events.Subscribe(1)
+	defer stop()
+
+	request := &apiv1.UpdateBeerRequest{CellarEntryId: 10, Quantity: pointy.Int64(2)}
+	_, err := suite.service.UpdateBeer(ctx, connect.NewRequest(request))
+	suite.Require().NoError(err)
+
+	suite.Require().Len(cellarEvents, 1)
+	suite.Equal(events.CellarEvent{Type: events.CellarEntryQuantityChanged, CellarID: 1, EntryID: 10, PreviousQuantity: 3}, <-cellarEvents)
+}
+
+func (suite *CellarTestSuite) TestUpdateBeer_PublishesUpdate() {
+	ctx := suite.userContext()
+	suite.expectCellarEntryRole(ctx, 10, model.CellarRoleEditor)
+	suite.cellarRepo.EXPECT().GetCellarEntryByID(ctx, uint(10)).
+		Return(&model.CellarEntry{Model: gorm.Model{ID: 10}, CellarID: 1, Quantity: 3}, nil)
+	suite.cellarRepo.EXPECT().UpdateCellarEntry(ctx, mock.Anything).
+		Return(&model.CellarEntry{Model: gorm.Model{ID: 10}, CellarID: 1, Quantity: 3, Special: true}, nil)
+
+	cellarEvents, stop := suite.events.Subscribe(1)
+	defer stop()
+
+	request := &apiv1.UpdateBeerRequest{CellarEntryId: 10, Special: pointy.Bool(true)}
+	_, err := suite.service.UpdateBeer(ctx, connect.NewRequest(request))
+	suite.Require().NoError(err)
+
+	suite.Require().Len(cellarEvents, 1)
+	suite.Equal(events.CellarEvent{Type: events.CellarEntryUpdated, CellarID: 1, EntryID: 10}, <-cellarEvents)
+}
+
+func (suite *CellarTestSuite) TestUpdateBeer_PublishesDeletion() {
+	ctx := suite.userContext()
+	suite.expectCellarEntryRole(ctx, 10, model.CellarRoleEditor)
+	suite.cellarRepo.EXPECT().GetCellarEntryByID(ctx, uint(10)).
+		Return(&model.CellarEntry{Model: gorm.Model{ID: 10}, CellarID: 1, Quantity: 1}, nil)
+	suite.cellarRepo.EXPECT().DeleteCellarEntry(ctx, uint(10)).Return(nil)
+
+	cellarEvents, stop := suite.events.Subscribe(1)
+	defer stop()
+
+	request := &apiv1.UpdateBeerRequest{CellarEntryId: 10, Quantity: pointy.Int64(0)}
+	_, err := suite.service.UpdateBeer(ctx, connect.NewRequest(request))
+	suite.Require().NoError(err)
+
+	suite.Require().Len(cellarEvents, 1)
+	suite.Equal(events.CellarEvent{Type: events.CellarEntryDeleted, CellarID: 1, EntryID: 10}, <-cellarEvents)
+}
+
+func (suite *CellarTestSuite) TestWatchCellar_StreamsEvents() {
+	user := &model.User{Model: gorm.Model{ID: 1}}
+	queue := &queuedEvents{events: []events.CellarEvent{
+		{Type: events.CellarEntryAdded, CellarID: 1, EntryID: 10},
+		{Type: events.CellarEntryUpdated, CellarID: 1, EntryID: 11},
+		{Type: events.CellarEntryQuantityChanged, CellarID: 1, EntryID: 12, PreviousQuantity: 4},
+		{Type: events.CellarEntryDeleted, CellarID: 1, EntryID: 10},
+	}}
+	service := server.NewCellarServer(suite.cellarRepo, nil, suite.userRepo, queue, zaptest.NewLogger(suite.T()), &configs.Config{})
+
+	suite.cellarRepo.EXPECT().GetCellarRole(mock.Anything, uint(1), uint(1)).Return(model.CellarRoleViewer, nil)
+	suite.cellarRepo.EXPECT().GetCellarEntryByID(mock.Anything, uint(10)).
+		Return(&model.CellarEntry{Model: gorm.Model{ID: 10}, CellarID: 1, Quantity: 1}, nil)
+	// The updated entry was deleted before its event was sent, so its event is skipped.
+	suite.cellarRepo.EXPECT().GetCellarEntryByID(mock.Anything, uint(11)).Return(nil, gorm.ErrRecordNotFound)
+	suite.cellarRepo.EXPECT().GetCellarEntryByID(mock.Anything, uint(12)).
+		Return(&model.CellarEntry{Model: gorm.Model{ID: 12}, CellarID: 1, Quantity: 3}, nil)
+
+	_, handler := apiv1connect.NewCellarServiceHandler(service,
+		connect.WithInterceptors(userInterceptor{user: user}, server.NewErrorInterceptor(zaptest.NewLogger(suite.T()))))
+	httpServer := httptest.NewServer(handler)
+	defer httpServer.Close()
+
+	client := apiv1connect.NewCellarServiceClient(httpServer.Client(), httpServer.URL)
+
+	stream, err := client.WatchCellar(context.Background(), connect.NewRequest(&apiv1.WatchCellarRequest{CellarId: 1}))
+	suite.Require().NoError(err)
+
+	var received []*apiv1.WatchCellarResponse
+	for stream.Receive() {
+		received = append(received, stream.Msg())
+	}
+
+	suite.Equal(connect.CodeAborted, connect.CodeOf(stream.Err()))
+	suite.Require().Len(received, 3)
+	suite.Equal(apiv1.CellarEventType_CELLAR_EVENT_TYPE_ENTRY_ADDED, received[0].GetType())
+	suite.Equal(uint64(10), received[0].GetEntry().GetCellarEntryId())
+	suite.Equal(apiv1.CellarEventType_CELLAR_EVENT_TYPE_QUANTITY_CHANGED, received[1].GetType())
+	suite.Equal(int64(3), received[1].GetEntry().GetQuantity())
+	suite.Equal(int64(4), received[1].GetPreviousQuantity())
+	suite.Equal(apiv1.CellarEventType_CELLAR_EVENT_TYPE_ENTRY_DELETED, received[2].GetType())
+	suite.Equal(uint64(10), received[2].GetCellarEntryId())
+	suite.Nil(received[2].GetEntry())
+}
+
+func (suite *CellarTestSuite) TestWatchCellar_StopsWhenAccessRemoved() {
+	user := &model.User{Model: gorm.Model{ID: 1}}
+	queue := &queuedEvents{events: []events.CellarEvent{
+		{Type: events.CellarEntryAdded, CellarID: 1, EntryID: 10},
+		{Type: events.CellarEntryAdded, CellarID: 1, EntryID: 11},
+	}}
+	service := server.NewCellarServer(suite.cellarRepo, nil, suite.userRepo, queue, zaptest.NewLogger(suite.T()), &configs.Config{})
+
+	// The user is removed from the cellar after the first event is sent.
+	suite.cellarRepo.EXPECT().GetCellarRole(mock.Anything, uint(1), uint(1)).Return(model.CellarRoleViewer, nil).Twice()
+	suite.cellarRepo.EXPECT().GetCellarRole(mock.Anything, uint(1), uint(1)).Return(model.CellarRoleNone, nil)
+	suite.cellarRepo.EXPECT().GetCellarEntryByID(mock.Anything, uint(10)).
+		Return(&model.CellarEntry{Model: gorm.Model{ID: 10}, CellarID: 1, Quantity: 1}, nil)
+
+	_, handler := apiv1connect.NewCellarServiceHandler(service,
+		connect.WithInterceptors(userInterceptor{user: user}, server.NewErrorInterceptor(zaptest.NewLogger(suite.T()))))
+	httpServer := httptest.NewServer(handler)
+	defer httpServer.Close()
+
+	client := apiv1connect.NewCellarServiceClient(httpServer.Client(), httpServer.URL)
+
+	stream, err := client.WatchCellar(context.Background(), connect.NewRequest(&apiv1.WatchCellarRequest{CellarId: 1}))
+	suite.Require().NoError(err)
+
+	var received []*apiv1.WatchCellarResponse
+	for stream.Receive() {
+		received = append(received, stream.Msg())
+	}
+
+	suite.Equal(connect.CodePermissionDenied, connect.CodeOf(stream.Err()))
+	suite.Require().Len(received, 1)
+	suite.Equal(uint64(10), received[0].GetCellarEntryId())
+}
+
+func (suite *CellarTestSuite) TestWatchCellar_RequiresAccess() {
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleNone)
+
+	err := suite.service.WatchCellar(ctx, connect.NewRequest(&apiv1.WatchCellarRequest{CellarId: 1}), nil)
+
+	suite.Require().ErrorIs(err, server.ErrPermissionDenied)
+}
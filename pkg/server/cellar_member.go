@@ -0,0 +1,208 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/google/uuid"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+	"droscher.com/BeerGargoyle/pkg/server/grpc"
+	api "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+)
+
+func (c *CellarServer) ListCellarMembers(ctx context.Context, request *connect.Request[api.ListCellarMembersRequest]) (*connect.Response[api.ListCellarMembersResponse], error) {
+	_, err := c.authorizeCellar(ctx, uint(request.Msg.GetCellarId()), model.CellarRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := c.cellarRepository.GetCellarMembers(ctx, uint(request.Msg.GetCellarId()))
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.ListCellarMembersResponse{Members: grpc.CellarMembersFromModel(members)}
+
+	return connect.NewResponse(&response), nil
+}
+
+func (c *CellarServer) InviteCellarMember(ctx context.Context, request *connect.Request[api.InviteCellarMemberRequest]) (*connect.Response[api.InviteCellarMemberResponse], error) {
+	cellarID := uint(request.Msg.GetCellarId())
+
+	_, err := c.authorizeCellar(ctx, cellarID, model.CellarRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	role := grpc.CellarRoleToModel(request.Msg.GetRole())
+	if !role.Valid() {
+		return nil, invalidField("role", "a role is required")
+	}
+
+	inviter, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	invitee, err := c.userRepository.GetUserFromEmail(ctx, request.Msg.GetEmail())
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrUserNotFound, request.Msg.GetEmail())
+		}
+
+		return nil, err
+	}
+
+	cellar, err := c.cellarRepository.GetCellarByID(ctx, cellarID)
+	if err != nil {
+		return nil, err
+	}
+
+	if cellar.OwnerID == invitee.ID {
+		return nil, fmt.Errorf("%w: user already owns the cellar", ErrInvalidInput)
+	}
+
+	member, err := c.cellarRepository.AddCellarMember(ctx, model.CellarMember{
+		CellarID:    cellarID,
+		UserID:      invitee.ID,
+		Role:        role,
+		InvitedByID: inviter.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	member.User = *invitee
+
+	response := api.InviteCellarMemberResponse{Member: grpc.CellarMemberFromModel(member)}
+
+	return connect.NewResponse(&response), nil
+}
+
+func (c *CellarServer) ListCellarInvitations(ctx context.Context, _ *connect.Request[api.ListCellarInvitationsRequest]) (*connect.Response[api.ListCellarInvitationsResponse], error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cellars, err := c.cellarRepository.GetCellarInvitationsForUser(ctx, *user)
+	if err != nil {
+		return nil, err
+	}
+
+	pbCellars := grpc.CellarsFromModel(cellars)
+	for index, cellar := range cellars {
+		if len(cellar.Members) > 0 {
+			pbCellars[index].Role = grpc.CellarRoleFromModel(cellar.Members[0].Role)
+		}
+	}
+
+	response := api.ListCellarInvitationsResponse{Cellars: pbCellars}
+
+	return connect.NewResponse(&response), nil
+}
+
+func (c *CellarServer) AcceptCellarInvitation(ctx context.Context, request *connect.Request[api.AcceptCellarInvitationRequest]) (*connect.Response[api.AcceptCellarInvitationResponse], error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cellarID := uint(request.Msg.GetCellarId())
+
+	err = c.cellarRepository.AcceptCellarInvitation(ctx, cellarID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := c.authorizeCellar(ctx, cellarID, model.CellarRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	cellar, err := c.cellarRepository.GetCellarByID(ctx, cellarID)
+	if err != nil {
+		return nil, err
+	}
+
+	pbCellar := grpc.CellarFromModel(cellar)
+	pbCellar.Role = grpc.CellarRoleFromModel(role)
+
+	response := api.AcceptCellarInvitationResponse{Cellar: pbCellar}
+
+	return connect.NewResponse(&response), nil
+}
+
+func (c *CellarServer) UpdateCellarMemberRole(ctx context.Context, request *connect.Request[api.UpdateCellarMemberRoleRequest]) (*connect.Response[api.UpdateCellarMemberRoleResponse], error) {
+	cellarID := uint(request.Msg.GetCellarId())
+
+	_, err := c.authorizeCellar(ctx, cellarID, model.CellarRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	role := grpc.CellarRoleToModel(request.Msg.GetRole())
+	if !role.Valid() {
+		return nil, invalidField("role", "a role is required")
+	}
+
+	member, err := c.memberFromUUID(ctx, request.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.cellarRepository.UpdateCellarMemberRole(ctx, cellarID, member.ID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&api.UpdateCellarMemberRoleResponse{}), nil
+}
+
+// RemoveCellarMember removes a member from a cellar, or declines an invitation. Owners can remove anyone, while other
+// members can only remove themselves.
+func (c *CellarServer) RemoveCellarMember(ctx context.Context, request *connect.Request[api.RemoveCellarMemberRequest]) (*connect.Response[api.RemoveCellarMemberResponse], error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cellarID := uint(request.Msg.GetCellarId())
+
+	member, err := c.memberFromUUID(ctx, request.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	if member.ID != user.ID {
+		_, err = c.authorizeCellar(ctx, cellarID, model.CellarRoleOwner)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = c.cellarRepository.RemoveCellarMember(ctx, cellarID, member.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&api.RemoveCellarMemberResponse{}), nil
+}
+
+func (c *CellarServer) memberFromUUID(ctx context.Context, userID string) (*model.User, error) {
+	memberUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", invalidField("user_id", "invalid user id"), err)
+	}
+
+	member, err := c.userRepository.GetUserByUUID(ctx, memberUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/bufbuild/connect-go"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"go.openly.dev/pointy"
@@ -14,9 +15,12 @@ import (
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"gorm.io/gorm"
 
+	"droscher.com/BeerGargoyle/configs"
 	"droscher.com/BeerGargoyle/mocks"
 	"droscher.com/BeerGargoyle/pkg/auth"
+	"droscher.com/BeerGargoyle/pkg/events"
 	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
 	"droscher.com/BeerGargoyle/pkg/server"
 	apiv1 "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
 )
@@ -24,20 +28,68 @@ import (
 type CellarTestSuite struct {
 	suite.Suite
 	cellarRepo   *mocks.CellarRepository
+	beerRepo     *mocks.BeerRepository
+	userRepo     *stubUserRepository
 	service      *server.CellarServer
+	events       *events.Broker
 	observedLogs *observer.ObservedLogs
 }
 
+type stubUserRepository struct {
+	users []*model.User
+}
+
+func (s *stubUserRepository) GetUserByUUID(_ context.Context, id uuid.UUID) (*model.User, error) {
+	for _, user := range s.users {
+		if user.UUID == id {
+			return user, nil
+		}
+	}
+
+	return nil, repository.ErrUserNotFound
+}
+
+func (s *stubUserRepository) GetUserFromEmail(_ context.Context, email string) (*model.User, error) {
+	for _, user := range s.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+
+	return nil, repository.ErrUserNotFound
+}
+
 func TestCellarTestSuite(t *testing.T) {
 	suite.Run(t, new(CellarTestSuite))
 }
 
 func (suite *CellarTestSuite) SetupTest() {
 	suite.cellarRepo = mocks.NewCellarRepository(suite.T())
+	suite.beerRepo = mocks.NewBeerRepository(suite.T())
 	observedZapCore, observedLogs := observer.New(zap.InfoLevel)
 	suite.observedLogs = observedLogs
 	observedLogger := zap.New(observedZapCore)
-	suite.service = server.NewCellarServer(suite.cellarRepo, nil, nil, observedLogger)
+	suite.userRepo = &stubUserRepository{users: []*model.User{
+		{Model: gorm.Model{ID: 2}, UUID: uuid.MustParse("0b7e1c52-2f0a-4c49-8d3c-62f1a9b6c0de"), Username: "friend", Email: "friend@example.com"},
+	}}
+	suite.events = events.NewBroker(nil, 0, observedLogger)
+	suite.service = server.NewCellarServer(suite.cellarRepo, suite.beerRepo, suite.userRepo, suite.events, observedLogger, &configs.Config{})
+}
+
+func (suite *CellarTestSuite) userContext() context.Context {
+	return context.WithValue(context.Background(), auth.UserKey{}, &model.User{
+		Model:    gorm.Model{ID: 1},
+		UUID:     uuid.MustParse("6f1c2a8e-4c1b-4a43-9d0e-1f6d4f8f2b11"),
+		Username: "testuser",
+	})
+}
+
+func (suite *CellarTestSuite) expectCellarRole(ctx context.Context, cellarID uint, role model.CellarRole) {
+	suite.cellarRepo.EXPECT().GetCellarRole(ctx, cellarID, uint(1)).Return(role, nil)
+}
+
+func (suite *CellarTestSuite) expectCellarEntryRole(ctx context.Context, cellarEntryID uint, role model.CellarRole) {
+	suite.cellarRepo.EXPECT().GetCellarEntryRole(ctx, cellarEntryID, uint(1)).Return(role, nil)
 }
 
 func (suite *CellarTestSuite) TestCreateAdventCalendar_ErrorMissingFilters() {
@@ -49,7 +101,10 @@ func (suite *CellarTestSuite) TestCreateAdventCalendar_ErrorMissingFilters() {
 		EndDate:     timestamppb.New(time.Now().AddDate(0, 0, 1)),
 		Filters:     nil,
 	}
-	adventCalendar, err := suite.service.CreateAdventCalendar(context.Background(), &connect.Request[apiv1.CreateAdventCalendarRequest]{Msg: request})
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleOwner)
+
+	adventCalendar, err := suite.service.CreateAdventCalendar(ctx, &connect.Request[apiv1.CreateAdventCalendarRequest]{Msg: request})
 	suite.Require().ErrorIs(err, server.ErrInvalidInput)
 	suite.Require().ErrorContains(err, "there must be a filter for each day in the calendar")
 	suite.Nil(adventCalendar)
@@ -66,9 +121,10 @@ func (suite *CellarTestSuite) TestCreateAdventCalendar_NoCandidatesError() {
 		EndDate:     timestamppb.New(time.Now().AddDate(0, 0, 1)),
 		Filters:     []*apiv1.CellarFilter{filter1, filter2},
 	}
-	ctx := context.Background()
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleOwner)
 
-	suite.cellarRepo.EXPECT().FindBeerRecommendations(ctx, uint64(1), filter1).Return(nil, nil)
+	suite.cellarRepo.EXPECT().FindBeerRecommendations(ctx, uint64(1), filter1, uint(0)).Return(nil, nil)
 
 	adventCalendar, err := suite.service.CreateAdventCalendar(ctx, &connect.Request[apiv1.CreateAdventCalendarRequest]{Msg: request})
 	suite.Require().ErrorIs(err, server.ErrCannotCreate)
@@ -87,11 +143,12 @@ func (suite *CellarTestSuite) TestCreateAdventCalendar_NoUniqueEntriesError() {
 		EndDate:     timestamppb.New(time.Now().AddDate(0, 0, 1)),
 		Filters:     []*apiv1.CellarFilter{filter1, filter2},
 	}
-	ctx := context.Background()
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleOwner)
 	cellarEntry := &model.CellarEntry{Model: gorm.Model{ID: 1}, CellarID: 1}
 
-	suite.cellarRepo.EXPECT().FindBeerRecommendations(ctx, uint64(1), filter1).Return([]*model.CellarEntry{cellarEntry}, nil)
-	suite.cellarRepo.EXPECT().FindBeerRecommendations(ctx, uint64(1), filter2).Return([]*model.CellarEntry{cellarEntry}, nil)
+	suite.cellarRepo.EXPECT().FindBeerRecommendations(ctx, uint64(1), filter1, uint(0)).Return([]*model.CellarEntry{cellarEntry}, nil)
+	suite.cellarRepo.EXPECT().FindBeerRecommendations(ctx, uint64(1), filter2, uint(0)).Return([]*model.CellarEntry{cellarEntry}, nil)
 
 	adventCalendar, err := suite.service.CreateAdventCalendar(ctx, &connect.Request[apiv1.CreateAdventCalendarRequest]{Msg: request})
 	suite.Require().ErrorIs(err, server.ErrCannotCreate)
@@ -110,12 +167,13 @@ func (suite *CellarTestSuite) TestCreateAdventCalendar_Success() {
 		EndDate:     timestamppb.New(time.Now().AddDate(0, 0, 1)),
 		Filters:     []*apiv1.CellarFilter{filter1, filter2},
 	}
-	ctx := context.Background()
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleOwner)
 	cellarEntry1 := &model.CellarEntry{Model: gorm.Model{ID: 1}, CellarID: 1}
 	cellarEntry2 := &model.CellarEntry{Model: gorm.Model{ID: 2}, CellarID: 1}
 
-	suite.cellarRepo.EXPECT().FindBeerRecommendations(ctx, uint64(1), filter1).Return([]*model.CellarEntry{cellarEntry1}, nil)
-	suite.cellarRepo.EXPECT().FindBeerRecommendations(ctx, uint64(1), filter2).Return([]*model.CellarEntry{cellarEntry1, cellarEntry2}, nil)
+	suite.cellarRepo.EXPECT().FindBeerRecommendations(ctx, uint64(1), filter1, uint(0)).Return([]*model.CellarEntry{cellarEntry1}, nil)
+	suite.cellarRepo.EXPECT().FindBeerRecommendations(ctx, uint64(1), filter2, uint(0)).Return([]*model.CellarEntry{cellarEntry1, cellarEntry2}, nil)
 	suite.cellarRepo.EXPECT().SaveAdventCalendar(ctx, mock.Anything).Return(&model.AdventCalendar{Model: gorm.Model{ID: 10}}, nil)
 	result, err := suite.service.CreateAdventCalendar(ctx, &connect.Request[apiv1.CreateAdventCalendarRequest]{Msg: request})
 	suite.Require().NoError(err)
@@ -142,8 +200,9 @@ func (suite *CellarTestSuite) TestGetCellarList_Success() {
 		{
 			Model:       gorm.Model{ID: 2},
 			Name:        "Cellar 2",
-			Description: "Second cellar",
-			OwnerID:     1,
+			Description: "Shared cellar",
+			OwnerID:     2,
+			Members:     []model.CellarMember{{UserID: 1, Role: model.CellarRoleViewer, AcceptedAt: pointy.Pointer(time.Now())}},
 		},
 	}
 
@@ -154,6 +213,8 @@ func (suite *CellarTestSuite) TestGetCellarList_Success() {
 	suite.Require().NoError(err)
 	suite.NotNil(result)
 	suite.Len(result.Msg.GetCellars(), 2)
+	suite.Equal(apiv1.CellarRole_CELLAR_ROLE_OWNER, result.Msg.GetCellars()[0].GetRole())
+	suite.Equal(apiv1.CellarRole_CELLAR_ROLE_VIEWER, result.Msg.GetCellars()[1].GetRole())
 }
 
 func (suite *CellarTestSuite) TestGetCellarList_NoUserInContext() {
@@ -161,13 +222,14 @@ func (suite *CellarTestSuite) TestGetCellarList_NoUserInContext() {
 
 	result, err := suite.service.GetCellarList(ctx, &connect.Request[apiv1.GetCellarListRequest]{})
 
-	suite.Require().ErrorIs(err, server.ErrInvalidInput)
+	suite.Require().ErrorIs(err, server.ErrUnauthenticated)
 	suite.Nil(result)
 	suite.ErrorContains(err, "no user in context")
 }
 
 func (suite *CellarTestSuite) TestGetCellar_Success() {
-	ctx := context.Background()
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleOwner)
 	expectedCellar := &model.Cellar{
 		Model:       gorm.Model{ID: 1},
 		Name:        "Test Cellar",
@@ -184,10 +246,12 @@ func (suite *CellarTestSuite) TestGetCellar_Success() {
 	suite.NotNil(result)
 	cellar := result.Msg.GetCellar()
 	suite.Equal("Test Cellar", cellar.GetName())
+	suite.Equal(apiv1.CellarRole_CELLAR_ROLE_OWNER, cellar.GetRole())
 }
 
 func (suite *CellarTestSuite) TestGetCellar_NotFound() {
-	ctx := context.Background()
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 999, model.CellarRoleOwner)
 
 	suite.cellarRepo.EXPECT().GetCellarByID(ctx, uint(999)).Return(nil, gorm.ErrRecordNotFound)
 
@@ -199,7 +263,8 @@ func (suite *CellarTestSuite) TestGetCellar_NotFound() {
 }
 
 func (suite *CellarTestSuite) TestGetCellarEntry_Success() {
-	ctx := context.Background()
+	ctx := suite.userContext()
+	suite.expectCellarEntryRole(ctx, 10, model.CellarRoleOwner)
 	expectedEntry := &model.CellarEntry{
 		Model:    gorm.Model{ID: 10},
 		CellarID: 1,
@@ -219,7 +284,8 @@ func (suite *CellarTestSuite) TestGetCellarEntry_Success() {
 }
 
 func (suite *CellarTestSuite) TestGetCellarStats_Success() {
-	ctx := context.Background()
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleOwner)
 	expectedStats := &model.CellarStats{
 		CellarID:      1,
 		BeerCount:     10,
@@ -232,7 +298,7 @@ func (suite *CellarTestSuite) TestGetCellarStats_Success() {
 		AverageRating: 4.2,
 	}
 
-	suite.cellarRepo.EXPECT().GetCellarStats(ctx, uint(1)).Return(expectedStats, nil)
+	suite.cellarRepo.EXPECT().GetCellarStats(ctx, uint(1), uint(0)).Return(expectedStats, nil)
 
 	request := &apiv1.GetCellarStatsRequest{CellarId: 1}
 	result, err := suite.service.GetCellarStats(ctx, &connect.Request[apiv1.GetCellarStatsRequest]{Msg: request})
@@ -246,13 +312,15 @@ func (suite *CellarTestSuite) TestGetCellarStats_Success() {
 }
 
 func (suite *CellarTestSuite) TestListCellarBeers_Success() {
-	ctx := context.Background()
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleOwner)
 	expectedBeers := []*model.CellarEntry{
 		{Model: gorm.Model{ID: 1}, CellarID: 1, BeerID: 100, Quantity: 2},
 		{Model: gorm.Model{ID: 2}, CellarID: 1, BeerID: 200, Quantity: 1},
 	}
 
-	suite.cellarRepo.EXPECT().GetCellarBeers(ctx, uint(1)).Return(expectedBeers, nil)
+	suite.cellarRepo.EXPECT().ListCellarBeers(ctx, uint(1), (*apiv1.CellarFilter)(nil), uint(0), repository.CellarSort{Field: repository.CellarSortName}, repository.Page{}).
+		Return(expectedBeers, "", 2, nil)
 
 	request := &apiv1.ListCellarBeersRequest{CellarId: 1}
 	result, err := suite.service.ListCellarBeers(ctx, &connect.Request[apiv1.ListCellarBeersRequest]{Msg: request})
@@ -261,15 +329,45 @@ func (suite *CellarTestSuite) TestListCellarBeers_Success() {
 	suite.NotNil(result)
 	beers := result.Msg.GetBeers()
 	suite.Len(beers, 2)
+	suite.Equal(int64(2), result.Msg.GetTotalCount())
+	suite.Empty(result.Msg.GetNextPageToken())
+}
+
+func (suite *CellarTestSuite) TestListCellarBeers_PassesPageSortAndFilter() {
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleOwner)
+	filter := &apiv1.CellarFilter{Special: pointy.Bool(true)}
+	sort := repository.CellarSort{Field: repository.CellarSortDrinkBefore, Descending: true}
+
+	suite.cellarRepo.EXPECT().ListCellarBeers(ctx, uint(1), filter, uint(0), sort, repository.Page{Size: 10, Token: "abc"}).
+		Return([]*model.CellarEntry{{Model: gorm.Model{ID: 3}, CellarID: 1, BeerID: 300, Quantity: 1}}, "def", 25, nil)
+
+	request := &apiv1.ListCellarBeersRequest{
+		CellarId:   1,
+		PageSize:   10,
+		PageToken:  "abc",
+		Sort:       apiv1.CellarSortField_CELLAR_SORT_FIELD_DRINK_BEFORE,
+		Descending: true,
+		Filter:     filter,
+	}
+	result, err := suite.service.ListCellarBeers(ctx, connect.NewRequest(request))
+
+	suite.Require().NoError(err)
+	suite.Len(result.Msg.GetBeers(), 1)
+	suite.Equal("def", result.Msg.GetNextPageToken())
+	suite.Equal(int64(25), result.Msg.GetTotalCount())
 }
 
 func (suite *CellarTestSuite) TestUpdateBeer_DeleteWhenQuantityZero() {
-	ctx := context.Background()
+	ctx := suite.userContext()
+	suite.expectCellarEntryRole(ctx, 10, model.CellarRoleOwner)
 	request := &apiv1.UpdateBeerRequest{
 		CellarEntryId: 10,
 		Quantity:      pointy.Int64(0),
 	}
 
+	suite.cellarRepo.EXPECT().GetCellarEntryByID(ctx, uint(10)).
+		Return(&model.CellarEntry{Model: gorm.Model{ID: 10}, CellarID: 1, Quantity: 1}, nil)
 	suite.cellarRepo.EXPECT().DeleteCellarEntry(ctx, uint(10)).Return(nil)
 
 	result, err := suite.service.UpdateBeer(ctx, &connect.Request[apiv1.UpdateBeerRequest]{Msg: request})
@@ -280,7 +378,8 @@ func (suite *CellarTestSuite) TestUpdateBeer_DeleteWhenQuantityZero() {
 }
 
 func (suite *CellarTestSuite) TestUpdateBeer_Success() {
-	ctx := context.Background()
+	ctx := suite.userContext()
+	suite.expectCellarEntryRole(ctx, 10, model.CellarRoleOwner)
 	existingEntry := &model.CellarEntry{
 		Model:    gorm.Model{ID: 10},
 		CellarID: 1,
@@ -315,8 +414,115 @@ func (suite *CellarTestSuite) TestUpdateBeer_Success() {
 	suite.Equal(uint64(10), beer.GetCellarEntryId())
 }
 
+func (suite *CellarTestSuite) TestUpdateBeer_MovesToLocationInCellar() {
+	ctx := suite.userContext()
+	suite.expectCellarEntryRole(ctx, 10, model.CellarRoleEditor)
+	cellar := model.Cellar{Model: gorm.Model{ID: 1}, Locations: []model.LocationInCellar{{Model: gorm.Model{ID: 3}, CellarID: 1}}}
+
+	suite.cellarRepo.EXPECT().GetCellarEntryByID(ctx, uint(10)).
+		Return(&model.CellarEntry{Model: gorm.Model{ID: 10}, CellarID: 1, Quantity: 1, Cellar: cellar}, nil)
+	suite.cellarRepo.EXPECT().UpdateCellarEntry(ctx, mock.MatchedBy(func(entry *model.CellarEntry) bool {
+		return *entry.LocationID == 3
+	})).Return(&model.CellarEntry{Model: gorm.Model{ID: 10}, CellarID: 1, Quantity: 1}, nil)
+
+	_, err := suite.service.UpdateBeer(ctx, connect.NewRequest(&apiv1.UpdateBeerRequest{CellarEntryId: 10, LocationId: pointy.Uint64(3)}))
+
+	suite.Require().NoError(err)
+}
+
+func (suite *CellarTestSuite) TestUpdateBeer_LocationOfAnotherCellar() {
+	ctx := suite.userContext()
+	suite.expectCellarEntryRole(ctx, 10, model.CellarRoleEditor)
+	cellar := model.Cellar{Model: gorm.Model{ID: 1}, Locations: []model.LocationInCellar{{Model: gorm.Model{ID: 3}, CellarID: 1}}}
+
+	suite.cellarRepo.EXPECT().GetCellarEntryByID(ctx, uint(10)).
+		Return(&model.CellarEntry{Model: gorm.Model{ID: 10}, CellarID: 1, Quantity: 1, Cellar: cellar}, nil)
+
+	_, err := suite.service.UpdateBeer(ctx, connect.NewRequest(&apiv1.UpdateBeerRequest{CellarEntryId: 10, LocationId: pointy.Uint64(8)}))
+
+	suite.Equal(connect.CodeInvalidArgument, server.ErrorCode(err))
+}
+
+func (suite *CellarTestSuite) TestAddCellarBeer_LocationOfAnotherCellar() {
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleEditor)
+	suite.cellarRepo.EXPECT().GetCellarByID(ctx, uint(1)).
+		Return(&model.Cellar{Model: gorm.Model{ID: 1}, Locations: []model.LocationInCellar{{Model: gorm.Model{ID: 3}, CellarID: 1}}}, nil)
+
+	request := &apiv1.AddCellarBeerRequest{CellarId: 1, BeerId: 5, Quantity: 1, LocationId: 8}
+	_, err := suite.service.AddCellarBeer(ctx, connect.NewRequest(request))
+
+	suite.Equal(connect.CodeInvalidArgument, server.ErrorCode(err))
+}
+
+func (suite *CellarTestSuite) TestAddCellarBeer_OwnCustomFormat() {
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleEditor)
+	suite.cellarRepo.EXPECT().GetCellarByID(ctx, uint(1)).Return(&model.Cellar{Model: gorm.Model{ID: 1}}, nil)
+	suite.beerRepo.EXPECT().GetBeerFormat(ctx, uint(40)).
+		Return(&model.BeerFormat{Model: gorm.Model{ID: 40}, Package: "Growler", OwnerID: pointy.Uint(1)}, nil)
+	suite.cellarRepo.EXPECT().AddBeerToCellar(ctx, mock.MatchedBy(func(entry model.CellarEntry) bool {
+		return entry.FormatID != nil && *entry.FormatID == 40
+	})).Return(&model.CellarEntry{Model: gorm.Model{ID: 10}, CellarID: 1, BeerID: 5, Quantity: 1}, nil)
+	suite.cellarRepo.EXPECT().GetCellarEntryByID(ctx, uint(10)).
+		Return(&model.CellarEntry{Model: gorm.Model{ID: 10}, CellarID: 1, BeerID: 5, Quantity: 1}, nil)
+
+	request := &apiv1.AddCellarBeerRequest{CellarId: 1, BeerId: 5, Quantity: 1, FormatId: pointy.Uint64(40)}
+	_, err := suite.service.AddCellarBeer(ctx, connect.NewRequest(request))
+
+	suite.Require().NoError(err)
+}
+
+func (suite *CellarTestSuite) TestAddCellarBeer_OtherUsersCustomFormat() {
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleEditor)
+	suite.cellarRepo.EXPECT().GetCellarByID(ctx, uint(1)).Return(&model.Cellar{Model: gorm.Model{ID: 1}}, nil)
+	suite.beerRepo.EXPECT().GetBeerFormat(ctx, uint(41)).
+		Return(&model.BeerFormat{Model: gorm.Model{ID: 41}, Package: "Growler", OwnerID: pointy.Uint(2)}, nil)
+
+	request := &apiv1.AddCellarBeerRequest{CellarId: 1, BeerId: 5, Quantity: 1, FormatId: pointy.Uint64(41)}
+	_, err := suite.service.AddCellarBeer(ctx, connect.NewRequest(request))
+
+	suite.Equal(connect.CodeInvalidArgument, server.ErrorCode(err))
+}
+
+func (suite *CellarTestSuite) TestUpdateBeer_UnknownFormat() {
+	ctx := suite.userContext()
+	suite.expectCellarEntryRole(ctx, 10, model.CellarRoleEditor)
+	suite.cellarRepo.EXPECT().GetCellarEntryByID(ctx, uint(10)).
+		Return(&model.CellarEntry{Model: gorm.Model{ID: 10}, CellarID: 1, Quantity: 1}, nil)
+	suite.beerRepo.EXPECT().GetBeerFormat(ctx, uint(42)).Return(nil, repository.ErrFormatNotFound)
+
+	_, err := suite.service.UpdateBeer(ctx, connect.NewRequest(&apiv1.UpdateBeerRequest{CellarEntryId: 10, FormatId: pointy.Uint64(42)}))
+
+	suite.Equal(connect.CodeInvalidArgument, server.ErrorCode(err))
+}
+
+func (suite *CellarTestSuite) TestAddCellarBeer_VintageOutOfRange() {
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleEditor)
+	suite.cellarRepo.EXPECT().GetCellarByID(ctx, uint(1)).Return(&model.Cellar{Model: gorm.Model{ID: 1}}, nil)
+
+	request := &apiv1.AddCellarBeerRequest{CellarId: 1, BeerId: 5, Quantity: 1, Vintage: pointy.Uint64(20211)}
+	_, err := suite.service.AddCellarBeer(ctx, connect.NewRequest(request))
+
+	suite.Equal(connect.CodeInvalidArgument, server.ErrorCode(err))
+}
+
+func (suite *CellarTestSuite) TestUpdateBeer_VintageOutOfRange() {
+	ctx := suite.userContext()
+	suite.expectCellarEntryRole(ctx, 10, model.CellarRoleEditor)
+	suite.cellarRepo.EXPECT().GetCellarEntryByID(ctx, uint(10)).
+		Return(&model.CellarEntry{Model: gorm.Model{ID: 10}, CellarID: 1, Quantity: 1}, nil)
+
+	_, err := suite.service.UpdateBeer(ctx, connect.NewRequest(&apiv1.UpdateBeerRequest{CellarEntryId: 10, Vintage: pointy.Uint64(21)}))
+
+	suite.Equal(connect.CodeInvalidArgument, server.ErrorCode(err))
+}
+
 func (suite *CellarTestSuite) TestRecommendBeer_Success() {
-	ctx := context.Background()
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleOwner)
 	filter := &apiv1.CellarFilter{MinimumAbv: pointy.Float64(5.0)}
 	candidates := []*model.CellarEntry{
 		{Model: gorm.Model{ID: 1}, CellarID: 1, BeerID: 100},
@@ -328,7 +534,7 @@ func (suite *CellarTestSuite) TestRecommendBeer_Success() {
 		Filter:   filter,
 	}
 
-	suite.cellarRepo.EXPECT().FindBeerRecommendations(ctx, uint64(1), filter).Return(candidates, nil)
+	suite.cellarRepo.EXPECT().FindBeerRecommendations(ctx, uint64(1), filter, uint(0)).Return(candidates, nil)
 
 	result, err := suite.service.RecommendBeer(ctx, &connect.Request[apiv1.RecommendBeerRequest]{Msg: request})
 
@@ -339,7 +545,8 @@ func (suite *CellarTestSuite) TestRecommendBeer_Success() {
 }
 
 func (suite *CellarTestSuite) TestRecommendBeer_NoCandidates() {
-	ctx := context.Background()
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleOwner)
 	filter := &apiv1.CellarFilter{MinimumAbv: pointy.Float64(20.0)}
 
 	request := &apiv1.RecommendBeerRequest{
@@ -347,7 +554,7 @@ func (suite *CellarTestSuite) TestRecommendBeer_NoCandidates() {
 		Filter:   filter,
 	}
 
-	suite.cellarRepo.EXPECT().FindBeerRecommendations(ctx, uint64(1), filter).Return([]*model.CellarEntry{}, nil)
+	suite.cellarRepo.EXPECT().FindBeerRecommendations(ctx, uint64(1), filter, uint(0)).Return([]*model.CellarEntry{}, nil)
 
 	result, err := suite.service.RecommendBeer(ctx, &connect.Request[apiv1.RecommendBeerRequest]{Msg: request})
 
@@ -357,13 +564,15 @@ func (suite *CellarTestSuite) TestRecommendBeer_NoCandidates() {
 }
 
 func (suite *CellarTestSuite) TestGetCellarRecommendationParams_Success() {
-	ctx := context.Background()
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleOwner)
 	expectedBreweries := []*model.Brewery{
 		{Model: gorm.Model{ID: 1}, Name: "Brewery A"},
 		{Model: gorm.Model{ID: 2}, Name: "Brewery B"},
 	}
 	expectedStyles := []*model.BeerStyle{
 		{Model: gorm.Model{ID: 1}, Name: "IPA"},
+		{Model: gorm.Model{ID: 3}, Name: "IPA - American", ParentID: pointy.Uint(1)},
 		{Model: gorm.Model{ID: 2}, Name: "Stout"},
 	}
 	expectedRanges := &model.CellarRecommendationRanges{
@@ -390,13 +599,17 @@ func (suite *CellarTestSuite) TestGetCellarRecommendationParams_Success() {
 	suite.NotNil(result)
 	params := result.Msg
 	suite.Len(params.GetBreweries(), 2)
-	suite.Len(params.GetStyles(), 2)
+	suite.Require().Len(params.GetStyles(), 2)
+	suite.Require().Len(params.GetStyles()[0].GetChildren(), 1)
+	suite.Equal("IPA - American", params.GetStyles()[0].GetChildren()[0].GetName())
+	suite.Empty(params.GetStyles()[1].GetChildren())
 	suite.InDelta(3.5, params.GetMinimumAbv(), 0.1)
 	suite.InDelta(12.0, params.GetMaximumAbv(), 0.1)
 }
 
 func (suite *CellarTestSuite) TestGetAdventCalendar_ByID() {
-	ctx := context.Background()
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleOwner)
 	expectedCalendar := &model.AdventCalendar{
 		Model:       gorm.Model{ID: 1},
 		CellarID:    1,
@@ -423,7 +636,8 @@ func (suite *CellarTestSuite) TestGetAdventCalendar_ByID() {
 }
 
 func (suite *CellarTestSuite) TestGetAdventCalendar_ByDate() {
-	ctx := context.Background()
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleOwner)
 	testDate := time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC)
 	expectedCalendar := &model.AdventCalendar{
 		Model:       gorm.Model{ID: 1},
@@ -450,7 +664,8 @@ func (suite *CellarTestSuite) TestGetAdventCalendar_ByDate() {
 }
 
 func (suite *CellarTestSuite) TestGetAdventCalendar_ByName() {
-	ctx := context.Background()
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleOwner)
 	expectedCalendar := &model.AdventCalendar{
 		Model:       gorm.Model{ID: 1},
 		CellarID:    1,
@@ -476,7 +691,8 @@ func (suite *CellarTestSuite) TestGetAdventCalendar_ByName() {
 }
 
 func (suite *CellarTestSuite) TestGetAdventCalendar_InvalidCriteria() {
-	ctx := context.Background()
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleOwner)
 
 	request := &apiv1.GetAdventCalendarRequest{
 		CellarId: 1,
@@ -490,7 +706,8 @@ func (suite *CellarTestSuite) TestGetAdventCalendar_InvalidCriteria() {
 }
 
 func (suite *CellarTestSuite) TestUpdateAdventCalendar_Success() {
-	ctx := context.Background()
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleOwner)
 	revealDay := time.Date(2023, 12, 15, 10, 30, 0, 0, time.UTC)
 	expectedDay := time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC) // Truncated to day
 
@@ -509,7 +726,8 @@ func (suite *CellarTestSuite) TestUpdateAdventCalendar_Success() {
 }
 
 func (suite *CellarTestSuite) TestDeleteAdventCalendar_Success() {
-	ctx := context.Background()
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleOwner)
 
 	request := &apiv1.DeleteAdventCalendarRequest{
 		CellarId: 1,
@@ -525,7 +743,8 @@ func (suite *CellarTestSuite) TestDeleteAdventCalendar_Success() {
 }
 
 func (suite *CellarTestSuite) TestRegenerateAdventCalendarDay_Success() {
-	ctx := context.Background()
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleOwner)
 	day := time.Date(2023, 12, 15, 10, 30, 0, 0, time.UTC)
 	expectedDay := time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC) // Truncated to day
 
@@ -555,7 +774,7 @@ func (suite *CellarTestSuite) TestRegenerateAdventCalendarDay_Success() {
 
 	suite.cellarRepo.EXPECT().GetAdventCalendarByID(ctx, uint64(1), uint64(1)).Return(adventCalendar, nil)
 	suite.cellarRepo.EXPECT().GetAdventCalendarFilter(ctx, uint64(1), uint64(1), expectedDay).Return(filter, nil)
-	suite.cellarRepo.EXPECT().FindBeerRecommendations(ctx, uint64(1), mock.Anything).Return(candidates, nil)
+	suite.cellarRepo.EXPECT().FindBeerRecommendations(ctx, uint64(1), mock.Anything, uint(0)).Return(candidates, nil)
 	suite.cellarRepo.EXPECT().UpdateAdventCalendarEntry(ctx, uint64(1), uint64(1), expectedDay, uint64(30)).Return(nil)
 
 	result, err := suite.service.RegenerateAdventCalendarDay(ctx, &connect.Request[apiv1.RegenerateAdventCalendarDayRequest]{Msg: request})
@@ -566,3 +785,290 @@ func (suite *CellarTestSuite) TestRegenerateAdventCalendarDay_Success() {
 	suite.NotNil(beer)
 	suite.Equal(uint64(30), beer.GetBeer().GetCellarEntryId())
 }
+
+func (suite *CellarTestSuite) TestAddCellar_Success() {
+	ctx := suite.userContext()
+	expectedCellar := &model.Cellar{
+		Model:     gorm.Model{ID: 5},
+		Name:      "New Cellar",
+		OwnerID:   1,
+		Locations: []model.LocationInCellar{{Model: gorm.Model{ID: 1}, Name: "Shelf"}},
+	}
+
+	suite.cellarRepo.EXPECT().AddCellar(ctx, "New Cellar", "", []string{"Shelf"}, mock.MatchedBy(func(user model.User) bool {
+		return user.ID == 1
+	})).Return(expectedCellar, nil)
+
+	request := &apiv1.AddCellarRequest{OwnerUuid: "6f1c2a8e-4c1b-4a43-9d0e-1f6d4f8f2b11", Name: "New Cellar", Locations: []string{"Shelf"}}
+	result, err := suite.service.AddCellar(ctx, &connect.Request[apiv1.AddCellarRequest]{Msg: request})
+
+	suite.Require().NoError(err)
+	suite.Equal(uint64(5), result.Msg.GetCellar().GetCellarId())
+	suite.Equal("testuser", result.Msg.GetCellar().GetOwner().GetUserName())
+	suite.Len(result.Msg.GetCellar().GetLocations(), 1)
+}
+
+func (suite *CellarTestSuite) TestAddCellar_ForAnotherUserDenied() {
+	ctx := suite.userContext()
+
+	request := &apiv1.AddCellarRequest{OwnerUuid: "0b7e1c52-2f0a-4c49-8d3c-62f1a9b6c0de", Name: "Not Mine"}
+	result, err := suite.service.AddCellar(ctx, &connect.Request[apiv1.AddCellarRequest]{Msg: request})
+
+	suite.Require().ErrorIs(err, server.ErrPermissionDenied)
+	suite.Equal(connect.CodePermissionDenied, server.ErrorCode(err))
+	suite.Nil(result)
+}
+
+func (suite *CellarTestSuite) TestGetCellar_OtherUsersCellarDenied() {
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 2, model.CellarRoleNone)
+
+	request := &apiv1.GetCellarRequest{CellarId: 2}
+	result, err := suite.service.GetCellar(ctx, &connect.Request[apiv1.GetCellarRequest]{Msg: request})
+
+	suite.Require().ErrorIs(err, server.ErrPermissionDenied)
+	suite.Equal(connect.CodePermissionDenied, server.ErrorCode(err))
+	suite.Nil(result)
+}
+
+func (suite *CellarTestSuite) TestListCellarBeers_OtherUsersCellarDenied() {
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 2, model.CellarRoleNone)
+
+	request := &apiv1.ListCellarBeersRequest{CellarId: 2}
+	result, err := suite.service.ListCellarBeers(ctx, &connect.Request[apiv1.ListCellarBeersRequest]{Msg: request})
+
+	suite.Require().ErrorIs(err, server.ErrPermissionDenied)
+	suite.Nil(result)
+}
+
+func (suite *CellarTestSuite) TestGetCellarEntry_OtherUsersEntryDenied() {
+	ctx := suite.userContext()
+	suite.expectCellarEntryRole(ctx, 20, model.CellarRoleNone)
+
+	request := &apiv1.GetCellarEntryRequest{CellarEntryId: 20}
+	result, err := suite.service.GetCellarEntry(ctx, &connect.Request[apiv1.GetCellarEntryRequest]{Msg: request})
+
+	suite.Require().ErrorIs(err, server.ErrPermissionDenied)
+	suite.Equal(connect.CodePermissionDenied, server.ErrorCode(err))
+	suite.Nil(result)
+}
+
+func (suite *CellarTestSuite) TestUpdateBeer_OtherUsersEntryDenied() {
+	ctx := suite.userContext()
+	suite.expectCellarEntryRole(ctx, 20, model.CellarRoleNone)
+
+	request := &apiv1.UpdateBeerRequest{CellarEntryId: 20, Quantity: pointy.Int64(0)}
+	result, err := suite.service.UpdateBeer(ctx, &connect.Request[apiv1.UpdateBeerRequest]{Msg: request})
+
+	suite.Require().ErrorIs(err, server.ErrPermissionDenied)
+	suite.Nil(result)
+}
+
+func (suite *CellarTestSuite) TestDeleteAdventCalendar_OtherUsersCellarDenied() {
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 2, model.CellarRoleNone)
+
+	request := &apiv1.DeleteAdventCalendarRequest{CellarId: 2, Id: 1}
+	result, err := suite.service.DeleteAdventCalendar(ctx, &connect.Request[apiv1.DeleteAdventCalendarRequest]{Msg: request})
+
+	suite.Require().ErrorIs(err, server.ErrPermissionDenied)
+	suite.Nil(result)
+}
+
+func (suite *CellarTestSuite) TestGetCellarStats_NoUserInContext() {
+	request := &apiv1.GetCellarStatsRequest{CellarId: 1}
+	result, err := suite.service.GetCellarStats(context.Background(), &connect.Request[apiv1.GetCellarStatsRequest]{Msg: request})
+
+	suite.Require().ErrorIs(err, server.ErrUnauthenticated)
+	suite.Nil(result)
+}
+
+func (suite *CellarTestSuite) TestListCellarBeers_ViewerAllowed() {
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 2, model.CellarRoleViewer)
+
+	suite.cellarRepo.EXPECT().ListCellarBeers(ctx, uint(2), (*apiv1.CellarFilter)(nil), uint(0), repository.CellarSort{Field: repository.CellarSortName}, repository.Page{}).
+		Return([]*model.CellarEntry{}, "", 0, nil)
+
+	request := &apiv1.ListCellarBeersRequest{CellarId: 2}
+	result, err := suite.service.ListCellarBeers(ctx, &connect.Request[apiv1.ListCellarBeersRequest]{Msg: request})
+
+	suite.Require().NoError(err)
+	suite.Empty(result.Msg.GetBeers())
+}
+
+func (suite *CellarTestSuite) TestUpdateBeer_ViewerDenied() {
+	ctx := suite.userContext()
+	suite.expectCellarEntryRole(ctx, 20, model.CellarRoleViewer)
+
+	request := &apiv1.UpdateBeerRequest{CellarEntryId: 20, Quantity: pointy.Int64(0)}
+	result, err := suite.service.UpdateBeer(ctx, &connect.Request[apiv1.UpdateBeerRequest]{Msg: request})
+
+	suite.Require().ErrorIs(err, server.ErrPermissionDenied)
+	suite.Nil(result)
+}
+
+func (suite *CellarTestSuite) TestDeleteAdventCalendar_EditorDenied() {
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 2, model.CellarRoleEditor)
+
+	request := &apiv1.DeleteAdventCalendarRequest{CellarId: 2, Id: 1}
+	result, err := suite.service.DeleteAdventCalendar(ctx, &connect.Request[apiv1.DeleteAdventCalendarRequest]{Msg: request})
+
+	suite.Require().ErrorIs(err, server.ErrPermissionDenied)
+	suite.Nil(result)
+}
+
+func (suite *CellarTestSuite) TestInviteCellarMember_Success() {
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleOwner)
+
+	suite.cellarRepo.EXPECT().GetCellarByID(ctx, uint(1)).Return(&model.Cellar{Model: gorm.Model{ID: 1}, OwnerID: 1}, nil)
+	suite.cellarRepo.EXPECT().AddCellarMember(ctx, model.CellarMember{
+		CellarID:    1,
+		UserID:      2,
+		Role:        model.CellarRoleEditor,
+		InvitedByID: 1,
+	}).Return(&model.CellarMember{Model: gorm.Model{ID: 3}, CellarID: 1, UserID: 2, Role: model.CellarRoleEditor}, nil)
+
+	request := &apiv1.InviteCellarMemberRequest{CellarId: 1, Email: "friend@example.com", Role: apiv1.CellarRole_CELLAR_ROLE_EDITOR}
+	result, err := suite.service.InviteCellarMember(ctx, &connect.Request[apiv1.InviteCellarMemberRequest]{Msg: request})
+
+	suite.Require().NoError(err)
+	member := result.Msg.GetMember()
+	suite.Equal("friend", member.GetUser().GetUserName())
+	suite.Equal(apiv1.CellarRole_CELLAR_ROLE_EDITOR, member.GetRole())
+	suite.False(member.GetAccepted())
+}
+
+func (suite *CellarTestSuite) TestInviteCellarMember_EditorDenied() {
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleEditor)
+
+	request := &apiv1.InviteCellarMemberRequest{CellarId: 1, Email: "friend@example.com", Role: apiv1.CellarRole_CELLAR_ROLE_VIEWER}
+	result, err := suite.service.InviteCellarMember(ctx, &connect.Request[apiv1.InviteCellarMemberRequest]{Msg: request})
+
+	suite.Require().ErrorIs(err, server.ErrPermissionDenied)
+	suite.Nil(result)
+}
+
+func (suite *CellarTestSuite) TestInviteCellarMember_UnknownEmail() {
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleOwner)
+
+	request := &apiv1.InviteCellarMemberRequest{CellarId: 1, Email: "nobody@example.com", Role: apiv1.CellarRole_CELLAR_ROLE_VIEWER}
+	result, err := suite.service.InviteCellarMember(ctx, &connect.Request[apiv1.InviteCellarMemberRequest]{Msg: request})
+
+	suite.Require().ErrorIs(err, server.ErrUserNotFound)
+	suite.Equal(connect.CodeNotFound, server.ErrorCode(err))
+	suite.Nil(result)
+}
+
+func (suite *CellarTestSuite) TestInviteCellarMember_MissingRole() {
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleOwner)
+
+	request := &apiv1.InviteCellarMemberRequest{CellarId: 1, Email: "friend@example.com"}
+	result, err := suite.service.InviteCellarMember(ctx, &connect.Request[apiv1.InviteCellarMemberRequest]{Msg: request})
+
+	suite.Require().ErrorIs(err, server.ErrInvalidInput)
+	suite.Nil(result)
+}
+
+func (suite *CellarTestSuite) TestListCellarInvitations_Success() {
+	ctx := suite.userContext()
+	invitations := []*model.Cellar{
+		{
+			Model:   gorm.Model{ID: 4},
+			Name:    "Tasting Group",
+			OwnerID: 2,
+			Members: []model.CellarMember{{CellarID: 4, UserID: 1, Role: model.CellarRoleEditor}},
+		},
+	}
+
+	suite.cellarRepo.EXPECT().GetCellarInvitationsForUser(ctx, mock.MatchedBy(func(user model.User) bool {
+		return user.ID == 1
+	})).Return(invitations, nil)
+
+	result, err := suite.service.ListCellarInvitations(ctx, &connect.Request[apiv1.ListCellarInvitationsRequest]{Msg: &apiv1.ListCellarInvitationsRequest{}})
+
+	suite.Require().NoError(err)
+	suite.Require().Len(result.Msg.GetCellars(), 1)
+	suite.Equal(apiv1.CellarRole_CELLAR_ROLE_EDITOR, result.Msg.GetCellars()[0].GetRole())
+}
+
+func (suite *CellarTestSuite) TestAcceptCellarInvitation_Success() {
+	ctx := suite.userContext()
+
+	suite.cellarRepo.EXPECT().AcceptCellarInvitation(ctx, uint(4), uint(1)).Return(nil)
+	suite.expectCellarRole(ctx, 4, model.CellarRoleEditor)
+	suite.cellarRepo.EXPECT().GetCellarByID(ctx, uint(4)).Return(&model.Cellar{Model: gorm.Model{ID: 4}, Name: "Tasting Group", OwnerID: 2}, nil)
+
+	request := &apiv1.AcceptCellarInvitationRequest{CellarId: 4}
+	result, err := suite.service.AcceptCellarInvitation(ctx, &connect.Request[apiv1.AcceptCellarInvitationRequest]{Msg: request})
+
+	suite.Require().NoError(err)
+	suite.Equal("Tasting Group", result.Msg.GetCellar().GetName())
+	suite.Equal(apiv1.CellarRole_CELLAR_ROLE_EDITOR, result.Msg.GetCellar().GetRole())
+}
+
+func (suite *CellarTestSuite) TestAcceptCellarInvitation_NotInvited() {
+	ctx := suite.userContext()
+
+	suite.cellarRepo.EXPECT().AcceptCellarInvitation(ctx, uint(4), uint(1)).Return(repository.ErrInvitationNotFound)
+
+	request := &apiv1.AcceptCellarInvitationRequest{CellarId: 4}
+	result, err := suite.service.AcceptCellarInvitation(ctx, &connect.Request[apiv1.AcceptCellarInvitationRequest]{Msg: request})
+
+	suite.Require().ErrorIs(err, repository.ErrInvitationNotFound)
+	suite.Equal(connect.CodeNotFound, server.ErrorCode(err))
+	suite.Nil(result)
+}
+
+func (suite *CellarTestSuite) TestUpdateCellarMemberRole_Success() {
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleOwner)
+
+	suite.cellarRepo.EXPECT().UpdateCellarMemberRole(ctx, uint(1), uint(2), model.CellarRoleViewer).Return(nil)
+
+	request := &apiv1.UpdateCellarMemberRoleRequest{CellarId: 1, UserId: "0b7e1c52-2f0a-4c49-8d3c-62f1a9b6c0de", Role: apiv1.CellarRole_CELLAR_ROLE_VIEWER}
+	_, err := suite.service.UpdateCellarMemberRole(ctx, &connect.Request[apiv1.UpdateCellarMemberRoleRequest]{Msg: request})
+
+	suite.Require().NoError(err)
+}
+
+func (suite *CellarTestSuite) TestRemoveCellarMember_OwnerRemovesMember() {
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleOwner)
+
+	suite.cellarRepo.EXPECT().RemoveCellarMember(ctx, uint(1), uint(2)).Return(nil)
+
+	request := &apiv1.RemoveCellarMemberRequest{CellarId: 1, UserId: "0b7e1c52-2f0a-4c49-8d3c-62f1a9b6c0de"}
+	_, err := suite.service.RemoveCellarMember(ctx, &connect.Request[apiv1.RemoveCellarMemberRequest]{Msg: request})
+
+	suite.Require().NoError(err)
+}
+
+func (suite *CellarTestSuite) TestRemoveCellarMember_EditorCannotRemoveOthers() {
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleEditor)
+
+	request := &apiv1.RemoveCellarMemberRequest{CellarId: 1, UserId: "0b7e1c52-2f0a-4c49-8d3c-62f1a9b6c0de"}
+	result, err := suite.service.RemoveCellarMember(ctx, &connect.Request[apiv1.RemoveCellarMemberRequest]{Msg: request})
+
+	suite.Require().ErrorIs(err, server.ErrPermissionDenied)
+	suite.Nil(result)
+}
+
+func (suite *CellarTestSuite) TestRemoveCellarMember_MemberLeaves() {
+	ctx := suite.userContext()
+	suite.userRepo.users = append(suite.userRepo.users, ctx.Value(auth.UserKey{}).(*model.User))
+
+	suite.cellarRepo.EXPECT().RemoveCellarMember(ctx, uint(4), uint(1)).Return(nil)
+
+	request := &apiv1.RemoveCellarMemberRequest{CellarId: 4, UserId: "6f1c2a8e-4c1b-4a43-9d0e-1f6d4f8f2b11"}
+	_, err := suite.service.RemoveCellarMember(ctx, &connect.Request[apiv1.RemoveCellarMemberRequest]{Msg: request})
+
+	suite.Require().NoError(err)
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/bufbuild/connect-go"
+	"go.openly.dev/pointy"
+
+	"droscher.com/BeerGargoyle/pkg/events"
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+	"droscher.com/BeerGargoyle/pkg/server/grpc"
+	api "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+)
+
+const maximumRating = 5
+
+// DrinkBeer records the current user drinking beers from a cellar entry, taking them out of the entry.
+func (c *CellarServer) DrinkBeer(ctx context.Context, request *connect.Request[api.DrinkBeerRequest]) (*connect.Response[api.DrinkBeerResponse], error) {
+	cellarEntryID := uint(request.Msg.GetCellarEntryId())
+
+	err := c.authorizeCellarEntry(ctx, cellarEntryID, model.CellarRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	consumption, err := consumptionFromRequest(request.Msg, user)
+	if err != nil {
+		return nil, err
+	}
+
+	cellarEntry, err := c.cellarRepository.GetCellarEntryByID(ctx, cellarEntryID)
+	if err != nil {
+		return nil, err
+	}
+
+	saved, entry, err := c.cellarRepository.DrinkBeer(ctx, consumption)
+	if err != nil {
+		return nil, err
+	}
+
+	saved.Beer = cellarEntry.Beer
+	saved.User = *user
+
+	response := api.DrinkBeerResponse{Consumption: grpc.ConsumptionFromModel(saved)}
+
+	if entry.DeletedAt.Valid {
+		c.publishEntryEvent(ctx, events.CellarEntryDeleted, entry, 0)
+
+		return connect.NewResponse(&response), nil
+	}
+
+	c.publishEntryEvent(ctx, events.CellarEntryQuantityChanged, entry, entry.Quantity+saved.Quantity)
+
+	cellarEntry.Quantity = entry.Quantity
+	cellarEntry.HadBefore = entry.HadBefore
+	response.Entry = grpc.CellarBeerFromModel(cellarEntry)
+
+	return connect.NewResponse(&response), nil
+}
+
+func consumptionFromRequest(request *api.DrinkBeerRequest, user *model.User) (model.Consumption, error) {
+	consumption := model.Consumption{
+		CellarEntryID: uint(request.GetCellarEntryId()),
+		UserID:        user.ID,
+		Quantity:      request.GetQuantity(),
+		DrankAt:       time.Now(),
+		Notes:         request.GetNotes(),
+	}
+
+	if consumption.Quantity < 0 {
+		return consumption, invalidField("quantity", "quantity cannot be negative")
+	}
+
+	if consumption.Quantity == 0 {
+		consumption.Quantity = 1
+	}
+
+	if request.GetDrankAt() != nil {
+		consumption.DrankAt = request.GetDrankAt().AsTime()
+	}
+
+	err := validateTastingScores(tastingScore{"rating", request.Rating})
+	if err != nil {
+		return consumption, err
+	}
+
+	if request.Rating != nil {
+		consumption.Rating = pointy.Float64(request.GetRating())
+	}
+
+	return consumption, nil
+}
+
+// ListConsumptions lists the beers drunk from the cellars the current user can see, along with the beers they drank
+// from cellars they can no longer see, optionally narrowed to a cellar, a beer or a user.
+func (c *CellarServer) ListConsumptions(ctx context.Context, request *connect.Request[api.ListConsumptionsRequest]) (*connect.Response[api.ListConsumptionsResponse], error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := repository.ConsumptionFilter{
+		CellarID: uint(request.Msg.GetCellarId()),
+		BeerID:   uint(request.Msg.GetBeerId()),
+		ViewerID: user.ID,
+	}
+
+	if request.Msg.CellarId != nil {
+		_, err = c.authorizeCellar(ctx, filter.CellarID, model.CellarRoleViewer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if request.Msg.GetUserId() != "" {
+		drinker, err := c.memberFromUUID(ctx, request.Msg.GetUserId())
+		if err != nil {
+			return nil, err
+		}
+
+		filter.UserID = drinker.ID
+	}
+
+	page := repository.Page{Size: int(request.Msg.GetPageSize()), Token: request.Msg.GetPageToken()}
+
+	consumptions, nextPageToken, err := c.cellarRepository.ListConsumptions(ctx, filter, page)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.ListConsumptionsResponse{
+		Consumptions:  grpc.ConsumptionsFromModel(consumptions),
+		NextPageToken: nextPageToken,
+	}
+
+	return connect.NewResponse(&response), nil
+}
@@ -0,0 +1,118 @@
+package server_test
+
+import (
+	"github.com/bufbuild/connect-go"
+	"github.com/stretchr/testify/mock"
+	"go.openly.dev/pointy"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/events"
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+	"droscher.com/BeerGargoyle/pkg/server"
+	apiv1 "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+)
+
+func (suite *CellarTestSuite) TestDrinkBeer_DrinksOneByDefault() {
+	ctx := suite.userContext()
+	suite.expectCellarEntryRole(ctx, 10, model.CellarRoleEditor)
+	suite.cellarRepo.EXPECT().GetCellarEntryByID(ctx, uint(10)).
+		Return(&model.CellarEntry{Model: gorm.Model{ID: 10}, CellarID: 1, Quantity: 3, Beer: model.Beer{Name: "Abt 12"}}, nil)
+	suite.cellarRepo.EXPECT().DrinkBeer(ctx, mock.MatchedBy(func(consumption model.Consumption) bool {
+		return consumption.CellarEntryID == 10 && consumption.UserID == 1 && consumption.Quantity == 1 &&
+			*consumption.Rating == 4.5 && consumption.Notes == "Still fresh"
+	})).Return(&model.Consumption{Model: gorm.Model{ID: 7}, CellarEntryID: 10, CellarID: 1, Quantity: 1, Rating: pointy.Float64(4.5)},
+		&model.CellarEntry{Model: gorm.Model{ID: 10}, CellarID: 1, Quantity: 2, HadBefore: true}, nil)
+
+	cellarEvents, stop := suite.events.Subscribe(1)
+	defer stop()
+
+	request := &apiv1.DrinkBeerRequest{CellarEntryId: 10, Rating: pointy.Float64(4.5), Notes: "Still fresh"}
+	response, err := suite.service.DrinkBeer(ctx, connect.NewRequest(request))
+	suite.Require().NoError(err)
+
+	suite.Equal(uint64(7), response.Msg.GetConsumption().GetConsumptionId())
+	suite.Equal("Abt 12", response.Msg.GetConsumption().GetBeer().GetName())
+	suite.Equal("testuser", response.Msg.GetConsumption().GetUser().GetUserName())
+	suite.Equal(int64(2), response.Msg.GetEntry().GetQuantity())
+	suite.True(response.Msg.GetEntry().GetHadBefore())
+
+	suite.Require().Len(cellarEvents, 1)
+	suite.Equal(events.CellarEvent{Type: events.CellarEntryQuantityChanged, CellarID: 1, EntryID: 10, PreviousQuantity: 3}, <-cellarEvents)
+}
+
+func (suite *CellarTestSuite) TestDrinkBeer_LastOneDeletesEntry() {
+	ctx := suite.userContext()
+	suite.expectCellarEntryRole(ctx, 10, model.CellarRoleEditor)
+	suite.cellarRepo.EXPECT().GetCellarEntryByID(ctx, uint(10)).
+		Return(&model.CellarEntry{Model: gorm.Model{ID: 10}, CellarID: 1, Quantity: 2}, nil)
+	suite.cellarRepo.EXPECT().DrinkBeer(ctx, mock.Anything).
+		Return(&model.Consumption{Model: gorm.Model{ID: 7}, CellarEntryID: 10, CellarID: 1, Quantity: 2},
+			&model.CellarEntry{Model: gorm.Model{ID: 10, DeletedAt: gorm.DeletedAt{Valid: true}}, CellarID: 1, HadBefore: true}, nil)
+
+	cellarEvents, stop := suite.events.Subscribe(1)
+	defer stop()
+
+	response, err := suite.service.DrinkBeer(ctx, connect.NewRequest(&apiv1.DrinkBeerRequest{CellarEntryId: 10, Quantity: 2}))
+	suite.Require().NoError(err)
+
+	suite.Equal(int64(2), response.Msg.GetConsumption().GetQuantity())
+	suite.Nil(response.Msg.GetEntry())
+
+	suite.Require().Len(cellarEvents, 1)
+	suite.Equal(events.CellarEvent{Type: events.CellarEntryDeleted, CellarID: 1, EntryID: 10}, <-cellarEvents)
+}
+
+func (suite *CellarTestSuite) TestDrinkBeer_InvalidRating() {
+	ctx := suite.userContext()
+	suite.expectCellarEntryRole(ctx, 10, model.CellarRoleEditor)
+
+	_, err := suite.service.DrinkBeer(ctx, connect.NewRequest(&apiv1.DrinkBeerRequest{CellarEntryId: 10, Rating: pointy.Float64(6)}))
+
+	suite.Equal(connect.CodeInvalidArgument, server.ErrorCode(err))
+}
+
+func (suite *CellarTestSuite) TestDrinkBeer_NotEnoughLeft() {
+	ctx := suite.userContext()
+	suite.expectCellarEntryRole(ctx, 10, model.CellarRoleEditor)
+	suite.cellarRepo.EXPECT().GetCellarEntryByID(ctx, uint(10)).
+		Return(&model.CellarEntry{Model: gorm.Model{ID: 10}, CellarID: 1, Quantity: 1}, nil)
+	suite.cellarRepo.EXPECT().DrinkBeer(ctx, mock.Anything).Return(nil, nil, repository.ErrNotEnoughBeer)
+
+	_, err := suite.service.DrinkBeer(ctx, connect.NewRequest(&apiv1.DrinkBeerRequest{CellarEntryId: 10, Quantity: 2}))
+
+	suite.Equal(connect.CodeFailedPrecondition, server.ErrorCode(err))
+}
+
+func (suite *CellarTestSuite) TestDrinkBeer_RequiresEditor() {
+	ctx := suite.userContext()
+	suite.expectCellarEntryRole(ctx, 10, model.CellarRoleViewer)
+
+	_, err := suite.service.DrinkBeer(ctx, connect.NewRequest(&apiv1.DrinkBeerRequest{CellarEntryId: 10}))
+
+	suite.Require().ErrorIs(err, server.ErrPermissionDenied)
+}
+
+func (suite *CellarTestSuite) TestListConsumptions_ForUser() {
+	ctx := suite.userContext()
+	filter := repository.ConsumptionFilter{BeerID: 5, UserID: 2, ViewerID: 1}
+	suite.cellarRepo.EXPECT().ListConsumptions(ctx, filter, repository.Page{Size: 10}).
+		Return([]*model.Consumption{{Model: gorm.Model{ID: 7}, BeerID: 5, UserID: 2, Quantity: 1}}, "next", nil)
+
+	request := &apiv1.ListConsumptionsRequest{BeerId: pointy.Uint64(5), UserId: "0b7e1c52-2f0a-4c49-8d3c-62f1a9b6c0de", PageSize: 10}
+	response, err := suite.service.ListConsumptions(ctx, connect.NewRequest(request))
+	suite.Require().NoError(err)
+
+	suite.Require().Len(response.Msg.GetConsumptions(), 1)
+	suite.Equal(uint64(7), response.Msg.GetConsumptions()[0].GetConsumptionId())
+	suite.Equal("next", response.Msg.GetNextPageToken())
+}
+
+func (suite *CellarTestSuite) TestListConsumptions_RequiresCellarAccess() {
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleNone)
+
+	_, err := suite.service.ListConsumptions(ctx, connect.NewRequest(&apiv1.ListConsumptionsRequest{CellarId: pointy.Uint64(1)}))
+
+	suite.Require().ErrorIs(err, server.ErrPermissionDenied)
+}
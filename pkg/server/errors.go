@@ -0,0 +1,176 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bufbuild/connect-go"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/repository"
+)
+
+var ErrInternal = errors.New("internal error")
+
+// FieldError is invalid input in a field of a request. It is sent to clients as a field violation.
+type FieldError struct {
+	Field       string
+	Description string
+}
+
+func invalidField(field string, description string) error {
+	return &FieldError{Field: field, Description: description}
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrInvalidInput, e.Description)
+}
+
+func (e *FieldError) Unwrap() error {
+	return ErrInvalidInput
+}
+
+var _ connect.Interceptor = (*ErrorInterceptor)(nil)
+
+// ErrorInterceptor translates the errors returned by the handlers into connect errors, so that clients get a code
+// matching the error along with structured details. Errors that are already connect errors are left alone, and the
+// details of internal errors are only logged.
+type ErrorInterceptor struct {
+	logger *zap.Logger
+}
+
+func NewErrorInterceptor(logger *zap.Logger) *ErrorInterceptor {
+	return &ErrorInterceptor{logger: logger}
+}
+
+func (e *ErrorInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		response, err := next(ctx, req)
+		if err != nil {
+			return nil, e.translate(req.Spec().Procedure, err)
+		}
+
+		return response, nil
+	}
+}
+
+func (e *ErrorInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (e *ErrorInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		err := next(ctx, conn)
+		if err != nil {
+			return e.translate(conn.Spec().Procedure, err)
+		}
+
+		return nil
+	}
+}
+
+func (e *ErrorInterceptor) translate(procedure string, err error) error {
+	var connectErr *connect.Error
+	if errors.As(err, &connectErr) {
+		return err
+	}
+
+	code := ErrorCode(err)
+	if code == connect.CodeInternal {
+		e.logger.Error("internal error", zap.String("procedure", procedure), zap.Error(err))
+
+		return connect.NewError(code, ErrInternal)
+	}
+
+	connectErr = connect.NewError(code, err)
+
+	for _, detail := range errorDetails(err) {
+		errorDetail, detailErr := connect.NewErrorDetail(detail)
+		if detailErr != nil {
+			e.logger.Warn("error adding error detail", zap.Error(detailErr))
+
+			continue
+		}
+
+		connectErr.AddDetail(errorDetail)
+	}
+
+	return connectErr
+}
+
+// errorCodeMapping is the code sent to clients for errors matching any of the errors.
+type errorCodeMapping struct {
+	code connect.Code
+	errs []error
+}
+
+// errorCodes lists the errors handlers return along with the code sent to clients for them, checked in order.
+func errorCodes() []errorCodeMapping {
+	return []errorCodeMapping{
+		{connect.CodeCanceled, []error{context.Canceled}},
+		{connect.CodeDeadlineExceeded, []error{context.DeadlineExceeded}},
+		{connect.CodeUnauthenticated, []error{ErrUnauthenticated}},
+		{connect.CodePermissionDenied, []error{ErrPermissionDenied}},
+		{connect.CodeNotFound, []error{repository.ErrNotFound, gorm.ErrRecordNotFound, ErrCellarNotFound, ErrUserNotFound}},
+		{connect.CodeAlreadyExists, []error{repository.ErrAlreadyExists, gorm.ErrDuplicatedKey}},
+		{connect.CodeFailedPrecondition, []error{repository.ErrConflict, gorm.ErrForeignKeyViolated, ErrCannotCreate}},
+		{connect.CodeInvalidArgument, []error{repository.ErrInvalid, ErrInvalidInput, gorm.ErrCheckConstraintViolated}},
+		{connect.CodeAborted, []error{ErrWatcherBehind}},
+	}
+}
+
+// ErrorCode returns the code clients get for an error. Errors that are not expected are internal errors.
+func ErrorCode(err error) connect.Code {
+	for _, mapping := range errorCodes() {
+		for _, target := range mapping.errs {
+			if errors.Is(err, target) {
+				return mapping.code
+			}
+		}
+	}
+
+	return connect.CodeInternal
+}
+
+// errorDetails describes the invalid fields and the resource the error is about.
+func errorDetails(err error) []proto.Message {
+	var details []proto.Message
+
+	if violations := fieldViolations(err); len(violations) > 0 {
+		details = append(details, &errdetails.BadRequest{FieldViolations: violations})
+	}
+
+	var resourceErr *repository.ResourceError
+	if errors.As(err, &resourceErr) {
+		details = append(details, &errdetails.ResourceInfo{
+			ResourceType: resourceErr.Resource,
+			ResourceName: resourceErr.ID,
+			Description:  resourceErr.Err.Error(),
+		})
+	}
+
+	return details
+}
+
+// fieldViolations collects every field error in the error tree, as validation may join the errors of several fields.
+func fieldViolations(err error) []*errdetails.BadRequest_FieldViolation {
+	switch wrapped := err.(type) { //nolint:errorlint // the error tree is walked to find every field error
+	case *FieldError:
+		return []*errdetails.BadRequest_FieldViolation{{Field: wrapped.Field, Description: wrapped.Description}}
+	case interface{ Unwrap() []error }:
+		var violations []*errdetails.BadRequest_FieldViolation
+		for _, joined := range wrapped.Unwrap() {
+			violations = append(violations, fieldViolations(joined)...)
+		}
+
+		return violations
+	case interface{ Unwrap() error }:
+		return fieldViolations(wrapped.Unwrap())
+	}
+
+	return nil
+}
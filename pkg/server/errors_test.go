@@ -0,0 +1,119 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/repository"
+	"droscher.com/BeerGargoyle/pkg/server"
+	apiv1 "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+)
+
+var errUnexpected = errors.New("connection reset by peer")
+
+type ErrorsTestSuite struct {
+	suite.Suite
+	interceptor *server.ErrorInterceptor
+}
+
+func TestErrorsTestSuite(t *testing.T) {
+	suite.Run(t, new(ErrorsTestSuite))
+}
+
+func (suite *ErrorsTestSuite) SetupTest() {
+	suite.interceptor = server.NewErrorInterceptor(zaptest.NewLogger(suite.T()))
+}
+
+// call runs a unary call through the interceptor with a handler failing with the error given.
+func (suite *ErrorsTestSuite) call(handlerErr error) *connect.Error {
+	handler := suite.interceptor.WrapUnary(func(context.Context, connect.AnyRequest) (connect.AnyResponse, error) {
+		return nil, handlerErr
+	})
+
+	_, err := handler(context.Background(), connect.NewRequest(&apiv1.GetBeerRequest{}))
+
+	var connectErr *connect.Error
+	suite.Require().ErrorAs(err, &connectErr)
+
+	return connectErr
+}
+
+func (suite *ErrorsTestSuite) TestErrorCode() {
+	for _, test := range []struct {
+		err  error
+		code connect.Code
+	}{
+		{fmt.Errorf("%w: 12", repository.ErrBeerNotFound), connect.CodeNotFound},
+		{repository.ErrTagNotFound, connect.CodeNotFound},
+		{gorm.ErrRecordNotFound, connect.CodeNotFound},
+		{repository.ErrFormatExists, connect.CodeAlreadyExists},
+		{gorm.ErrDuplicatedKey, connect.CodeAlreadyExists},
+		{repository.ErrFormatInUse, connect.CodeFailedPrecondition},
+		{repository.ErrInvalidPageToken, connect.CodeInvalidArgument},
+		{server.ErrInvalidInput, connect.CodeInvalidArgument},
+		{server.ErrUnauthenticated, connect.CodeUnauthenticated},
+		{fmt.Errorf("%w: not a member", server.ErrPermissionDenied), connect.CodePermissionDenied},
+		{context.Canceled, connect.CodeCanceled},
+		{errUnexpected, connect.CodeInternal},
+	} {
+		suite.Equal(test.code, server.ErrorCode(test.err), test.err.Error())
+	}
+}
+
+func (suite *ErrorsTestSuite) TestInterceptor_NotFoundIncludesResource() {
+	err := suite.call(&repository.ResourceError{Resource: "beer", ID: "12", Err: repository.ErrBeerNotFound})
+
+	suite.Equal(connect.CodeNotFound, err.Code())
+	suite.Require().Len(err.Details(), 1)
+
+	detail, detailErr := err.Details()[0].Value()
+	suite.Require().NoError(detailErr)
+
+	resource, ok := detail.(*errdetails.ResourceInfo)
+	suite.Require().True(ok)
+	suite.Equal("beer", resource.GetResourceType())
+	suite.Equal("12", resource.GetResourceName())
+}
+
+func (suite *ErrorsTestSuite) TestInterceptor_InvalidArgumentIncludesFieldViolations() {
+	err := suite.call(fmt.Errorf("invalid guidelines: %w", errors.Join(
+		&server.FieldError{Field: "guidelines.min_abv", Description: "min_abv is above max_abv"},
+		&server.FieldError{Field: "guidelines.min_srm", Description: "min_srm is above max_srm"},
+	)))
+
+	suite.Equal(connect.CodeInvalidArgument, err.Code())
+	suite.Require().Len(err.Details(), 1)
+
+	detail, detailErr := err.Details()[0].Value()
+	suite.Require().NoError(detailErr)
+
+	badRequest, ok := detail.(*errdetails.BadRequest)
+	suite.Require().True(ok)
+	suite.Require().Len(badRequest.GetFieldViolations(), 2)
+	suite.Equal("guidelines.min_abv", badRequest.GetFieldViolations()[0].GetField())
+	suite.Equal("guidelines.min_srm", badRequest.GetFieldViolations()[1].GetField())
+}
+
+func (suite *ErrorsTestSuite) TestInterceptor_HidesInternalErrors() {
+	err := suite.call(fmt.Errorf("error loading cellar: %w", errUnexpected))
+
+	suite.Equal(connect.CodeInternal, err.Code())
+	suite.Equal(server.ErrInternal.Error(), err.Message())
+	suite.Empty(err.Details())
+}
+
+func (suite *ErrorsTestSuite) TestInterceptor_KeepsConnectErrors() {
+	original := connect.NewError(connect.CodeUnavailable, errUnexpected)
+
+	err := suite.call(original)
+
+	suite.Same(original, err)
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/bufbuild/connect-go"
+	"go.uber.org/zap"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+	"droscher.com/BeerGargoyle/pkg/server/grpc"
+	api "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+)
+
+// millilitresPerFluidOunce converts between the metric and imperial sizes of formats, which use US fluid ounces.
+const millilitresPerFluidOunce = 29.5735
+
+func (b *BeerServer) GetBeerFormats(ctx context.Context, _ *connect.Request[api.GetBeerFormatsRequest]) (*connect.Response[api.GetBeerFormatsResponse], error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	formats, err := b.repository.GetBeerFormats(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.GetBeerFormatsResponse{
+		Formats: grpc.FormatsFromModel(formats),
+	}
+
+	return connect.NewResponse(&response), nil
+}
+
+// AddBeerFormat creates a custom format for the current user.
+func (b *BeerServer) AddBeerFormat(ctx context.Context, request *connect.Request[api.AddBeerFormatRequest]) (*connect.Response[api.AddBeerFormatResponse], error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(request.Msg.GetPackageType()) == 0 {
+		return nil, invalidField("package_type", "package type must be set")
+	}
+
+	format := model.BeerFormat{Package: request.Msg.GetPackageType(), OwnerID: &user.ID}
+
+	err = setFormatSizes(&format, request.Msg.MetricSize, request.Msg.ImperialSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if format.SizeMetric == 0 {
+		return nil, invalidField("metric_size", "a size must be set")
+	}
+
+	err = b.repository.AddBeerFormat(ctx, &format)
+	if err != nil {
+		return nil, err
+	}
+
+	b.logger.Info("beer format added", zap.Uint("id", format.ID), zap.Uint("owner_id", user.ID))
+
+	return connect.NewResponse(&api.AddBeerFormatResponse{Format: grpc.FormatFromModel(format)}), nil
+}
+
+func (b *BeerServer) UpdateBeerFormat(ctx context.Context, request *connect.Request[api.UpdateBeerFormatRequest]) (*connect.Response[api.UpdateBeerFormatResponse], error) {
+	format, err := b.customFormat(ctx, uint(request.Msg.GetFormatId()))
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Msg.PackageType != nil {
+		if len(request.Msg.GetPackageType()) == 0 {
+			return nil, invalidField("package_type", "package type must not be empty")
+		}
+
+		format.Package = request.Msg.GetPackageType()
+	}
+
+	err = setFormatSizes(format, request.Msg.MetricSize, request.Msg.ImperialSize)
+	if err != nil {
+		return nil, err
+	}
+
+	err = b.repository.UpdateBeerFormat(ctx, format)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&api.UpdateBeerFormatResponse{Format: grpc.FormatFromModel(*format)}), nil
+}
+
+func (b *BeerServer) DeleteBeerFormat(ctx context.Context, request *connect.Request[api.DeleteBeerFormatRequest]) (*connect.Response[api.DeleteBeerFormatResponse], error) {
+	format, err := b.customFormat(ctx, uint(request.Msg.GetFormatId()))
+	if err != nil {
+		return nil, err
+	}
+
+	err = b.repository.DeleteBeerFormat(ctx, format.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	b.logger.Info("beer format deleted", zap.Uint("id", format.ID))
+
+	return connect.NewResponse(&api.DeleteBeerFormatResponse{}), nil
+}
+
+// customFormat gets a format the current user is allowed to change, which are only their own custom formats.
+func (b *BeerServer) customFormat(ctx context.Context, formatID uint) (*model.BeerFormat, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := b.repository.GetBeerFormat(ctx, formatID)
+	if err != nil {
+		return nil, err
+	}
+
+	if format.OwnerID == nil {
+		return nil, fmt.Errorf("%w: global formats cannot be changed", ErrPermissionDenied)
+	}
+
+	if *format.OwnerID != user.ID {
+		// Other users' custom formats are reported as missing, so their IDs are not revealed.
+		return nil, fmt.Errorf("%w: %d", repository.ErrFormatNotFound, formatID)
+	}
+
+	return format, nil
+}
+
+// setFormatSizes sets the sizes given, converting the missing size from the other one when only one is given.
+func setFormatSizes(format *model.BeerFormat, metric *float64, imperial *float64) error {
+	if (metric != nil && *metric <= 0) || (imperial != nil && *imperial <= 0) {
+		return fmt.Errorf("%w: sizes must be positive", ErrInvalidInput)
+	}
+
+	switch {
+	case metric != nil && imperial != nil:
+		format.SizeMetric = *metric
+		format.SizeImperial = *imperial
+	case metric != nil:
+		format.SizeMetric = *metric
+		format.SizeImperial = math.Round(*metric/millilitresPerFluidOunce*10) / 10
+	case imperial != nil:
+		format.SizeMetric = math.Round(*imperial * millilitresPerFluidOunce)
+		format.SizeImperial = *imperial
+	}
+
+	return nil
+}
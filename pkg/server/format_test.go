@@ -0,0 +1,139 @@
+package server_test
+
+import (
+	"context"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/stretchr/testify/mock"
+	"go.openly.dev/pointy"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/auth"
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+	"droscher.com/BeerGargoyle/pkg/server"
+	apiv1 "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+)
+
+func formatUserContext() context.Context {
+	return context.WithValue(context.Background(), auth.UserKey{}, &model.User{Model: gorm.Model{ID: 3}})
+}
+
+func (suite *BeerTestSuite) TestGetBeerFormats_IncludesCustomFormats() {
+	suite.beerRepo.EXPECT().GetBeerFormats(mock.Anything, uint(3)).Return([]*model.BeerFormat{
+		{Model: gorm.Model{ID: 1}, Package: "Can", SizeMetric: 355, SizeImperial: 12},
+		{Model: gorm.Model{ID: 40}, Package: "Can", SizeMetric: 440, SizeImperial: 14.9, OwnerID: pointy.Uint(3)},
+	}, nil)
+
+	result, err := suite.service.GetBeerFormats(formatUserContext(), connect.NewRequest(&apiv1.GetBeerFormatsRequest{}))
+
+	suite.Require().NoError(err)
+	suite.Require().Len(result.Msg.GetFormats(), 2)
+	suite.False(result.Msg.GetFormats()[0].GetCustom())
+	suite.True(result.Msg.GetFormats()[1].GetCustom())
+}
+
+func (suite *BeerTestSuite) TestAddBeerFormat_ConvertsMetricSize() {
+	suite.beerRepo.EXPECT().AddBeerFormat(mock.Anything, mock.MatchedBy(func(format *model.BeerFormat) bool {
+		return format.Package == "Can" && format.SizeMetric == 440 && format.SizeImperial == 14.9 && *format.OwnerID == 3
+	})).RunAndReturn(func(_ context.Context, format *model.BeerFormat) error {
+		format.ID = 40
+
+		return nil
+	})
+
+	result, err := suite.service.AddBeerFormat(formatUserContext(), connect.NewRequest(&apiv1.AddBeerFormatRequest{
+		PackageType: "Can",
+		MetricSize:  pointy.Float64(440),
+	}))
+
+	suite.Require().NoError(err)
+	suite.Equal(uint64(40), result.Msg.GetFormat().GetFormatId())
+	suite.InDelta(14.9, result.Msg.GetFormat().GetImperialSize(), 0.01)
+	suite.True(result.Msg.GetFormat().GetCustom())
+}
+
+func (suite *BeerTestSuite) TestAddBeerFormat_ConvertsImperialSize() {
+	suite.beerRepo.EXPECT().AddBeerFormat(mock.Anything, mock.MatchedBy(func(format *model.BeerFormat) bool {
+		return format.SizeMetric == 473 && format.SizeImperial == 16
+	})).Return(nil)
+
+	_, err := suite.service.AddBeerFormat(formatUserContext(), connect.NewRequest(&apiv1.AddBeerFormatRequest{
+		PackageType:  "Can",
+		ImperialSize: pointy.Float64(16),
+	}))
+
+	suite.Require().NoError(err)
+}
+
+func (suite *BeerTestSuite) TestAddBeerFormat_RequiresPackageAndSize() {
+	_, err := suite.service.AddBeerFormat(formatUserContext(), connect.NewRequest(&apiv1.AddBeerFormatRequest{MetricSize: pointy.Float64(440)}))
+	suite.Require().ErrorIs(err, server.ErrInvalidInput)
+
+	_, err = suite.service.AddBeerFormat(formatUserContext(), connect.NewRequest(&apiv1.AddBeerFormatRequest{PackageType: "Can"}))
+	suite.Require().ErrorIs(err, server.ErrInvalidInput)
+
+	_, err = suite.service.AddBeerFormat(formatUserContext(), connect.NewRequest(&apiv1.AddBeerFormatRequest{PackageType: "Can", MetricSize: pointy.Float64(-1)}))
+	suite.Require().ErrorIs(err, server.ErrInvalidInput)
+}
+
+func (suite *BeerTestSuite) TestAddBeerFormat_Duplicate() {
+	suite.beerRepo.EXPECT().AddBeerFormat(mock.Anything, mock.Anything).Return(repository.ErrFormatExists)
+
+	_, err := suite.service.AddBeerFormat(formatUserContext(), connect.NewRequest(&apiv1.AddBeerFormatRequest{
+		PackageType: "Can",
+		MetricSize:  pointy.Float64(355),
+	}))
+
+	suite.Equal(connect.CodeAlreadyExists, server.ErrorCode(err))
+}
+
+func (suite *BeerTestSuite) TestUpdateBeerFormat_RecomputesOtherSize() {
+	suite.beerRepo.EXPECT().GetBeerFormat(mock.Anything, uint(40)).
+		Return(&model.BeerFormat{Model: gorm.Model{ID: 40}, Package: "Keg", SizeMetric: 5000, SizeImperial: 169.1, OwnerID: pointy.Uint(3)}, nil)
+	suite.beerRepo.EXPECT().UpdateBeerFormat(mock.Anything, mock.MatchedBy(func(format *model.BeerFormat) bool {
+		return format.Package == "Mini-keg" && format.SizeMetric == 1500 && format.SizeImperial == 50.7
+	})).Return(nil)
+
+	result, err := suite.service.UpdateBeerFormat(formatUserContext(), connect.NewRequest(&apiv1.UpdateBeerFormatRequest{
+		FormatId:    40,
+		PackageType: pointy.String("Mini-keg"),
+		MetricSize:  pointy.Float64(1500),
+	}))
+
+	suite.Require().NoError(err)
+	suite.Equal("Mini-keg", result.Msg.GetFormat().GetPackageType())
+}
+
+func (suite *BeerTestSuite) TestUpdateBeerFormat_OnlyOwnCustomFormats() {
+	suite.beerRepo.EXPECT().GetBeerFormat(mock.Anything, uint(1)).
+		Return(&model.BeerFormat{Model: gorm.Model{ID: 1}, Package: "Can", SizeMetric: 355}, nil)
+	suite.beerRepo.EXPECT().GetBeerFormat(mock.Anything, uint(41)).
+		Return(&model.BeerFormat{Model: gorm.Model{ID: 41}, Package: "Can", SizeMetric: 440, OwnerID: pointy.Uint(9)}, nil)
+
+	_, err := suite.service.UpdateBeerFormat(formatUserContext(), connect.NewRequest(&apiv1.UpdateBeerFormatRequest{FormatId: 1}))
+	suite.Equal(connect.CodePermissionDenied, server.ErrorCode(err))
+
+	_, err = suite.service.UpdateBeerFormat(formatUserContext(), connect.NewRequest(&apiv1.UpdateBeerFormatRequest{FormatId: 41}))
+	suite.Equal(connect.CodeNotFound, server.ErrorCode(err))
+}
+
+func (suite *BeerTestSuite) TestDeleteBeerFormat_InUse() {
+	suite.beerRepo.EXPECT().GetBeerFormat(mock.Anything, uint(40)).
+		Return(&model.BeerFormat{Model: gorm.Model{ID: 40}, OwnerID: pointy.Uint(3)}, nil)
+	suite.beerRepo.EXPECT().DeleteBeerFormat(mock.Anything, uint(40)).Return(repository.ErrFormatInUse)
+
+	_, err := suite.service.DeleteBeerFormat(formatUserContext(), connect.NewRequest(&apiv1.DeleteBeerFormatRequest{FormatId: 40}))
+
+	suite.Equal(connect.CodeFailedPrecondition, server.ErrorCode(err))
+}
+
+func (suite *BeerTestSuite) TestDeleteBeerFormat_Deletes() {
+	suite.beerRepo.EXPECT().GetBeerFormat(mock.Anything, uint(40)).
+		Return(&model.BeerFormat{Model: gorm.Model{ID: 40}, OwnerID: pointy.Uint(3)}, nil)
+	suite.beerRepo.EXPECT().DeleteBeerFormat(mock.Anything, uint(40)).Return(nil)
+
+	_, err := suite.service.DeleteBeerFormat(formatUserContext(), connect.NewRequest(&apiv1.DeleteBeerFormatRequest{FormatId: 40}))
+
+	suite.Require().NoError(err)
+}
@@ -2,6 +2,7 @@ package grpc
 
 import (
 	"go.openly.dev/pointy"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"droscher.com/BeerGargoyle/pkg/model"
@@ -19,37 +20,99 @@ func BeersFromModel(beers []model.Beer) []*api.Beer {
 	return pbBeers
 }
 
-func BeerFromModel(beer model.Beer) *api.Beer {
-	address := api.Address{Locality: beer.Brewery.Address.Locality}
-	brewery := api.Brewery{
-		Name:     beer.Brewery.Name,
-		Address:  &address,
-		ImageUrl: beer.Brewery.ImageURL,
+// CatalogBeersFromModel converts beers loaded from the local catalog.
+func CatalogBeersFromModel(beers []*model.Beer) []*api.Beer {
+	pbBeers := make([]*api.Beer, 0, len(beers))
+
+	for _, beer := range beers {
+		pbBeers = append(pbBeers, BeerFromModel(*beer))
+	}
+
+	return pbBeers
+}
+
+func BreweryFromModel(brewery model.Brewery) *api.Brewery {
+	pbBrewery := api.Brewery{
+		Name:        brewery.Name,
+		Description: brewery.Description,
+		Address:     AddressFromModel(brewery.Address),
+		ImageUrl:    brewery.ImageURL,
+	}
+
+	if brewery.ID != 0 {
+		pbBrewery.Id = uint64(brewery.ID)
+	}
+
+	if brewery.ExternalID != nil {
+		pbBrewery.ExternalId = pointy.Uint64(*brewery.ExternalID)
+	}
+
+	if brewery.ExternalSource != nil {
+		pbBrewery.ExternalSource = pointy.String(*brewery.ExternalSource)
 	}
 
-	if beer.Brewery.ID != 0 {
-		brewery.Id = uint64(beer.Brewery.ID)
+	if brewery.ExternalRating != nil {
+		pbBrewery.ExternalRating = pointy.Float64(*brewery.ExternalRating)
+	}
+
+	return &pbBrewery
+}
+
+func BeerMatchesFromModel(matches []model.BeerMatch) []*api.BeerMatch {
+	pbMatches := make([]*api.BeerMatch, 0, len(matches))
+
+	for _, match := range matches {
+		pbMatches = append(pbMatches, &api.BeerMatch{Beer: BeerFromModel(match.Beer), Score: match.Score})
 	}
 
-	if beer.Brewery.ExternalID != nil {
-		brewery.ExternalId = pointy.Uint64(*beer.Brewery.ExternalID)
+	return pbMatches
+}
+
+func BreweryMatchesFromModel(matches []model.BreweryMatch) []*api.BreweryMatch {
+	pbMatches := make([]*api.BreweryMatch, 0, len(matches))
+
+	for _, match := range matches {
+		pbMatches = append(pbMatches, &api.BreweryMatch{Brewery: BreweryFromModel(match.Brewery), Score: match.Score})
 	}
 
-	if beer.Brewery.ExternalSource != nil {
-		brewery.ExternalSource = pointy.String(*beer.Brewery.ExternalSource)
+	return pbMatches
+}
+
+func DuplicateCandidatesFromModel(candidates []model.DuplicateCandidate) []*api.DuplicateCandidate {
+	pbCandidates := make([]*api.DuplicateCandidate, 0, len(candidates))
+
+	for _, candidate := range candidates {
+		pbCandidates = append(pbCandidates, &api.DuplicateCandidate{
+			KeepId:    uint64(candidate.KeepID),
+			KeepName:  candidate.KeepName,
+			MergeId:   uint64(candidate.MergeID),
+			MergeName: candidate.MergeName,
+			Reason:    DuplicateReasonFromModel(candidate.Reason),
+		})
 	}
 
-	if beer.Brewery.ExternalRating != nil {
-		brewery.ExternalRating = pointy.Float64(*beer.Brewery.ExternalRating)
+	return pbCandidates
+}
+
+func DuplicateReasonFromModel(reason model.DuplicateReason) api.DuplicateReason {
+	switch reason {
+	case model.DuplicateReasonName:
+		return api.DuplicateReason_DUPLICATE_REASON_NAME
+	case model.DuplicateReasonExternalID:
+		return api.DuplicateReason_DUPLICATE_REASON_EXTERNAL_ID
 	}
 
+	return api.DuplicateReason_DUPLICATE_REASON_UNSPECIFIED
+}
+
+func BeerFromModel(beer model.Beer) *api.Beer {
 	pbBeer := api.Beer{
 		Id:          uint64(beer.ID),
 		Name:        beer.Name,
 		Description: beer.Description,
-		Style:       &api.BeerStyle{Name: beer.Style.Name},
+		Style:       StyleFromModel(beer.Style),
 		ImageUrl:    pointy.String(beer.ImageURL),
-		Brewery:     &brewery,
+		Brewery:     BreweryFromModel(beer.Brewery),
 	}
 
 	if beer.ABV != nil {
@@ -72,6 +135,10 @@ func BeerFromModel(beer model.Beer) *api.Beer {
 		pbBeer.ExternalRating = pointy.Float64(*beer.ExternalRating)
 	}
 
+	if len(beer.Tags) > 0 {
+		pbBeer.Tags = TagsFromModel(beer.Tags)
+	}
+
 	return &pbBeer
 }
 
@@ -208,9 +275,40 @@ func CellarFilterToModel(pbFilter *api.CellarFilter) model.AdventCalendarFilter
 		filter.Tags = tags
 	}
 
+	if len(pbFilter.BeerTags) > 0 {
+		beerTags := make([]model.Tag, 0, len(pbFilter.BeerTags))
+		for _, tagName := range pbFilter.BeerTags {
+			beerTags = append(beerTags, model.Tag{Tag: tagName})
+		}
+		filter.BeerTags = beerTags
+	}
+
+	filter.UseMyRating = pbFilter.GetRatingSource() == api.RatingSource_RATING_SOURCE_MINE
+
 	return filter
 }
 
+func AddressFromModel(address model.Address) *api.Address {
+	return &api.Address{
+		Id:            uint64(address.ID),
+		Country:       address.Country,
+		Locality:      address.Locality,
+		Region:        pointy.StringValue(address.Region, ""),
+		PostalCode:    pointy.StringValue(address.PostalCode, ""),
+		StreetAddress: pointy.StringValue(address.StreetAddress, ""),
+	}
+}
+
+func CatalogBreweriesFromModel(breweries []*model.Brewery) []*api.Brewery {
+	pbBreweries := make([]*api.Brewery, 0, len(breweries))
+
+	for _, brewery := range breweries {
+		pbBreweries = append(pbBreweries, BreweryFromModel(*brewery))
+	}
+
+	return pbBreweries
+}
+
 func AddressToModel(pbAddress *api.Address) model.Address {
 	return model.Address{
 		Country:       pbAddress.Country,
@@ -242,6 +340,54 @@ func CellarFromModel(cellar *model.Cellar) *api.Cellar {
 	}
 }
 
+func CellarRoleFromModel(role model.CellarRole) api.CellarRole {
+	switch role {
+	case model.CellarRoleViewer:
+		return api.CellarRole_CELLAR_ROLE_VIEWER
+	case model.CellarRoleEditor:
+		return api.CellarRole_CELLAR_ROLE_EDITOR
+	case model.CellarRoleOwner:
+		return api.CellarRole_CELLAR_ROLE_OWNER
+	case model.CellarRoleNone:
+		return api.CellarRole_CELLAR_ROLE_UNSPECIFIED
+	}
+
+	return api.CellarRole_CELLAR_ROLE_UNSPECIFIED
+}
+
+func CellarRoleToModel(role api.CellarRole) model.CellarRole {
+	switch role {
+	case api.CellarRole_CELLAR_ROLE_VIEWER:
+		return model.CellarRoleViewer
+	case api.CellarRole_CELLAR_ROLE_EDITOR:
+		return model.CellarRoleEditor
+	case api.CellarRole_CELLAR_ROLE_OWNER:
+		return model.CellarRoleOwner
+	case api.CellarRole_CELLAR_ROLE_UNSPECIFIED:
+		return model.CellarRoleNone
+	}
+
+	return model.CellarRoleNone
+}
+
+func CellarMembersFromModel(members []*model.CellarMember) []*api.CellarMember {
+	pbMembers := make([]*api.CellarMember, 0, len(members))
+
+	for index := range members {
+		pbMembers = append(pbMembers, CellarMemberFromModel(members[index]))
+	}
+
+	return pbMembers
+}
+
+func CellarMemberFromModel(member *model.CellarMember) *api.CellarMember {
+	return &api.CellarMember{
+		User:     UserFromModel(member.User),
+		Role:     CellarRoleFromModel(member.Role),
+		Accepted: member.AcceptedAt != nil,
+	}
+}
+
 func CellarBeersFromModel(cellarEntries []*model.CellarEntry) []*api.CellarBeer {
 	beers := make([]*api.CellarBeer, 0, len(cellarEntries))
 	for index := range cellarEntries {
@@ -295,6 +441,66 @@ func CellarBeerFromModel(cellarEntry *model.CellarEntry) *api.CellarBeer {
 	return &cellarBeer
 }
 
+func ConsumptionsFromModel(consumptions []*model.Consumption) []*api.Consumption {
+	pbConsumptions := make([]*api.Consumption, 0, len(consumptions))
+
+	for _, consumption := range consumptions {
+		pbConsumptions = append(pbConsumptions, ConsumptionFromModel(consumption))
+	}
+
+	return pbConsumptions
+}
+
+func ConsumptionFromModel(consumption *model.Consumption) *api.Consumption {
+	pbConsumption := api.Consumption{
+		ConsumptionId: uint64(consumption.ID),
+		CellarId:      uint64(consumption.CellarID),
+		CellarEntryId: uint64(consumption.CellarEntryID),
+		Beer:          BeerFromModel(consumption.Beer),
+		User:          UserFromModel(consumption.User),
+		Quantity:      consumption.Quantity,
+		DrankAt:       timestamppb.New(consumption.DrankAt),
+		Notes:         consumption.Notes,
+	}
+
+	if consumption.Rating != nil {
+		pbConsumption.Rating = pointy.Float64(*consumption.Rating)
+	}
+
+	return &pbConsumption
+}
+
+func TastingNotesFromModel(notes []*model.TastingNote) []*api.TastingNote {
+	pbNotes := make([]*api.TastingNote, 0, len(notes))
+
+	for _, note := range notes {
+		pbNotes = append(pbNotes, TastingNoteFromModel(note))
+	}
+
+	return pbNotes
+}
+
+func TastingNoteFromModel(note *model.TastingNote) *api.TastingNote {
+	pbNote := api.TastingNote{
+		TastingNoteId: uint64(note.ID),
+		Beer:          BeerFromModel(note.Beer),
+		CellarEntryId: optionalUint64(note.CellarEntryID),
+		Vintage:       note.Vintage,
+		Rating:        note.Rating,
+		Aroma:         note.Aroma,
+		Appearance:    note.Appearance,
+		Taste:         note.Taste,
+		Mouthfeel:     note.Mouthfeel,
+		Notes:         note.Notes,
+	}
+
+	if note.ServedAt != nil {
+		pbNote.ServedAt = timestamppb.New(*note.ServedAt)
+	}
+
+	return &pbNote
+}
+
 func TagsFromModel(tags []model.Tag) []string {
 	tagNames := make([]string, 0, len(tags))
 	for _, tag := range tags {
@@ -304,11 +510,53 @@ func TagsFromModel(tags []model.Tag) []string {
 	return tagNames
 }
 
+func TagFromModel(tag model.Tag) *api.Tag {
+	return &api.Tag{Id: uint64(tag.ID), Name: tag.Tag}
+}
+
+func TagUsagesFromModel(usages []*model.TagUsage) []*api.TagUsage {
+	pbUsages := make([]*api.TagUsage, 0, len(usages))
+
+	for _, usage := range usages {
+		cellars := make([]*api.CellarTagUsage, 0, len(usage.Cellars))
+		for _, cellar := range usage.Cellars {
+			cellars = append(cellars, &api.CellarTagUsage{
+				CellarId:   uint64(cellar.CellarID),
+				CellarName: cellar.CellarName,
+				EntryCount: cellar.EntryCount,
+				BeerCount:  cellar.BeerCount,
+			})
+		}
+
+		pbUsages = append(pbUsages, &api.TagUsage{
+			Tag:       TagFromModel(usage.Tag),
+			BeerCount: usage.BeerCount,
+			Cellars:   cellars,
+		})
+	}
+
+	return pbUsages
+}
+
+func UsersFromModel(users []*model.User) []*api.User {
+	pbUsers := make([]*api.User, 0, len(users))
+
+	for _, user := range users {
+		pbUsers = append(pbUsers, UserFromModel(*user))
+	}
+
+	return pbUsers
+}
+
 func UserFromModel(user model.User) *api.User {
 	pbUser := api.User{
-		Id:       user.UUID.String(),
-		UserName: user.Username,
-		Email:    user.Email,
+		Id:        user.UUID.String(),
+		UserName:  user.Username,
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Role:      UserRoleFromModel(user.Role),
+		Disabled:  user.Disabled,
 	}
 
 	if user.UntappdUserName != nil {
@@ -318,6 +566,81 @@ func UserFromModel(user model.User) *api.User {
 	return &pbUser
 }
 
+func AccessTokensFromModel(tokens []*model.AccessToken) []*api.AccessToken {
+	pbTokens := make([]*api.AccessToken, 0, len(tokens))
+
+	for _, token := range tokens {
+		pbTokens = append(pbTokens, AccessTokenFromModel(token))
+	}
+
+	return pbTokens
+}
+
+func AccessTokenFromModel(token *model.AccessToken) *api.AccessToken {
+	pbToken := api.AccessToken{
+		Id:        token.UUID.String(),
+		Name:      token.Name,
+		Scope:     AccessTokenScopeFromModel(token.Scope),
+		Prefix:    token.Prefix,
+		CreatedAt: timestamppb.New(token.CreatedAt),
+	}
+
+	if token.LastUsedAt != nil {
+		pbToken.LastUsedAt = timestamppb.New(*token.LastUsedAt)
+	}
+
+	if token.ExpiresAt != nil {
+		pbToken.ExpiresAt = timestamppb.New(*token.ExpiresAt)
+	}
+
+	return &pbToken
+}
+
+func AccessTokenScopeFromModel(scope model.AccessTokenScope) api.AccessTokenScope {
+	switch scope {
+	case model.AccessTokenScopeReadOnly:
+		return api.AccessTokenScope_ACCESS_TOKEN_SCOPE_READ_ONLY
+	case model.AccessTokenScopeReadWrite:
+		return api.AccessTokenScope_ACCESS_TOKEN_SCOPE_READ_WRITE
+	}
+
+	return api.AccessTokenScope_ACCESS_TOKEN_SCOPE_UNSPECIFIED
+}
+
+// AccessTokenScopeToModel defaults to read-only, so a token only gets write access when it is asked for.
+func AccessTokenScopeToModel(scope api.AccessTokenScope) model.AccessTokenScope {
+	switch scope {
+	case api.AccessTokenScope_ACCESS_TOKEN_SCOPE_READ_WRITE:
+		return model.AccessTokenScopeReadWrite
+	case api.AccessTokenScope_ACCESS_TOKEN_SCOPE_READ_ONLY, api.AccessTokenScope_ACCESS_TOKEN_SCOPE_UNSPECIFIED:
+		return model.AccessTokenScopeReadOnly
+	}
+
+	return model.AccessTokenScopeReadOnly
+}
+
+func UserRoleFromModel(role model.UserRole) api.UserRole {
+	switch role {
+	case model.UserRoleUser:
+		return api.UserRole_USER_ROLE_USER
+	case model.UserRoleAdmin:
+		return api.UserRole_USER_ROLE_ADMIN
+	}
+
+	return api.UserRole_USER_ROLE_UNSPECIFIED
+}
+
+func UserRoleToModel(role api.UserRole) model.UserRole {
+	switch role {
+	case api.UserRole_USER_ROLE_ADMIN:
+		return model.UserRoleAdmin
+	case api.UserRole_USER_ROLE_USER, api.UserRole_USER_ROLE_UNSPECIFIED:
+		return model.UserRoleUser
+	}
+
+	return model.UserRoleUser
+}
+
 func LocationsFromModel(locations []model.LocationInCellar) []*api.LocationInCellar {
 	pbLocations := make([]*api.LocationInCellar, 0, len(locations))
 
@@ -342,6 +665,7 @@ func FormatFromModel(format model.BeerFormat) *api.BeerFormat {
 		PackageType:  format.Package,
 		MetricSize:   format.SizeMetric,
 		ImperialSize: format.SizeImperial,
+		Custom:       format.OwnerID != nil,
 	}
 }
 
@@ -367,7 +691,41 @@ func CellarStatsFromModel(stats *model.CellarStats) *api.CellarStats {
 		AverageAbv:    stats.AverageABV,
 		AverageRating: stats.AverageRating,
 		SpecialCount:  stats.SpecialCount,
+		Verticals:     VerticalSummariesFromModel(stats.Verticals),
+	}
+}
+
+func VerticalSummariesFromModel(verticals []model.VerticalSummary) []*api.VerticalSummary {
+	pbVerticals := make([]*api.VerticalSummary, 0, len(verticals))
+
+	for _, vertical := range verticals {
+		pbVerticals = append(pbVerticals, &api.VerticalSummary{
+			BeerId:          uint64(vertical.BeerID),
+			BeerName:        vertical.BeerName,
+			FirstVintage:    vertical.Vintages[0],
+			LastVintage:     vertical.Vintages[len(vertical.Vintages)-1],
+			Vintages:        vertical.Vintages,
+			MissingVintages: vertical.MissingVintages,
+			Complete:        vertical.Complete(),
+		})
+	}
+
+	return pbVerticals
+}
+
+func VerticalVintagesFromModel(vintages []model.VerticalVintage) []*api.VerticalVintage {
+	pbVintages := make([]*api.VerticalVintage, 0, len(vintages))
+
+	for _, vintage := range vintages {
+		pbVintages = append(pbVintages, &api.VerticalVintage{
+			Vintage:      vintage.Vintage,
+			Quantity:     vintage.Quantity(),
+			Entries:      CellarBeersFromModel(vintage.Entries),
+			TastingNotes: TastingNotesFromModel(vintage.TastingNotes),
+		})
 	}
+
+	return pbVintages
 }
 
 func BreweriesFromModel(breweries []*model.Brewery) []*api.Brewery {
@@ -382,16 +740,62 @@ func BreweriesFromModel(breweries []*model.Brewery) []*api.Brewery {
 	return pbBreweries
 }
 
-func StylesFromModel(styles []*model.BeerStyle) []*api.BeerStyle {
-	pbStyles := make([]*api.BeerStyle, 0, len(styles))
+func StyleFromModel(style model.BeerStyle) *api.BeerStyle {
+	pbStyle := api.BeerStyle{Id: uint64(style.ID), Name: style.Name}
 
-	for index := range styles {
-		style := styles[index]
-		pbStyle := api.BeerStyle{Id: uint64(style.ID), Name: style.Name}
-		pbStyles = append(pbStyles, &pbStyle)
+	if style.ParentID != nil {
+		pbStyle.ParentId = pointy.Uint64(uint64(*style.ParentID))
 	}
 
-	return pbStyles
+	guidelines := api.StyleGuidelines{
+		MinAbv:      style.MinABV,
+		MaxAbv:      style.MaxABV,
+		MinIbu:      optionalUint64(style.MinIBU),
+		MaxIbu:      optionalUint64(style.MaxIBU),
+		MinSrm:      style.MinSRM,
+		MaxSrm:      style.MaxSRM,
+		CellarYears: optionalUint64(style.CellarYears),
+	}
+
+	// Styles without any guideline are returned without guidelines.
+	if proto.Size(&guidelines) > 0 {
+		pbStyle.Guidelines = &guidelines
+	}
+
+	return &pbStyle
+}
+
+// StyleTreeFromModel nests the styles under their families, keeping their order. Styles whose family is not among
+// the styles are returned at the top of the tree.
+func StyleTreeFromModel(styles []*model.BeerStyle) []*api.BeerStyle {
+	pbStyles := make(map[uint]*api.BeerStyle, len(styles))
+	for _, style := range styles {
+		pbStyles[style.ID] = StyleFromModel(*style)
+	}
+
+	roots := make([]*api.BeerStyle, 0, len(styles))
+
+	for _, style := range styles {
+		if style.ParentID != nil {
+			if parent, found := pbStyles[*style.ParentID]; found {
+				parent.Children = append(parent.Children, pbStyles[style.ID])
+
+				continue
+			}
+		}
+
+		roots = append(roots, pbStyles[style.ID])
+	}
+
+	return roots
+}
+
+func optionalUint64(value *uint) *uint64 {
+	if value == nil {
+		return nil
+	}
+
+	return pointy.Uint64(uint64(*value))
 }
 
 func AdventCalendarBeersFromModel(beers []model.AdventCalendarBeer) []*api.AdventCalendarBeer {
@@ -480,5 +884,13 @@ func CellarFilterFromModel(filter *model.AdventCalendarFilter) *api.CellarFilter
 		pbFilter.Tags = TagsFromModel(filter.Tags)
 	}
 
+	if len(filter.BeerTags) > 0 {
+		pbFilter.BeerTags = TagsFromModel(filter.BeerTags)
+	}
+
+	if filter.UseMyRating {
+		pbFilter.RatingSource = api.RatingSource_RATING_SOURCE_MINE
+	}
+
 	return &pbFilter
 }
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"context"
+
+	"github.com/bufbuild/connect-go"
+	"go.uber.org/zap"
+
+	"droscher.com/BeerGargoyle/pkg/server/grpc"
+	api "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+)
+
+func (b *BeerServer) FindDuplicateBeers(ctx context.Context, _ *connect.Request[api.FindDuplicateBeersRequest]) (*connect.Response[api.FindDuplicateBeersResponse], error) {
+	candidates, err := b.repository.FindDuplicateBeers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&api.FindDuplicateBeersResponse{Candidates: grpc.DuplicateCandidatesFromModel(candidates)}), nil
+}
+
+func (b *BeerServer) FindDuplicateBreweries(ctx context.Context, _ *connect.Request[api.FindDuplicateBreweriesRequest]) (*connect.Response[api.FindDuplicateBreweriesResponse], error) {
+	candidates, err := b.repository.FindDuplicateBreweries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&api.FindDuplicateBreweriesResponse{Candidates: grpc.DuplicateCandidatesFromModel(candidates)}), nil
+}
+
+func (b *BeerServer) MergeBeers(ctx context.Context, request *connect.Request[api.MergeBeersRequest]) (*connect.Response[api.MergeBeersResponse], error) {
+	beer, err := b.repository.MergeBeers(ctx, uint(request.Msg.GetKeepId()), uint(request.Msg.GetMergeId()))
+	if err != nil {
+		return nil, err
+	}
+
+	b.logger.Info("beers merged", zap.Uint64("keep_id", request.Msg.GetKeepId()), zap.Uint64("merge_id", request.Msg.GetMergeId()))
+
+	return connect.NewResponse(&api.MergeBeersResponse{Beer: grpc.BeerFromModel(*beer)}), nil
+}
+
+func (b *BeerServer) MergeBreweries(ctx context.Context, request *connect.Request[api.MergeBreweriesRequest]) (*connect.Response[api.MergeBreweriesResponse], error) {
+	brewery, err := b.repository.MergeBreweries(ctx, uint(request.Msg.GetKeepId()), uint(request.Msg.GetMergeId()))
+	if err != nil {
+		return nil, err
+	}
+
+	b.logger.Info("breweries merged", zap.Uint64("keep_id", request.Msg.GetKeepId()), zap.Uint64("merge_id", request.Msg.GetMergeId()))
+
+	return connect.NewResponse(&api.MergeBreweriesResponse{Brewery: grpc.BreweryFromModel(*brewery)}), nil
+}
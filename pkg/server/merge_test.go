@@ -0,0 +1,57 @@
+package server_test
+
+import (
+	"context"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+	"droscher.com/BeerGargoyle/pkg/server"
+	apiv1 "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+)
+
+func (suite *BeerTestSuite) TestFindDuplicateBeers_ReturnsCandidates() {
+	suite.beerRepo.EXPECT().FindDuplicateBeers(mock.Anything).Return([]model.DuplicateCandidate{
+		{KeepID: 3, KeepName: "Pliny the Elder", MergeID: 8, MergeName: "Pliny The Elder", Reason: model.DuplicateReasonName},
+	}, nil)
+
+	result, err := suite.service.FindDuplicateBeers(context.Background(), connect.NewRequest(&apiv1.FindDuplicateBeersRequest{}))
+
+	suite.Require().NoError(err)
+	suite.Require().Len(result.Msg.GetCandidates(), 1)
+	suite.Equal(uint64(8), result.Msg.GetCandidates()[0].GetMergeId())
+	suite.Equal(apiv1.DuplicateReason_DUPLICATE_REASON_NAME, result.Msg.GetCandidates()[0].GetReason())
+}
+
+func (suite *BeerTestSuite) TestMergeBeers_ReturnsSurvivor() {
+	suite.beerRepo.EXPECT().MergeBeers(mock.Anything, uint(3), uint(8)).
+		Return(&model.Beer{Model: gorm.Model{ID: 3}, Name: "Pliny the Elder"}, nil)
+
+	result, err := suite.service.MergeBeers(context.Background(), connect.NewRequest(&apiv1.MergeBeersRequest{KeepId: 3, MergeId: 8}))
+
+	suite.Require().NoError(err)
+	suite.Equal(uint64(3), result.Msg.GetBeer().GetId())
+}
+
+func (suite *BeerTestSuite) TestMergeBeers_MapsErrors() {
+	suite.beerRepo.EXPECT().MergeBeers(mock.Anything, uint(3), uint(3)).Return(nil, repository.ErrInvalidMerge)
+	suite.beerRepo.EXPECT().MergeBeers(mock.Anything, uint(3), uint(9)).Return(nil, repository.ErrBeerNotFound)
+
+	_, err := suite.service.MergeBeers(context.Background(), connect.NewRequest(&apiv1.MergeBeersRequest{KeepId: 3, MergeId: 3}))
+	suite.Equal(connect.CodeInvalidArgument, server.ErrorCode(err))
+
+	_, err = suite.service.MergeBeers(context.Background(), connect.NewRequest(&apiv1.MergeBeersRequest{KeepId: 3, MergeId: 9}))
+	suite.Equal(connect.CodeNotFound, server.ErrorCode(err))
+}
+
+func (suite *BeerTestSuite) TestMergeBreweries_ReturnsSurvivor() {
+	suite.beerRepo.EXPECT().MergeBreweries(mock.Anything, uint(1), uint(2)).Return(bellwoods(), nil)
+
+	result, err := suite.service.MergeBreweries(context.Background(), connect.NewRequest(&apiv1.MergeBreweriesRequest{KeepId: 1, MergeId: 2}))
+
+	suite.Require().NoError(err)
+	suite.Equal("Bellwoods Brewery", result.Msg.GetBrewery().GetName())
+}
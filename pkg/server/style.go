@@ -0,0 +1,92 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"errors"
+
+	"github.com/bufbuild/connect-go"
+	"go.openly.dev/pointy"
+	"go.uber.org/zap"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/server/grpc"
+	api "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+)
+
+// ListBeerStyles returns every style as a tree, with the styles of each family under it.
+func (b *BeerServer) ListBeerStyles(ctx context.Context, _ *connect.Request[api.ListBeerStylesRequest]) (*connect.Response[api.ListBeerStylesResponse], error) {
+	styles, err := b.repository.ListBeerStyles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&api.ListBeerStylesResponse{Styles: grpc.StyleTreeFromModel(styles)}), nil
+}
+
+func (b *BeerServer) UpdateBeerStyle(ctx context.Context, request *connect.Request[api.UpdateBeerStyleRequest]) (*connect.Response[api.UpdateBeerStyleResponse], error) {
+	style, err := b.repository.GetBeerStyle(ctx, uint(request.Msg.GetStyleId()))
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Msg.ParentId != nil {
+		style.ParentID = nil
+		if request.Msg.GetParentId() != 0 {
+			style.ParentID = pointy.Uint(uint(request.Msg.GetParentId()))
+		}
+	}
+
+	if request.Msg.Guidelines != nil {
+		err = setStyleGuidelines(style, request.Msg.GetGuidelines())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = b.repository.UpdateBeerStyle(ctx, style)
+	if err != nil {
+		return nil, err
+	}
+
+	b.logger.Info("beer style updated", zap.Uint("id", style.ID), zap.String("name", style.Name))
+
+	return connect.NewResponse(&api.UpdateBeerStyleResponse{Style: grpc.StyleFromModel(*style)}), nil
+}
+
+func setStyleGuidelines(style *model.BeerStyle, guidelines *api.StyleGuidelines) error {
+	err := errors.Join(
+		checkRange("abv", guidelines.MinAbv, guidelines.MaxAbv),
+		checkRange("ibu", guidelines.MinIbu, guidelines.MaxIbu),
+		checkRange("srm", guidelines.MinSrm, guidelines.MaxSrm),
+	)
+	if err != nil {
+		return err
+	}
+
+	style.MinABV = guidelines.MinAbv
+	style.MaxABV = guidelines.MaxAbv
+	style.MinIBU = optionalUint(guidelines.MinIbu)
+	style.MaxIBU = optionalUint(guidelines.MaxIbu)
+	style.MinSRM = guidelines.MinSrm
+	style.MaxSRM = guidelines.MaxSrm
+	style.CellarYears = optionalUint(guidelines.CellarYears)
+
+	return nil
+}
+
+func checkRange[T cmp.Ordered](name string, minimum *T, maximum *T) error {
+	if minimum != nil && maximum != nil && *minimum > *maximum {
+		return invalidField("guidelines.min_"+name, "min_"+name+" is above max_"+name)
+	}
+
+	return nil
+}
+
+func optionalUint(value *uint64) *uint {
+	if value == nil {
+		return nil
+	}
+
+	return pointy.Uint(uint(*value))
+}
@@ -0,0 +1,94 @@
+package server_test
+
+import (
+	"context"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/stretchr/testify/mock"
+	"go.openly.dev/pointy"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+	"droscher.com/BeerGargoyle/pkg/server"
+	apiv1 "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+)
+
+func (suite *BeerTestSuite) TestListBeerStyles_ReturnsTree() {
+	suite.beerRepo.EXPECT().ListBeerStyles(mock.Anything).Return([]*model.BeerStyle{
+		{Model: gorm.Model{ID: 1}, Name: "IPA"},
+		{Model: gorm.Model{ID: 2}, Name: "IPA - American", ParentID: pointy.Uint(1), MinABV: pointy.Float64(5.5)},
+		{Model: gorm.Model{ID: 3}, Name: "IPA - New England", ParentID: pointy.Uint(1)},
+		{Model: gorm.Model{ID: 4}, Name: "Saison"},
+	}, nil)
+
+	result, err := suite.service.ListBeerStyles(context.Background(), connect.NewRequest(&apiv1.ListBeerStylesRequest{}))
+
+	suite.Require().NoError(err)
+	styles := result.Msg.GetStyles()
+	suite.Require().Len(styles, 2)
+	suite.Equal("IPA", styles[0].GetName())
+	suite.Nil(styles[0].Guidelines)
+	suite.Require().Len(styles[0].GetChildren(), 2)
+	suite.Equal(uint64(1), styles[0].GetChildren()[0].GetParentId())
+	suite.InDelta(5.5, styles[0].GetChildren()[0].GetGuidelines().GetMinAbv(), 0.01)
+	suite.Equal("Saison", styles[1].GetName())
+}
+
+func (suite *BeerTestSuite) TestUpdateBeerStyle_SetsFamilyAndGuidelines() {
+	suite.beerRepo.EXPECT().GetBeerStyle(mock.Anything, uint(2)).Return(&model.BeerStyle{Model: gorm.Model{ID: 2}, Name: "IPA - American"}, nil)
+	suite.beerRepo.EXPECT().UpdateBeerStyle(mock.Anything, mock.MatchedBy(func(style *model.BeerStyle) bool {
+		return *style.ParentID == 1 && *style.MinIBU == 40 && *style.MaxIBU == 70 && *style.CellarYears == 1 && style.MinABV == nil
+	})).Return(nil)
+
+	result, err := suite.service.UpdateBeerStyle(context.Background(), connect.NewRequest(&apiv1.UpdateBeerStyleRequest{
+		StyleId:    2,
+		ParentId:   pointy.Uint64(1),
+		Guidelines: &apiv1.StyleGuidelines{MinIbu: pointy.Uint64(40), MaxIbu: pointy.Uint64(70), CellarYears: pointy.Uint64(1)},
+	}))
+
+	suite.Require().NoError(err)
+	suite.Equal(uint64(1), result.Msg.GetStyle().GetParentId())
+	suite.Equal(uint64(70), result.Msg.GetStyle().GetGuidelines().GetMaxIbu())
+}
+
+func (suite *BeerTestSuite) TestUpdateBeerStyle_ZeroParentMakesFamily() {
+	suite.beerRepo.EXPECT().GetBeerStyle(mock.Anything, uint(2)).
+		Return(&model.BeerStyle{Model: gorm.Model{ID: 2}, Name: "IPA - American", ParentID: pointy.Uint(1), MinABV: pointy.Float64(5.5)}, nil)
+	suite.beerRepo.EXPECT().UpdateBeerStyle(mock.Anything, mock.MatchedBy(func(style *model.BeerStyle) bool {
+		return style.ParentID == nil && *style.MinABV == 5.5
+	})).Return(nil)
+
+	result, err := suite.service.UpdateBeerStyle(context.Background(), connect.NewRequest(&apiv1.UpdateBeerStyleRequest{StyleId: 2, ParentId: pointy.Uint64(0)}))
+
+	suite.Require().NoError(err)
+	suite.Nil(result.Msg.GetStyle().ParentId)
+}
+
+func (suite *BeerTestSuite) TestUpdateBeerStyle_InvalidRange() {
+	suite.beerRepo.EXPECT().GetBeerStyle(mock.Anything, uint(2)).Return(&model.BeerStyle{Model: gorm.Model{ID: 2}}, nil)
+
+	_, err := suite.service.UpdateBeerStyle(context.Background(), connect.NewRequest(&apiv1.UpdateBeerStyleRequest{
+		StyleId:    2,
+		Guidelines: &apiv1.StyleGuidelines{MinAbv: pointy.Float64(9), MaxAbv: pointy.Float64(6)},
+	}))
+
+	suite.Require().ErrorIs(err, server.ErrInvalidInput)
+}
+
+func (suite *BeerTestSuite) TestUpdateBeerStyle_ParentInFamily() {
+	suite.beerRepo.EXPECT().GetBeerStyle(mock.Anything, uint(1)).Return(&model.BeerStyle{Model: gorm.Model{ID: 1}}, nil)
+	suite.beerRepo.EXPECT().UpdateBeerStyle(mock.Anything, mock.Anything).Return(repository.ErrInvalidStyleParent)
+
+	_, err := suite.service.UpdateBeerStyle(context.Background(), connect.NewRequest(&apiv1.UpdateBeerStyleRequest{StyleId: 1, ParentId: pointy.Uint64(2)}))
+
+	suite.Equal(connect.CodeInvalidArgument, server.ErrorCode(err))
+}
+
+func (suite *BeerTestSuite) TestUpdateBeerStyle_NotFound() {
+	suite.beerRepo.EXPECT().GetBeerStyle(mock.Anything, uint(9)).Return(nil, repository.ErrStyleNotFound)
+
+	_, err := suite.service.UpdateBeerStyle(context.Background(), connect.NewRequest(&apiv1.UpdateBeerStyleRequest{StyleId: 9}))
+
+	suite.Equal(connect.CodeNotFound, server.ErrorCode(err))
+}
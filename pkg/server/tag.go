@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"slices"
+
+	"github.com/bufbuild/connect-go"
+	"go.uber.org/zap"
+
+	"droscher.com/BeerGargoyle/configs"
+	"droscher.com/BeerGargoyle/pkg/repository"
+	"droscher.com/BeerGargoyle/pkg/server/grpc"
+	api "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+	"droscher.com/BeerGargoyle/pkg/server/grpc/api/v1/apiv1connect"
+)
+
+type TagServer struct {
+	apiv1connect.UnimplementedTagServiceHandler
+	repository repository.TagRepository
+	logger     *zap.Logger
+	config     *configs.Config
+}
+
+func NewTagServer(repository repository.TagRepository, logger *zap.Logger, config *configs.Config) *TagServer {
+	return &TagServer{repository: repository, logger: logger, config: config}
+}
+
+// ListTags returns every tag with the number of beers having it, and how it is used in the cellars of the current user.
+func (t *TagServer) ListTags(ctx context.Context, _ *connect.Request[api.ListTagsRequest]) (*connect.Response[api.ListTagsResponse], error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	usages, err := t.repository.ListTagUsage(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&api.ListTagsResponse{Tags: grpc.TagUsagesFromModel(usages)}), nil
+}
+
+func (t *TagServer) RenameTag(ctx context.Context, request *connect.Request[api.RenameTagRequest]) (*connect.Response[api.RenameTagResponse], error) {
+	name := normalizeTagName(t.config.Tags, request.Msg.GetName())
+	if len(name) == 0 {
+		return nil, invalidField("name", "tag name must be set")
+	}
+
+	tag, err := t.repository.RenameTag(ctx, uint(request.Msg.GetId()), name)
+	if err != nil {
+		return nil, err
+	}
+
+	t.logger.Info("tag renamed", zap.Uint64("id", request.Msg.GetId()), zap.String("name", name))
+
+	return connect.NewResponse(&api.RenameTagResponse{Tag: grpc.TagFromModel(*tag)}), nil
+}
+
+func (t *TagServer) MergeTags(ctx context.Context, request *connect.Request[api.MergeTagsRequest]) (*connect.Response[api.MergeTagsResponse], error) {
+	mergeIDs := make([]uint, 0, len(request.Msg.GetMergeIds()))
+	for _, mergeID := range request.Msg.GetMergeIds() {
+		if !slices.Contains(mergeIDs, uint(mergeID)) {
+			mergeIDs = append(mergeIDs, uint(mergeID))
+		}
+	}
+
+	tag, err := t.repository.MergeTags(ctx, uint(request.Msg.GetKeepId()), mergeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	t.logger.Info("tags merged", zap.Uint64("keep_id", request.Msg.GetKeepId()), zap.Uint64s("merge_ids", request.Msg.GetMergeIds()))
+
+	return connect.NewResponse(&api.MergeTagsResponse{Tag: grpc.TagFromModel(*tag)}), nil
+}
+
+func (t *TagServer) DeleteTag(ctx context.Context, request *connect.Request[api.DeleteTagRequest]) (*connect.Response[api.DeleteTagResponse], error) {
+	err := t.repository.DeleteTag(ctx, uint(request.Msg.GetId()))
+	if err != nil {
+		return nil, err
+	}
+
+	t.logger.Info("tag deleted", zap.Uint64("id", request.Msg.GetId()))
+
+	return connect.NewResponse(&api.DeleteTagResponse{}), nil
+}
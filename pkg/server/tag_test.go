@@ -0,0 +1,112 @@
+package server_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/configs"
+	"droscher.com/BeerGargoyle/mocks"
+	"droscher.com/BeerGargoyle/pkg/auth"
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+	"droscher.com/BeerGargoyle/pkg/server"
+	apiv1 "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+)
+
+type TagTestSuite struct {
+	suite.Suite
+	tagRepo *mocks.TagRepository
+	service *server.TagServer
+}
+
+func TestTagTestSuite(t *testing.T) {
+	suite.Run(t, new(TagTestSuite))
+}
+
+func (suite *TagTestSuite) SetupTest() {
+	suite.tagRepo = mocks.NewTagRepository(suite.T())
+	suite.service = server.NewTagServer(suite.tagRepo, zaptest.NewLogger(suite.T()), &configs.Config{
+		Tags: configs.Tags{FoldCase: true, TrimSpace: true},
+	})
+}
+
+func (suite *TagTestSuite) TestListTags_ReturnsUsage() {
+	ctx := context.WithValue(context.Background(), auth.UserKey{}, &model.User{Model: gorm.Model{ID: 7}})
+
+	suite.tagRepo.EXPECT().ListTagUsage(mock.Anything, uint(7)).Return([]*model.TagUsage{
+		{
+			Tag:       model.Tag{Model: gorm.Model{ID: 2}, Tag: "barrel-aged"},
+			BeerCount: 5,
+			Cellars:   []model.CellarTagUsage{{TagID: 2, CellarID: 3, CellarName: "Basement", EntryCount: 1, BeerCount: 2}},
+		},
+	}, nil)
+
+	result, err := suite.service.ListTags(ctx, connect.NewRequest(&apiv1.ListTagsRequest{}))
+
+	suite.Require().NoError(err)
+	suite.Require().Len(result.Msg.GetTags(), 1)
+	suite.Equal("barrel-aged", result.Msg.GetTags()[0].GetTag().GetName())
+	suite.Equal(uint64(5), result.Msg.GetTags()[0].GetBeerCount())
+	suite.Require().Len(result.Msg.GetTags()[0].GetCellars(), 1)
+	suite.Equal("Basement", result.Msg.GetTags()[0].GetCellars()[0].GetCellarName())
+	suite.Equal(uint64(2), result.Msg.GetTags()[0].GetCellars()[0].GetBeerCount())
+}
+
+func (suite *TagTestSuite) TestRenameTag_NormalizesName() {
+	suite.tagRepo.EXPECT().RenameTag(mock.Anything, uint(2), "barrel aged").
+		Return(&model.Tag{Model: gorm.Model{ID: 2}, Tag: "barrel aged"}, nil)
+
+	result, err := suite.service.RenameTag(context.Background(), connect.NewRequest(&apiv1.RenameTagRequest{Id: 2, Name: "  Barrel   Aged "}))
+
+	suite.Require().NoError(err)
+	suite.Equal("barrel aged", result.Msg.GetTag().GetName())
+}
+
+func (suite *TagTestSuite) TestRenameTag_MapsErrors() {
+	suite.tagRepo.EXPECT().RenameTag(mock.Anything, uint(2), "sour").Return(nil, repository.ErrTagExists)
+	suite.tagRepo.EXPECT().RenameTag(mock.Anything, uint(9), "sour").Return(nil, repository.ErrTagNotFound)
+
+	_, err := suite.service.RenameTag(context.Background(), connect.NewRequest(&apiv1.RenameTagRequest{Id: 2, Name: "sour"}))
+	suite.Equal(connect.CodeAlreadyExists, server.ErrorCode(err))
+
+	_, err = suite.service.RenameTag(context.Background(), connect.NewRequest(&apiv1.RenameTagRequest{Id: 9, Name: "sour"}))
+	suite.Equal(connect.CodeNotFound, server.ErrorCode(err))
+
+	_, err = suite.service.RenameTag(context.Background(), connect.NewRequest(&apiv1.RenameTagRequest{Id: 2, Name: "  "}))
+	suite.ErrorIs(err, server.ErrInvalidInput)
+}
+
+func (suite *TagTestSuite) TestMergeTags_ReturnsSurvivor() {
+	suite.tagRepo.EXPECT().MergeTags(mock.Anything, uint(1), []uint{2, 3}).
+		Return(&model.Tag{Model: gorm.Model{ID: 1}, Tag: "sour"}, nil)
+
+	result, err := suite.service.MergeTags(context.Background(), connect.NewRequest(&apiv1.MergeTagsRequest{KeepId: 1, MergeIds: []uint64{2, 3, 2}}))
+
+	suite.Require().NoError(err)
+	suite.Equal(uint64(1), result.Msg.GetTag().GetId())
+}
+
+func (suite *TagTestSuite) TestMergeTags_IntoItself() {
+	suite.tagRepo.EXPECT().MergeTags(mock.Anything, uint(1), []uint{1}).Return(nil, repository.ErrInvalidMerge)
+
+	_, err := suite.service.MergeTags(context.Background(), connect.NewRequest(&apiv1.MergeTagsRequest{KeepId: 1, MergeIds: []uint64{1}}))
+
+	suite.Equal(connect.CodeInvalidArgument, server.ErrorCode(err))
+}
+
+func (suite *TagTestSuite) TestDeleteTag_Deletes() {
+	suite.tagRepo.EXPECT().DeleteTag(mock.Anything, uint(2)).Return(nil)
+	suite.tagRepo.EXPECT().DeleteTag(mock.Anything, uint(9)).Return(repository.ErrTagNotFound)
+
+	_, err := suite.service.DeleteTag(context.Background(), connect.NewRequest(&apiv1.DeleteTagRequest{Id: 2}))
+	suite.Require().NoError(err)
+
+	_, err = suite.service.DeleteTag(context.Background(), connect.NewRequest(&apiv1.DeleteTagRequest{Id: 9}))
+	suite.Equal(connect.CodeNotFound, server.ErrorCode(err))
+}
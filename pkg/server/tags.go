@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"droscher.com/BeerGargoyle/configs"
+	"droscher.com/BeerGargoyle/pkg/model"
+	api "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+)
+
+type tagRepository interface {
+	GetTagsByNames(ctx context.Context, names []string) (map[string]model.Tag, error)
+}
+
+// normalizeTagName cleans up a tag name as configured. Empty names are returned as is, and are dropped by the callers.
+func normalizeTagName(conf configs.Tags, name string) string {
+	if conf.TrimSpace {
+		name = strings.Join(strings.Fields(name), " ")
+	}
+
+	if conf.FoldCase {
+		name = strings.ToLower(name)
+	}
+
+	return name
+}
+
+// normalizeTagNames cleans up the tag names as configured, dropping empty names and names that became duplicates.
+func normalizeTagNames(conf configs.Tags, names []string) []string {
+	normalized := make([]string, 0, len(names))
+	seen := make(map[string]struct{}, len(names))
+
+	for _, name := range names {
+		name = normalizeTagName(conf, name)
+		if _, found := seen[name]; found || len(name) == 0 {
+			continue
+		}
+
+		seen[name] = struct{}{}
+		normalized = append(normalized, name)
+	}
+
+	return normalized
+}
+
+// normalizeFilterTags cleans up the tag names of a filter, so that they match the tags as they were stored.
+func normalizeFilterTags(conf configs.Tags, filter *api.CellarFilter) {
+	if filter == nil {
+		return
+	}
+
+	filter.Tags = normalizeTagNames(conf, filter.GetTags())
+	filter.BeerTags = normalizeTagNames(conf, filter.GetBeerTags())
+}
+
+// tagsFromNames returns the tags with the given names, reusing the tags that already exist. Tags that do not exist yet
+// are returned unsaved, to be created along with the row they are attached to. The names are normalized first.
+func tagsFromNames(ctx context.Context, repository tagRepository, logger *zap.Logger, conf configs.Tags, names []string) []model.Tag {
+	names = normalizeTagNames(conf, names)
+	tags := make([]model.Tag, 0, len(names))
+
+	tagsByName, err := repository.GetTagsByNames(ctx, names)
+	if err != nil {
+		logger.Error("error getting tags by name", zap.Error(err))
+
+		tagsByName = map[string]model.Tag{}
+	}
+
+	for _, tagName := range names {
+		if tag, ok := tagsByName[tagName]; ok {
+			tags = append(tags, tag)
+		} else {
+			tags = append(tags, model.Tag{Tag: tagName})
+		}
+	}
+
+	return tags
+}
@@ -0,0 +1,205 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/bufbuild/connect-go"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+	"droscher.com/BeerGargoyle/pkg/server/grpc"
+	api "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+)
+
+// tastingScore is one of the optional scores of a tasting note, named after its field in the request.
+type tastingScore struct {
+	field string
+	value *float64
+}
+
+// raterID returns the user whose tasting notes rate beers for the rating source, or zero for the external rating.
+func raterID(ctx context.Context, source api.RatingSource) (uint, error) {
+	if source != api.RatingSource_RATING_SOURCE_MINE {
+		return 0, nil
+	}
+
+	user, err := currentUser(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return user.ID, nil
+}
+
+func validateTastingScores(scores ...tastingScore) error {
+	for _, score := range scores {
+		if score.value != nil && (*score.value < 0 || *score.value > maximumRating) {
+			return invalidField(score.field, score.field+" must be between 0 and 5")
+		}
+	}
+
+	return nil
+}
+
+func optionalTime(timestamp *timestamppb.Timestamp) *time.Time {
+	if timestamp == nil {
+		return nil
+	}
+
+	at := timestamp.AsTime()
+
+	return &at
+}
+
+// AddTastingNote records the current user's impression of a beer. Notes of a cellar entry need access to its cellar,
+// and take the beer and vintage from the entry.
+func (c *CellarServer) AddTastingNote(ctx context.Context, request *connect.Request[api.AddTastingNoteRequest]) (*connect.Response[api.AddTastingNoteResponse], error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateTastingScores(
+		tastingScore{"rating", request.Msg.Rating},
+		tastingScore{"aroma", request.Msg.Aroma},
+		tastingScore{"appearance", request.Msg.Appearance},
+		tastingScore{"taste", request.Msg.Taste},
+		tastingScore{"mouthfeel", request.Msg.Mouthfeel},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	note := model.TastingNote{
+		UserID:     user.ID,
+		BeerID:     uint(request.Msg.GetBeerId()),
+		Vintage:    request.Msg.Vintage,
+		Rating:     request.Msg.Rating,
+		Aroma:      request.Msg.Aroma,
+		Appearance: request.Msg.Appearance,
+		Taste:      request.Msg.Taste,
+		Mouthfeel:  request.Msg.Mouthfeel,
+		Notes:      request.Msg.GetNotes(),
+		ServedAt:   optionalTime(request.Msg.GetServedAt()),
+	}
+
+	if request.Msg.CellarEntryId != nil {
+		err = c.tastingNoteOfEntry(ctx, uint(request.Msg.GetCellarEntryId()), &note)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if note.BeerID == 0 {
+		return nil, invalidField("beer_id", "a beer or cellar entry is required")
+	}
+
+	saved, err := c.cellarRepository.AddTastingNote(ctx, note)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&api.AddTastingNoteResponse{TastingNote: grpc.TastingNoteFromModel(saved)}), nil
+}
+
+// tastingNoteOfEntry ties the note to a cellar entry the current user can see, filling in the beer and vintage.
+func (c *CellarServer) tastingNoteOfEntry(ctx context.Context, cellarEntryID uint, note *model.TastingNote) error {
+	err := c.authorizeCellarEntry(ctx, cellarEntryID, model.CellarRoleViewer)
+	if err != nil {
+		return err
+	}
+
+	entry, err := c.cellarRepository.GetCellarEntryByID(ctx, cellarEntryID)
+	if err != nil {
+		return err
+	}
+
+	if note.BeerID != 0 && note.BeerID != entry.BeerID {
+		return invalidField("beer_id", "the beer does not match the cellar entry")
+	}
+
+	note.BeerID = entry.BeerID
+	note.CellarEntryID = &cellarEntryID
+
+	if note.Vintage == nil {
+		note.Vintage = entry.Vintage
+	}
+
+	return nil
+}
+
+func (c *CellarServer) UpdateTastingNote(ctx context.Context, request *connect.Request[api.UpdateTastingNoteRequest]) (*connect.Response[api.UpdateTastingNoteResponse], error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateTastingScores(
+		tastingScore{"rating", request.Msg.Rating},
+		tastingScore{"aroma", request.Msg.Aroma},
+		tastingScore{"appearance", request.Msg.Appearance},
+		tastingScore{"taste", request.Msg.Taste},
+		tastingScore{"mouthfeel", request.Msg.Mouthfeel},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	note := model.TastingNote{
+		Model:      gorm.Model{ID: uint(request.Msg.GetTastingNoteId())},
+		UserID:     user.ID,
+		Vintage:    request.Msg.Vintage,
+		Rating:     request.Msg.Rating,
+		Aroma:      request.Msg.Aroma,
+		Appearance: request.Msg.Appearance,
+		Taste:      request.Msg.Taste,
+		Mouthfeel:  request.Msg.Mouthfeel,
+		Notes:      request.Msg.GetNotes(),
+		ServedAt:   optionalTime(request.Msg.GetServedAt()),
+	}
+
+	updated, err := c.cellarRepository.UpdateTastingNote(ctx, &note)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&api.UpdateTastingNoteResponse{TastingNote: grpc.TastingNoteFromModel(updated)}), nil
+}
+
+func (c *CellarServer) DeleteTastingNote(ctx context.Context, request *connect.Request[api.DeleteTastingNoteRequest]) (*connect.Response[api.DeleteTastingNoteResponse], error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.cellarRepository.DeleteTastingNote(ctx, uint(request.Msg.GetTastingNoteId()), user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&api.DeleteTastingNoteResponse{}), nil
+}
+
+func (c *CellarServer) ListTastingNotes(ctx context.Context, request *connect.Request[api.ListTastingNotesRequest]) (*connect.Response[api.ListTastingNotesResponse], error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	page := repository.Page{Size: int(request.Msg.GetPageSize()), Token: request.Msg.GetPageToken()}
+
+	notes, nextPageToken, err := c.cellarRepository.ListTastingNotes(ctx, user.ID, uint(request.Msg.GetBeerId()), page)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.ListTastingNotesResponse{
+		TastingNotes:  grpc.TastingNotesFromModel(notes),
+		NextPageToken: nextPageToken,
+	}
+
+	return connect.NewResponse(&response), nil
+}
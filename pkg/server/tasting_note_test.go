@@ -0,0 +1,109 @@
+package server_test
+
+import (
+	"github.com/bufbuild/connect-go"
+	"github.com/stretchr/testify/mock"
+	"go.openly.dev/pointy"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+	"droscher.com/BeerGargoyle/pkg/server"
+	apiv1 "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+)
+
+func (suite *CellarTestSuite) TestAddTastingNote_FromCellarEntry() {
+	ctx := suite.userContext()
+	suite.expectCellarEntryRole(ctx, 10, model.CellarRoleViewer)
+	suite.cellarRepo.EXPECT().GetCellarEntryByID(ctx, uint(10)).
+		Return(&model.CellarEntry{Model: gorm.Model{ID: 10}, CellarID: 1, BeerID: 5, Vintage: pointy.Uint64(2021)}, nil)
+	suite.cellarRepo.EXPECT().AddTastingNote(ctx, mock.MatchedBy(func(note model.TastingNote) bool {
+		return note.UserID == 1 && note.BeerID == 5 && *note.CellarEntryID == 10 && *note.Vintage == 2021 &&
+			*note.Rating == 4.25 && note.Aroma == nil
+	})).Return(&model.TastingNote{Model: gorm.Model{ID: 7}, UserID: 1, BeerID: 5, Rating: pointy.Float64(4.25)}, nil)
+
+	request := &apiv1.AddTastingNoteRequest{CellarEntryId: pointy.Uint64(10), Rating: pointy.Float64(4.25)}
+	response, err := suite.service.AddTastingNote(ctx, connect.NewRequest(request))
+	suite.Require().NoError(err)
+
+	suite.Equal(uint64(7), response.Msg.GetTastingNote().GetTastingNoteId())
+	suite.InDelta(4.25, response.Msg.GetTastingNote().GetRating(), 0.001)
+	suite.Nil(response.Msg.GetTastingNote().Aroma)
+}
+
+func (suite *CellarTestSuite) TestAddTastingNote_BeerDoesNotMatchEntry() {
+	ctx := suite.userContext()
+	suite.expectCellarEntryRole(ctx, 10, model.CellarRoleViewer)
+	suite.cellarRepo.EXPECT().GetCellarEntryByID(ctx, uint(10)).
+		Return(&model.CellarEntry{Model: gorm.Model{ID: 10}, CellarID: 1, BeerID: 5}, nil)
+
+	request := &apiv1.AddTastingNoteRequest{BeerId: 6, CellarEntryId: pointy.Uint64(10)}
+	_, err := suite.service.AddTastingNote(ctx, connect.NewRequest(request))
+
+	suite.Equal(connect.CodeInvalidArgument, server.ErrorCode(err))
+}
+
+func (suite *CellarTestSuite) TestAddTastingNote_ScoreOutOfRange() {
+	ctx := suite.userContext()
+
+	request := &apiv1.AddTastingNoteRequest{BeerId: 5, Taste: pointy.Float64(5.5)}
+	_, err := suite.service.AddTastingNote(ctx, connect.NewRequest(request))
+
+	suite.Equal(connect.CodeInvalidArgument, server.ErrorCode(err))
+}
+
+func (suite *CellarTestSuite) TestAddTastingNote_RequiresBeer() {
+	ctx := suite.userContext()
+
+	_, err := suite.service.AddTastingNote(ctx, connect.NewRequest(&apiv1.AddTastingNoteRequest{Rating: pointy.Float64(3)}))
+
+	suite.Equal(connect.CodeInvalidArgument, server.ErrorCode(err))
+}
+
+func (suite *CellarTestSuite) TestUpdateTastingNote_OtherUsersNote() {
+	ctx := suite.userContext()
+	suite.cellarRepo.EXPECT().UpdateTastingNote(ctx, mock.MatchedBy(func(note *model.TastingNote) bool {
+		return note.ID == 7 && note.UserID == 1
+	})).Return(nil, repository.ErrTastingNoteNotFound)
+
+	_, err := suite.service.UpdateTastingNote(ctx, connect.NewRequest(&apiv1.UpdateTastingNoteRequest{TastingNoteId: 7}))
+
+	suite.Equal(connect.CodeNotFound, server.ErrorCode(err))
+}
+
+func (suite *CellarTestSuite) TestListTastingNotes_ForBeer() {
+	ctx := suite.userContext()
+	suite.cellarRepo.EXPECT().ListTastingNotes(ctx, uint(1), uint(5), repository.Page{Size: 10}).
+		Return([]*model.TastingNote{{Model: gorm.Model{ID: 7}, UserID: 1, BeerID: 5}}, "next", nil)
+
+	response, err := suite.service.ListTastingNotes(ctx, connect.NewRequest(&apiv1.ListTastingNotesRequest{BeerId: pointy.Uint64(5), PageSize: 10}))
+	suite.Require().NoError(err)
+
+	suite.Require().Len(response.Msg.GetTastingNotes(), 1)
+	suite.Equal("next", response.Msg.GetNextPageToken())
+}
+
+func (suite *CellarTestSuite) TestListCellarBeers_FiltersOnMyRating() {
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleOwner)
+	filter := &apiv1.CellarFilter{MinimumRating: pointy.Float64(4), RatingSource: apiv1.RatingSource_RATING_SOURCE_MINE}
+
+	suite.cellarRepo.EXPECT().ListCellarBeers(ctx, uint(1), filter, uint(1), repository.CellarSort{Field: repository.CellarSortName}, repository.Page{}).
+		Return(nil, "", 0, nil)
+
+	_, err := suite.service.ListCellarBeers(ctx, connect.NewRequest(&apiv1.ListCellarBeersRequest{CellarId: 1, Filter: filter}))
+
+	suite.Require().NoError(err)
+}
+
+func (suite *CellarTestSuite) TestGetCellarStats_AveragesMyRatings() {
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleOwner)
+	suite.cellarRepo.EXPECT().GetCellarStats(ctx, uint(1), uint(1)).Return(&model.CellarStats{CellarID: 1, AverageRating: 3.5}, nil)
+
+	request := &apiv1.GetCellarStatsRequest{CellarId: 1, RatingSource: apiv1.RatingSource_RATING_SOURCE_MINE}
+	result, err := suite.service.GetCellarStats(ctx, connect.NewRequest(request))
+
+	suite.Require().NoError(err)
+	suite.InDelta(3.5, result.Msg.GetCellarStats().GetAverageRating(), 0.001)
+}
@@ -3,12 +3,18 @@ package server
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/bufbuild/connect-go"
+	"github.com/google/uuid"
 	"go.openly.dev/pointy"
 	"go.uber.org/zap"
 
+	"droscher.com/BeerGargoyle/pkg/auth"
+	"droscher.com/BeerGargoyle/pkg/model"
 	"droscher.com/BeerGargoyle/pkg/repository"
+	"droscher.com/BeerGargoyle/pkg/server/grpc"
 	api "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
 	"droscher.com/BeerGargoyle/pkg/server/grpc/api/v1/apiv1connect"
 )
@@ -17,34 +23,43 @@ var ErrUserNotFound = errors.New("user not found")
 
 type UserServer struct {
 	apiv1connect.UnimplementedUserServiceHandler
-	repository *repository.Repository
+	repository repository.UserRepository
 	logger     *zap.Logger
 }
 
-func NewUserServer(repository *repository.Repository, logger *zap.Logger) *UserServer {
+func NewUserServer(repository repository.UserRepository, logger *zap.Logger) *UserServer {
 	return &UserServer{repository: repository, logger: logger}
 }
 
+// AddUser signs up the user the token in the request was issued to. The email is always the one from the token, so
+// that nobody can create an account for someone else's email.
 func (u *UserServer) AddUser(ctx context.Context, request *connect.Request[api.AddUserRequest]) (*connect.Response[api.AddUserResponse], error) {
+	profile, ok := ctx.Value(auth.ProfileKey{}).(*model.User)
+	if !ok || profile == nil {
+		return nil, fmt.Errorf("%w: no verified token in context", ErrUnauthenticated)
+	}
+
+	if request.Msg.GetEmail() != "" && !strings.EqualFold(request.Msg.GetEmail(), profile.Email) {
+		return nil, invalidField("email", "the email must match the signed in account")
+	}
+
+	name := request.Msg.GetName()
+	if name == "" {
+		name = profile.Username
+	}
+
 	var untappdUserName *string
 
 	if len(request.Msg.GetUntappedUsername()) > 0 {
 		untappdUserName = pointy.String(request.Msg.GetUntappedUsername())
 	}
 
-	user, err := u.repository.AddUser(ctx, request.Msg.GetName(), request.Msg.GetEmail(), untappdUserName)
+	user, err := u.repository.AddUser(ctx, name, profile.Email, untappdUserName)
 	if err != nil {
 		return nil, err
 	}
 
-	grpcUser := api.User{
-		Id:               user.UUID.String(),
-		UserName:         user.Username,
-		Email:            user.Email,
-		UntappedUsername: untappdUserName,
-	}
-
-	return connect.NewResponse(&api.AddUserResponse{User: &grpcUser}), nil
+	return connect.NewResponse(&api.AddUserResponse{User: grpc.UserFromModel(*user)}), nil
 }
 
 func (u *UserServer) GetUserByEmail(ctx context.Context, request *connect.Request[api.GetUserByEmailRequest]) (*connect.Response[api.GetUserByEmailResponse], error) {
@@ -53,12 +68,141 @@ func (u *UserServer) GetUserByEmail(ctx context.Context, request *connect.Reques
 		return nil, err
 	}
 
-	grpcUser := api.User{
-		Id:               user.UUID.String(),
-		UserName:         user.Username,
-		Email:            user.Email,
-		UntappedUsername: user.UntappdUserName,
+	return connect.NewResponse(&api.GetUserByEmailResponse{User: grpc.UserFromModel(*user)}), nil
+}
+
+func (u *UserServer) GetMe(ctx context.Context, _ *connect.Request[api.GetMeRequest]) (*connect.Response[api.GetMeResponse], error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&api.GetMeResponse{User: grpc.UserFromModel(*user)}), nil
+}
+
+func (u *UserServer) ListUsers(ctx context.Context, request *connect.Request[api.ListUsersRequest]) (*connect.Response[api.ListUsersResponse], error) {
+	page := repository.Page{Size: int(request.Msg.GetPageSize()), Token: request.Msg.GetPageToken()}
+
+	users, nextPageToken, err := u.repository.ListUsers(ctx, page)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.ListUsersResponse{Users: grpc.UsersFromModel(users), NextPageToken: nextPageToken}
+
+	return connect.NewResponse(&response), nil
+}
+
+func (u *UserServer) UpdateUser(ctx context.Context, request *connect.Request[api.UpdateUserRequest]) (*connect.Response[api.UpdateUserResponse], error) {
+	caller, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := u.userFromID(ctx, request.Msg.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Msg.UserName != nil {
+		user.Username = request.Msg.GetUserName()
+	}
+
+	if request.Msg.FirstName != nil {
+		user.FirstName = request.Msg.GetFirstName()
+	}
+
+	if request.Msg.LastName != nil {
+		user.LastName = request.Msg.GetLastName()
+	}
+
+	if request.Msg.UntappedUsername != nil {
+		user.UntappdUserName = pointy.String(request.Msg.GetUntappedUsername())
+	}
+
+	if request.Msg.GetRole() != api.UserRole_USER_ROLE_UNSPECIFIED {
+		role := grpc.UserRoleToModel(request.Msg.GetRole())
+		if user.ID == caller.ID && role != user.Role {
+			return nil, fmt.Errorf("%w: cannot change your own role", ErrInvalidInput)
+		}
+
+		user.Role = role
+	}
+
+	err = u.repository.UpdateUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	u.logger.Info("user updated", zap.Uint("user_id", user.ID), zap.Uint("updated_by", caller.ID))
+
+	return connect.NewResponse(&api.UpdateUserResponse{User: grpc.UserFromModel(*user)}), nil
+}
+
+func (u *UserServer) DisableUser(ctx context.Context, request *connect.Request[api.DisableUserRequest]) (*connect.Response[api.DisableUserResponse], error) {
+	user, err := u.otherUserFromID(ctx, request.Msg.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.repository.SetUserDisabled(ctx, user.ID, request.Msg.GetDisabled())
+	if err != nil {
+		return nil, err
+	}
+
+	user.Disabled = request.Msg.GetDisabled()
+
+	u.logger.Info("user disabled state changed", zap.Uint("user_id", user.ID), zap.Bool("disabled", user.Disabled))
+
+	return connect.NewResponse(&api.DisableUserResponse{User: grpc.UserFromModel(*user)}), nil
+}
+
+func (u *UserServer) DeleteUser(ctx context.Context, request *connect.Request[api.DeleteUserRequest]) (*connect.Response[api.DeleteUserResponse], error) {
+	user, err := u.otherUserFromID(ctx, request.Msg.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.repository.DeleteUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	u.logger.Info("user deleted", zap.Uint("user_id", user.ID))
+
+	return connect.NewResponse(&api.DeleteUserResponse{}), nil
+}
+
+func (u *UserServer) userFromID(ctx context.Context, id string) (*model.User, error) {
+	userUUID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", invalidField("id", "invalid user id"), err)
+	}
+
+	user, err := u.repository.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// otherUserFromID looks up a user that an administrator is acting on, which must not be the administrator themselves
+// so that they cannot lock themselves out.
+func (u *UserServer) otherUserFromID(ctx context.Context, id string) (*model.User, error) {
+	caller, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := u.userFromID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.ID == caller.ID {
+		return nil, fmt.Errorf("%w: cannot disable or delete your own account", ErrInvalidInput)
 	}
 
-	return connect.NewResponse(&api.GetUserByEmailResponse{User: &grpcUser}), nil
+	return user, nil
 }
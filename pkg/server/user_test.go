@@ -0,0 +1,206 @@
+package server_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.openly.dev/pointy"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/mocks"
+	"droscher.com/BeerGargoyle/pkg/auth"
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+	"droscher.com/BeerGargoyle/pkg/server"
+	apiv1 "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+)
+
+const friendUUID = "0b7e1c52-2f0a-4c49-8d3c-62f1a9b6c0de"
+
+type UserTestSuite struct {
+	suite.Suite
+	userRepo *mocks.UserRepository
+	service  *server.UserServer
+	admin    *model.User
+}
+
+func TestUserTestSuite(t *testing.T) {
+	suite.Run(t, new(UserTestSuite))
+}
+
+func (suite *UserTestSuite) SetupTest() {
+	suite.userRepo = mocks.NewUserRepository(suite.T())
+	suite.service = server.NewUserServer(suite.userRepo, zaptest.NewLogger(suite.T()))
+	suite.admin = &model.User{
+		Model:    gorm.Model{ID: 1},
+		UUID:     uuid.MustParse("6f1c2a8e-4c1b-4a43-9d0e-1f6d4f8f2b11"),
+		Username: "admin",
+		Email:    "admin@example.com",
+		Role:     model.UserRoleAdmin,
+	}
+}
+
+func (suite *UserTestSuite) adminContext() context.Context {
+	return context.WithValue(context.Background(), auth.UserKey{}, suite.admin)
+}
+
+func (suite *UserTestSuite) friend() *model.User {
+	return &model.User{
+		Model:    gorm.Model{ID: 2},
+		UUID:     uuid.MustParse(friendUUID),
+		Username: "friend",
+		Email:    "friend@example.com",
+		Role:     model.UserRoleUser,
+	}
+}
+
+func (suite *UserTestSuite) TestGetMe_ReturnsCurrentUser() {
+	response, err := suite.service.GetMe(suite.adminContext(), connect.NewRequest(&apiv1.GetMeRequest{}))
+
+	suite.Require().NoError(err)
+	suite.Equal("admin@example.com", response.Msg.GetUser().GetEmail())
+	suite.Equal(apiv1.UserRole_USER_ROLE_ADMIN, response.Msg.GetUser().GetRole())
+}
+
+func (suite *UserTestSuite) TestGetMe_Unauthenticated() {
+	_, err := suite.service.GetMe(context.Background(), connect.NewRequest(&apiv1.GetMeRequest{}))
+
+	suite.Require().ErrorIs(err, server.ErrUnauthenticated)
+}
+
+func (suite *UserTestSuite) signUpContext() context.Context {
+	return context.WithValue(context.Background(), auth.ProfileKey{}, &model.User{Email: "new@example.com", Username: "newbie"})
+}
+
+func (suite *UserTestSuite) TestAddUser_UsesEmailFromToken() {
+	ctx := suite.signUpContext()
+	suite.userRepo.EXPECT().AddUser(ctx, "newbie", "new@example.com", (*string)(nil)).
+		Return(&model.User{Model: gorm.Model{ID: 5}, Username: "newbie", Email: "new@example.com"}, nil)
+
+	response, err := suite.service.AddUser(ctx, connect.NewRequest(&apiv1.AddUserRequest{}))
+
+	suite.Require().NoError(err)
+	suite.Equal("new@example.com", response.Msg.GetUser().GetEmail())
+}
+
+func (suite *UserTestSuite) TestAddUser_SecondSignUpRejected() {
+	ctx := suite.signUpContext()
+	suite.userRepo.EXPECT().AddUser(ctx, "newbie", "new@example.com", (*string)(nil)).
+		Return(&model.User{Model: gorm.Model{ID: 5}, Username: "newbie", Email: "new@example.com"}, nil).Once()
+	suite.userRepo.EXPECT().AddUser(ctx, "newbie", "new@example.com", (*string)(nil)).
+		Return(nil, repository.ErrUserExists).Once()
+
+	_, err := suite.service.AddUser(ctx, connect.NewRequest(&apiv1.AddUserRequest{}))
+	suite.Require().NoError(err)
+
+	_, err = suite.service.AddUser(ctx, connect.NewRequest(&apiv1.AddUserRequest{}))
+
+	suite.Equal(connect.CodeAlreadyExists, server.ErrorCode(err))
+}
+
+func (suite *UserTestSuite) TestAddUser_OtherEmailRejected() {
+	request := &apiv1.AddUserRequest{Name: "imposter", Email: "friend@example.com"}
+	_, err := suite.service.AddUser(suite.signUpContext(), connect.NewRequest(request))
+
+	suite.Equal(connect.CodeInvalidArgument, server.ErrorCode(err))
+}
+
+func (suite *UserTestSuite) TestAddUser_RequiresVerifiedToken() {
+	_, err := suite.service.AddUser(context.Background(), connect.NewRequest(&apiv1.AddUserRequest{Email: "new@example.com"}))
+
+	suite.Require().ErrorIs(err, server.ErrUnauthenticated)
+}
+
+func (suite *UserTestSuite) TestListUsers_ReturnsPage() {
+	suite.userRepo.EXPECT().ListUsers(mock.Anything, repository.Page{Size: 2, Token: "abc"}).
+		Return([]*model.User{suite.admin, suite.friend()}, "next", nil)
+
+	response, err := suite.service.ListUsers(suite.adminContext(), connect.NewRequest(&apiv1.ListUsersRequest{PageSize: 2, PageToken: "abc"}))
+
+	suite.Require().NoError(err)
+	suite.Len(response.Msg.GetUsers(), 2)
+	suite.Equal(friendUUID, response.Msg.GetUsers()[1].GetId())
+	suite.Equal("next", response.Msg.GetNextPageToken())
+}
+
+func (suite *UserTestSuite) TestListUsers_InvalidPageToken() {
+	suite.userRepo.EXPECT().ListUsers(mock.Anything, mock.Anything).Return(nil, "", repository.ErrInvalidPageToken)
+
+	_, err := suite.service.ListUsers(suite.adminContext(), connect.NewRequest(&apiv1.ListUsersRequest{PageToken: "bad"}))
+
+	suite.Equal(connect.CodeInvalidArgument, server.ErrorCode(err))
+}
+
+func (suite *UserTestSuite) TestUpdateUser_UpdatesSetFields() {
+	suite.userRepo.EXPECT().GetUserByUUID(mock.Anything, uuid.MustParse(friendUUID)).Return(suite.friend(), nil)
+	suite.userRepo.EXPECT().UpdateUser(mock.Anything, mock.MatchedBy(func(user *model.User) bool {
+		return user.Username == "friend" && user.FirstName == "Fred" && user.Role == model.UserRoleAdmin
+	})).Return(nil)
+
+	response, err := suite.service.UpdateUser(suite.adminContext(), connect.NewRequest(&apiv1.UpdateUserRequest{
+		Id:        friendUUID,
+		FirstName: pointy.String("Fred"),
+		Role:      apiv1.UserRole_USER_ROLE_ADMIN,
+	}))
+
+	suite.Require().NoError(err)
+	suite.Equal("Fred", response.Msg.GetUser().GetFirstName())
+	suite.Equal(apiv1.UserRole_USER_ROLE_ADMIN, response.Msg.GetUser().GetRole())
+}
+
+func (suite *UserTestSuite) TestUpdateUser_CannotChangeOwnRole() {
+	suite.userRepo.EXPECT().GetUserByUUID(mock.Anything, suite.admin.UUID).Return(suite.admin, nil)
+
+	_, err := suite.service.UpdateUser(suite.adminContext(), connect.NewRequest(&apiv1.UpdateUserRequest{
+		Id:   suite.admin.UUID.String(),
+		Role: apiv1.UserRole_USER_ROLE_USER,
+	}))
+
+	suite.Require().ErrorIs(err, server.ErrInvalidInput)
+}
+
+func (suite *UserTestSuite) TestUpdateUser_NotFound() {
+	suite.userRepo.EXPECT().GetUserByUUID(mock.Anything, uuid.MustParse(friendUUID)).Return(nil, repository.ErrUserNotFound)
+
+	_, err := suite.service.UpdateUser(suite.adminContext(), connect.NewRequest(&apiv1.UpdateUserRequest{Id: friendUUID}))
+
+	suite.Equal(connect.CodeNotFound, server.ErrorCode(err))
+}
+
+func (suite *UserTestSuite) TestDisableUser_DisablesUser() {
+	suite.userRepo.EXPECT().GetUserByUUID(mock.Anything, uuid.MustParse(friendUUID)).Return(suite.friend(), nil)
+	suite.userRepo.EXPECT().SetUserDisabled(mock.Anything, uint(2), true).Return(nil)
+
+	response, err := suite.service.DisableUser(suite.adminContext(), connect.NewRequest(&apiv1.DisableUserRequest{Id: friendUUID, Disabled: true}))
+
+	suite.Require().NoError(err)
+	suite.True(response.Msg.GetUser().GetDisabled())
+}
+
+func (suite *UserTestSuite) TestDisableUser_CannotDisableSelf() {
+	suite.userRepo.EXPECT().GetUserByUUID(mock.Anything, suite.admin.UUID).Return(suite.admin, nil)
+
+	_, err := suite.service.DisableUser(suite.adminContext(), connect.NewRequest(&apiv1.DisableUserRequest{Id: suite.admin.UUID.String(), Disabled: true}))
+
+	suite.Require().ErrorIs(err, server.ErrInvalidInput)
+}
+
+func (suite *UserTestSuite) TestDeleteUser_DeletesUser() {
+	suite.userRepo.EXPECT().GetUserByUUID(mock.Anything, uuid.MustParse(friendUUID)).Return(suite.friend(), nil)
+	suite.userRepo.EXPECT().DeleteUser(mock.Anything, uint(2)).Return(nil)
+
+	_, err := suite.service.DeleteUser(suite.adminContext(), connect.NewRequest(&apiv1.DeleteUserRequest{Id: friendUUID}))
+
+	suite.Require().NoError(err)
+}
+
+func (suite *UserTestSuite) TestDeleteUser_InvalidID() {
+	_, err := suite.service.DeleteUser(suite.adminContext(), connect.NewRequest(&apiv1.DeleteUserRequest{Id: "not-a-uuid"}))
+
+	suite.Require().ErrorIs(err, server.ErrInvalidInput)
+}
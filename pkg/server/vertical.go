@@ -0,0 +1,38 @@
+package server
+
+import (
+	"context"
+
+	"github.com/bufbuild/connect-go"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/server/grpc"
+	api "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+)
+
+// GetBeerVertical lists every vintage of a beer in a cellar with its entries and the current user's tasting notes.
+func (c *CellarServer) GetBeerVertical(ctx context.Context, request *connect.Request[api.GetBeerVerticalRequest]) (*connect.Response[api.GetBeerVerticalResponse], error) {
+	cellarID := uint(request.Msg.GetCellarId())
+
+	_, err := c.authorizeCellar(ctx, cellarID, model.CellarRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	vertical, err := c.cellarRepository.GetBeerVertical(ctx, cellarID, uint(request.Msg.GetBeerId()), user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.GetBeerVerticalResponse{
+		Beer:     grpc.BeerFromModel(vertical.Beer),
+		Vintages: grpc.VerticalVintagesFromModel(vertical.Vintages),
+	}
+
+	return connect.NewResponse(&response), nil
+}
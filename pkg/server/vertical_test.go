@@ -0,0 +1,86 @@
+package server_test
+
+import (
+	"github.com/bufbuild/connect-go"
+	"go.openly.dev/pointy"
+	"gorm.io/gorm"
+
+	"droscher.com/BeerGargoyle/pkg/model"
+	"droscher.com/BeerGargoyle/pkg/repository"
+	"droscher.com/BeerGargoyle/pkg/server"
+	apiv1 "droscher.com/BeerGargoyle/pkg/server/grpc/api/v1"
+)
+
+func (suite *CellarTestSuite) TestGetBeerVertical_ListsVintages() {
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleViewer)
+
+	beer := model.Beer{Model: gorm.Model{ID: 5}, Name: "Bourbon County"}
+	suite.cellarRepo.EXPECT().GetBeerVertical(ctx, uint(1), uint(5), uint(1)).Return(&model.BeerVertical{
+		Beer: beer,
+		Vintages: []model.VerticalVintage{
+			{
+				Vintage: pointy.Uint64(2019),
+				Entries: []*model.CellarEntry{
+					{Model: gorm.Model{ID: 10}, CellarID: 1, BeerID: 5, Vintage: pointy.Uint64(2019), Quantity: 2, Beer: beer},
+					{Model: gorm.Model{ID: 11}, CellarID: 1, BeerID: 5, Vintage: pointy.Uint64(2019), Quantity: 1, Beer: beer},
+				},
+				TastingNotes: []*model.TastingNote{{Model: gorm.Model{ID: 21}, BeerID: 5, Vintage: pointy.Uint64(2019), Beer: beer}},
+			},
+			{Vintage: pointy.Uint64(2020), TastingNotes: []*model.TastingNote{{Model: gorm.Model{ID: 22}, BeerID: 5, Beer: beer}}},
+		},
+	}, nil)
+
+	response, err := suite.service.GetBeerVertical(ctx, connect.NewRequest(&apiv1.GetBeerVerticalRequest{CellarId: 1, BeerId: 5}))
+	suite.Require().NoError(err)
+
+	suite.Equal("Bourbon County", response.Msg.GetBeer().GetName())
+	suite.Require().Len(response.Msg.GetVintages(), 2)
+	suite.Equal(uint64(2019), response.Msg.GetVintages()[0].GetVintage())
+	suite.Equal(int64(3), response.Msg.GetVintages()[0].GetQuantity())
+	suite.Len(response.Msg.GetVintages()[0].GetEntries(), 2)
+	suite.Len(response.Msg.GetVintages()[0].GetTastingNotes(), 1)
+	suite.Equal(int64(0), response.Msg.GetVintages()[1].GetQuantity())
+}
+
+func (suite *CellarTestSuite) TestGetBeerVertical_UnknownBeer() {
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleViewer)
+	suite.cellarRepo.EXPECT().GetBeerVertical(ctx, uint(1), uint(5), uint(1)).Return(nil, repository.ErrBeerNotFound)
+
+	_, err := suite.service.GetBeerVertical(ctx, connect.NewRequest(&apiv1.GetBeerVerticalRequest{CellarId: 1, BeerId: 5}))
+
+	suite.Equal(connect.CodeNotFound, server.ErrorCode(err))
+}
+
+func (suite *CellarTestSuite) TestGetBeerVertical_RequiresCellarAccess() {
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleNone)
+
+	_, err := suite.service.GetBeerVertical(ctx, connect.NewRequest(&apiv1.GetBeerVerticalRequest{CellarId: 1, BeerId: 5}))
+
+	suite.Require().ErrorIs(err, server.ErrPermissionDenied)
+}
+
+func (suite *CellarTestSuite) TestGetCellarStats_ReportsVerticalGaps() {
+	ctx := suite.userContext()
+	suite.expectCellarRole(ctx, 1, model.CellarRoleViewer)
+	suite.cellarRepo.EXPECT().GetCellarStats(ctx, uint(1), uint(0)).Return(&model.CellarStats{
+		CellarID: 1,
+		Verticals: []model.VerticalSummary{
+			{BeerID: 4, BeerName: "Abt 12", Vintages: []uint64{2019, 2020}},
+			{BeerID: 7, BeerName: "Bourbon County", Vintages: []uint64{2018, 2021}, MissingVintages: []uint64{2019, 2020}},
+		},
+	}, nil)
+
+	result, err := suite.service.GetCellarStats(ctx, connect.NewRequest(&apiv1.GetCellarStatsRequest{CellarId: 1}))
+	suite.Require().NoError(err)
+
+	verticals := result.Msg.GetCellarStats().GetVerticals()
+	suite.Require().Len(verticals, 2)
+	suite.True(verticals[0].GetComplete())
+	suite.False(verticals[1].GetComplete())
+	suite.Equal(uint64(2018), verticals[1].GetFirstVintage())
+	suite.Equal(uint64(2021), verticals[1].GetLastVintage())
+	suite.Equal([]uint64{2019, 2020}, verticals[1].GetMissingVintages())
+}